@@ -295,6 +295,13 @@ func IdentityFilterByUsername(username string) func(db *gorm.DB) *gorm.DB {
 	}
 }
 
+// IdentityFilterByUsernames is a gorm filter by a list of 'username'
+func IdentityFilterByUsernames(usernames []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("username IN (?)", usernames)
+	}
+}
+
 // IdentityFilterByProfileURL is a gorm filter by 'profile_url'
 func IdentityFilterByProfileURL(profileURL string) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
@@ -6,7 +6,9 @@ import (
 	"github.com/almighty/almighty-core/auth"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -15,6 +17,7 @@ import (
 type Application interface {
 	WorkItems() workitem.WorkItemRepository
 	WorkItemTypes() workitem.WorkItemTypeRepository
+	WorkItemRevisions() workitem.RevisionRepository
 	Trackers() TrackerRepository
 	TrackerQueries() TrackerQueryRepository
 	SearchItems() SearchRepository
@@ -23,12 +26,15 @@ type Application interface {
 	WorkItemLinkTypes() link.WorkItemLinkTypeRepository
 	WorkItemLinks() link.WorkItemLinkRepository
 	Comments() comment.Repository
+	CommentRevisions() comment.RevisionRepository
+	References() reference.Repository
 	Spaces() space.Repository
 	SpaceResources() space.ResourceRepository
 	Iterations() iteration.Repository
 	Users() account.UserRepository
 	Areas() area.Repository
 	OauthStates() auth.OauthStateReferenceRepository
+	Webhooks() webhook.Repository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
@@ -0,0 +1,166 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/almighty/almighty-core/log"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// bufferKey is the name of the gorm setting holding the events published in a transaction
+const bufferKey = "almighty:events"
+
+// Handler handles an event, with the context of the repository call which published it
+type Handler func(ctx context.Context, e Event)
+
+// TxHandler stores an event published in a transaction with the transaction itself, so that the event is
+// stored if and only if the change which published it is committed
+type TxHandler func(tx *gorm.DB, e Event) error
+
+// Bus dispatches the events to the handlers subscribed to their type
+type Bus struct {
+	mu         sync.RWMutex
+	handlers   map[string][]Handler
+	txHandlers []TxHandler
+}
+
+// NewBus creates a new Bus without any subscriber
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+// DefaultBus is the bus the repositories publish their events to
+var DefaultBus = NewBus()
+
+// Subscribe registers the given handler for the events of the given type
+func (b *Bus) Subscribe(eventType string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], h)
+}
+
+// SubscribeInTransaction registers the given handler for all the events published in a transaction.
+// The handler is called right before the commit: if it fails, the transaction is rolled back.
+func (b *Bus) SubscribeInTransaction(h TxHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.txHandlers = append(b.txHandlers, h)
+}
+
+// Dispatch calls the handlers of the given event, in their order of subscription. A panicking handler
+// is logged and doesn't prevent the other handlers from being called.
+func (b *Bus) Dispatch(ctx context.Context, e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.Type()]
+	b.mu.RUnlock()
+	for _, h := range handlers {
+		call(ctx, h, e)
+	}
+}
+
+func call(ctx context.Context, h Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error(ctx, map[string]interface{}{
+				"eventType": e.Type(),
+				"err":       fmt.Sprint(r),
+			}, "event handler failed")
+		}
+	}()
+	h(ctx, e)
+}
+
+// Subscribe registers the given handler for the events of the given type on the DefaultBus
+func Subscribe(eventType string, h Handler) {
+	DefaultBus.Subscribe(eventType, h)
+}
+
+// SubscribeInTransaction registers the given handler for all the events published in a transaction on the DefaultBus
+func SubscribeInTransaction(h TxHandler) {
+	DefaultBus.SubscribeInTransaction(h)
+}
+
+// published is an event published in a transaction, along with the context of its publication
+type published struct {
+	ctx   context.Context
+	event Event
+}
+
+// buffer holds the events published in a transaction until it is committed
+type buffer struct {
+	mu     sync.Mutex
+	events []published
+}
+
+// WithBuffer returns the given transaction, set up to hold the events published in it
+// until Flush is called after the commit
+func WithBuffer(tx *gorm.DB) *gorm.DB {
+	return tx.Set(bufferKey, &buffer{})
+}
+
+// Publish publishes the given event of a change made with the given database: the event is held
+// until the commit if the database is a transaction created with WithBuffer, otherwise it is
+// dispatched on the DefaultBus right away
+func Publish(ctx context.Context, db *gorm.DB, e Event) {
+	if v, ok := db.Get(bufferKey); ok {
+		if b, ok := v.(*buffer); ok {
+			b.mu.Lock()
+			b.events = append(b.events, published{ctx: ctx, event: e})
+			b.mu.Unlock()
+			return
+		}
+	}
+	DefaultBus.Dispatch(ctx, e)
+}
+
+// BeforeCommit calls the transaction handlers of the DefaultBus with the events held by the given transaction,
+// in their order of publication. It must be called right before the commit of the transaction, which must be
+// rolled back if an error is returned.
+func BeforeCommit(tx *gorm.DB) error {
+	v, ok := tx.Get(bufferKey)
+	if !ok {
+		return nil
+	}
+	b, ok := v.(*buffer)
+	if !ok {
+		return nil
+	}
+	b.mu.Lock()
+	events := append([]published(nil), b.events...)
+	b.mu.Unlock()
+	DefaultBus.mu.RLock()
+	handlers := DefaultBus.txHandlers
+	DefaultBus.mu.RUnlock()
+	for _, p := range events {
+		for _, h := range handlers {
+			if err := h(tx, p.event); err != nil {
+				return errs.Wrapf(err, "failed to handle the %s event", p.event.Type())
+			}
+		}
+	}
+	return nil
+}
+
+// Flush dispatches the events held by the given transaction on the DefaultBus, in their order of publication.
+// It must be called once the transaction is committed.
+func Flush(tx *gorm.DB) {
+	v, ok := tx.Get(bufferKey)
+	if !ok {
+		return
+	}
+	b, ok := v.(*buffer)
+	if !ok {
+		return
+	}
+	b.mu.Lock()
+	events := b.events
+	b.events = nil
+	b.mu.Unlock()
+	for _, p := range events {
+		DefaultBus.Dispatch(p.ctx, p.event)
+	}
+}
@@ -0,0 +1,117 @@
+package event_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/almighty/almighty-core/application/event"
+	"github.com/almighty/almighty-core/resource"
+
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// testEvent has its own type for each test, so that the tests don't see the events of each other on the DefaultBus
+type testEvent struct {
+	eventType string
+	name      string
+}
+
+func (e testEvent) Type() string {
+	return e.eventType
+}
+
+func TestDispatch(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	bus := event.NewBus()
+	var received []string
+	bus.Subscribe(event.TypeWorkItemCreated, func(ctx context.Context, e event.Event) {
+		received = append(received, "first "+e.(event.WorkItemCreated).WorkItemID)
+	})
+	bus.Subscribe(event.TypeWorkItemCreated, func(ctx context.Context, e event.Event) {
+		panic("failed")
+	})
+	bus.Subscribe(event.TypeWorkItemCreated, func(ctx context.Context, e event.Event) {
+		received = append(received, "third "+e.(event.WorkItemCreated).WorkItemID)
+	})
+	bus.Subscribe(event.TypeWorkItemDeleted, func(ctx context.Context, e event.Event) {
+		received = append(received, "deleted")
+	})
+	// when
+	bus.Dispatch(context.Background(), event.WorkItemCreated{WorkItemID: "1", SpaceID: uuid.NewV4()})
+	// then
+	assert.Equal(t, []string{"first 1", "third 1"}, received)
+}
+
+func TestPublishWithoutTransaction(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	eventType := "test-" + uuid.NewV4().String()
+	var received []string
+	event.Subscribe(eventType, func(ctx context.Context, e event.Event) {
+		received = append(received, e.(testEvent).name)
+	})
+	// when
+	event.Publish(context.Background(), &gorm.DB{}, testEvent{eventType: eventType, name: "a"})
+	// then
+	assert.Equal(t, []string{"a"}, received)
+}
+
+func TestPublishInTransaction(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	eventType := "test-" + uuid.NewV4().String()
+	var received []string
+	event.Subscribe(eventType, func(ctx context.Context, e event.Event) {
+		received = append(received, e.(testEvent).name)
+	})
+	tx := event.WithBuffer(&gorm.DB{})
+	// when
+	event.Publish(context.Background(), tx, testEvent{eventType: eventType, name: "a"})
+	event.Publish(context.Background(), tx.Where("id = ?", 1), testEvent{eventType: eventType, name: "b"})
+	// then the events are held until the commit
+	assert.Empty(t, received)
+	event.Flush(tx)
+	assert.Equal(t, []string{"a", "b"}, received)
+	// and dispatched only once
+	event.Flush(tx)
+	assert.Equal(t, []string{"a", "b"}, received)
+}
+
+func TestBeforeCommit(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	eventType := "test-" + uuid.NewV4().String()
+	var stored []string
+	event.SubscribeInTransaction(func(tx *gorm.DB, e event.Event) error {
+		if e.Type() != eventType {
+			return nil
+		}
+		if e.(testEvent).name == "invalid" {
+			return errors.New("invalid event")
+		}
+		stored = append(stored, e.(testEvent).name)
+		return nil
+	})
+	var received []string
+	event.Subscribe(eventType, func(ctx context.Context, e event.Event) {
+		received = append(received, e.(testEvent).name)
+	})
+	tx := event.WithBuffer(&gorm.DB{})
+	event.Publish(context.Background(), tx, testEvent{eventType: eventType, name: "a"})
+	event.Publish(context.Background(), tx, testEvent{eventType: eventType, name: "b"})
+	// when
+	err := event.BeforeCommit(tx)
+	// then the events are stored in the transaction, but not dispatched yet
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, stored)
+	assert.Empty(t, received)
+	// and a failure is returned, so that the transaction is rolled back
+	invalid := event.WithBuffer(&gorm.DB{})
+	event.Publish(context.Background(), invalid, testEvent{eventType: eventType, name: "invalid"})
+	assert.NotNil(t, event.BeforeCommit(invalid))
+}
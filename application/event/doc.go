@@ -0,0 +1,8 @@
+// Package event contains the in-process bus of the domain events published by the repositories
+// (work item created, link created, comment added, iteration started...).
+//
+// The events published within a transaction are held until the transaction is committed, and
+// dropped if it is rolled back, so that the subscribers only see the changes which were stored.
+// The side effects which must be stored along with the change (e.g. the revisions) are still
+// made by the repositories themselves, in the same transaction.
+package event
@@ -0,0 +1,166 @@
+package event
+
+import (
+	uuid "github.com/satori/go.uuid"
+)
+
+// The types of the events published by the repositories
+const (
+	TypeWorkItemCreated  = "WorkItemCreated"
+	TypeWorkItemUpdated  = "WorkItemUpdated"
+	TypeWorkItemDeleted  = "WorkItemDeleted"
+	TypeLinkCreated      = "LinkCreated"
+	TypeLinkUpdated      = "LinkUpdated"
+	TypeLinkDeleted      = "LinkDeleted"
+	TypeCommentAdded     = "CommentAdded"
+	TypeCommentUpdated   = "CommentUpdated"
+	TypeCommentDeleted   = "CommentDeleted"
+	TypeIterationStarted = "IterationStarted"
+	TypeIterationClosed  = "IterationClosed"
+)
+
+// Event is a change of the domain, published by a repository
+type Event interface {
+	// Type returns the type of the event, used to route it to the subscribers
+	Type() string
+}
+
+// WorkItemCreated is published when a work item is created
+type WorkItemCreated struct {
+	WorkItemID string    `json:"workItemID"`
+	SpaceID    uuid.UUID `json:"spaceID"`
+	CreatorID  uuid.UUID `json:"creatorID"`
+}
+
+// Type implements the Event interface
+func (e WorkItemCreated) Type() string {
+	return TypeWorkItemCreated
+}
+
+// WorkItemUpdated is published when the fields of a work item are updated, or when it is reordered or restored
+type WorkItemUpdated struct {
+	WorkItemID string    `json:"workItemID"`
+	SpaceID    uuid.UUID `json:"spaceID"`
+	ModifierID uuid.UUID `json:"modifierID"`
+	// Version is the version of the work item after the update
+	Version int `json:"version"`
+}
+
+// Type implements the Event interface
+func (e WorkItemUpdated) Type() string {
+	return TypeWorkItemUpdated
+}
+
+// WorkItemDeleted is published when a work item is deleted
+type WorkItemDeleted struct {
+	WorkItemID   string    `json:"workItemID"`
+	SpaceID      uuid.UUID `json:"spaceID"`
+	SuppressorID uuid.UUID `json:"suppressorID"`
+}
+
+// Type implements the Event interface
+func (e WorkItemDeleted) Type() string {
+	return TypeWorkItemDeleted
+}
+
+// LinkCreated is published when a link between two work items is created
+type LinkCreated struct {
+	LinkID     uuid.UUID `json:"linkID"`
+	LinkTypeID uuid.UUID `json:"linkTypeID"`
+	SourceID   uint64    `json:"sourceID"`
+	TargetID   uint64    `json:"targetID"`
+	CreatorID  uuid.UUID `json:"creatorID"`
+}
+
+// Type implements the Event interface
+func (e LinkCreated) Type() string {
+	return TypeLinkCreated
+}
+
+// LinkUpdated is published when the source, the target or the type of a link between two work items is updated
+type LinkUpdated struct {
+	LinkID     uuid.UUID `json:"linkID"`
+	LinkTypeID uuid.UUID `json:"linkTypeID"`
+	SourceID   uint64    `json:"sourceID"`
+	TargetID   uint64    `json:"targetID"`
+	ModifierID uuid.UUID `json:"modifierID"`
+}
+
+// Type implements the Event interface
+func (e LinkUpdated) Type() string {
+	return TypeLinkUpdated
+}
+
+// LinkDeleted is published when a link between two work items is deleted, including when one of the
+// work items is deleted
+type LinkDeleted struct {
+	LinkID       uuid.UUID `json:"linkID"`
+	LinkTypeID   uuid.UUID `json:"linkTypeID"`
+	SourceID     uint64    `json:"sourceID"`
+	TargetID     uint64    `json:"targetID"`
+	SuppressorID uuid.UUID `json:"suppressorID"`
+}
+
+// Type implements the Event interface
+func (e LinkDeleted) Type() string {
+	return TypeLinkDeleted
+}
+
+// CommentAdded is published when a comment is added to a work item
+type CommentAdded struct {
+	CommentID uuid.UUID `json:"commentID"`
+	// WorkItemID is the id of the commented work item
+	WorkItemID string    `json:"workItemID"`
+	CreatorID  uuid.UUID `json:"creatorID"`
+}
+
+// Type implements the Event interface
+func (e CommentAdded) Type() string {
+	return TypeCommentAdded
+}
+
+// CommentUpdated is published when the body of a comment is updated
+type CommentUpdated struct {
+	CommentID  uuid.UUID `json:"commentID"`
+	WorkItemID string    `json:"workItemID"`
+	ModifierID uuid.UUID `json:"modifierID"`
+}
+
+// Type implements the Event interface
+func (e CommentUpdated) Type() string {
+	return TypeCommentUpdated
+}
+
+// CommentDeleted is published when a comment is deleted
+type CommentDeleted struct {
+	CommentID    uuid.UUID `json:"commentID"`
+	WorkItemID   string    `json:"workItemID"`
+	SuppressorID uuid.UUID `json:"suppressorID"`
+}
+
+// Type implements the Event interface
+func (e CommentDeleted) Type() string {
+	return TypeCommentDeleted
+}
+
+// IterationStarted is published when the state of an iteration changes to "start"
+type IterationStarted struct {
+	IterationID uuid.UUID `json:"iterationID"`
+	SpaceID     uuid.UUID `json:"spaceID"`
+}
+
+// Type implements the Event interface
+func (e IterationStarted) Type() string {
+	return TypeIterationStarted
+}
+
+// IterationClosed is published when the state of an iteration changes to "close"
+type IterationClosed struct {
+	IterationID uuid.UUID `json:"iterationID"`
+	SpaceID     uuid.UUID `json:"spaceID"`
+}
+
+// Type implements the Event interface
+func (e IterationClosed) Type() string {
+	return TypeIterationClosed
+}
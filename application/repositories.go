@@ -3,6 +3,8 @@ package application
 import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/keyset"
+	"github.com/almighty/almighty-core/workitem"
 
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
@@ -15,18 +17,22 @@ type TrackerRepository interface {
 	Delete(ctx context.Context, ID string) error
 	Create(ctx context.Context, url string, typeID string) (*app.Tracker, error)
 	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.Tracker, error)
+	SetWebhookSecret(ctx context.Context, ID string, secret string) error
 }
 
 // TrackerQueryRepository encapsulate storage & retrieval of tracker queries
 type TrackerQueryRepository interface {
-	Create(ctx context.Context, query string, schedule string, tracker string, spaceID uuid.UUID) (*app.TrackerQuery, error)
+	Create(ctx context.Context, query string, schedule string, tracker string, syncPolicy string, workItemTypeID *uuid.UUID, fieldMappings []*app.TrackerQueryFieldMapping, spaceID uuid.UUID) (*app.TrackerQuery, error)
 	Save(ctx context.Context, tq app.TrackerQuery) (*app.TrackerQuery, error)
 	Load(ctx context.Context, ID string) (*app.TrackerQuery, error)
 	Delete(ctx context.Context, ID string) error
 	List(ctx context.Context) ([]*app.TrackerQuery, error)
+	ListConflicts(ctx context.Context, ID string) ([]*app.TrackerItemConflict, error)
+	ListRuns(ctx context.Context, ID string, limit int) ([]*app.TrackerQueryRun, error)
 }
 
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
-	SearchFullText(ctx context.Context, searchStr string, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFullText(ctx context.Context, searchStr string, filter criteria.Expression, sortKeys []workitem.SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	SearchFullTextByCursor(ctx context.Context, searchStr string, filter criteria.Expression, sortKeys []workitem.SortKey, cursor keyset.Cursor, limit int) ([]*app.WorkItem, *keyset.Page, error)
 }
@@ -2,11 +2,16 @@ package comment
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 
@@ -20,19 +25,21 @@ type Repository interface {
 	Save(ctx context.Context, comment *Comment, modifier uuid.UUID) error
 	Delete(ctx context.Context, commentID uuid.UUID, suppressor uuid.UUID) error
 	List(ctx context.Context, parent string, start *int, limit *int) ([]*Comment, uint64, error)
+	ListByCursor(ctx context.Context, parent string, cursor keyset.Cursor, limit int) ([]*Comment, *keyset.Page, error)
 	Load(ctx context.Context, id uuid.UUID) (*Comment, error)
 	Count(ctx context.Context, parent string) (int, error)
 }
 
 // NewRepository creates a new storage type.
 func NewRepository(db *gorm.DB) Repository {
-	return &GormCommentRepository{db: db, revisionRepository: &GormCommentRevisionRepository{db}}
+	return &GormCommentRepository{db: db, revisionRepository: &GormCommentRevisionRepository{db}, referenceRepository: reference.NewRepository(db)}
 }
 
 // GormCommentRepository is the implementation of the storage interface for Comments.
 type GormCommentRepository struct {
-	db                 *gorm.DB
-	revisionRepository RevisionRepository
+	db                  *gorm.DB
+	revisionRepository  RevisionRepository
+	referenceRepository reference.Repository
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -60,6 +67,21 @@ func (m *GormCommentRepository) Create(ctx context.Context, comment *Comment, cr
 	if err := m.revisionRepository.Create(ctx, creatorID, RevisionTypeCreate, *comment); err != nil {
 		return errs.Wrapf(err, "error while creating comment")
 	}
+	if err := m.updateReferences(ctx, comment); err != nil {
+		return errs.Wrapf(err, "error while creating comment")
+	}
+	payload := map[string]interface{}{
+		"id":        comment.ID,
+		"parentID":  comment.ParentID,
+		"createdBy": comment.CreatedBy,
+		"createdAt": comment.CreatedAt,
+		"body":      comment.Body,
+		"markup":    comment.Markup,
+	}
+	if err := webhook.EnqueueForWorkItem(m.db, comment.ParentID, webhook.EventCommentCreated, payload); err != nil {
+		return errs.Wrapf(err, "error while creating comment")
+	}
+	event.Publish(ctx, m.db, event.CommentAdded{CommentID: comment.ID, WorkItemID: comment.ParentID, CreatorID: creatorID})
 	log.Debug(ctx, map[string]interface{}{
 		"commentID": comment.ID,
 	}, "Comment created!")
@@ -103,6 +125,10 @@ func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment, modi
 	if err := m.revisionRepository.Create(ctx, modifierID, RevisionTypeUpdate, *comment); err != nil {
 		return errs.Wrapf(err, "error while saving work item")
 	}
+	if err := m.updateReferences(ctx, comment); err != nil {
+		return errs.Wrapf(err, "error while saving comment")
+	}
+	event.Publish(ctx, m.db, event.CommentUpdated{CommentID: comment.ID, WorkItemID: c.ParentID, ModifierID: modifierID})
 	log.Debug(ctx, map[string]interface{}{
 		"commentID": comment.ID,
 	}, "Comment updated!")
@@ -110,6 +136,12 @@ func (m *GormCommentRepository) Save(ctx context.Context, comment *Comment, modi
 	return nil
 }
 
+// updateReferences stores the references to work items found in the given comment
+func (m *GormCommentRepository) updateReferences(ctx context.Context, comment *Comment) error {
+	source := reference.Source{WorkItemID: comment.ParentID, CommentID: &comment.ID}
+	return m.referenceRepository.Update(ctx, source, rendering.NewMarkupContent(comment.Body, comment.Markup))
+}
+
 // Delete a single comment
 func (m *GormCommentRepository) Delete(ctx context.Context, commentID uuid.UUID, suppressorID uuid.UUID) error {
 	if commentID == uuid.Nil {
@@ -129,6 +161,7 @@ func (m *GormCommentRepository) Delete(ctx context.Context, commentID uuid.UUID,
 	if err := m.revisionRepository.Create(ctx, suppressorID, RevisionTypeDelete, c); err != nil {
 		return errs.Wrapf(err, "error while deleting work item")
 	}
+	event.Publish(ctx, m.db, event.CommentDeleted{CommentID: c.ID, WorkItemID: c.ParentID, SuppressorID: suppressorID})
 	return nil
 }
 
@@ -202,6 +235,47 @@ func (m *GormCommentRepository) List(ctx context.Context, parent string, start *
 	return result, count, nil
 }
 
+// sortKeys is the order of comments when paging with cursors, newest first
+var sortKeys = []keyset.Key{
+	{Expression: "created_at", Type: "timestamptz", Descending: true},
+	{Expression: "id", Type: "uuid", Descending: true},
+}
+
+// ListByCursor lists at most limit comments related to a single item which
+// come after (or before, for backward cursors) the given cursor.
+// Unlike List, it does not count the comments.
+func (m *GormCommentRepository) ListByCursor(ctx context.Context, parent string, cursor keyset.Cursor, limit int) ([]*Comment, *keyset.Page, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
+	if limit <= 0 {
+		return nil, nil, errors.NewBadParameterError("limit", limit)
+	}
+	where, parameters, err := keyset.Where(sortKeys, cursor)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	// fetch one more comment to find out if there is a next page
+	db := m.db.Model(&Comment{}).Where("parent_id = ?", parent).Where(where, parameters...)
+	db = db.Select("*, " + keyset.Columns(sortKeys)).Order(keyset.Order(sortKeys, cursor.Backward)).Limit(limit + 1)
+
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	values, page, err := keyset.ScanPage(rows, sortKeys, cursor, limit, func(rows *sql.Rows) (interface{}, error) {
+		value := &Comment{}
+		return value, db.ScanRows(rows, value)
+	})
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	result := make([]*Comment, len(values))
+	for i, value := range values {
+		result[i] = value.(*Comment)
+	}
+	return result, page, nil
+}
+
 // Count all comments related to a single item
 func (m *GormCommentRepository) Count(ctx context.Context, parent string) (int, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
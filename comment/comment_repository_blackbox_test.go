@@ -11,6 +11,7 @@ import (
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/rendering"
@@ -221,6 +222,40 @@ func (s *TestCommentRepository) TestListCommentsWrongLimit() {
 	assert.NotNil(s.T(), err)
 }
 
+func (s *TestCommentRepository) TestListCommentsByCursor() {
+	// given
+	comment1 := newComment("A", "Test 1", rendering.SystemMarkupMarkdown)
+	comment2 := newComment("A", "Test 2", rendering.SystemMarkupMarkdown)
+	comment3 := newComment("A", "Test 3", rendering.SystemMarkupMarkdown)
+	s.createComments([]*comment.Comment{comment1, comment2, comment3}, s.testIdentity.ID)
+	// when
+	firstPage, page, err := s.repo.ListByCursor(s.ctx, "A", keyset.Cursor{}, 2)
+	// then newest first
+	require.Nil(s.T(), err)
+	require.Equal(s.T(), 2, len(firstPage))
+	assert.Equal(s.T(), comment3.ID, firstPage[0].ID)
+	assert.Equal(s.T(), comment2.ID, firstPage[1].ID)
+	assert.Nil(s.T(), page.Prev)
+	require.NotNil(s.T(), page.Next)
+	// when
+	secondPage, page, err := s.repo.ListByCursor(s.ctx, "A", *page.Next, 2)
+	// then
+	require.Nil(s.T(), err)
+	require.Equal(s.T(), 1, len(secondPage))
+	assert.Equal(s.T(), comment1.ID, secondPage[0].ID)
+	assert.Nil(s.T(), page.Next)
+	require.NotNil(s.T(), page.Prev)
+	// when
+	prevPage, page, err := s.repo.ListByCursor(s.ctx, "A", *page.Prev, 2)
+	// then
+	require.Nil(s.T(), err)
+	require.Equal(s.T(), 2, len(prevPage))
+	assert.Equal(s.T(), comment3.ID, prevPage[0].ID)
+	assert.Equal(s.T(), comment2.ID, prevPage[1].ID)
+	assert.Nil(s.T(), page.Prev)
+	assert.NotNil(s.T(), page.Next)
+}
+
 func (s *TestCommentRepository) TestLoadComment() {
 	// given
 	comment := newComment("A", "Test A", rendering.SystemMarkupMarkdown)
@@ -47,3 +47,11 @@ const (
 func (w Revision) TableName() string {
 	return revisionTableName
 }
+
+// RevisionCount holds the number of revisions of a comment
+type RevisionCount struct {
+	// the number of all revisions of the comment
+	Total int
+	// the number of revisions which changed the comment after its creation
+	Updates int
+}
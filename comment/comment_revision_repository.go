@@ -19,6 +19,8 @@ type RevisionRepository interface {
 	Create(ctx context.Context, modifierID uuid.UUID, revisionType RevisionType, comment Comment) error
 	// List retrieves all revisions for a given comment
 	List(ctx context.Context, workitemID uuid.UUID) ([]Revision, error)
+	// Count retrieves the number of revisions of the given comments
+	Count(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID]RevisionCount, error)
 }
 
 // NewRevisionRepository creates a GormCommentRevisionRepository
@@ -69,3 +71,34 @@ func (r *GormCommentRevisionRepository) List(ctx context.Context, commentID uuid
 	}
 	return revisions, nil
 }
+
+// Count retrieves the number of revisions of the given comments. Comments without
+// any revision are missing in the result.
+func (r *GormCommentRevisionRepository) Count(ctx context.Context, commentIDs []uuid.UUID) (map[uuid.UUID]RevisionCount, error) {
+	result := map[uuid.UUID]RevisionCount{}
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	ids := make([]string, len(commentIDs))
+	for i, id := range commentIDs {
+		ids[i] = id.String()
+	}
+	rows, err := r.db.Table(revisionTableName).
+		Select("comment_id, count(*), count(case revision_type when ? then 1 else null end)", RevisionTypeUpdate).
+		Where("comment_id IN (?)", ids).
+		Group("comment_id").
+		Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to count comment revisions: %s", err.Error()))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		var count RevisionCount
+		if err := rows.Scan(&id, &count.Total, &count.Updates); err != nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("failed to count comment revisions: %s", err.Error()))
+		}
+		result[id] = count
+	}
+	return result, nil
+}
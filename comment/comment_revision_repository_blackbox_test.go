@@ -17,6 +17,7 @@ import (
 	"github.com/almighty/almighty-core/workitem"
 
 	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -130,3 +131,25 @@ func (s *revisionRepositoryBlackBoxTest) TestStoreCommentRevisions() {
 	assert.Nil(s.T(), revision4.CommentMarkup)
 	assert.Equal(s.T(), s.testIdentity3.ID, revision4.ModifierIdentity)
 }
+
+func (s *revisionRepositoryBlackBoxTest) TestCountCommentRevisions() {
+	// given
+	c1 := newComment("A", "Body", rendering.SystemMarkupMarkdown)
+	err := s.repository.Create(context.Background(), c1, s.testIdentity1.ID)
+	require.Nil(s.T(), err)
+	c1.Body = "Updated body"
+	err = s.repository.Save(context.Background(), c1, s.testIdentity2.ID)
+	require.Nil(s.T(), err)
+	c2 := newComment("A", "Other body", rendering.SystemMarkupMarkdown)
+	err = s.repository.Create(context.Background(), c2, s.testIdentity1.ID)
+	require.Nil(s.T(), err)
+	unknownID := uuid.NewV4()
+	// when
+	counts, err := s.revisionRepository.Count(context.Background(), []uuid.UUID{c1.ID, c2.ID, unknownID})
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), counts, 2)
+	assert.Equal(s.T(), comment.RevisionCount{Total: 2, Updates: 1}, counts[c1.ID])
+	assert.Equal(s.T(), comment.RevisionCount{Total: 1, Updates: 0}, counts[c2.ID])
+	assert.Equal(s.T(), comment.RevisionCount{}, counts[unknownID])
+}
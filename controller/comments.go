@@ -3,6 +3,8 @@ package controller
 import (
 	"html"
 
+	"golang.org/x/net/context"
+
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/comment"
@@ -11,8 +13,16 @@ import (
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
 )
 
+// commentRevisionTypes maps the comment revision types to their names in the REST API
+var commentRevisionTypes = map[comment.RevisionType]string{
+	comment.RevisionTypeCreate: "create",
+	comment.RevisionTypeUpdate: "update",
+	comment.RevisionTypeDelete: "delete",
+}
+
 // CommentsController implements the comments resource.
 type CommentsController struct {
 	*goa.Controller
@@ -33,11 +43,21 @@ func (c *CommentsController) Show(ctx *app.ShowCommentsContext) error {
 			return ctx.NotFound(jerrors)
 		}
 
+		includeRevisionCount, err := loadCommentRevisionCounts(ctx, appl, c)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeReferences, err := loadCommentReferences(ctx, appl, ctx.RequestData, c)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res := &app.CommentSingle{}
 		res.Data = ConvertComment(
 			ctx.RequestData,
 			c,
-			CommentIncludeParentWorkItem())
+			CommentIncludeParentWorkItem(),
+			includeRevisionCount,
+			includeReferences)
 
 		return ctx.OK(res)
 	})
@@ -69,8 +89,16 @@ func (c *CommentsController) Update(ctx *app.UpdateCommentsContext) error {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
 
+		includeRevisionCount, err := loadCommentRevisionCounts(ctx, appl, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeReferences, err := loadCommentReferences(ctx, appl, ctx.RequestData, cm)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res := &app.CommentSingle{
-			Data: ConvertComment(ctx.RequestData, cm, CommentIncludeParentWorkItem()),
+			Data: ConvertComment(ctx.RequestData, cm, CommentIncludeParentWorkItem(), includeRevisionCount, includeReferences),
 		}
 		return ctx.OK(res)
 	})
@@ -102,6 +130,27 @@ func (c *CommentsController) Delete(ctx *app.DeleteCommentsContext) error {
 	})
 }
 
+// Revisions runs the revisions action.
+func (c *CommentsController) Revisions(ctx *app.RevisionsCommentsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		revisions, err := appl.CommentRevisions().List(ctx, ctx.CommentID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if len(revisions) == 0 {
+			// the revisions of deleted comments are still available, so only
+			// check that the comment exists if there is no revision at all
+			if _, err := appl.Comments().Load(ctx, ctx.CommentID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		res := &app.CommentRevisionList{
+			Data: ConvertCommentRevisions(ctx.RequestData, revisions),
+		}
+		return ctx.OK(res)
+	})
+}
+
 // CommentConvertFunc is a open ended function to add additional links/data/relations to a Comment during
 // conversion from internal to API
 type CommentConvertFunc func(*goa.RequestData, *comment.Comment, *app.Comment)
@@ -192,3 +241,88 @@ func CommentIncludeParent(request *goa.RequestData, comment *comment.Comment, da
 		},
 	}
 }
+
+// loadCommentRevisionCounts returns a CommentConvertFunc which adds the revision counts of the given comments
+func loadCommentRevisionCounts(ctx context.Context, appl application.Application, comments ...*comment.Comment) (CommentConvertFunc, error) {
+	ids := make([]uuid.UUID, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+	counts, err := appl.CommentRevisions().Count(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return CommentIncludeRevisionCount(counts), nil
+}
+
+// CommentIncludeRevisionCount adds the "edited" indicator and the number of revisions to the Comment.
+// Comments missing in the given counts are treated as never modified.
+func CommentIncludeRevisionCount(counts map[uuid.UUID]comment.RevisionCount) CommentConvertFunc {
+	return func(request *goa.RequestData, comment *comment.Comment, data *app.Comment) {
+		count := counts[comment.ID]
+		edited := count.Updates > 0
+		data.Attributes.Edited = &edited
+		data.Attributes.RevisionCount = &count.Total
+	}
+}
+
+// ConvertCommentRevisions converts between internal and external REST representation.
+// The revisions must be sorted from the oldest to the newest, so that each revision
+// can be compared with the previous one.
+func ConvertCommentRevisions(request *goa.RequestData, revisions []comment.Revision) []*app.CommentRevision {
+	result := []*app.CommentRevision{}
+	var previous *comment.Revision
+	for i := range revisions {
+		result = append(result, ConvertCommentRevision(request, revisions[i], previous))
+		previous = &revisions[i]
+	}
+	return result
+}
+
+// ConvertCommentRevision converts between internal and external REST representation,
+// rendering the body of the revision and the body of the previous revision (if any)
+func ConvertCommentRevision(request *goa.RequestData, revision comment.Revision, previous *comment.Revision) *app.CommentRevision {
+	commentID := revision.CommentID.String()
+	commentType := "comments"
+	commentSelf := rest.AbsoluteURL(request, app.CommentsHref(commentID))
+	result := &app.CommentRevision{
+		Type: "commentrevisions",
+		ID:   revision.ID,
+		Attributes: &app.CommentRevisionAttributes{
+			RevisionType: commentRevisionTypes[revision.Type],
+			CreatedAt:    revision.Time,
+		},
+		Relationships: &app.CommentRevisionRelations{
+			Modifier: &app.RevisionModifier{
+				Data: &app.IdentityRelationData{
+					Type: "identities",
+					ID:   &revision.ModifierIdentity,
+				},
+			},
+			Comment: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &commentType,
+					ID:   &commentID,
+				},
+				Links: &app.GenericLinks{
+					Self: &commentSelf,
+				},
+			},
+		},
+	}
+	if revision.CommentBody != nil {
+		markup := rendering.NilSafeGetMarkup(revision.CommentMarkup)
+		bodyRendered := rendering.RenderMarkupToHTML(html.EscapeString(*revision.CommentBody), markup)
+		result.Attributes.Body = revision.CommentBody
+		result.Attributes.BodyRendered = &bodyRendered
+		result.Attributes.Markup = &markup
+	}
+	if previous != nil && previous.CommentBody != nil {
+		markup := rendering.NilSafeGetMarkup(previous.CommentMarkup)
+		bodyRendered := rendering.RenderMarkupToHTML(html.EscapeString(*previous.CommentBody), markup)
+		result.Attributes.PreviousBody = previous.CommentBody
+		result.Attributes.PreviousBodyRendered = &bodyRendered
+		result.Attributes.PreviousMarkup = &markup
+	}
+	return result
+}
@@ -160,6 +160,13 @@ func (s *CommentsSuite) validateComment(result *app.CommentSingle, expectedBody
 	assert.Equal(s.T(), s.testIdentity.ID, *result.Data.Relationships.CreatedBy.Data.ID)
 }
 
+func (s *CommentsSuite) validateRevisionCount(result *app.CommentSingle, expectedEdited bool, expectedRevisionCount int) {
+	require.NotNil(s.T(), result.Data.Attributes.Edited)
+	assert.Equal(s.T(), expectedEdited, *result.Data.Attributes.Edited)
+	require.NotNil(s.T(), result.Data.Attributes.RevisionCount)
+	assert.Equal(s.T(), expectedRevisionCount, *result.Data.Attributes.RevisionCount)
+}
+
 func (s *CommentsSuite) TestShowCommentWithoutAuth() {
 	// given
 	workitemId := s.createWorkItem(s.testIdentity)
@@ -169,6 +176,7 @@ func (s *CommentsSuite) TestShowCommentWithoutAuth() {
 	_, result := test.ShowCommentsOK(s.T(), userSvc.Context, userSvc, commentsCtrl, commentId)
 	// then
 	s.validateComment(result, "body", rendering.SystemMarkupMarkdown)
+	s.validateRevisionCount(result, false, 1)
 }
 
 func (s *CommentsSuite) TestShowCommentWithoutAuthWithMarkup() {
@@ -223,6 +231,7 @@ func (s *CommentsSuite) TestUpdateCommentWithSameUserWithOtherMarkup() {
 	userSvc, _, _, commentsCtrl := s.securedControllers(s.testIdentity)
 	_, result := test.UpdateCommentsOK(s.T(), userSvc.Context, userSvc, commentsCtrl, commentId, updateCommentPayload)
 	s.validateComment(result, "updated body", rendering.SystemMarkupMarkdown)
+	s.validateRevisionCount(result, true, 2)
 }
 
 func (s *CommentsSuite) TestUpdateCommentWithSameUserWithNilMarkup() {
@@ -278,3 +287,36 @@ func (s *CommentsSuite) TestDeleteCommentWithOtherAuthenticatedUser() {
 	userSvc, _, _, commentsCtrl := s.securedControllers(s.testIdentity2)
 	test.DeleteCommentsForbidden(s.T(), userSvc.Context, userSvc, commentsCtrl, commentId)
 }
+
+func (s *CommentsSuite) TestListCommentRevisions() {
+	// given
+	workitemId := s.createWorkItem(s.testIdentity)
+	commentId := s.createWorkItemComment(s.testIdentity, workitemId, "body", &plaintextMarkup)
+	updateCommentPayload := s.newUpdateCommentsPayload("*updated* body", &markdownMarkup)
+	userSvc, _, _, commentsCtrl := s.securedControllers(s.testIdentity)
+	test.UpdateCommentsOK(s.T(), userSvc.Context, userSvc, commentsCtrl, commentId, updateCommentPayload)
+	// when
+	userSvc, commentsCtrl = s.unsecuredController()
+	_, result := test.RevisionsCommentsOK(s.T(), userSvc.Context, userSvc, commentsCtrl, commentId)
+	// then
+	require.Len(s.T(), result.Data, 2)
+	created := result.Data[0]
+	assert.Equal(s.T(), "create", created.Attributes.RevisionType)
+	assert.Equal(s.T(), "body", *created.Attributes.Body)
+	assert.Nil(s.T(), created.Attributes.PreviousBody)
+	assert.Nil(s.T(), created.Attributes.PreviousBodyRendered)
+	updated := result.Data[1]
+	assert.Equal(s.T(), "update", updated.Attributes.RevisionType)
+	assert.Equal(s.T(), rendering.RenderMarkupToHTML("*updated* body", markdownMarkup), *updated.Attributes.BodyRendered)
+	assert.Equal(s.T(), markdownMarkup, *updated.Attributes.Markup)
+	assert.Equal(s.T(), rendering.RenderMarkupToHTML("body", plaintextMarkup), *updated.Attributes.PreviousBodyRendered)
+	assert.Equal(s.T(), plaintextMarkup, *updated.Attributes.PreviousMarkup)
+	require.NotNil(s.T(), updated.Relationships.Modifier.Data.ID)
+	assert.Equal(s.T(), s.testIdentity.ID, *updated.Relationships.Modifier.Data.ID)
+	assert.Equal(s.T(), commentId.String(), *updated.Relationships.Comment.Data.ID)
+}
+
+func (s *CommentsSuite) TestListCommentRevisionsNotFound() {
+	userSvc, commentsCtrl := s.unsecuredController()
+	test.RevisionsCommentsNotFound(s.T(), userSvc.Context, userSvc, commentsCtrl, uuid.NewV4())
+}
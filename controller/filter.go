@@ -1,11 +1,79 @@
 package controller
 
 import (
+	"strings"
+
+	"golang.org/x/net/context"
+
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/query"
 	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 )
 
+// workItemQueryAliases maps the short field names that can be used in work item
+// queries to the names of the work item fields
+var workItemQueryAliases = map[string]string{
+	"id":        "ID",
+	"type":      "Type",
+	"title":     workitem.SystemTitle,
+	"state":     workitem.SystemState,
+	"assignee":  workitem.SystemAssignees,
+	"assignees": workitem.SystemAssignees,
+	"creator":   workitem.SystemCreator,
+	"iteration": workitem.SystemIteration,
+	"area":      workitem.SystemArea,
+	"created":   workitem.SystemCreatedAt,
+	"updated":   workitem.SystemUpdatedAt,
+}
+
+// parseWorkItemQuery parses the given work item query (see package query).
+// The keyword "me" refers to the identity of the current user.
+func parseWorkItemQuery(ctx context.Context, q *string) (criteria.Expression, error) {
+	p := query.Parser{
+		Aliases:    workItemQueryAliases,
+		ListFields: map[string]bool{workitem.SystemAssignees: true},
+	}
+	if q == nil || len(strings.TrimSpace(*q)) == 0 {
+		return p.Parse("")
+	}
+	if currentUserIdentityID, err := login.ContextIdentity(ctx); err == nil {
+		p.Me = currentUserIdentityID.String()
+	}
+	return p.Parse(*q)
+}
+
+// parseWorkItemSort parses a JSON-API style sort parameter, a comma separated
+// list of field names that may be prefixed with "-" for descending order,
+// e.g. "-created,system.title"
+func parseWorkItemSort(sort *string) ([]workitem.SortKey, error) {
+	if sort == nil || len(strings.TrimSpace(*sort)) == 0 {
+		return nil, nil
+	}
+	result := []workitem.SortKey{}
+	for _, field := range strings.Split(*sort, ",") {
+		field = strings.TrimSpace(field)
+		key := workitem.SortKey{}
+		if strings.HasPrefix(field, "-") {
+			key.Descending = true
+			field = field[1:]
+		}
+		if len(field) == 0 || strings.ContainsAny(field, "'\" ()") {
+			return nil, errors.NewBadParameterError("sort", *sort).Expected("comma separated list of field names")
+		}
+		if alias, ok := workItemQueryAliases[field]; ok {
+			field = alias
+		}
+		key.Field = field
+		result = append(result, key)
+	}
+	return result, nil
+}
+
 // FilterController implements the filter resource.
 type FilterController struct {
 	*goa.Controller
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -114,6 +115,26 @@ func setPagingLinks(links *app.PagingLinks, path string, resultLen, offset, limi
 	links.Last = &last
 }
 
+// setCursorPagingLinks sets the links of a page fetched with a cursor. There
+// is no last link since the number of items is not known.
+func setCursorPagingLinks(links *app.PagingLinks, path string, limit int, page *keyset.Page, additionalQuery ...string) {
+	var additional string
+	if len(additionalQuery) > 0 {
+		additional = "&" + strings.Join(additionalQuery, "&")
+	}
+	link := func(cursor string) *string {
+		result := fmt.Sprintf("%s?page[cursor]=%s&page[limit]=%d%s", path, cursor, limit, additional)
+		return &result
+	}
+	links.First = link("")
+	if page.Prev != nil {
+		links.Prev = link(page.Prev.String())
+	}
+	if page.Next != nil {
+		links.Next = link(page.Next.String())
+	}
+}
+
 func buildAbsoluteURL(req *goa.RequestData) string {
 	return rest.AbsoluteURL(req, req.URL.Path)
 }
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"html"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// loadReferences resolves the work items ("#123") and the users ("@username") referenced in
+// the given contents, with one query for the work items and one for the users. References to
+// unknown work items or users are left out.
+func loadReferences(ctx context.Context, appl application.Application, request *goa.RequestData, contents ...rendering.MarkupContent) (rendering.ResolvedReferences, error) {
+	result := rendering.ResolvedReferences{
+		WorkItems: map[string]rendering.WorkItemReference{},
+		Users:     map[string]rendering.UserReference{},
+	}
+	// the references of each work item id, e.g. "#7" and "#007"
+	workItemReferences := map[uint64][]string{}
+	usernames := map[string]bool{}
+	for _, content := range contents {
+		references := rendering.ExtractReferences(content.Content, content.Markup)
+		for _, id := range references.WorkItemIDs {
+			if wiID, err := strconv.ParseUint(id, 10, 64); err == nil {
+				workItemReferences[wiID] = append(workItemReferences[wiID], id)
+			}
+		}
+		for _, username := range references.Usernames {
+			usernames[username] = true
+		}
+	}
+	if len(workItemReferences) > 0 {
+		var ids []criteria.Expression
+		for id := range workItemReferences {
+			ids = append(ids, criteria.Literal(id))
+		}
+		start, limit := 0, len(ids)
+		wis, _, err := appl.WorkItems().List(ctx, criteria.In(criteria.Field("ID"), ids...), nil, &start, &limit)
+		if err != nil {
+			return result, errs.Wrap(err, "failed to load the referenced work items")
+		}
+		for _, wi := range wis {
+			wiID, err := strconv.ParseUint(wi.ID, 10, 64)
+			if err != nil {
+				return result, errors.NewInternalError(err.Error())
+			}
+			title, _ := wi.Fields[workitem.SystemTitle].(string)
+			for _, id := range workItemReferences[wiID] {
+				result.WorkItems[id] = rendering.WorkItemReference{
+					URL:   rest.AbsoluteURL(request, app.WorkitemHref(id)),
+					Title: title,
+				}
+			}
+		}
+	}
+	if len(usernames) > 0 {
+		var names []string
+		for username := range usernames {
+			names = append(names, username)
+		}
+		identities, err := appl.Identities().Query(account.IdentityFilterByUsernames(names), account.IdentityWithUser())
+		if err != nil {
+			return result, errs.Wrap(err, "failed to load the mentioned users")
+		}
+		for _, identity := range identities {
+			if _, ok := result.Users[identity.Username]; ok {
+				continue
+			}
+			result.Users[identity.Username] = rendering.UserReference{
+				URL:      rest.AbsoluteURL(request, app.UsersHref(identity.ID.String())),
+				FullName: identity.User.FullName,
+				ImageURL: identity.User.ImageURL,
+			}
+		}
+	}
+	return result, nil
+}
+
+// loadWorkItemReferences returns a WorkItemConvertFunc which renders the descriptions of the
+// given work items with links to the work items and users they reference
+func loadWorkItemReferences(ctx context.Context, appl application.Application, request *goa.RequestData, wis ...*app.WorkItem) (WorkItemConvertFunc, error) {
+	var contents []rendering.MarkupContent
+	for _, wi := range wis {
+		if description := rendering.NewMarkupContentFromValue(wi.Fields[workitem.SystemDescription]); description != nil {
+			contents = append(contents, *description)
+		}
+	}
+	references, err := loadReferences(ctx, appl, request, contents...)
+	if err != nil {
+		return nil, err
+	}
+	return WorkItemIncludeReferences(references), nil
+}
+
+// WorkItemIncludeReferences renders the description of the WorkItem with links to the given work items and users
+func WorkItemIncludeReferences(references rendering.ResolvedReferences) WorkItemConvertFunc {
+	return func(request *goa.RequestData, wi *app.WorkItem, data *app.WorkItem2) {
+		description := rendering.NewMarkupContentFromValue(wi.Fields[workitem.SystemDescription])
+		if description == nil {
+			return
+		}
+		data.Attributes[workitem.SystemDescriptionRendered] =
+			rendering.RenderMarkupToHTMLWithReferences(html.EscapeString(description.Content), description.Markup, references)
+	}
+}
+
+// loadCommentReferences returns a CommentConvertFunc which renders the bodies of the given
+// comments with links to the work items and users they reference
+func loadCommentReferences(ctx context.Context, appl application.Application, request *goa.RequestData, comments ...*comment.Comment) (CommentConvertFunc, error) {
+	contents := make([]rendering.MarkupContent, len(comments))
+	for i, c := range comments {
+		contents[i] = rendering.NewMarkupContent(c.Body, c.Markup)
+	}
+	references, err := loadReferences(ctx, appl, request, contents...)
+	if err != nil {
+		return nil, err
+	}
+	return CommentIncludeReferences(references), nil
+}
+
+// CommentIncludeReferences renders the body of the Comment with links to the given work items and users
+func CommentIncludeReferences(references rendering.ResolvedReferences) CommentConvertFunc {
+	return func(request *goa.RequestData, comment *comment.Comment, data *app.Comment) {
+		bodyRendered := rendering.RenderMarkupToHTMLWithReferences(html.EscapeString(comment.Body), comment.Markup, references)
+		data.Attributes.BodyRendered = &bodyRendered
+	}
+}
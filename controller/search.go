@@ -2,11 +2,13 @@ package controller
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/log"
 	"github.com/almighty/almighty-core/search"
 	"github.com/almighty/almighty-core/space"
@@ -39,6 +41,21 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 	var limit int
 
 	offset, limit = computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	filter, err := parseWorkItemQuery(ctx, ctx.Filter)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
+	}
+	additionalQuery := []string{"q=" + ctx.Q}
+	if ctx.Filter != nil {
+		additionalQuery = append(additionalQuery, "filter="+url.QueryEscape(*ctx.Filter))
+	}
+	sortKeys, err := parseWorkItemSort(ctx.Sort)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if ctx.Sort != nil {
+		additionalQuery = append(additionalQuery, "sort="+url.QueryEscape(*ctx.Sort))
+	}
 
 	// ToDo : Keep URL registeration central somehow.
 	hostString := ctx.RequestData.Host
@@ -52,7 +69,23 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 
 	return application.Transactional(c.db, func(appl application.Application) error {
 		//return transaction.Do(c.ts, func() error {
-		result, c, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, &offset, &limit)
+		if ctx.PageCursor != nil {
+			cursor, err := keyset.Parse(*ctx.PageCursor)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			result, page, err := appl.SearchItems().SearchFullTextByCursor(ctx.Context, ctx.Q, filter, sortKeys, *cursor, limit)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error listing work items"))
+			}
+			response := app.SearchWorkItemList{
+				Links: &app.PagingLinks{},
+				Data:  ConvertWorkItems(ctx.RequestData, result),
+			}
+			setCursorPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), limit, page, additionalQuery...)
+			return ctx.OK(&response)
+		}
+		result, c, err := appl.SearchItems().SearchFullText(ctx.Context, ctx.Q, filter, sortKeys, &offset, &limit)
 		count := int(c)
 		if err != nil {
 			cause := errs.Cause(err)
@@ -75,7 +108,7 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 			Data:  ConvertWorkItems(ctx.RequestData, result),
 		}
 
-		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, "q="+ctx.Q)
+		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
 		return ctx.OK(&response)
 	})
 }
@@ -102,7 +102,7 @@ func (s *searchBlackBoxTest) TestSearchWorkItems() {
 	require.Nil(s.T(), err)
 	// when
 	q := "specialwordforsearch"
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	require.NotEmpty(s.T(), sr.Data)
 	r := sr.Data[0]
@@ -125,7 +125,7 @@ func (s *searchBlackBoxTest) TestSearchPagination() {
 	require.Nil(s.T(), err)
 	// when
 	q := "specialwordforsearch2"
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	// defaults in paging.go is 'pageSizeDefault = 20'
 	assert.Equal(s.T(), "http:///api/search?page[offset]=0&page[limit]=20&q=specialwordforsearch2", *sr.Links.First)
@@ -150,7 +150,7 @@ func (s *searchBlackBoxTest) TestSearchWithEmptyValue() {
 	require.Nil(s.T(), err)
 	// when
 	q := ""
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	require.NotNil(s.T(), sr.Data)
 	assert.Empty(s.T(), sr.Data)
@@ -172,7 +172,7 @@ func (s *searchBlackBoxTest) TestSearchWithDomainPortCombination() {
 	require.Nil(s.T(), err)
 	// when
 	q := `"http://localhost:8080/detail/154687364529310"`
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	require.NotEmpty(s.T(), sr.Data)
 	r := sr.Data[0]
@@ -196,7 +196,7 @@ func (s *searchBlackBoxTest) TestSearchURLWithoutPort() {
 	require.Nil(s.T(), err)
 	// when
 	q := `"http://localhost/detail/876394"`
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	require.NotEmpty(s.T(), sr.Data)
 	r := sr.Data[0]
@@ -220,7 +220,7 @@ func (s *searchBlackBoxTest) TestUnregisteredURLWithPort() {
 	require.Nil(s.T(), err)
 	// when
 	q := `http://some-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	require.NotEmpty(s.T(), sr.Data)
 	r := sr.Data[0]
@@ -245,7 +245,7 @@ func (s *searchBlackBoxTest) TestUnwantedCharactersRelatedToSearchLogic() {
 	// when
 	// add url: in the query, that is not expected by the code hence need to make sure it gives expected result.
 	q := `http://url:some-random-other-domain:8080/different-path/`
-	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, q)
+	_, sr := test.ShowSearchOK(s.T(), nil, nil, s.controller, nil, nil, nil, nil, q, nil)
 	// then
 	require.NotNil(s.T(), sr.Data)
 	assert.Empty(s.T(), sr.Data)
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeDependencies is the type of the dependency analysis resource
+const APIStringTypeDependencies = "dependencies"
+
+// SpaceDependenciesController implements the space-dependencies resource.
+type SpaceDependenciesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceDependenciesController creates a space-dependencies controller.
+func NewSpaceDependenciesController(service *goa.Service, db application.DB) *SpaceDependenciesController {
+	return &SpaceDependenciesController{Controller: service.NewController("SpaceDependenciesController"), db: db}
+}
+
+// Show runs the show action.
+func (c *SpaceDependenciesController) Show(ctx *app.ShowSpaceDependenciesContext) error {
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	var iterationID *uuid.UUID
+	if ctx.FilterIteration != nil {
+		id, err := uuid.FromString(*ctx.FilterIteration)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter[iteration]", *ctx.FilterIteration).Expected("uuid"))
+		}
+		iterationID = &id
+	}
+	var weightField string
+	if ctx.Weight != nil {
+		weightField = *ctx.Weight
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.Spaces().Load(ctx, spaceID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if iterationID != nil {
+			iteration, err := appl.Iterations().Load(ctx, *iterationID)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if !uuid.Equal(iteration.SpaceID, spaceID) {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewNotFoundError("iteration", iterationID.String()))
+			}
+		}
+		analysis, err := appl.WorkItemLinks().AnalyzeDependencies(ctx, spaceID, iterationID, weightField)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK(&app.DependenciesSingle{
+			Data: ConvertDependencies(spaceID, *analysis),
+		})
+	})
+}
+
+// ConvertDependencies converts the analysis of the dependencies of a space from internal to external REST representation
+func ConvertDependencies(spaceID uuid.UUID, analysis link.DependencyAnalysis) *app.Dependencies {
+	blocked := make(map[string][]string, len(analysis.Blocked))
+	for id, blockers := range analysis.Blocked {
+		blocked[strconv.FormatUint(id, 10)] = convertWorkItemIDs(blockers)
+	}
+	cycles := make([][]string, len(analysis.Cycles))
+	for i, cycle := range analysis.Cycles {
+		cycles[i] = convertWorkItemIDs(cycle)
+	}
+	return &app.Dependencies{
+		Type: APIStringTypeDependencies,
+		ID:   spaceID,
+		Attributes: &app.DependenciesAttributes{
+			Order:              convertWorkItemIDs(analysis.Order),
+			Blocked:            blocked,
+			CriticalPath:       convertWorkItemIDs(analysis.CriticalPath),
+			CriticalPathWeight: analysis.CriticalPathWeight,
+			Cycles:             cycles,
+		},
+	}
+}
+
+func convertWorkItemIDs(ids []uint64) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = strconv.FormatUint(id, 10)
+	}
+	return result
+}
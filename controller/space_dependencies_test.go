@@ -0,0 +1,157 @@
+package controller_test
+
+import (
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app/test"
+	. "github.com/almighty/almighty-core/controller"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceDependenciesREST struct {
+	gormtestsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	ctx   context.Context
+	clean func()
+	// the work items, the first one blocks the second one, which blocks the third one
+	spaceID     uuid.UUID
+	workItemIDs []string
+}
+
+func TestRunSpaceDependenciesREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceDependenciesREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (rest *TestSpaceDependenciesREST) SetupSuite() {
+	rest.DBTestSuite.SetupSuite()
+	rest.ctx = migration.NewMigrationContext(context.Background())
+	// Make sure the database is populated with the correct types (e.g. bug etc.)
+	if err := models.Transactional(rest.DB, func(tx *gorm.DB) error {
+		return migration.PopulateCommonTypes(rest.ctx, tx, workitem.NewWorkItemTypeRepository(tx))
+	}); err != nil {
+		panic(err.Error())
+	}
+}
+
+func (rest *TestSpaceDependenciesREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+	testSpace, err := space.NewRepository(rest.DB).Create(rest.ctx, &space.Space{
+		Name: "test-space" + uuid.NewV4().String(),
+	})
+	require.Nil(rest.T(), err)
+	rest.spaceID = testSpace.ID
+	workItemRepository := workitem.NewWorkItemRepository(rest.DB)
+	rest.workItemIDs = nil
+	var ids []uint64
+	for i, state := range []string{workitem.SystemStateInProgress, workitem.SystemStateNew, workitem.SystemStateNew} {
+		wi, err := workItemRepository.Create(rest.ctx, testSpace.ID, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: "Bug " + strconv.Itoa(i),
+				workitem.SystemState: state,
+			}, testsupport.TestIdentity.ID)
+		require.Nil(rest.T(), err)
+		rest.workItemIDs = append(rest.workItemIDs, wi.ID)
+		id, err := strconv.ParseUint(wi.ID, 10, 64)
+		require.Nil(rest.T(), err)
+		ids = append(ids, id)
+	}
+	categoryName := "test-category" + uuid.NewV4().String()
+	linkCategory, err := link.NewWorkItemLinkCategoryRepository(rest.DB).Create(rest.ctx, &categoryName, nil)
+	require.Nil(rest.T(), err)
+	linkType, err := link.NewWorkItemLinkTypeRepository(rest.DB).Create(rest.ctx, "test-blocker"+uuid.NewV4().String(), nil, workitem.SystemBug, workitem.SystemBug, "blocks", "blocked by", link.TopologyDependency, *linkCategory.Data.ID, testSpace.ID)
+	require.Nil(rest.T(), err)
+	linkRepository := link.NewWorkItemLinkRepository(rest.DB)
+	_, err = linkRepository.Create(rest.ctx, ids[0], ids[1], *linkType.Data.ID, testsupport.TestIdentity.ID)
+	require.Nil(rest.T(), err)
+	_, err = linkRepository.Create(rest.ctx, ids[1], ids[2], *linkType.Data.ID, testsupport.TestIdentity.ID)
+	require.Nil(rest.T(), err)
+}
+
+func (rest *TestSpaceDependenciesREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceDependenciesREST) UnSecuredController() (*goa.Service, *SpaceDependenciesController, *WorkitemController) {
+	svc := goa.New("SpaceDependencies-Service")
+	return svc, NewSpaceDependenciesController(svc, rest.db), NewWorkitemController(svc, rest.db)
+}
+
+func (rest *TestSpaceDependenciesREST) TestShowDependencies() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	svc, ctrl, _ := rest.UnSecuredController()
+	// when
+	_, dependencies := test.ShowSpaceDependenciesOK(t, svc.Context, svc, ctrl, rest.spaceID.String(), nil, nil)
+	// then
+	require.NotNil(t, dependencies.Data)
+	assert.Equal(t, rest.spaceID, dependencies.Data.ID)
+	attributes := dependencies.Data.Attributes
+	assert.Equal(t, rest.workItemIDs, attributes.Order)
+	assert.Equal(t, rest.workItemIDs, attributes.CriticalPath)
+	assert.Equal(t, map[string][]string{
+		rest.workItemIDs[1]: {rest.workItemIDs[0]},
+		rest.workItemIDs[2]: {rest.workItemIDs[1]},
+	}, attributes.Blocked)
+	assert.Empty(t, attributes.Cycles)
+}
+
+func (rest *TestSpaceDependenciesREST) TestShowDependenciesOfEmptyIteration() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	svc, ctrl, _ := rest.UnSecuredController()
+	itr := iteration.Iteration{Name: "Sprint " + uuid.NewV4().String(), SpaceID: rest.spaceID}
+	require.Nil(t, iteration.NewIterationRepository(rest.DB).Create(rest.ctx, &itr))
+	iterationID := itr.ID.String()
+	// when
+	_, dependencies := test.ShowSpaceDependenciesOK(t, svc.Context, svc, ctrl, rest.spaceID.String(), &iterationID, nil)
+	// then
+	assert.Empty(t, dependencies.Data.Attributes.Order)
+	assert.Empty(t, dependencies.Data.Attributes.Blocked)
+}
+
+func (rest *TestSpaceDependenciesREST) TestShowDependenciesNotFound() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	svc, ctrl, _ := rest.UnSecuredController()
+	// when/then
+	test.ShowSpaceDependenciesNotFound(t, svc.Context, svc, ctrl, uuid.NewV4().String(), nil, nil)
+}
+
+func (rest *TestSpaceDependenciesREST) TestShowWorkItemBlocked() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	svc, _, workItemCtrl := rest.UnSecuredController()
+	// when
+	_, first := test.ShowWorkitemOK(t, svc.Context, svc, workItemCtrl, rest.workItemIDs[0])
+	_, second := test.ShowWorkitemOK(t, svc.Context, svc, workItemCtrl, rest.workItemIDs[1])
+	// then
+	assert.Equal(t, false, first.Data.Attributes[WorkItemAttributeBlocked])
+	assert.Equal(t, true, second.Data.Attributes[WorkItemAttributeBlocked])
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/log"
+
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+const (
+	// eventStreamBatchSize is the maximum number of events read at once for a stream
+	eventStreamBatchSize = 100
+	// eventStreamHeartbeatInterval is the time after which a comment is sent on an idle stream
+	eventStreamHeartbeatInterval = 30 * time.Second
+)
+
+// SpaceEventsController implements the space-events resource.
+type SpaceEventsController struct {
+	*goa.Controller
+	db  application.DB
+	hub *eventstream.Hub
+}
+
+// NewSpaceEventsController creates a space-events controller.
+func NewSpaceEventsController(service *goa.Service, db application.DB, hub *eventstream.Hub) *SpaceEventsController {
+	return &SpaceEventsController{Controller: service.NewController("SpaceEventsController"), db: db, hub: hub}
+}
+
+// Stream runs the stream action: it streams the changes of the space until the client disconnects.
+// Only the changes of the entities which the caller can see are streamed, see visibleEvent.
+func (c *SpaceEventsController) Stream(ctx *app.StreamSpaceEventsContext) error {
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		_, err := appl.Spaces().Load(ctx, spaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	flusher, ok := ctx.ResponseData.ResponseWriter.(http.Flusher)
+	if !ok {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal("streaming is not supported"))
+	}
+	// subscribe before reading the events, so that no event recorded meanwhile is missed
+	notifications, unsubscribe := c.hub.Subscribe(spaceID)
+	defer unsubscribe()
+	var last eventstream.Position
+	if ctx.LastEventID != nil {
+		if last, err = eventstream.ParsePosition(*ctx.LastEventID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, goa.ErrBadRequest(err.Error()))
+		}
+	} else if last, err = c.hub.Latest(spaceID); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
+	}
+	var closed <-chan bool
+	if notifier, ok := ctx.ResponseData.ResponseWriter.(http.CloseNotifier); ok {
+		closed = notifier.CloseNotify()
+	}
+
+	header := ctx.ResponseData.Header()
+	header.Set("Content-Type", eventstream.ContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.ResponseData.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	heartbeat := time.NewTicker(eventStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		records, err := c.hub.Since(spaceID, last, eventStreamBatchSize)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"spaceID": spaceID,
+				"err":     err,
+			}, "unable to read the events of the space")
+			return nil
+		}
+		var visible []eventstream.Record
+		err = application.Transactional(c.db, func(appl application.Application) error {
+			for _, r := range records {
+				ok, err := visibleEvent(ctx, appl, r)
+				if err != nil {
+					return err
+				}
+				if ok {
+					visible = append(visible, r)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"spaceID": spaceID,
+				"err":     err,
+			}, "unable to check the visibility of the events of the space")
+			return nil
+		}
+		for _, r := range visible {
+			if err := eventstream.WriteEvent(ctx.ResponseData, r); err != nil {
+				return nil
+			}
+		}
+		if len(records) > 0 {
+			last = records[len(records)-1].Position()
+		}
+		flusher.Flush()
+		if len(records) == eventStreamBatchSize {
+			continue
+		}
+		select {
+		case <-closed:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-notifications:
+		case <-heartbeat.C:
+			if err := eventstream.WriteHeartbeat(ctx.ResponseData); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// visibleEvent returns true if the caller can see the entity of the given event, as with the show action
+// of the entity: the created or updated work item, link or comment must still exist, and the work item of
+// a deleted link or comment must still exist. The deletions of the work items of the space are visible.
+func visibleEvent(ctx context.Context, appl application.Application, r eventstream.Record) (bool, error) {
+	var err error
+	switch r.EventType {
+	case eventstream.EventWorkItemCreated, eventstream.EventWorkItemUpdated:
+		_, err = appl.WorkItems().Load(ctx, r.EntityID)
+	case eventstream.EventWorkItemDeleted:
+		return true, nil
+	case eventstream.EventLinkCreated, eventstream.EventLinkUpdated, eventstream.EventCommentCreated, eventstream.EventCommentUpdated:
+		var entityID uuid.UUID
+		if entityID, err = uuid.FromString(r.EntityID); err != nil {
+			return false, errors.NewInternalError(err.Error())
+		}
+		if r.EventType == eventstream.EventLinkCreated || r.EventType == eventstream.EventLinkUpdated {
+			_, err = appl.WorkItemLinks().Load(ctx, entityID)
+		} else {
+			_, err = appl.Comments().Load(ctx, entityID)
+		}
+	case eventstream.EventLinkDeleted, eventstream.EventCommentDeleted:
+		var data struct {
+			WorkItemID string `json:"workItemID"`
+			SourceID   uint64 `json:"sourceID"`
+		}
+		if err = json.Unmarshal([]byte(r.Data), &data); err != nil {
+			return false, errors.NewInternalError(err.Error())
+		}
+		workItemID := data.WorkItemID
+		if r.EventType == eventstream.EventLinkDeleted {
+			workItemID = strconv.FormatUint(data.SourceID, 10)
+		}
+		_, err = appl.WorkItems().Load(ctx, workItemID)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,152 @@
+package controller_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application/event"
+	. "github.com/almighty/almighty-core/controller"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// streamDuration is how long the tests read a stream before disconnecting
+const streamDuration = 500 * time.Millisecond
+
+type TestSpaceEventsREST struct {
+	gormtestsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	hub   *eventstream.Hub
+	ctx   context.Context
+	clean func()
+	// the events recorded by the test, oldest first, and the position of the stream before them
+	spaceID uuid.UUID
+	start   eventstream.Position
+	records []eventstream.Record
+}
+
+func TestRunSpaceEventsREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestSpaceEventsREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (rest *TestSpaceEventsREST) SetupSuite() {
+	rest.DBTestSuite.SetupSuite()
+	rest.ctx = migration.NewMigrationContext(context.Background())
+	// Make sure the database is populated with the correct types (e.g. bug etc.)
+	if err := models.Transactional(rest.DB, func(tx *gorm.DB) error {
+		return migration.PopulateCommonTypes(rest.ctx, tx, workitem.NewWorkItemTypeRepository(tx))
+	}); err != nil {
+		panic(err.Error())
+	}
+}
+
+// SetupTest records the creation of two work items, the update and the deletion of the second one, and
+// the creation of a comment which doesn't exist
+func (rest *TestSpaceEventsREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.hub = eventstream.NewHub(rest.DB, "")
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+	testSpace, err := space.NewRepository(rest.DB).Create(rest.ctx, &space.Space{
+		Name: "test-space" + uuid.NewV4().String(),
+	})
+	require.Nil(rest.T(), err)
+	rest.spaceID = testSpace.ID
+	rest.start, err = rest.hub.Latest(rest.spaceID)
+	require.Nil(rest.T(), err)
+	workItemRepository := workitem.NewWorkItemRepository(rest.DB)
+	var ids []string
+	for _, title := range []string{"visible", "deleted"} {
+		wi, err := workItemRepository.Create(rest.ctx, rest.spaceID, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: workitem.SystemStateNew,
+			}, testsupport.TestIdentity.ID)
+		require.Nil(rest.T(), err)
+		ids = append(ids, wi.ID)
+	}
+	require.Nil(rest.T(), workItemRepository.Delete(rest.ctx, ids[1], testsupport.TestIdentity.ID))
+	events := []event.Event{
+		event.WorkItemCreated{WorkItemID: ids[0], SpaceID: rest.spaceID},
+		event.WorkItemCreated{WorkItemID: ids[1], SpaceID: rest.spaceID},
+		event.WorkItemUpdated{WorkItemID: ids[1], SpaceID: rest.spaceID, Version: 1},
+		event.CommentAdded{CommentID: uuid.NewV4(), WorkItemID: ids[0]},
+		event.WorkItemDeleted{WorkItemID: ids[1], SpaceID: rest.spaceID},
+	}
+	for _, e := range events {
+		err := models.Transactional(rest.DB, func(tx *gorm.DB) error {
+			return eventstream.Append(tx, e)
+		})
+		require.Nil(rest.T(), err)
+	}
+	rest.records, err = rest.hub.Since(rest.spaceID, rest.start, 10)
+	require.Nil(rest.T(), err)
+	require.Len(rest.T(), rest.records, len(events))
+}
+
+func (rest *TestSpaceEventsREST) TearDownTest() {
+	rest.DB.Exec("DELETE FROM space_events WHERE space_id = ?", rest.spaceID)
+	rest.clean()
+}
+
+// stream reads the stream of the space after the given event until the client disconnects
+func (rest *TestSpaceEventsREST) stream(lastEventID string) string {
+	svc := goa.New("SpaceEvents-Service")
+	ctrl := NewSpaceEventsController(svc, rest.db, rest.hub)
+	ctx, cancel := context.WithTimeout(svc.Context, streamDuration)
+	defer cancel()
+	rw := test.StreamSpaceEventsOK(rest.T(), ctx, svc, ctrl, rest.spaceID.String(), &lastEventID)
+	return rw.(*httptest.ResponseRecorder).Body.String()
+}
+
+func (rest *TestSpaceEventsREST) TestStreamVisibleEvents() {
+	t := rest.T()
+	// when
+	body := rest.stream(rest.start.String())
+	// then only the creation of the remaining work item and the deletion of the other one are streamed
+	assert.Contains(t, body, "id: "+rest.records[0].Position().String()+"\nevent: workitem.created\n")
+	assert.NotContains(t, body, "id: "+rest.records[1].Position().String()+"\n")
+	assert.NotContains(t, body, "id: "+rest.records[2].Position().String()+"\n")
+	assert.NotContains(t, body, "id: "+rest.records[3].Position().String()+"\n")
+	assert.Contains(t, body, "id: "+rest.records[4].Position().String()+"\nevent: workitem.deleted\n")
+}
+
+func (rest *TestSpaceEventsREST) TestStreamResumesAfterLastEventID() {
+	t := rest.T()
+	// when
+	body := rest.stream(rest.records[0].Position().String())
+	// then
+	assert.NotContains(t, body, "id: "+rest.records[0].Position().String()+"\n")
+	assert.Contains(t, body, "id: "+rest.records[4].Position().String()+"\nevent: workitem.deleted\n")
+}
+
+func (rest *TestSpaceEventsREST) TestStreamBadLastEventID() {
+	t := rest.T()
+	// given
+	svc := goa.New("SpaceEvents-Service")
+	ctrl := NewSpaceEventsController(svc, rest.db, rest.hub)
+	// an id out of the range of the positions
+	lastEventID := "1-99999999999999999999"
+	// when/then
+	test.StreamSpaceEventsBadRequest(t, svc.Context, svc, ctrl, rest.spaceID.String(), &lastEventID)
+}
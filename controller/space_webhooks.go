@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/webhook"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceWebhooksController implements the space-webhooks resource.
+type SpaceWebhooksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceWebhooksController creates a space-webhooks controller.
+func NewSpaceWebhooksController(service *goa.Service, db application.DB) *SpaceWebhooksController {
+	return &SpaceWebhooksController{Controller: service.NewController("SpaceWebhooksController"), db: db}
+}
+
+// Create runs the create action.
+func (c *SpaceWebhooksController) Create(ctx *app.CreateSpaceWebhooksContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	// Validate Request
+	if ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data", nil).Expected("not nil"))
+	}
+	reqWebhook := ctx.Payload.Data
+	if reqWebhook.Attributes.URL == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.url", nil).Expected("not nil"))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if err := checkSpaceOwner(ctx, appl, spaceID, *currentUser); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		newWebhook := webhook.Subscription{
+			SpaceID:    spaceID,
+			URL:        *reqWebhook.Attributes.URL,
+			EventTypes: reqWebhook.Attributes.EventTypes,
+		}
+		if reqWebhook.Attributes.Secret != nil {
+			newWebhook.Secret = *reqWebhook.Attributes.Secret
+		}
+		if err := appl.Webhooks().Create(ctx, &newWebhook); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookSingle{
+			Data: ConvertWebhook(ctx.RequestData, &newWebhook),
+		}
+		ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.RequestData, app.WebhookHref(res.Data.ID)))
+		return ctx.Created(res)
+	})
+}
+
+// List runs the list action.
+func (c *SpaceWebhooksController) List(ctx *app.ListSpaceWebhooksContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	spaceID, err := uuid.FromString(ctx.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if err := checkSpaceOwner(ctx, appl, spaceID, *currentUser); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		webhooks, err := appl.Webhooks().List(ctx, spaceID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookList{
+			Data: ConvertWebhooks(ctx.RequestData, webhooks),
+		}
+		return ctx.OK(res)
+	})
+}
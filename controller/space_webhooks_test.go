@@ -0,0 +1,147 @@
+package controller_test
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/application"
+	. "github.com/almighty/almighty-core/controller"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
+
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestSpaceWebhooksREST struct {
+	gormtestsupport.DBTestSuite
+
+	db    *gormapplication.GormDB
+	clean func()
+}
+
+func TestRunSpaceWebhooksREST(t *testing.T) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		require.Nil(t, err)
+	}
+	suite.Run(t, &TestSpaceWebhooksREST{DBTestSuite: gormtestsupport.NewDBTestSuite(pwd + "/../config.yaml")})
+}
+
+func (rest *TestSpaceWebhooksREST) SetupTest() {
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+}
+
+func (rest *TestSpaceWebhooksREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestSpaceWebhooksREST) SecuredControllers() (*goa.Service, *SpaceWebhooksController, *WebhookController) {
+	pub, _ := almtoken.ParsePublicKey([]byte(almtoken.RSAPublicKey))
+
+	svc := testsupport.ServiceAsUser("Webhook-Service", almtoken.NewManager(pub), testsupport.TestIdentity)
+	return svc, NewSpaceWebhooksController(svc, rest.db), NewWebhookController(svc, rest.db)
+}
+
+func (rest *TestSpaceWebhooksREST) createSpace(owner uuid.UUID) *space.Space {
+	var s *space.Space
+	err := application.Transactional(rest.db, func(appl application.Application) error {
+		var err error
+		s, err = appl.Spaces().Create(context.Background(), &space.Space{
+			Name:    "Test Space " + uuid.NewV4().String(),
+			OwnerId: owner,
+		})
+		return err
+	})
+	require.Nil(rest.T(), err)
+	return s
+}
+
+func newWebhookPayload(url string, eventTypes ...string) *app.WebhookSingle {
+	secret := "s3cr3t"
+	return &app.WebhookSingle{
+		Data: &app.Webhook{
+			Type: webhook.APIStringTypeWebhooks,
+			Attributes: &app.WebhookAttributes{
+				URL:        &url,
+				Secret:     &secret,
+				EventTypes: eventTypes,
+			},
+		},
+	}
+}
+
+func (rest *TestSpaceWebhooksREST) TestCreateAndListWebhooks() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := rest.createSpace(testsupport.TestIdentity.ID)
+	svc, ctrl, webhookCtrl := rest.SecuredControllers()
+	// when
+	_, created := test.CreateSpaceWebhooksCreated(t, svc.Context, svc, ctrl, s.ID.String(), newWebhookPayload("https://ci.example.com/hooks", webhook.EventWorkItemCreated))
+	// then
+	require.NotNil(t, created.Data.ID)
+	assert.Equal(t, "https://ci.example.com/hooks", *created.Data.Attributes.URL)
+	assert.Nil(t, created.Data.Attributes.Secret)
+	assert.Equal(t, []string{webhook.EventWorkItemCreated}, created.Data.Attributes.EventTypes)
+	assert.Equal(t, s.ID.String(), *created.Data.Relationships.Space.Data.ID)
+	_, list := test.ListSpaceWebhooksOK(t, svc.Context, svc, ctrl, s.ID.String())
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, *created.Data.ID, *list.Data[0].ID)
+	_, deliveries := test.ListDeliveriesWebhookOK(t, svc.Context, svc, webhookCtrl, created.Data.ID.String(), nil)
+	assert.Len(t, deliveries.Data, 0)
+}
+
+func (rest *TestSpaceWebhooksREST) TestUpdateAndDeleteWebhook() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := rest.createSpace(testsupport.TestIdentity.ID)
+	svc, ctrl, webhookCtrl := rest.SecuredControllers()
+	_, created := test.CreateSpaceWebhooksCreated(t, svc.Context, svc, ctrl, s.ID.String(), newWebhookPayload("https://ci.example.com/hooks", webhook.EventWorkItemCreated))
+	// when
+	_, updated := test.UpdateWebhookOK(t, svc.Context, svc, webhookCtrl, created.Data.ID.String(), newWebhookPayload("https://chat.example.com/hooks", webhook.EventCommentCreated, webhook.EventLinkCreated))
+	// then
+	assert.Equal(t, "https://chat.example.com/hooks", *updated.Data.Attributes.URL)
+	assert.Equal(t, []string{webhook.EventCommentCreated, webhook.EventLinkCreated}, updated.Data.Attributes.EventTypes)
+	// when
+	test.DeleteWebhookOK(t, svc.Context, svc, webhookCtrl, created.Data.ID.String())
+	// then
+	test.ShowWebhookNotFound(t, svc.Context, svc, webhookCtrl, created.Data.ID.String())
+}
+
+func (rest *TestSpaceWebhooksREST) TestCreateInvalidWebhook() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := rest.createSpace(testsupport.TestIdentity.ID)
+	svc, ctrl, _ := rest.SecuredControllers()
+	// then
+	test.CreateSpaceWebhooksBadRequest(t, svc.Context, svc, ctrl, s.ID.String(), newWebhookPayload("ci.example.com", webhook.EventWorkItemCreated))
+	test.CreateSpaceWebhooksBadRequest(t, svc.Context, svc, ctrl, s.ID.String(), newWebhookPayload("https://ci.example.com/hooks", "workitem.deleted"))
+}
+
+func (rest *TestSpaceWebhooksREST) TestWebhooksOfAnotherOwnerForbidden() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := rest.createSpace(testsupport.TestIdentity2.ID)
+	svc, ctrl, _ := rest.SecuredControllers()
+	// then
+	test.ListSpaceWebhooksForbidden(t, svc.Context, svc, ctrl, s.ID.String())
+	test.CreateSpaceWebhooksForbidden(t, svc.Context, svc, ctrl, s.ID.String(), newWebhookPayload("https://ci.example.com/hooks", webhook.EventWorkItemCreated))
+}
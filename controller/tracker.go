@@ -2,12 +2,14 @@ package controller
 
 import (
 	"fmt"
+	"io"
+	"io/ioutil"
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/log"
-	query "github.com/almighty/almighty-core/query/simple"
+	"github.com/almighty/almighty-core/query"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -40,7 +42,8 @@ func NewTrackerController(service *goa.Service, db application.DB, scheduler *re
 
 // Create runs the create action.
 func (c *TrackerController) Create(ctx *app.CreateTrackerContext) error {
-	result := application.Transactional(c.db, func(appl application.Application) error {
+	// a new tracker has no tracker query to schedule yet
+	return application.Transactional(c.db, func(appl application.Application) error {
 		t, err := appl.Trackers().Create(ctx.Context, ctx.Payload.URL, ctx.Payload.Type)
 		if err != nil {
 			cause := errs.Cause(err)
@@ -53,12 +56,16 @@ func (c *TrackerController) Create(ctx *app.CreateTrackerContext) error {
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		if ctx.Payload.WebhookSecret != nil {
+			if err := appl.Trackers().SetWebhookSecret(ctx.Context, t.ID, *ctx.Payload.WebhookSecret); err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+			t.WebhookEnabled = *ctx.Payload.WebhookSecret != ""
+		}
 		ctx.ResponseData.Header().Set("Location", app.TrackerHref(t.ID))
 		return ctx.Created(t)
 	})
-	accessTokens := GetAccessTokens(c.configuration) //configuration.GetGithubAuthToken()
-	c.scheduler.ScheduleAllQueries(ctx, accessTokens)
-	return result
 }
 
 // Delete runs the delete action.
@@ -78,6 +85,7 @@ func (c *TrackerController) Delete(ctx *app.DeleteTrackerContext) error {
 		}
 		return ctx.OK([]byte{})
 	})
+	// the tracker queries of the deleted tracker are removed from the schedule
 	accessTokens := GetAccessTokens(c.configuration) //configuration.GetGithubAuthToken()
 	c.scheduler.ScheduleAllQueries(ctx, accessTokens)
 	return result
@@ -130,7 +138,8 @@ func (c *TrackerController) List(ctx *app.ListTrackerContext) error {
 
 // Update runs the update action.
 func (c *TrackerController) Update(ctx *app.UpdateTrackerContext) error {
-	result := application.Transactional(c.db, func(appl application.Application) error {
+	// the tracker queries load the configuration of their tracker at each run, they don't need to be rescheduled
+	return application.Transactional(c.db, func(appl application.Application) error {
 
 		toSave := app.Tracker{
 			ID:   ctx.ID,
@@ -150,9 +159,44 @@ func (c *TrackerController) Update(ctx *app.UpdateTrackerContext) error {
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		if ctx.Payload.WebhookSecret != nil {
+			if err := appl.Trackers().SetWebhookSecret(ctx.Context, t.ID, *ctx.Payload.WebhookSecret); err != nil {
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+				return ctx.InternalServerError(jerrors)
+			}
+			t.WebhookEnabled = *ctx.Payload.WebhookSecret != ""
+		}
 		return ctx.OK(t)
 	})
-	accessTokens := GetAccessTokens(c.configuration) //configuration.GetGithubAuthToken()
-	c.scheduler.ScheduleAllQueries(ctx, accessTokens)
-	return result
+}
+
+// maxWebhookEventSize is the maximum size of the body of a webhook event
+const maxWebhookEventSize = 10 * 1024 * 1024
+
+// Webhook runs the webhook action.
+func (c *TrackerController) Webhook(ctx *app.WebhookTrackerContext) error {
+	body, err := ioutil.ReadAll(io.LimitReader(ctx.Request.Body, maxWebhookEventSize))
+	if err != nil {
+		jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(fmt.Sprintf("could not read the webhook event: %s", err.Error())))
+		return ctx.BadRequest(jerrors)
+	}
+	err = c.scheduler.ReceiveWebhook(ctx, ctx.ID, ctx.Request, body)
+	if err != nil {
+		cause := errs.Cause(err)
+		switch cause.(type) {
+		case remoteworkitem.NotFoundError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		case remoteworkitem.BadParameterError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrBadRequest(err.Error()))
+			return ctx.BadRequest(jerrors)
+		case remoteworkitem.UnauthorizedError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrUnauthorized(err.Error()))
+			return ctx.Unauthorized(jerrors)
+		default:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+	}
+	return ctx.OK([]byte{})
 }
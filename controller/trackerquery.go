@@ -11,6 +11,7 @@ import (
 
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
+	"golang.org/x/net/context"
 )
 
 type trackerQueryConfiguration interface {
@@ -40,8 +41,13 @@ func NewTrackerqueryController(service *goa.Service, db application.DB, schedule
 
 // Create runs the create action.
 func (c *TrackerqueryController) Create(ctx *app.CreateTrackerqueryContext) error {
+	var created *app.TrackerQuery
 	result := application.Transactional(c.db, func(appl application.Application) error {
-		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID, *ctx.Payload.Relationships.Space.Data.ID)
+		var syncPolicy string
+		if ctx.Payload.SyncPolicy != nil {
+			syncPolicy = *ctx.Payload.SyncPolicy
+		}
+		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID, syncPolicy, ctx.Payload.WorkItemTypeID, ctx.Payload.FieldMappings, *ctx.Payload.Relationships.Space.Data.ID)
 		if err != nil {
 			cause := errs.Cause(err)
 			switch cause.(type) {
@@ -53,11 +59,13 @@ func (c *TrackerqueryController) Create(ctx *app.CreateTrackerqueryContext) erro
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		created = tq
 		ctx.ResponseData.Header().Set("Location", app.TrackerqueryHref(tq.ID))
 		return ctx.Created(tq)
 	})
-	accessTokens := getAccessTokensForTrackerQuery(c.configuration) //configuration.GetGithubAuthToken()
-	c.scheduler.ScheduleAllQueries(ctx, accessTokens)
+	if created != nil {
+		c.scheduleQuery(ctx, created)
+	}
 	return result
 }
 
@@ -84,14 +92,20 @@ func (c *TrackerqueryController) Show(ctx *app.ShowTrackerqueryContext) error {
 
 // Update runs the update action.
 func (c *TrackerqueryController) Update(ctx *app.UpdateTrackerqueryContext) error {
+	var updated *app.TrackerQuery
 	result := application.Transactional(c.db, func(appl application.Application) error {
 
 		toSave := app.TrackerQuery{
-			ID:            ctx.ID,
-			Query:         ctx.Payload.Query,
-			Schedule:      ctx.Payload.Schedule,
-			TrackerID:     ctx.Payload.TrackerID,
-			Relationships: ctx.Payload.Relationships,
+			ID:             ctx.ID,
+			Query:          ctx.Payload.Query,
+			Schedule:       ctx.Payload.Schedule,
+			WorkItemTypeID: ctx.Payload.WorkItemTypeID,
+			FieldMappings:  ctx.Payload.FieldMappings,
+			TrackerID:      ctx.Payload.TrackerID,
+			Relationships:  ctx.Payload.Relationships,
+		}
+		if ctx.Payload.SyncPolicy != nil {
+			toSave.SyncPolicy = *ctx.Payload.SyncPolicy
 		}
 		tq, err := appl.TrackerQueries().Save(ctx.Context, toSave)
 
@@ -106,15 +120,18 @@ func (c *TrackerqueryController) Update(ctx *app.UpdateTrackerqueryContext) erro
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		updated = tq
 		return ctx.OK(tq)
 	})
-	accessTokens := getAccessTokensForTrackerQuery(c.configuration) //configuration.GetGithubAuthToken()
-	c.scheduler.ScheduleAllQueries(ctx, accessTokens)
+	if updated != nil {
+		c.scheduleQuery(ctx, updated)
+	}
 	return result
 }
 
 // Delete runs the delete action.
 func (c *TrackerqueryController) Delete(ctx *app.DeleteTrackerqueryContext) error {
+	deleted := false
 	result := application.Transactional(c.db, func(appl application.Application) error {
 		err := appl.TrackerQueries().Delete(ctx.Context, ctx.ID)
 		if err != nil {
@@ -128,13 +145,26 @@ func (c *TrackerqueryController) Delete(ctx *app.DeleteTrackerqueryContext) erro
 				return ctx.InternalServerError(jerrors)
 			}
 		}
+		deleted = true
 		return ctx.OK([]byte{})
 	})
-	accessTokens := getAccessTokensForTrackerQuery(c.configuration) //configuration.GetGithubAuthToken()
-	c.scheduler.ScheduleAllQueries(ctx, accessTokens)
+	if deleted {
+		c.scheduler.UnscheduleQuery(ctx.ID)
+	}
 	return result
 }
 
+// scheduleQuery adds or replaces the cron entry of the given tracker query
+func (c *TrackerqueryController) scheduleQuery(ctx context.Context, tq *app.TrackerQuery) {
+	accessTokens := getAccessTokensForTrackerQuery(c.configuration) //configuration.GetGithubAuthToken()
+	if err := c.scheduler.ScheduleQuery(ctx, tq.ID, tq.Schedule, accessTokens); err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"trackerQueryID": tq.ID,
+			"err":            err,
+		}, "unable to schedule the tracker query")
+	}
+}
+
 // List runs the list action.
 func (c *TrackerqueryController) List(ctx *app.ListTrackerqueryContext) error {
 	return application.Transactional(c.db, func(appl application.Application) error {
@@ -147,3 +177,60 @@ func (c *TrackerqueryController) List(ctx *app.ListTrackerqueryContext) error {
 	})
 
 }
+
+// Run runs the run action.
+func (c *TrackerqueryController) Run(ctx *app.RunTrackerqueryContext) error {
+	accessTokens := getAccessTokensForTrackerQuery(c.configuration)
+	run, err := c.scheduler.RunQuery(ctx, ctx.ID, accessTokens)
+	if err != nil {
+		cause := errs.Cause(err)
+		switch cause.(type) {
+		case remoteworkitem.NotFoundError:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+			return ctx.NotFound(jerrors)
+		default:
+			jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(err.Error()))
+			return ctx.InternalServerError(jerrors)
+		}
+	}
+	return ctx.Accepted(remoteworkitem.ConvertTrackerQueryRunToApp(*run))
+}
+
+// Runs runs the runs action.
+func (c *TrackerqueryController) Runs(ctx *app.RunsTrackerqueryContext) error {
+	_, limit := computePagingLimts(nil, ctx.PageLimit)
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.TrackerQueries().ListRuns(ctx.Context, ctx.ID, limit)
+		if err != nil {
+			cause := errs.Cause(err)
+			switch cause.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing tracker query runs: %s", err.Error())))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(result)
+	})
+}
+
+// Conflicts runs the conflicts action.
+func (c *TrackerqueryController) Conflicts(ctx *app.ConflictsTrackerqueryContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.TrackerQueries().ListConflicts(ctx.Context, ctx.ID)
+		if err != nil {
+			cause := errs.Cause(err)
+			switch cause.(type) {
+			case remoteworkitem.NotFoundError:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrNotFound(err.Error()))
+				return ctx.NotFound(jerrors)
+			default:
+				jerrors, _ := jsonapi.ErrorToJSONAPIErrors(goa.ErrInternal(fmt.Sprintf("Error listing tracker item conflicts: %s", err.Error())))
+				return ctx.InternalServerError(jerrors)
+			}
+		}
+		return ctx.OK(result)
+	})
+}
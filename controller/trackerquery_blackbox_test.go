@@ -163,6 +163,22 @@ func getTrackerQueryTestData(t *testing.T) []testSecureAPI {
 			payload:            createTrackerQueryPayload,
 			jwtToken:           "",
 		},
+		// Run tracker query API with different parameters
+		{
+			method:             http.MethodPost,
+			url:                "/api/trackerqueries/12345/run",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           getExpiredAuthHeader(t, privatekey),
+		}, {
+			method:             http.MethodPost,
+			url:                "/api/trackerqueries/12345/run",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorCode:  jsonapi.ErrorCodeJWTSecurityError,
+			payload:            nil,
+			jwtToken:           "",
+		},
 		// Try fetching a random tracker query
 		// We do not have security on GET hence this should return 404 not found
 		{
@@ -333,6 +349,80 @@ func (rest *TestTrackerQueryREST) TestCreateTrackerQueryValidId() {
 	}
 }
 
+func (rest *TestTrackerQueryREST) TestCreateTrackerQueryWithSyncPolicy() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, trackerCtrl, trackerQueryCtrl := rest.SecuredController()
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://api.github.com",
+		Type: "github",
+	}
+	_, result := test.CreateTrackerCreated(t, svc.Context, svc, trackerCtrl, &payload)
+	// when
+	tqpayload := getCreateTrackerQueryPayload(result.ID)
+	syncPolicy := remoteworkitem.SyncPolicyFlagConflict
+	tqpayload.SyncPolicy = &syncPolicy
+	_, tqresult := test.CreateTrackerqueryCreated(t, nil, nil, trackerQueryCtrl, &tqpayload)
+	// then
+	require.Equal(t, remoteworkitem.SyncPolicyFlagConflict, tqresult.SyncPolicy)
+	_, conflicts := test.ConflictsTrackerqueryOK(t, nil, nil, trackerQueryCtrl, tqresult.ID)
+	require.Empty(t, conflicts)
+}
+
+func (rest *TestTrackerQueryREST) TestCreateTrackerQueryWithInvalidSchedule() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, trackerCtrl, trackerQueryCtrl := rest.SecuredController()
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://api.github.com",
+		Type: "github",
+	}
+	_, result := test.CreateTrackerCreated(t, svc.Context, svc, trackerCtrl, &payload)
+	// when
+	tqpayload := getCreateTrackerQueryPayload(result.ID)
+	tqpayload.Schedule = "every minute"
+	// then
+	test.CreateTrackerqueryBadRequest(t, nil, nil, trackerQueryCtrl, &tqpayload)
+}
+
+func (rest *TestTrackerQueryREST) TestListConflictsUnknownTrackerQuery() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	_, _, trackerQueryCtrl := rest.SecuredController()
+	test.ConflictsTrackerqueryNotFound(t, nil, nil, trackerQueryCtrl, "10000000")
+}
+
+func (rest *TestTrackerQueryREST) TestListTrackerQueryRuns() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	svc, trackerCtrl, trackerQueryCtrl := rest.SecuredController()
+	payload := app.CreateTrackerAlternatePayload{
+		URL:  "http://api.github.com",
+		Type: "github",
+	}
+	_, result := test.CreateTrackerCreated(t, svc.Context, svc, trackerCtrl, &payload)
+	tqpayload := getCreateTrackerQueryPayload(result.ID)
+	_, tqresult := test.CreateTrackerqueryCreated(t, nil, nil, trackerQueryCtrl, &tqpayload)
+	// when
+	limit := 5
+	_, runs := test.RunsTrackerqueryOK(t, nil, nil, trackerQueryCtrl, tqresult.ID, &limit)
+	// then
+	require.Empty(t, runs)
+}
+
+func (rest *TestTrackerQueryREST) TestRunUnknownTrackerQuery() {
+	t := rest.T()
+	resource.Require(t, resource.Database)
+
+	_, _, trackerQueryCtrl := rest.SecuredController()
+	test.RunTrackerqueryNotFound(t, nil, nil, trackerQueryCtrl, "10000000")
+	test.RunsTrackerqueryNotFound(t, nil, nil, trackerQueryCtrl, "10000000", nil)
+}
+
 func getCreateTrackerQueryPayload(trackerID string) app.CreateTrackerQueryAlternatePayload {
 	reqLong := &goa.RequestData{
 		Request: &http.Request{Host: "api.service.domain.org"},
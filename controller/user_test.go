@@ -14,9 +14,11 @@ import (
 	"github.com/almighty/almighty-core/comment"
 	. "github.com/almighty/almighty-core/controller"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/space"
 	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	token "github.com/dgrijalva/jwt-go"
@@ -192,6 +194,10 @@ func (g *GormTestBase) WorkItemTypes() workitem.WorkItemTypeRepository {
 	return nil
 }
 
+func (g *GormTestBase) WorkItemRevisions() workitem.RevisionRepository {
+	return nil
+}
+
 func (g *GormTestBase) Spaces() space.Repository {
 	return nil
 }
@@ -241,6 +247,16 @@ func (g *GormTestBase) Comments() comment.Repository {
 	return nil
 }
 
+// CommentRevisions returns a comment revision repository
+func (g *GormTestBase) CommentRevisions() comment.RevisionRepository {
+	return nil
+}
+
+// References returns a work item reference repository
+func (g *GormTestBase) References() reference.Repository {
+	return nil
+}
+
 // Iterations returns a iteration repository
 func (g *GormTestBase) Iterations() iteration.Repository {
 	return nil
@@ -255,6 +271,10 @@ func (g *GormTestBase) OauthStates() auth.OauthStateReferenceRepository {
 	return nil
 }
 
+func (g *GormTestBase) Webhooks() webhook.Repository {
+	return nil
+}
+
 func (g *GormTestBase) DB() *gorm.DB {
 	return nil
 }
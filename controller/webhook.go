@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
+
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+const (
+	defaultDeliveriesLimit = 20
+	maxDeliveriesLimit     = 100
+)
+
+// WebhookController implements the webhook resource.
+type WebhookController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWebhookController creates a webhook controller.
+func NewWebhookController(service *goa.Service, db application.DB) *WebhookController {
+	return &WebhookController{Controller: service.NewController("WebhookController"), db: db}
+}
+
+// Show runs the show action.
+func (c *WebhookController) Show(ctx *app.ShowWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.WebhookID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Webhooks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := checkSpaceOwner(ctx, appl, s.SpaceID, *currentUser); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookSingle{
+			Data: ConvertWebhook(ctx.RequestData, s),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Update runs the update action.
+func (c *WebhookController) Update(ctx *app.UpdateWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.WebhookID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Webhooks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := checkSpaceOwner(ctx, appl, s.SpaceID, *currentUser); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if ctx.Payload.Data != nil && ctx.Payload.Data.Attributes != nil {
+			attributes := ctx.Payload.Data.Attributes
+			if attributes.URL != nil {
+				s.URL = *attributes.URL
+			}
+			if attributes.Secret != nil {
+				s.Secret = *attributes.Secret
+			}
+			if attributes.EventTypes != nil {
+				s.EventTypes = attributes.EventTypes
+			}
+		}
+		if err := appl.Webhooks().Save(ctx, s); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookSingle{
+			Data: ConvertWebhook(ctx.RequestData, s),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Delete runs the delete action.
+func (c *WebhookController) Delete(ctx *app.DeleteWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.WebhookID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Webhooks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := checkSpaceOwner(ctx, appl, s.SpaceID, *currentUser); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := appl.Webhooks().Delete(ctx, id); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return ctx.OK([]byte{})
+	})
+}
+
+// ListDeliveries runs the list-deliveries action.
+func (c *WebhookController) ListDeliveries(ctx *app.ListDeliveriesWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	id, err := uuid.FromString(ctx.WebhookID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrNotFound(err.Error()))
+	}
+	limit := defaultDeliveriesLimit
+	if ctx.PageLimit != nil && *ctx.PageLimit > 0 {
+		limit = *ctx.PageLimit
+	}
+	if limit > maxDeliveriesLimit {
+		limit = maxDeliveriesLimit
+	}
+
+	return application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Webhooks().Load(ctx, id)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if err := checkSpaceOwner(ctx, appl, s.SpaceID, *currentUser); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		deliveries, err := appl.Webhooks().ListDeliveries(ctx, id, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WebhookDeliveryList{
+			Data: ConvertWebhookDeliveries(deliveries),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// checkSpaceOwner returns an error if the given identity is not the owner of the given space:
+// only the owner of a space can see and manage its webhooks
+func checkSpaceOwner(ctx context.Context, appl application.Application, spaceID uuid.UUID, identityID uuid.UUID) error {
+	s, err := appl.Spaces().Load(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	if !uuid.Equal(identityID, s.OwnerId) {
+		log.Error(ctx, map[string]interface{}{"currentUser": identityID, "owner": s.OwnerId}, "Current user is not owner")
+		return goa.NewErrorClass("forbidden", 403)("User is not the space owner")
+	}
+	return nil
+}
+
+// ConvertWebhooks converts between internal and external REST representation
+func ConvertWebhooks(request *goa.RequestData, subscriptions []*webhook.Subscription) []*app.Webhook {
+	var ws = []*app.Webhook{}
+	for _, s := range subscriptions {
+		ws = append(ws, ConvertWebhook(request, s))
+	}
+	return ws
+}
+
+// ConvertWebhook converts between internal and external REST representation,
+// the secret of the subscription is never returned
+func ConvertWebhook(request *goa.RequestData, s *webhook.Subscription) *app.Webhook {
+	spaceID := s.SpaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.WebhookHref(s.ID))
+	spaceSelfURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	deliveriesURL := rest.AbsoluteURL(request, app.WebhookHref(s.ID)+"/deliveries")
+	eventTypes := []string(s.EventTypes)
+	return &app.Webhook{
+		Type: webhook.APIStringTypeWebhooks,
+		ID:   &s.ID,
+		Attributes: &app.WebhookAttributes{
+			URL:        &s.URL,
+			EventTypes: eventTypes,
+			CreatedAt:  &s.CreatedAt,
+		},
+		Relationships: &app.WebhookRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &space.SpaceType,
+					ID:   &spaceID,
+				},
+				Links: &app.GenericLinks{
+					Self: &spaceSelfURL,
+				},
+			},
+			Deliveries: &app.RelationGeneric{
+				Links: &app.GenericLinks{
+					Related: &deliveriesURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// ConvertWebhookDeliveries converts between internal and external REST representation
+func ConvertWebhookDeliveries(deliveries []*webhook.Delivery) []*app.WebhookDelivery {
+	var ds = []*app.WebhookDelivery{}
+	for _, d := range deliveries {
+		ds = append(ds, ConvertWebhookDelivery(d))
+	}
+	return ds
+}
+
+// ConvertWebhookDelivery converts between internal and external REST representation
+func ConvertWebhookDelivery(d *webhook.Delivery) *app.WebhookDelivery {
+	result := &app.WebhookDelivery{
+		Type: "webhook-deliveries",
+		ID:   d.ID,
+		Attributes: &app.WebhookDeliveryAttributes{
+			EventID:        d.EventID,
+			EventType:      d.EventType,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			ResponseStatus: d.ResponseStatus,
+			Error:          d.Error,
+			CreatedAt:      d.CreatedAt,
+			DeliveredAt:    d.DeliveredAt,
+		},
+	}
+	if d.Status == webhook.DeliveryPending {
+		nextAttemptAt := d.NextAttemptAt
+		result.Attributes.NextAttemptAt = &nextAttemptAt
+	}
+	return result
+}
@@ -44,7 +44,11 @@ type workItemChildSuite struct {
 	svc                      *goa.Service
 	typeCtrl                 *WorkitemtypeController
 	// These IDs can safely be used by all tests
-	bug1ID uint64
+	bug1ID     uint64
+	bug2ID     uint64
+	bug3ID     uint64
+	spaceID    uuid.UUID
+	linkTypeID uuid.UUID
 
 	// Store IDs of resources that need to be removed at the beginning or end of a test
 	testIdentity account.Identity
@@ -126,6 +130,7 @@ func (s *workItemChildSuite) SetupTest() {
 	createSpacePayload := CreateSpacePayload("test-space"+uuid.NewV4().String(), "description")
 	_, space := test.CreateSpaceCreated(s.T(), s.svc.Context, s.svc, s.spaceCtrl, createSpacePayload)
 	userSpaceID := *space.Data.ID
+	s.spaceID = userSpaceID
 	s.T().Logf("Created link space with ID: %s\n", *space.Data.ID)
 
 	// Create 3 work items (bug1, bug2, and bug3)
@@ -141,7 +146,7 @@ func (s *workItemChildSuite) SetupTest() {
 	_, bug2 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.workItemCtrl, bug2Payload)
 	require.NotNil(s.T(), bug2)
 
-	bug2ID, err := strconv.ParseUint(*bug2.Data.ID, 10, 64)
+	s.bug2ID, err = strconv.ParseUint(*bug2.Data.ID, 10, 64)
 	require.Nil(s.T(), err)
 	s.T().Logf("Created bug2 with ID: %s\n", *bug2.Data.ID)
 
@@ -149,7 +154,7 @@ func (s *workItemChildSuite) SetupTest() {
 	_, bug3 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.workItemCtrl, bug3Payload)
 	require.NotNil(s.T(), bug3)
 
-	bug3ID, err := strconv.ParseUint(*bug3.Data.ID, 10, 64)
+	s.bug3ID, err = strconv.ParseUint(*bug3.Data.ID, 10, 64)
 	require.Nil(s.T(), err)
 	s.T().Logf("Created bug3 with ID: %s\n", *bug3.Data.ID)
 
@@ -165,13 +170,14 @@ func (s *workItemChildSuite) SetupTest() {
 	_, workItemLinkType := test.CreateWorkItemLinkTypeCreated(s.T(), s.svc.Context, s.svc, s.workItemLinkTypeCtrl, createLinkTypePayload)
 	require.NotNil(s.T(), workItemLinkType)
 	bugBlockerLinkTypeID := *workItemLinkType.Data.ID
+	s.linkTypeID = bugBlockerLinkTypeID
 	s.T().Logf("Created link type with ID: %s\n", *workItemLinkType.Data.ID)
 
-	createPayload := CreateWorkItemLink(s.bug1ID, bug2ID, bugBlockerLinkTypeID)
+	createPayload := CreateWorkItemLink(s.bug1ID, s.bug2ID, bugBlockerLinkTypeID)
 	_, workItemLink := test.CreateWorkItemLinkCreated(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, createPayload)
 	require.NotNil(s.T(), workItemLink)
 
-	createPayload2 := CreateWorkItemLink(s.bug1ID, bug3ID, bugBlockerLinkTypeID)
+	createPayload2 := CreateWorkItemLink(s.bug1ID, s.bug3ID, bugBlockerLinkTypeID)
 	_, workItemLink2 := test.CreateWorkItemLinkCreated(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, createPayload2)
 	require.NotNil(s.T(), workItemLink2)
 }
@@ -236,3 +242,97 @@ func (s *workItemChildSuite) TestListChildren() {
 	}
 	assert.Equal(s.T(), 2, count)
 }
+
+// createGrandChild creates bug4 as a child of bug2
+func (s *workItemChildSuite) createGrandChild() string {
+	bug4Payload := CreateWorkItem(s.spaceID, workitem.SystemBug, "bug4")
+	_, bug4 := test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.workItemCtrl, bug4Payload)
+	require.NotNil(s.T(), bug4)
+	bug4ID, err := strconv.ParseUint(*bug4.Data.ID, 10, 64)
+	require.Nil(s.T(), err)
+	createPayload := CreateWorkItemLink(s.bug2ID, bug4ID, s.linkTypeID)
+	_, workItemLink := test.CreateWorkItemLinkCreated(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, createPayload)
+	require.NotNil(s.T(), workItemLink)
+	return *bug4.Data.ID
+}
+
+func workItemTitles(workItems []*app.WorkItem2) []interface{} {
+	result := make([]interface{}, len(workItems))
+	for i, wi := range workItems {
+		result[i] = wi.Attributes[workitem.SystemTitle]
+	}
+	return result
+}
+
+func (s *workItemChildSuite) TestListAncestors() {
+	// given
+	bug4ID := s.createGrandChild()
+	// when
+	_, workItemList := test.ListAncestorsWorkitemOK(s.T(), s.svc.Context, s.svc, s.workItemCtrl, bug4ID)
+	// then
+	assert.Equal(s.T(), []interface{}{"bug2", "bug1"}, workItemTitles(workItemList.Data))
+}
+
+func (s *workItemChildSuite) TestListAncestorsOfRoot() {
+	// when
+	_, workItemList := test.ListAncestorsWorkitemOK(s.T(), s.svc.Context, s.svc, s.workItemCtrl, strconv.FormatUint(s.bug1ID, 10))
+	// then
+	assert.Empty(s.T(), workItemList.Data)
+}
+
+func (s *workItemChildSuite) TestListAncestorsNotFound() {
+	test.ListAncestorsWorkitemNotFound(s.T(), s.svc.Context, s.svc, s.workItemCtrl, "88888888")
+}
+
+func (s *workItemChildSuite) TestListDescendants() {
+	// given
+	s.createGrandChild()
+	workItemID1 := strconv.FormatUint(s.bug1ID, 10)
+	// when
+	_, workItemList := test.ListDescendantsWorkitemOK(s.T(), s.svc.Context, s.svc, s.workItemCtrl, workItemID1, nil)
+	// then
+	assert.Equal(s.T(), []interface{}{"bug2", "bug3", "bug4"}, workItemTitles(workItemList.Data))
+}
+
+func (s *workItemChildSuite) TestListDescendantsToDepth() {
+	// given
+	s.createGrandChild()
+	workItemID1 := strconv.FormatUint(s.bug1ID, 10)
+	depth := 1
+	// when
+	_, workItemList := test.ListDescendantsWorkitemOK(s.T(), s.svc.Context, s.svc, s.workItemCtrl, workItemID1, &depth)
+	// then
+	assert.Equal(s.T(), []interface{}{"bug2", "bug3"}, workItemTitles(workItemList.Data))
+}
+
+func (s *workItemChildSuite) TestShowTree() {
+	// given
+	bug4ID := s.createGrandChild()
+	workItemID1 := strconv.FormatUint(s.bug1ID, 10)
+	// when
+	_, tree := test.ShowTreeWorkitemOK(s.T(), s.svc.Context, s.svc, s.workItemCtrl, workItemID1, nil)
+	// then
+	require.NotNil(s.T(), tree.Data)
+	assert.Equal(s.T(), workItemID1, *tree.Data.Data.ID)
+	require.Len(s.T(), tree.Data.Children, 2)
+	bug2 := tree.Data.Children[0]
+	assert.Equal(s.T(), strconv.FormatUint(s.bug2ID, 10), *bug2.Data.ID)
+	require.Len(s.T(), bug2.Children, 1)
+	assert.Equal(s.T(), bug4ID, *bug2.Children[0].Data.ID)
+	assert.Empty(s.T(), bug2.Children[0].Children)
+	assert.Empty(s.T(), tree.Data.Children[1].Children)
+}
+
+func (s *workItemChildSuite) TestShowTreeToDepth() {
+	// given
+	s.createGrandChild()
+	workItemID1 := strconv.FormatUint(s.bug1ID, 10)
+	depth := 1
+	// when
+	_, tree := test.ShowTreeWorkitemOK(s.T(), s.svc.Context, s.svc, s.workItemCtrl, workItemID1, &depth)
+	// then
+	require.Len(s.T(), tree.Data.Children, 2)
+	for _, child := range tree.Data.Children {
+		assert.Empty(s.T(), child.Children)
+	}
+}
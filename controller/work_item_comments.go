@@ -5,6 +5,7 @@ import (
 	"github.com/almighty/almighty-core/application"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/login"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/rest"
@@ -51,8 +52,16 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
 		}
 
+		includeRevisionCount, err := loadCommentRevisionCounts(ctx, appl, &newComment)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeReferences, err := loadCommentReferences(ctx, appl, ctx.RequestData, &newComment)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res := &app.CommentSingle{
-			Data: ConvertComment(ctx.RequestData, &newComment),
+			Data: ConvertComment(ctx.RequestData, &newComment, includeRevisionCount, includeReferences),
 		}
 		return ctx.OK(res)
 	})
@@ -70,13 +79,44 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 		res := &app.CommentList{}
 		res.Data = []*app.Comment{}
 
+		if ctx.PageCursor != nil {
+			cursor, err := keyset.Parse(*ctx.PageCursor)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			comments, page, err := appl.Comments().ListByCursor(ctx, ctx.ID, *cursor, limit)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			includeRevisionCount, err := loadCommentRevisionCounts(ctx, appl, comments...)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			includeReferences, err := loadCommentReferences(ctx, appl, ctx.RequestData, comments...)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			res.Data = ConvertComments(ctx.RequestData, comments, includeRevisionCount, includeReferences)
+			res.Links = &app.PagingLinks{}
+			setCursorPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), limit, page)
+			return ctx.OK(res)
+		}
+
 		comments, tc, err := appl.Comments().List(ctx, ctx.ID, &offset, &limit)
 		count := int(tc)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, goa.ErrInternal(err.Error()))
 		}
+		includeRevisionCount, err := loadCommentRevisionCounts(ctx, appl, comments...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeReferences, err := loadCommentReferences(ctx, appl, ctx.RequestData, comments...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		res.Meta = &app.CommentListMeta{TotalCount: count}
-		res.Data = ConvertComments(ctx.RequestData, comments)
+		res.Data = ConvertComments(ctx.RequestData, comments, includeRevisionCount, includeReferences)
 		res.Links = &app.PagingLinks{}
 		setPagingLinks(res.Links, buildAbsoluteURL(ctx.RequestData), len(comments), offset, limit, count)
 
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/workitem/link"
+)
+
+// Bulk runs the bulk action: it applies all the operations in one transaction,
+// or none of them if any operation is rejected.
+func (c *WorkItemLinkController) Bulk(ctx *app.BulkWorkItemLinkContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	operations := make([]link.BulkOperation, len(ctx.Payload.Data))
+	rejected := make([]error, len(ctx.Payload.Data))
+	for i, op := range ctx.Payload.Data {
+		operations[i], rejected[i] = convertLinkOperationToModel(op)
+	}
+	if jerrors := bulkErrorsToJSONAPIErrors(rejected); jerrors != nil {
+		return ctx.BadRequest(jerrors)
+	}
+
+	var results []link.BulkResult
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		results, err = appl.WorkItemLinks().ApplyBulk(ctx, operations, *currentUserIdentityID)
+		return err
+	})
+	if err != nil {
+		if results != nil {
+			for i, r := range results {
+				rejected[i] = r.Err
+			}
+			if jerrors := bulkErrorsToJSONAPIErrors(rejected); jerrors != nil {
+				return ctx.BadRequest(jerrors)
+			}
+		}
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+
+	res := &app.WorkItemLinkOperationResultList{
+		Data: make([]*app.WorkItemLinkOperationResult, len(operations)),
+	}
+	created := &app.WorkItemLinkList{}
+	for i, op := range operations {
+		res.Data[i] = &app.WorkItemLinkOperationResult{Op: op.Op}
+		if op.Op == link.BulkOperationCreate {
+			res.Data[i].Data = results[i].Link.Data
+			created.Data = append(created.Data, results[i].Link.Data)
+		} else {
+			linkID := op.LinkID
+			res.Data[i].ID = &linkID
+		}
+	}
+	// the links of the created links are set along with the included resources
+	linkCtx := newWorkItemLinkContext(ctx.Context, c.db, c.db, ctx.RequestData, ctx.ResponseData, app.WorkItemLinkHref, currentUserIdentityID)
+	if err := enrichLinkList(linkCtx, created); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res.Included = created.Included
+	return ctx.OK(res)
+}
+
+// convertLinkOperationToModel converts an operation of a bulk request from
+// the app to the model representation
+func convertLinkOperationToModel(op *app.WorkItemLinkOperation) (link.BulkOperation, error) {
+	result := link.BulkOperation{Op: op.Op}
+	switch op.Op {
+	case link.BulkOperationCreate:
+		if op.Data == nil {
+			return result, errors.NewBadParameterError("data", nil).Expected("the work item link to create")
+		}
+		model := link.WorkItemLink{}
+		if err := link.ConvertLinkToModel(app.WorkItemLinkSingle{Data: op.Data}, &model); err != nil {
+			return result, err
+		}
+		result.SourceID = model.SourceID
+		result.TargetID = model.TargetID
+		result.LinkTypeID = model.LinkTypeID
+	case link.BulkOperationDelete:
+		if op.ID == nil {
+			return result, errors.NewBadParameterError("id", nil).Expected("the ID of the work item link to delete")
+		}
+		result.LinkID = *op.ID
+	}
+	return result, nil
+}
+
+// bulkErrorsToJSONAPIErrors returns a JSONAPI error for each rejected
+// operation of a bulk request, pointing at the operation, or nil if no
+// operation was rejected
+func bulkErrorsToJSONAPIErrors(rejected []error) *app.JSONAPIErrors {
+	var jerrors *app.JSONAPIErrors
+	for i, err := range rejected {
+		if err == nil {
+			continue
+		}
+		if jerrors == nil {
+			jerrors = &app.JSONAPIErrors{}
+		}
+		jerr, _ := jsonapi.ErrorToJSONAPIError(err)
+		jerr.Source = map[string]interface{}{
+			"pointer": "/data/" + strconv.Itoa(i),
+		}
+		jerrors.Errors = append(jerrors.Errors, &jerr)
+	}
+	return jerrors
+}
@@ -0,0 +1,131 @@
+package controller_test
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkCreateOperation returns the operation of a bulk request creating a link of the given type from the source to the target
+func bulkCreateOperation(sourceID, targetID uint64, linkTypeID uuid.UUID) *app.WorkItemLinkOperation {
+	return &app.WorkItemLinkOperation{
+		Op:   link.BulkOperationCreate,
+		Data: CreateWorkItemLink(sourceID, targetID, linkTypeID).Data,
+	}
+}
+
+// bulkDeleteOperation returns the operation of a bulk request deleting the given link
+func bulkDeleteOperation(linkID uuid.UUID) *app.WorkItemLinkOperation {
+	return &app.WorkItemLinkOperation{
+		Op: link.BulkOperationDelete,
+		ID: &linkID,
+	}
+}
+
+// errorPointers returns the pointers to the rejected operations of the given errors
+func errorPointers(jerrors *app.JSONAPIErrors) []interface{} {
+	pointers := []interface{}{}
+	for _, jerr := range jerrors.Errors {
+		pointers = append(pointers, jerr.Source["pointer"])
+	}
+	return pointers
+}
+
+func (s *workItemLinkSuite) TestBulkCreateAndDelete() {
+	// given
+	workItemLink1, workItemLink2 := s.createSomeLinks()
+	payload := &app.BulkWorkItemLinkPayload{
+		Data: []*app.WorkItemLinkOperation{
+			bulkCreateOperation(s.bug1ID, s.bug3ID, s.bugBlockerLinkTypeID),
+			bulkDeleteOperation(*workItemLink1.Data.ID),
+			// the reverse of the link deleted by the previous operation
+			bulkCreateOperation(s.bug2ID, s.bug1ID, s.bugBlockerLinkTypeID),
+		},
+	}
+	// when
+	_, results := test.BulkWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, payload)
+	// then
+	require.Len(s.T(), results.Data, 3)
+	assert.Equal(s.T(), link.BulkOperationCreate, results.Data[0].Op)
+	require.NotNil(s.T(), results.Data[0].Data)
+	require.NotNil(s.T(), results.Data[0].Data.Links)
+	assert.Equal(s.T(), link.BulkOperationDelete, results.Data[1].Op)
+	assert.Equal(s.T(), *workItemLink1.Data.ID, *results.Data[1].ID)
+	require.NotNil(s.T(), results.Data[2].Data)
+	assert.Equal(s.T(), strconv.FormatUint(s.bug2ID, 10), results.Data[2].Data.Relationships.Source.Data.ID)
+	test.ShowWorkItemLinkNotFound(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, *workItemLink1.Data.ID)
+	_, links := test.ListWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl)
+	ids := []uuid.UUID{}
+	for _, l := range links.Data {
+		ids = append(ids, *l.ID)
+	}
+	assert.Len(s.T(), ids, 3)
+	assert.Contains(s.T(), ids, *workItemLink2.Data.ID)
+	assert.Contains(s.T(), ids, *results.Data[0].Data.ID)
+	assert.Contains(s.T(), ids, *results.Data[2].Data.ID)
+}
+
+func (s *workItemLinkSuite) TestBulkBadRequestAppliesNoOperation() {
+	// given
+	workItemLink1, _ := s.createSomeLinks()
+	payload := &app.BulkWorkItemLinkPayload{
+		Data: []*app.WorkItemLinkOperation{
+			bulkDeleteOperation(*workItemLink1.Data.ID),
+			bulkCreateOperation(s.bug1ID, s.bug3ID, s.bugBlockerLinkTypeID),
+			// the reverse of the link created by the previous operation
+			bulkCreateOperation(s.bug3ID, s.bug1ID, s.bugBlockerLinkTypeID),
+			bulkDeleteOperation(uuid.NewV4()),
+			bulkCreateOperation(s.bug1ID, s.feature1ID, s.bugBlockerLinkTypeID),
+		},
+	}
+	// when
+	_, jerrors := test.BulkWorkItemLinkBadRequest(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, payload)
+	// then
+	require.NotNil(s.T(), jerrors)
+	assert.Equal(s.T(), []interface{}{"/data/2", "/data/3", "/data/4"}, errorPointers(jerrors))
+	assert.Equal(s.T(), "404", *jerrors.Errors[1].Status)
+	test.ShowWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, *workItemLink1.Data.ID)
+	_, links := test.ListWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl)
+	assert.Len(s.T(), links.Data, 2)
+}
+
+func (s *workItemLinkSuite) TestBulkBadRequestDueToDuplicate() {
+	// given
+	s.createSomeLinks()
+	payload := &app.BulkWorkItemLinkPayload{
+		Data: []*app.WorkItemLinkOperation{
+			bulkCreateOperation(s.bug1ID, s.bug3ID, s.bugBlockerLinkTypeID),
+			bulkCreateOperation(s.bug1ID, s.bug3ID, s.bugBlockerLinkTypeID),
+			bulkCreateOperation(s.bug1ID, s.bug2ID, s.bugBlockerLinkTypeID),
+		},
+	}
+	// when
+	_, jerrors := test.BulkWorkItemLinkBadRequest(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, payload)
+	// then
+	require.NotNil(s.T(), jerrors)
+	assert.Equal(s.T(), []interface{}{"/data/1", "/data/2"}, errorPointers(jerrors))
+}
+
+func (s *workItemLinkSuite) TestBulkBadRequestDueToIncompleteOperation() {
+	// given
+	payload := &app.BulkWorkItemLinkPayload{
+		Data: []*app.WorkItemLinkOperation{
+			bulkCreateOperation(s.bug1ID, s.bug2ID, s.bugBlockerLinkTypeID),
+			{Op: link.BulkOperationCreate},
+			{Op: link.BulkOperationDelete},
+		},
+	}
+	// when
+	_, jerrors := test.BulkWorkItemLinkBadRequest(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, payload)
+	// then
+	require.NotNil(s.T(), jerrors)
+	assert.Equal(s.T(), []interface{}{"/data/1", "/data/2"}, errorPointers(jerrors))
+	_, links := test.ListWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl)
+	assert.Empty(s.T(), links.Data)
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/workitem/link"
+)
+
+// Graph runs the graph action.
+func (c *WorkItemLinkController) Graph(ctx *app.GraphWorkItemLinkContext) error {
+	if (ctx.Root == nil) == (ctx.Filter == nil) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("root + filter", nil).Expected("either a root work item or a filter"))
+	}
+	filter := link.GraphFilter{
+		LinkTypeIDs:     ctx.FilterLinktype,
+		LinkCategoryIDs: ctx.FilterLinkcategory,
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		var wiIDs []uint64
+		var depth int
+		if ctx.Root != nil {
+			// all the links are walked from a root work item by default
+			depth = -1
+			wi, err := appl.WorkItems().Load(ctx, *ctx.Root)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			id, err := strconv.ParseUint(wi.ID, 10, 64)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+			}
+			wiIDs = append(wiIDs, id)
+		} else {
+			exp, err := parseWorkItemQuery(ctx, ctx.Filter)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
+			}
+			offset, limit := 0, link.GraphMaxNodes+1
+			wis, _, err := appl.WorkItems().List(ctx, exp, nil, &offset, &limit)
+			if err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+			if len(wis) > link.GraphMaxNodes {
+				return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("filter", *ctx.Filter).Expected("a filter matching at most "+strconv.Itoa(link.GraphMaxNodes)+" work items"))
+			}
+			for _, wi := range wis {
+				id, err := strconv.ParseUint(wi.ID, 10, 64)
+				if err != nil {
+					return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(err.Error()))
+				}
+				wiIDs = append(wiIDs, id)
+			}
+		}
+		if ctx.Depth != nil {
+			depth = *ctx.Depth
+		}
+		graph, err := appl.WorkItemLinks().LoadGraph(ctx, wiIDs, depth, filter)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		contentType, write := link.GraphContentTypeJSON, link.WriteJSON
+		if ctx.Format != nil && *ctx.Format == "dot" {
+			contentType, write = link.GraphContentTypeDOT, link.WriteDOT
+		}
+		ctx.ResponseData.Header().Set("Content-Type", contentType)
+		ctx.ResponseData.WriteHeader(http.StatusOK)
+		return write(ctx.ResponseData, *graph)
+	})
+}
@@ -0,0 +1,107 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+
+	"github.com/almighty/almighty-core/app/test"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphIDs returns the IDs of the nodes and the source and target IDs of the edges of the given JSON graph
+func graphIDs(body []byte) ([]string, [][]string, error) {
+	var g struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+			Edges []struct {
+				Source string `json:"source"`
+				Target string `json:"target"`
+			} `json:"edges"`
+		} `json:"graph"`
+	}
+	if err := json.Unmarshal(body, &g); err != nil {
+		return nil, nil, err
+	}
+	nodes := []string{}
+	for _, n := range g.Graph.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	edges := [][]string{}
+	for _, e := range g.Graph.Edges {
+		edges = append(edges, []string{e.Source, e.Target})
+	}
+	return nodes, edges, nil
+}
+
+func (s *workItemLinkSuite) TestGraphFromRoot() {
+	// given
+	s.createSomeLinks()
+	root := strconv.FormatUint(s.bug1ID, 10)
+	// when
+	res := test.GraphWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, nil, nil, nil, nil, nil, &root)
+	// then
+	assert.Equal(s.T(), link.GraphContentTypeJSON, res.Header().Get("Content-Type"))
+	nodes, edges, err := graphIDs(res.(*httptest.ResponseRecorder).Body.Bytes())
+	require.Nil(s.T(), err)
+	bug1, bug2, bug3 := strconv.FormatUint(s.bug1ID, 10), strconv.FormatUint(s.bug2ID, 10), strconv.FormatUint(s.bug3ID, 10)
+	assert.Equal(s.T(), []string{bug1, bug2, bug3}, nodes)
+	assert.Equal(s.T(), [][]string{{bug1, bug2}, {bug2, bug3}}, edges)
+}
+
+func (s *workItemLinkSuite) TestGraphFromRootToDepth() {
+	// given
+	s.createSomeLinks()
+	root := strconv.FormatUint(s.bug1ID, 10)
+	depth := 1
+	// when
+	res := test.GraphWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, &depth, nil, nil, nil, nil, &root)
+	// then
+	nodes, edges, err := graphIDs(res.(*httptest.ResponseRecorder).Body.Bytes())
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), nodes, 2)
+	assert.Len(s.T(), edges, 1)
+}
+
+func (s *workItemLinkSuite) TestGraphFilteredByLinkType() {
+	// given
+	s.createSomeLinks()
+	root := strconv.FormatUint(s.bug1ID, 10)
+	// when
+	res := test.GraphWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, nil, nil, nil, []uuid.UUID{uuid.NewV4()}, nil, &root)
+	// then
+	nodes, edges, err := graphIDs(res.(*httptest.ResponseRecorder).Body.Bytes())
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), []string{root}, nodes)
+	assert.Empty(s.T(), edges)
+}
+
+func (s *workItemLinkSuite) TestGraphAsDOT() {
+	// given
+	s.createSomeLinks()
+	root := strconv.FormatUint(s.bug1ID, 10)
+	format := "dot"
+	// when
+	res := test.GraphWorkItemLinkOK(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, nil, nil, nil, nil, &format, &root)
+	// then
+	assert.Equal(s.T(), link.GraphContentTypeDOT, res.Header().Get("Content-Type"))
+	body := res.(*httptest.ResponseRecorder).Body.String()
+	assert.Contains(s.T(), body, "digraph workitems {")
+	assert.Contains(s.T(), body, fmt.Sprintf(`"%d" -> "%d" [label="forward name string for test-bug-blocker"];`, s.bug1ID, s.bug2ID))
+}
+
+func (s *workItemLinkSuite) TestGraphBadRequestWithoutRootNorFilter() {
+	test.GraphWorkItemLinkBadRequest(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, nil, nil, nil, nil, nil, nil)
+}
+
+func (s *workItemLinkSuite) TestGraphNotFoundRoot() {
+	root := "88888888"
+	test.GraphWorkItemLinkNotFound(s.T(), s.svc.Context, s.svc, s.workItemLinkCtrl, nil, nil, nil, nil, nil, &root)
+}
@@ -183,6 +183,27 @@ func (s *workItemLinkTypeSuite) TestCreateAndDeleteWorkItemLinkType() {
 	_ = test.DeleteWorkItemLinkTypeOK(s.T(), s.svc.Context, s.svc, s.linkTypeCtrl, *workItemLinkType.Data.ID)
 }
 
+// TestCreateWorkItemLinkTypeWithTopology tests that link types can be created
+// with the directed topologies, e.g. a "blocks / blocked by" dependency
+func (s *workItemLinkTypeSuite) TestCreateWorkItemLinkTypeWithTopology() {
+	createPayload := s.createDemoLinkType(s.linkTypeName)
+	for _, topology := range []string{link.TopologyDependency, link.TopologyDirectedNetwork} {
+		// given
+		name := s.linkTypeName + " " + topology
+		t := topology
+		createPayload.Data.Attributes.Name = &name
+		createPayload.Data.Attributes.Topology = &t
+		// when
+		_, workItemLinkType := test.CreateWorkItemLinkTypeCreated(s.T(), s.svc.Context, s.svc, s.linkTypeCtrl, createPayload)
+		// then
+		require.NotNil(s.T(), workItemLinkType)
+		require.Equal(s.T(), topology, *workItemLinkType.Data.Attributes.Topology)
+		_, readIn := test.ShowWorkItemLinkTypeOK(s.T(), nil, nil, s.linkTypeCtrl, *workItemLinkType.Data.ID, nil, nil)
+		require.NotNil(s.T(), readIn)
+		require.Equal(s.T(), topology, *readIn.Data.Attributes.Topology)
+	}
+}
+
 //func (s *workItemLinkTypeSuite) TestCreateWorkItemLinkTypeBadRequest() {
 //	createPayload := s.createDemoLinkType("") // empty name causes bad request
 //	_, _ = test.CreateWorkItemLinkTypeBadRequest(s.T(), nil, nil, s.linkTypeCtrl, createPayload)
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/reference"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/goadesign/goa"
+)
+
+// WorkItemMentionsController implements the work_item_mentions resource.
+type WorkItemMentionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemMentionsController creates a work_item_mentions controller.
+func NewWorkItemMentionsController(service *goa.Service, db application.DB) *WorkItemMentionsController {
+	return &WorkItemMentionsController{Controller: service.NewController("WorkItemMentionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemMentionsController) List(ctx *app.ListWorkItemMentionsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.WorkItems().Load(ctx, ctx.ID); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		references, err := appl.References().List(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		res := &app.WorkItemMentionList{
+			Data: ConvertWorkItemMentions(ctx.RequestData, references),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// ConvertWorkItemMentions converts between internal and external REST representation
+func ConvertWorkItemMentions(request *goa.RequestData, references []reference.Reference) []*app.WorkItemMention {
+	result := []*app.WorkItemMention{}
+	for _, ref := range references {
+		result = append(result, ConvertWorkItemMention(request, ref))
+	}
+	return result
+}
+
+// ConvertWorkItemMention converts between internal and external REST representation
+func ConvertWorkItemMention(request *goa.RequestData, ref reference.Reference) *app.WorkItemMention {
+	workItemID := strconv.FormatUint(ref.SourceWorkItemID, 10)
+	workItemType := APIStringTypeWorkItem
+	workItemSelf := rest.AbsoluteURL(request, app.WorkitemHref(workItemID))
+	result := &app.WorkItemMention{
+		Type: "mentions",
+		ID:   ref.ID,
+		Attributes: &app.WorkItemMentionAttributes{
+			CreatedAt: ref.CreatedAt,
+		},
+		Relationships: &app.WorkItemMentionRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &workItemType,
+					ID:   &workItemID,
+				},
+				Links: &app.GenericLinks{
+					Self: &workItemSelf,
+				},
+			},
+		},
+	}
+	if ref.CommentID != nil {
+		commentID := ref.CommentID.String()
+		commentType := "comments"
+		commentSelf := rest.AbsoluteURL(request, app.CommentsHref(commentID))
+		result.Relationships.Comment = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: &commentType,
+				ID:   &commentID,
+			},
+			Links: &app.GenericLinks{
+				Self: &commentSelf,
+			},
+		}
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/login"
+	"github.com/almighty/almighty-core/rest"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/goadesign/goa"
+)
+
+// revisionTypes maps the revision types to their names in the REST API
+var revisionTypes = map[workitem.RevisionType]string{
+	workitem.RevisionTypeCreate: "create",
+	workitem.RevisionTypeUpdate: "update",
+	workitem.RevisionTypeDelete: "delete",
+}
+
+// WorkItemRevisionsController implements the work_item_revisions resource.
+type WorkItemRevisionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemRevisionsController creates a work_item_revisions controller.
+func NewWorkItemRevisionsController(service *goa.Service, db application.DB) *WorkItemRevisionsController {
+	return &WorkItemRevisionsController{Controller: service.NewController("WorkItemRevisionsController"), db: db}
+}
+
+// List runs the list action.
+func (c *WorkItemRevisionsController) List(ctx *app.ListWorkItemRevisionsContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		revisions, err := appl.WorkItemRevisions().ListChanges(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		if len(revisions) == 0 {
+			// the revisions of deleted work items are still available, so only
+			// check that the work item exists if there is no revision at all
+			if _, err := appl.WorkItems().Load(ctx, ctx.ID); err != nil {
+				return jsonapi.JSONErrorResponse(ctx, err)
+			}
+		}
+		res := &app.WorkItemRevisionList{
+			Data: ConvertWorkItemRevisions(ctx.RequestData, revisions),
+		}
+		return ctx.OK(res)
+	})
+}
+
+// Restore runs the restore action.
+func (c *WorkItemRevisionsController) Restore(ctx *app.RestoreWorkItemRevisionsContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("missing data.attributes element in request", nil))
+		}
+		wi, err := appl.WorkItems().Restore(ctx, ctx.ID, ctx.RevisionID, ctx.Payload.Data.Attributes.Version, *currentUserIdentityID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeReferences, err := loadWorkItemReferences(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		self := rest.AbsoluteURL(ctx.RequestData, app.WorkitemHref(wi.ID))
+		resp := &app.WorkItem2Single{
+			Data: ConvertWorkItem(ctx.RequestData, wi, includeReferences),
+			Links: &app.WorkItemLinks{
+				Self: self,
+			},
+		}
+		ctx.ResponseData.Header().Set("Last-Modified", lastModified(wi))
+		return ctx.OK(resp)
+	})
+}
+
+// ConvertWorkItemRevisions converts between internal and external REST representation
+func ConvertWorkItemRevisions(request *goa.RequestData, revisions []workitem.RevisionChanges) []*app.WorkItemRevision {
+	result := []*app.WorkItemRevision{}
+	for _, revision := range revisions {
+		result = append(result, ConvertWorkItemRevision(request, revision))
+	}
+	return result
+}
+
+// ConvertWorkItemRevision converts between internal and external REST representation
+func ConvertWorkItemRevision(request *goa.RequestData, revision workitem.RevisionChanges) *app.WorkItemRevision {
+	workItemID := strconv.FormatUint(revision.WorkItemID, 10)
+	workItemType := APIStringTypeWorkItem
+	workItemSelf := rest.AbsoluteURL(request, app.WorkitemHref(workItemID))
+	changes := make([]*app.WorkItemFieldChange, len(revision.Changes))
+	for i, change := range revision.Changes {
+		changes[i] = &app.WorkItemFieldChange{
+			Field:    change.Field,
+			OldValue: change.OldValue,
+			NewValue: change.NewValue,
+			Added:    change.Added,
+			Removed:  change.Removed,
+		}
+	}
+	return &app.WorkItemRevision{
+		Type: "workitemrevisions",
+		ID:   revision.ID,
+		Attributes: &app.WorkItemRevisionAttributes{
+			RevisionType: revisionTypes[revision.Type],
+			CreatedAt:    revision.Time,
+			Version:      revision.WorkItemVersion,
+			Changes:      changes,
+		},
+		Relationships: &app.WorkItemRevisionRelations{
+			Modifier: &app.RevisionModifier{
+				Data: &app.IdentityRelationData{
+					Type: APIStringTypeUser,
+					ID:   &revision.ModifierIdentity,
+				},
+			},
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &workItemType,
+					ID:   &workItemID,
+				},
+				Links: &app.GenericLinks{
+					Self: &workItemSelf,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package controller_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/app/test"
+	. "github.com/almighty/almighty-core/controller"
+	"github.com/almighty/almighty-core/gormapplication"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	almtoken "github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemRevisionsREST struct {
+	gormtestsupport.DBTestSuite
+	db           *gormapplication.GormDB
+	clean        func()
+	testIdentity account.Identity
+	ctx          context.Context
+}
+
+func TestRunWorkItemRevisionsREST(t *testing.T) {
+	suite.Run(t, &TestWorkItemRevisionsREST{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (rest *TestWorkItemRevisionsREST) SetupTest() {
+	resource.Require(rest.T(), resource.Database)
+	rest.db = gormapplication.NewGormDB(rest.DB)
+	rest.clean = cleaner.DeleteCreatedEntities(rest.DB)
+	testIdentity, err := testsupport.CreateTestIdentity(rest.DB, "test user", "test provider")
+	require.Nil(rest.T(), err)
+	rest.testIdentity = testIdentity
+	req := &http.Request{Host: "localhost"}
+	params := url.Values{}
+	rest.ctx = goa.NewContext(context.Background(), nil, req, params)
+}
+
+func (rest *TestWorkItemRevisionsREST) TearDownTest() {
+	rest.clean()
+}
+
+func (rest *TestWorkItemRevisionsREST) SecuredController() (*goa.Service, *WorkItemRevisionsController) {
+	priv, _ := almtoken.ParsePrivateKey([]byte(almtoken.RSAPrivateKey))
+	svc := testsupport.ServiceAsUser("WorkItemRevisions-Service", almtoken.NewManagerWithPrivateKey(priv), rest.testIdentity)
+	return svc, NewWorkItemRevisionsController(svc, rest.db)
+}
+
+func (rest *TestWorkItemRevisionsREST) UnSecuredController() (*goa.Service, *WorkItemRevisionsController) {
+	svc := goa.New("WorkItemRevisions-Service")
+	return svc, NewWorkItemRevisionsController(svc, rest.db)
+}
+
+func (rest *TestWorkItemRevisionsREST) TestListRevisionsOK() {
+	// given
+	repo := workitem.NewWorkItemRepository(rest.DB)
+	wi, err := repo.Create(rest.ctx, space.SystemSpace, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "A",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, rest.testIdentity.ID)
+	require.Nil(rest.T(), err)
+	wi.Fields[workitem.SystemTitle] = "B"
+	_, err = repo.Save(rest.ctx, *wi, rest.testIdentity.ID)
+	require.Nil(rest.T(), err)
+	// when
+	svc, ctrl := rest.UnSecuredController()
+	_, revisions := test.ListWorkItemRevisionsOK(rest.T(), svc.Context, svc, ctrl, wi.ID)
+	// then
+	require.Len(rest.T(), revisions.Data, 2)
+	assert.Equal(rest.T(), "create", revisions.Data[0].Attributes.RevisionType)
+	update := revisions.Data[1]
+	assert.Equal(rest.T(), "update", update.Attributes.RevisionType)
+	require.Len(rest.T(), update.Attributes.Changes, 1)
+	assert.Equal(rest.T(), workitem.SystemTitle, update.Attributes.Changes[0].Field)
+	assert.Equal(rest.T(), "A", update.Attributes.Changes[0].OldValue)
+	assert.Equal(rest.T(), "B", update.Attributes.Changes[0].NewValue)
+	require.NotNil(rest.T(), update.Relationships.Modifier.Data.ID)
+	assert.Equal(rest.T(), rest.testIdentity.ID, *update.Relationships.Modifier.Data.ID)
+	assert.Equal(rest.T(), wi.ID, *update.Relationships.Workitem.Data.ID)
+}
+
+func (rest *TestWorkItemRevisionsREST) TestListRevisionsNotFound() {
+	svc, ctrl := rest.UnSecuredController()
+	test.ListWorkItemRevisionsNotFound(rest.T(), svc.Context, svc, ctrl, "12345678")
+}
+
+func (rest *TestWorkItemRevisionsREST) newRestorePayload(version int) *app.RestoreWorkItemRevisionPayload {
+	return &app.RestoreWorkItemRevisionPayload{
+		Data: &app.RestoreWorkItemRevision{
+			Type: "workitems",
+			Attributes: &app.RestoreWorkItemRevisionAttributes{
+				Version: version,
+			},
+		},
+	}
+}
+
+func (rest *TestWorkItemRevisionsREST) TestRestoreRevisionOK() {
+	// given
+	repo := workitem.NewWorkItemRepository(rest.DB)
+	wi, err := repo.Create(rest.ctx, space.SystemSpace, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "A",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, rest.testIdentity.ID)
+	require.Nil(rest.T(), err)
+	err = repo.Delete(rest.ctx, wi.ID, rest.testIdentity.ID)
+	require.Nil(rest.T(), err)
+	revisions, err := workitem.NewRevisionRepository(rest.DB).List(rest.ctx, wi.ID)
+	require.Nil(rest.T(), err)
+	require.Len(rest.T(), revisions, 2)
+	// when
+	svc, ctrl := rest.SecuredController()
+	_, restored := test.RestoreWorkItemRevisionsOK(rest.T(), svc.Context, svc, ctrl, wi.ID, revisions[0].ID, rest.newRestorePayload(wi.Version))
+	// then
+	assert.Equal(rest.T(), wi.ID, *restored.Data.ID)
+	assert.Equal(rest.T(), "A", restored.Data.Attributes[workitem.SystemTitle])
+	assert.Equal(rest.T(), wi.Version+1, restored.Data.Attributes["version"])
+}
+
+func (rest *TestWorkItemRevisionsREST) TestRestoreRevisionUnauthorized() {
+	svc, ctrl := rest.UnSecuredController()
+	test.RestoreWorkItemRevisionsUnauthorized(rest.T(), svc.Context, svc, ctrl, "12345678", uuid.NewV4(), rest.newRestorePayload(0))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"html"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -15,12 +16,13 @@ import (
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/jsonapi"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/login"
-	query "github.com/almighty/almighty-core/query/simple"
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
 
 	"github.com/goadesign/goa"
 	errs "github.com/pkg/errors"
@@ -34,6 +36,9 @@ const (
 	APIStringTypeWorkItemType = "workitemtypes"
 )
 
+// WorkItemAttributeBlocked is the computed attribute telling whether a work item has unresolved predecessors
+const WorkItemAttributeBlocked = "blocked"
+
 // WorkitemController implements the workitem resource.
 type WorkitemController struct {
 	*goa.Controller
@@ -53,10 +58,20 @@ func NewWorkitemController(service *goa.Service, db application.DB) *WorkitemCon
 // Last will always be present. Total Item count needs to be computed from the "Last" link.
 func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 	var additionalQuery []string
-	exp, err := query.Parse(ctx.Filter)
+	exp, err := parseWorkItemQuery(ctx, ctx.Filter)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("could not parse filter", err))
 	}
+	if ctx.Filter != nil {
+		additionalQuery = append(additionalQuery, "filter="+url.QueryEscape(*ctx.Filter))
+	}
+	sortKeys, err := parseWorkItemSort(ctx.Sort)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if ctx.Sort != nil {
+		additionalQuery = append(additionalQuery, "sort="+url.QueryEscape(*ctx.Sort))
+	}
 	if ctx.FilterAssignee != nil {
 		exp = criteria.And(exp, criteria.Equals(criteria.Field("system.assignees"), criteria.Literal([]string{*ctx.FilterAssignee})))
 		additionalQuery = append(additionalQuery, "filter[assignee]="+*ctx.FilterAssignee)
@@ -79,8 +94,15 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 	}
 
 	offset, limit := computePagingLimts(ctx.PageOffset, ctx.PageLimit)
+	if ctx.PageCursor != nil {
+		cursor, err := keyset.Parse(*ctx.PageCursor)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		return c.listByCursor(ctx, exp, sortKeys, *cursor, limit, additionalQuery)
+	}
 	return application.Transactional(c.db, func(tx application.Application) error {
-		result, tc, err := tx.WorkItems().List(ctx.Context, exp, &offset, &limit)
+		result, tc, err := tx.WorkItems().List(ctx.Context, exp, sortKeys, &offset, &limit)
 		count := int(tc)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error listing work items"))
@@ -97,10 +119,18 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 			}
 		}
 
+		includeReferences, err := loadWorkItemReferences(ctx, tx, ctx.RequestData, result...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeBlocked, err := loadWorkItemBlocked(ctx, tx, result...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
 		response := app.WorkItem2List{
 			Links: &app.PagingLinks{},
 			Meta:  &app.WorkItemListResponseMeta{TotalCount: count},
-			Data:  ConvertWorkItems(ctx.RequestData, result),
+			Data:  ConvertWorkItems(ctx.RequestData, result, includeReferences, includeBlocked),
 		}
 		setPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), len(result), offset, limit, count, additionalQuery...)
 		addFilterLinks(response.Links, ctx.RequestData)
@@ -110,6 +140,45 @@ func (c *WorkitemController) List(ctx *app.ListWorkitemContext) error {
 	})
 }
 
+// listByCursor lists the page of work items after (or before) the given cursor
+func (c *WorkitemController) listByCursor(ctx *app.ListWorkitemContext, exp criteria.Expression, sortKeys []workitem.SortKey, cursor keyset.Cursor, limit int, additionalQuery []string) error {
+	return application.Transactional(c.db, func(tx application.Application) error {
+		result, page, err := tx.WorkItems().ListByCursor(ctx.Context, exp, sortKeys, cursor, limit)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error listing work items"))
+		}
+
+		lastMod := findLastModified(result)
+
+		if ifMod, ok := ctx.RequestData.Header["If-Modified-Since"]; ok {
+			ifModSince, err := http.ParseTime(ifMod[0])
+			if err == nil {
+				if lastMod.Before(ifModSince) || lastMod.Equal(ifModSince) {
+					return ctx.NotModified()
+				}
+			}
+		}
+
+		includeReferences, err := loadWorkItemReferences(ctx, tx, ctx.RequestData, result...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeBlocked, err := loadWorkItemBlocked(ctx, tx, result...)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		response := app.WorkItem2List{
+			Links: &app.PagingLinks{},
+			Data:  ConvertWorkItems(ctx.RequestData, result, includeReferences, includeBlocked),
+		}
+		setCursorPagingLinks(response.Links, buildAbsoluteURL(ctx.RequestData), limit, page, additionalQuery...)
+		addFilterLinks(response.Links, ctx.RequestData)
+
+		ctx.ResponseData.Header().Set("Last-Modified", lastModifiedTime(lastMod))
+		return ctx.OK(&response)
+	})
+}
+
 // Update does PATCH workitem
 func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	currentUserIdentityID, err := login.ContextIdentity(ctx)
@@ -137,7 +206,11 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, "Error updating work item"))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi)
+		includeReferences, err := loadWorkItemReferences(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi2 := ConvertWorkItem(ctx.RequestData, wi, includeReferences)
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 			Links: &app.WorkItemLinks{
@@ -230,7 +303,11 @@ func (c *WorkitemController) Create(ctx *app.CreateWorkitemContext) error {
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errs.Wrap(err, fmt.Sprintf("Error creating work item")))
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi)
+		includeReferences, err := loadWorkItemReferences(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi2 := ConvertWorkItem(ctx.RequestData, wi, includeReferences)
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 			Links: &app.WorkItemLinks{
@@ -261,7 +338,15 @@ func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 				}
 			}
 		}
-		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments)
+		includeReferences, err := loadWorkItemReferences(ctx, appl, ctx.RequestData, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		includeBlocked, err := loadWorkItemBlocked(ctx, appl, wi)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		wi2 := ConvertWorkItem(ctx.RequestData, wi, comments, includeReferences, includeBlocked)
 		resp := &app.WorkItem2Single{
 			Data: wi2,
 		}
@@ -555,6 +640,93 @@ func (c *WorkitemController) ListChildren(ctx *app.ListChildrenWorkitemContext)
 	})
 }
 
+// ListAncestors runs the list-ancestors action.
+func (c *WorkitemController) ListAncestors(ctx *app.ListAncestorsWorkitemContext) error {
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.WorkItemLinks().ListWorkItemAncestors(ctx, ctx.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		response := app.WorkItem2List{
+			Data: ConvertWorkItems(ctx.RequestData, result),
+		}
+		return ctx.OK(&response)
+	})
+}
+
+// ListDescendants runs the list-descendants action.
+func (c *WorkitemController) ListDescendants(ctx *app.ListDescendantsWorkitemContext) error {
+	var depth int
+	if ctx.Depth != nil {
+		depth = *ctx.Depth
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		result, err := appl.WorkItemLinks().ListWorkItemDescendants(ctx, ctx.ID, depth)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		response := app.WorkItem2List{
+			Data: ConvertWorkItems(ctx.RequestData, result),
+		}
+		return ctx.OK(&response)
+	})
+}
+
+// ShowTree runs the show-tree action.
+func (c *WorkitemController) ShowTree(ctx *app.ShowTreeWorkitemContext) error {
+	var depth int
+	if ctx.Depth != nil {
+		depth = *ctx.Depth
+	}
+	return application.Transactional(c.db, func(appl application.Application) error {
+		tree, err := appl.WorkItemLinks().LoadWorkItemTree(ctx, ctx.ID, depth)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+		response := app.WorkItemTreeSingle{
+			Data: ConvertWorkItemTree(ctx.RequestData, tree),
+		}
+		return ctx.OK(&response)
+	})
+}
+
+// ConvertWorkItemTree converts a work item with its descendants from internal to external REST representation
+func ConvertWorkItemTree(request *goa.RequestData, node *link.WorkItemTreeNode) *app.WorkItemTreeNode {
+	converted := &app.WorkItemTreeNode{
+		Data:     ConvertWorkItem(request, node.WorkItem),
+		Children: make([]*app.WorkItemTreeNode, len(node.Children)),
+	}
+	for i, child := range node.Children {
+		converted.Children[i] = ConvertWorkItemTree(request, child)
+	}
+	return converted
+}
+
+// loadWorkItemBlocked returns a WorkItemConvertFunc which tells whether the given work items
+// are blocked by unresolved predecessors
+func loadWorkItemBlocked(ctx context.Context, appl application.Application, wis ...*app.WorkItem) (WorkItemConvertFunc, error) {
+	ids := make([]uint64, 0, len(wis))
+	for _, wi := range wis {
+		if id, err := strconv.ParseUint(wi.ID, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	blockers, err := appl.WorkItemLinks().ListBlockers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return WorkItemIncludeBlocked(blockers), nil
+}
+
+// WorkItemIncludeBlocked sets the computed "blocked" attribute of the WorkItem, true if the given
+// unresolved predecessors by work item hold some for it
+func WorkItemIncludeBlocked(blockers map[uint64][]uint64) WorkItemConvertFunc {
+	return func(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
+		id, _ := strconv.ParseUint(wi.ID, 10, 64)
+		wi2.Attributes[WorkItemAttributeBlocked] = len(blockers[id]) > 0
+	}
+}
+
 // WorkItemIncludeChildren adds relationship about children to workitem (include totalCount)
 func WorkItemIncludeChildren(request *goa.RequestData, wi *app.WorkItem, wi2 *app.WorkItem2) {
 	childrenRelated := rest.AbsoluteURL(request, app.WorkitemHref(wi.ID)) + "/children"
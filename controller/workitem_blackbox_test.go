@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -345,14 +346,14 @@ func (s *WorkItemSuite) TestListByFields() {
 	filter := "{\"system.title\":\"run integration test\"}"
 	offset := "0"
 	limit := 1
-	_, result := test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result := test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	// then
 	require.NotNil(s.T(), result)
 	require.Equal(s.T(), 1, len(result.Data))
 	// when
 	filter = fmt.Sprintf("{\"system.creator\":\"%s\"}", s.testIdentity.ID.String())
 	// then
-	_, result = test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, &limit, &offset)
+	_, result = test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 	require.NotNil(s.T(), result)
 	require.Equal(s.T(), 1, len(result.Data))
 }
@@ -484,6 +485,107 @@ func getWorkItemTestData(t *testing.T) []testSecureAPI {
 	}
 }
 
+func (s *WorkItemSuite) TestListByQuery() {
+	// given
+	payload := minimumRequiredCreateWithType(workitem.SystemBug)
+	payload.Data.Attributes[workitem.SystemTitle] = "run query language test"
+	payload.Data.Attributes[workitem.SystemState] = workitem.SystemStateClosed
+	test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.controller, &payload)
+	offset := "0"
+	limit := 1
+	// when
+	filter := `title = "run query language test" AND state IN ("open", "closed")`
+	_, result := test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
+	// then
+	require.NotNil(s.T(), result)
+	require.Equal(s.T(), 1, len(result.Data))
+	require.NotNil(s.T(), result.Links.First)
+	assert.Contains(s.T(), *result.Links.First, "filter=")
+	// when
+	filter = `title = "run query language test" AND state = "open"`
+	_, result = test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
+	// then
+	require.NotNil(s.T(), result)
+	require.Equal(s.T(), 0, len(result.Data))
+	// when
+	filter = `title = "run query language test" AND (state = "open"`
+	_, jerrs := test.ListWorkitemBadRequest(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
+	// then
+	require.NotNil(s.T(), jerrs)
+	require.Len(s.T(), jerrs.Errors, 1)
+	assert.Contains(s.T(), jerrs.Errors[0].Detail, "position")
+}
+
+func (s *WorkItemSuite) TestListSorted() {
+	// given
+	title := "run sort test " + uuid.NewV4().String()
+	for _, state := range []string{workitem.SystemStateOpen, workitem.SystemStateClosed} {
+		payload := minimumRequiredCreateWithType(workitem.SystemBug)
+		payload.Data.Attributes[workitem.SystemTitle] = title
+		payload.Data.Attributes[workitem.SystemState] = state
+		test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.controller, &payload)
+	}
+	filter := fmt.Sprintf("title = %q", title)
+	offset := "0"
+	limit := 1
+	// when
+	sort := "-state"
+	_, result := test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, &sort)
+	// then
+	require.NotNil(s.T(), result)
+	require.Len(s.T(), result.Data, 1)
+	assert.Equal(s.T(), workitem.SystemStateOpen, result.Data[0].Attributes[workitem.SystemState])
+	require.NotNil(s.T(), result.Links.Next)
+	assert.Contains(s.T(), *result.Links.Next, "sort=-state")
+	// when
+	sort = "state"
+	_, result = test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, &sort)
+	// then
+	require.Len(s.T(), result.Data, 1)
+	assert.Equal(s.T(), workitem.SystemStateClosed, result.Data[0].Attributes[workitem.SystemState])
+	// when
+	sort = "state,'foo"
+	test.ListWorkitemBadRequest(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, nil, &limit, &offset, &sort)
+}
+
+func (s *WorkItemSuite) TestListByCursor() {
+	// given
+	title := "run cursor test " + uuid.NewV4().String()
+	for _, state := range []string{workitem.SystemStateOpen, workitem.SystemStateClosed} {
+		payload := minimumRequiredCreateWithType(workitem.SystemBug)
+		payload.Data.Attributes[workitem.SystemTitle] = title
+		payload.Data.Attributes[workitem.SystemState] = state
+		test.CreateWorkitemCreated(s.T(), s.svc.Context, s.svc, s.controller, &payload)
+	}
+	filter := fmt.Sprintf("title = %q", title)
+	sort := "state"
+	cursor := ""
+	limit := 1
+	// when
+	_, result := test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, &cursor, &limit, nil, &sort)
+	// then
+	require.Len(s.T(), result.Data, 1)
+	assert.Equal(s.T(), workitem.SystemStateClosed, result.Data[0].Attributes[workitem.SystemState])
+	assert.Nil(s.T(), result.Meta)
+	assert.Nil(s.T(), result.Links.Prev)
+	assert.Nil(s.T(), result.Links.Last)
+	require.NotNil(s.T(), result.Links.Next)
+	assert.Contains(s.T(), *result.Links.Next, "sort=state")
+	next, err := url.Parse(*result.Links.Next)
+	require.Nil(s.T(), err)
+	cursor = next.Query().Get("page[cursor]")
+	// when
+	_, result = test.ListWorkitemOK(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, &cursor, &limit, nil, &sort)
+	// then
+	require.Len(s.T(), result.Data, 1)
+	assert.Equal(s.T(), workitem.SystemStateOpen, result.Data[0].Attributes[workitem.SystemState])
+	assert.NotNil(s.T(), result.Links.Prev)
+	assert.Nil(s.T(), result.Links.Next)
+	// when
+	cursor = "not a cursor"
+	test.ListWorkitemBadRequest(s.T(), nil, nil, s.controller, &filter, nil, nil, nil, nil, nil, &cursor, &limit, nil, &sort)
+}
+
 // This test case will check authorized access to Create/Update/Delete APIs
 func (s *WorkItemSuite) TestUnauthorizeWorkItemCUD() {
 	UnauthorizeCreateUpdateDeleteTest(s.T(), getWorkItemTestData, func() *goa.Service {
@@ -501,7 +603,7 @@ func createPagingTest(t *testing.T, ctx context.Context, controller *WorkitemCon
 		repo.ListReturns(makeWorkItems(count), uint64(totalCount), nil)
 		offset := strconv.Itoa(start)
 
-		_, response := test.ListWorkitemOK(t, ctx, nil, controller, nil, nil, nil, nil, nil, nil, &limit, &offset)
+		_, response := test.ListWorkitemOK(t, ctx, nil, controller, nil, nil, nil, nil, nil, nil, nil, &limit, &offset, nil)
 		assertLink(t, "first", first, response.Links.First)
 		assertLink(t, "last", last, response.Links.Last)
 		assertLink(t, "prev", prev, response.Links.Prev)
@@ -1247,7 +1349,7 @@ func (s *WorkItem2Suite) TestWI2ListByAssigneeFilter() {
 	assert.Len(s.T(), wi.Data.Relationships.Assignees.Data, 1)
 	assert.Equal(s.T(), newUser.ID.String(), *wi.Data.Relationships.Assignees.Data[0].ID)
 	newUserID := newUser.ID.String()
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, &newUserID, nil, nil, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, &newUserID, nil, nil, nil, nil, nil, nil, nil)
 	assert.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), newUser.ID.String(), *list.Data[0].Relationships.Assignees.Data[0].ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[assignee]"))
@@ -1270,7 +1372,7 @@ func (s *WorkItem2Suite) TestWI2ListByWorkitemtypeFilter() {
 	assert.NotNil(s.T(), expected.Data)
 	require.NotNil(s.T(), expected.Data.ID)
 	require.NotNil(s.T(), expected.Data.Type)
-	_, actual := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, nil, nil, &workitem.SystemBug, nil, nil)
+	_, actual := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, nil, nil, &workitem.SystemBug, nil, nil, nil, nil)
 	require.NotNil(s.T(), actual)
 	require.True(s.T(), len(actual.Data) > 1)
 	assert.Contains(s.T(), *actual.Links.First, fmt.Sprintf("filter[workitemtype]=%s", workitem.SystemBug))
@@ -1313,7 +1415,7 @@ func (s *WorkItem2Suite) TestWI2ListByWorkitemstateFilter() {
 	dataArray = append(dataArray, expected)
 	wiNew := workitem.SystemStateNew
 	// var foundExpected bool
-	_, actual := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, nil, &wiNew, nil, nil, nil)
+	_, actual := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, nil, &wiNew, nil, nil, nil, nil, nil)
 
 	require.NotNil(s.T(), actual)
 	require.True(s.T(), len(actual.Data) > 1)
@@ -1354,7 +1456,7 @@ func (s *WorkItem2Suite) TestWI2ListByAreaFilter() {
 	require.NotNil(s.T(), wi.Data.Relationships.Area)
 	assert.Equal(s.T(), areaID, *wi.Data.Relationships.Area.Data.ID)
 
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, &areaID, nil, nil, nil, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, &areaID, nil, nil, nil, nil, nil, nil, nil, nil)
 	require.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), areaID, *list.Data[0].Relationships.Area.Data.ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[area]"))
@@ -1386,7 +1488,7 @@ func (s *WorkItem2Suite) TestWI2ListByIterationFilter() {
 	require.NotNil(s.T(), wi.Data.Relationships.Iteration)
 	assert.Equal(s.T(), iterationID, *wi.Data.Relationships.Iteration.Data.ID)
 
-	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, &iterationID, nil, nil, nil, nil)
+	_, list := test.ListWorkitemOK(s.T(), s.svc.Context, s.svc, s.wi2Ctrl, nil, nil, nil, &iterationID, nil, nil, nil, nil, nil, nil)
 	require.Len(s.T(), list.Data, 1)
 	assert.Equal(s.T(), iterationID, *list.Data[0].Relationships.Iteration.Data.ID)
 	assert.True(s.T(), strings.Contains(*list.Links.First, "filter[iteration]"))
@@ -35,12 +35,28 @@ type BinaryExpression interface {
 	Right() Expression
 }
 
+// UnaryExpression represents expressions with a single child
+type UnaryExpression interface {
+	Expression
+	Operand() Expression
+}
+
 // ExpressionVisitor is an implementation of the visitor pattern for expressions
 type ExpressionVisitor interface {
 	Field(t *FieldExpression) interface{}
 	And(a *AndExpression) interface{}
 	Or(a *OrExpression) interface{}
+	Not(n *NotExpression) interface{}
 	Equals(e *EqualsExpression) interface{}
+	NotEquals(e *NotEqualsExpression) interface{}
+	LessThan(e *LessThanExpression) interface{}
+	LessThanOrEqual(e *LessThanOrEqualExpression) interface{}
+	GreaterThan(e *GreaterThanExpression) interface{}
+	GreaterThanOrEqual(e *GreaterThanOrEqualExpression) interface{}
+	In(e *InExpression) interface{}
+	Like(e *LikeExpression) interface{}
+	ILike(e *ILikeExpression) interface{}
+	IsNull(e *IsNullExpression) interface{}
 	Parameter(v *ParameterExpression) interface{}
 	Literal(c *LiteralExpression) interface{}
 }
@@ -198,3 +214,208 @@ func (t *EqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
 func Equals(left Expression, right Expression) Expression {
 	return reparent(&EqualsExpression{binaryExpression{expression{}, left, right}})
 }
+
+// !=
+
+// NotEqualsExpression represents the inequality operator
+type NotEqualsExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *NotEqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.NotEquals(t)
+}
+
+// NotEquals constructs a NotEqualsExpression
+func NotEquals(left Expression, right Expression) Expression {
+	return reparent(&NotEqualsExpression{binaryExpression{expression{}, left, right}})
+}
+
+// <
+
+// LessThanExpression represents the "less than" operator
+type LessThanExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *LessThanExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.LessThan(t)
+}
+
+// LessThan constructs a LessThanExpression
+func LessThan(left Expression, right Expression) Expression {
+	return reparent(&LessThanExpression{binaryExpression{expression{}, left, right}})
+}
+
+// <=
+
+// LessThanOrEqualExpression represents the "less than or equal" operator
+type LessThanOrEqualExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *LessThanOrEqualExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.LessThanOrEqual(t)
+}
+
+// LessThanOrEqual constructs a LessThanOrEqualExpression
+func LessThanOrEqual(left Expression, right Expression) Expression {
+	return reparent(&LessThanOrEqualExpression{binaryExpression{expression{}, left, right}})
+}
+
+// >
+
+// GreaterThanExpression represents the "greater than" operator
+type GreaterThanExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *GreaterThanExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.GreaterThan(t)
+}
+
+// GreaterThan constructs a GreaterThanExpression
+func GreaterThan(left Expression, right Expression) Expression {
+	return reparent(&GreaterThanExpression{binaryExpression{expression{}, left, right}})
+}
+
+// >=
+
+// GreaterThanOrEqualExpression represents the "greater than or equal" operator
+type GreaterThanOrEqualExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *GreaterThanOrEqualExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.GreaterThanOrEqual(t)
+}
+
+// GreaterThanOrEqual constructs a GreaterThanOrEqualExpression
+func GreaterThanOrEqual(left Expression, right Expression) Expression {
+	return reparent(&GreaterThanOrEqualExpression{binaryExpression{expression{}, left, right}})
+}
+
+// like
+
+// LikeExpression represents the case-sensitive pattern matching operator.
+// The right side is a pattern where "%" matches any sequence of characters and "_" any single character
+type LikeExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *LikeExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.Like(t)
+}
+
+// Like constructs a LikeExpression
+func Like(left Expression, right Expression) Expression {
+	return reparent(&LikeExpression{binaryExpression{expression{}, left, right}})
+}
+
+// ilike
+
+// ILikeExpression represents the case-insensitive variant of LikeExpression
+type ILikeExpression struct {
+	binaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *ILikeExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.ILike(t)
+}
+
+// ILike constructs an ILikeExpression
+func ILike(left Expression, right Expression) Expression {
+	return reparent(&ILikeExpression{binaryExpression{expression{}, left, right}})
+}
+
+// in
+
+// InExpression tests whether the left expression is equal to one of the given values
+type InExpression struct {
+	expression
+	left   Expression
+	values []Expression
+}
+
+// Accept implements ExpressionVisitor
+func (t *InExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.In(t)
+}
+
+// Left returns the tested expression
+func (t *InExpression) Left() Expression {
+	return t.left
+}
+
+// Values returns the expressions the left expression is compared with
+func (t *InExpression) Values() []Expression {
+	return t.values
+}
+
+// In constructs an InExpression
+func In(left Expression, values ...Expression) Expression {
+	result := &InExpression{expression{}, left, values}
+	left.setParent(result)
+	for _, value := range values {
+		value.setParent(result)
+	}
+	return result
+}
+
+// unaryExpression is an "abstract" type for unary expressions.
+type unaryExpression struct {
+	expression
+	operand Expression
+}
+
+// Operand implements UnaryExpression
+func (exp *unaryExpression) Operand() Expression {
+	return exp.operand
+}
+
+// make sure the child has the correct parent
+func reparentUnary(parent UnaryExpression) Expression {
+	parent.Operand().setParent(parent)
+	return parent
+}
+
+// Not
+
+// NotExpression represents the negation of a term
+type NotExpression struct {
+	unaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *NotExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.Not(t)
+}
+
+// Not constructs a NotExpression
+func Not(operand Expression) Expression {
+	return reparentUnary(&NotExpression{unaryExpression{expression{}, operand}})
+}
+
+// is null
+
+// IsNullExpression tests whether the operand has no value
+type IsNullExpression struct {
+	unaryExpression
+}
+
+// Accept implements ExpressionVisitor
+func (t *IsNullExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.IsNull(t)
+}
+
+// IsNull constructs an IsNullExpression
+func IsNull(operand Expression) Expression {
+	return reparentUnary(&IsNullExpression{unaryExpression{expression{}, operand}})
+}
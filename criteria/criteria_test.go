@@ -15,3 +15,25 @@ func TestGetParent(t *testing.T) {
 		t.Errorf("parent should be %v, but is %v", expr, l.Parent())
 	}
 }
+
+func TestGetParentOfUnaryAndInExpressions(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	f := Field("a")
+	isNull := IsNull(f)
+	if f.Parent() != isNull {
+		t.Errorf("parent should be %v, but is %v", isNull, f.Parent())
+	}
+	not := Not(isNull)
+	if isNull.Parent() != not {
+		t.Errorf("parent should be %v, but is %v", not, isNull.Parent())
+	}
+	l := Field("b")
+	v1 := Literal(1)
+	v2 := Literal(2)
+	in := In(l, v1, v2)
+	for _, child := range []Expression{l, v1, v2} {
+		if child.Parent() != in {
+			t.Errorf("parent should be %v, but is %v", in, child.Parent())
+		}
+	}
+}
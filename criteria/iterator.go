@@ -27,6 +27,54 @@ func (i *postOrderIterator) Equals(exp *EqualsExpression) interface{} {
 	return i.binary(exp)
 }
 
+func (i *postOrderIterator) Not(exp *NotExpression) interface{} {
+	return i.unary(exp)
+}
+
+func (i *postOrderIterator) NotEquals(exp *NotEqualsExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) LessThan(exp *LessThanExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) LessThanOrEqual(exp *LessThanOrEqualExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) GreaterThan(exp *GreaterThanExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) GreaterThanOrEqual(exp *GreaterThanOrEqualExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) Like(exp *LikeExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) ILike(exp *ILikeExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) In(exp *InExpression) interface{} {
+	if exp.Left().Accept(i) == false {
+		return false
+	}
+	for _, value := range exp.Values() {
+		if value.Accept(i) == false {
+			return false
+		}
+	}
+	return i.visit(exp)
+}
+
+func (i *postOrderIterator) IsNull(exp *IsNullExpression) interface{} {
+	return i.unary(exp)
+}
+
 func (i *postOrderIterator) Parameter(exp *ParameterExpression) interface{} {
 	return i.visit(exp)
 }
@@ -44,3 +92,10 @@ func (i *postOrderIterator) binary(exp BinaryExpression) bool {
 	}
 	return i.visit(exp)
 }
+
+func (i *postOrderIterator) unary(exp UnaryExpression) bool {
+	if exp.Operand().Accept(i) == false {
+		return false
+	}
+	return i.visit(exp)
+}
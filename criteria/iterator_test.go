@@ -41,3 +41,23 @@ func TestIterator(t *testing.T) {
 	}
 
 }
+
+func TestIteratorUnaryAndIn(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+
+	visited := []Expression{}
+	recorder := func(expr Expression) bool {
+		visited = append(visited, expr)
+		return true
+	}
+	f := Field("a")
+	v1 := Literal(1)
+	v2 := Literal(2)
+	in := In(f, v1, v2)
+	not := Not(in)
+	IteratePostOrder(not, recorder)
+	expected := []Expression{f, v1, v2, in, not}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Errorf("Visited should be %v, but is %v", expected, visited)
+	}
+}
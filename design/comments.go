@@ -42,6 +42,10 @@ var commentAttributes = a.Type("CommentAttributes", func() {
 	a.Attribute("markup", d.String, "The comment markup associated with the body", func() {
 		a.Example("Markdown")
 	})
+	a.Attribute("edited", d.Boolean, "Whether the comment was changed after its creation")
+	a.Attribute("revision-count", d.Integer, "The number of revisions of the comment", func() {
+		a.Example(2)
+	})
 })
 
 var createCommentAttributes = a.Type("CreateCommentAttributes", func() {
@@ -74,6 +78,12 @@ var identityRelationData = a.Type("IdentityRelationData", func() {
 	a.Required("type")
 })
 
+// revisionModifier is the relation of the revisions of work items and of comments to their modifier
+var revisionModifier = a.Type("RevisionModifier", func() {
+	a.Attribute("data", identityRelationData)
+	a.Required("data")
+})
+
 var commentRelationshipsArray = JSONList(
 	"CommentRelationship", "Holds the response of comments",
 	comment,
@@ -104,6 +114,60 @@ var createSingleComment = JSONSingle(
 	nil,
 )
 
+var commentRevision = a.Type("CommentRevision", func() {
+	a.Description(`JSONAPI store for a revision of a comment. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("commentrevisions")
+	})
+	a.Attribute("id", d.UUID, "ID of the revision", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", commentRevisionAttributes)
+	a.Attribute("relationships", commentRevisionRelationships)
+	a.Required("type", "id", "attributes", "relationships")
+})
+
+var commentRevisionAttributes = a.Type("CommentRevisionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a comment revision. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("revision-type", d.String, "The kind of modification", func() {
+		a.Enum("create", "update", "delete")
+	})
+	a.Attribute("created-at", d.DateTime, "When the modification happened", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("body", d.String, "The comment body after the modification, missing if the comment was deleted", func() {
+		a.Example("This is really interesting")
+	})
+	a.Attribute("body.rendered", d.String, "The comment body after the modification rendered in HTML", func() {
+		a.Example("<p>This is really interesting</p>\n")
+	})
+	a.Attribute("markup", d.String, "The comment markup after the modification", func() {
+		a.Example("Markdown")
+	})
+	a.Attribute("previous-body", d.String, "The comment body before the modification, missing for the creation", func() {
+		a.Example("This is interesting")
+	})
+	a.Attribute("previous-body.rendered", d.String, "The comment body before the modification rendered in HTML", func() {
+		a.Example("<p>This is interesting</p>\n")
+	})
+	a.Attribute("previous-markup", d.String, "The comment markup before the modification", func() {
+		a.Example("Markdown")
+	})
+	a.Required("revision-type", "created-at")
+})
+
+var commentRevisionRelationships = a.Type("CommentRevisionRelations", func() {
+	a.Attribute("modifier", revisionModifier, "The identity which made the modification")
+	a.Attribute("comment", relationGeneric, "The comment that was modified")
+	a.Required("modifier", "comment")
+})
+
+var commentRevisionList = JSONList(
+	"CommentRevision", "Holds the revisions of a comment, oldest first",
+	commentRevision,
+	nil,
+	nil)
+
 var _ = a.Resource("comments", func() {
 	a.BasePath("/comments")
 
@@ -157,6 +221,21 @@ var _ = a.Resource("comments", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.Forbidden, JSONAPIErrors)
 	})
+	a.Action("revisions", func() {
+		a.Routing(
+			a.GET("/:commentId/revisions"),
+		)
+		a.Description("List the revisions of the comment with the given commentId along with the bodies before and after each modification.")
+		a.Params(func() {
+			a.Param("commentId", d.UUID, "commentId")
+		})
+		a.Response(d.OK, func() {
+			a.Media(commentRevisionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
 
 })
 
@@ -172,6 +251,9 @@ var _ = a.Resource("work_item_comments", func() {
 			a.Param("page[offset]", d.String, `Paging start position is a string pointing to
 			the beginning of pagination.  The value starts from 0 onwards.`)
 			a.Param("page[limit]", d.Integer, `Paging size is the number of items in a page`)
+			a.Param("page[cursor]", d.String, `Opaque paging position taken from the prev/next links. Selects cursor based paging,
+				which is not affected by items being added or removed between requests but does not return
+				a total count. Leave it empty to get the first page`)
 		})
 		a.Response(d.OK, func() {
 			a.Media(commentArray)
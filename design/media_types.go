@@ -86,15 +86,18 @@ var Tracker = a.MediaType("application/vnd.tracker+json", func() {
 	a.Attribute("id", d.String, "unique id per tracker")
 	a.Attribute("url", d.String, "URL of the tracker")
 	a.Attribute("type", d.String, "Type of the tracker")
+	a.Attribute("webhookEnabled", d.Boolean, "Whether the tracker accepts the events of its webhooks, i.e. if a webhook secret is set")
 
 	a.Required("id")
 	a.Required("url")
 	a.Required("type")
+	a.Required("webhookEnabled")
 
 	a.View("default", func() {
 		a.Attribute("id")
 		a.Attribute("url")
 		a.Attribute("type")
+		a.Attribute("webhookEnabled")
 	})
 })
 
@@ -105,12 +108,17 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 	a.Attribute("id", d.String, "unique id per installation")
 	a.Attribute("query", d.String, "Search query")
 	a.Attribute("schedule", d.String, "Schedule for fetch and import")
+	a.Attribute("syncPolicy", d.String, "Policy applied to the fields modified both locally and on the remote tracker")
+	a.Attribute("lastUpdatedAt", d.DateTime, "Last update time of the remote items imported so far")
+	a.Attribute("workItemTypeID", d.UUID, "Type of the work items created by the tracker query")
+	a.Attribute("fieldMappings", a.ArrayOf(trackerQueryFieldMapping), "Mappings of the remote attributes to the work item fields, overriding the ones of the tracker type")
 	a.Attribute("trackerID", d.String, "Tracker ID")
 	a.Attribute("relationships", trackerQueryRelationships)
 
 	a.Required("id")
 	a.Required("query")
 	a.Required("schedule")
+	a.Required("syncPolicy")
 	a.Required("trackerID")
 	a.Required("relationships")
 
@@ -118,11 +126,101 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 		a.Attribute("id")
 		a.Attribute("query")
 		a.Attribute("schedule")
+		a.Attribute("syncPolicy")
+		a.Attribute("lastUpdatedAt")
+		a.Attribute("workItemTypeID")
+		a.Attribute("fieldMappings")
 		a.Attribute("trackerID")
 		a.Attribute("relationships")
 	})
 })
 
+// TrackerQueryRun represents an execution of a tracker query
+var TrackerQueryRun = a.MediaType("application/vnd.trackerqueryrun+json", func() {
+	a.TypeName("TrackerQueryRun")
+	a.Description("Execution of a tracker query")
+	a.Attribute("id", d.UUID, "unique id of the run")
+	a.Attribute("status", d.String, "Status of the run: 'running', 'succeeded' or 'failed'")
+	a.Attribute("startedAt", d.DateTime, "When the run started")
+	a.Attribute("endedAt", d.DateTime, "When the run ended")
+	a.Attribute("fetched", d.Integer, "Number of items fetched from the remote tracker")
+	a.Attribute("created", d.Integer, "Number of work items created")
+	a.Attribute("updated", d.Integer, "Number of work items updated")
+	a.Attribute("failed", d.Integer, "Number of items which could not be imported")
+	a.Attribute("errors", a.ArrayOf(trackerQueryRunError), "Errors which occurred during the run")
+
+	a.Required("id")
+	a.Required("status")
+	a.Required("startedAt")
+	a.Required("fetched")
+	a.Required("created")
+	a.Required("updated")
+	a.Required("failed")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("status")
+		a.Attribute("startedAt")
+		a.Attribute("endedAt")
+		a.Attribute("fetched")
+		a.Attribute("created")
+		a.Attribute("updated")
+		a.Attribute("failed")
+		a.Attribute("errors")
+	})
+})
+
+var trackerQueryRunError = a.Type("TrackerQueryRunError", func() {
+	a.Attribute("itemID", d.String, "ID of the remote item which could not be imported, if any")
+	a.Attribute("message", d.String, "Error message")
+	a.Required("message")
+})
+
+// trackerQueryFieldMapping maps a remote attribute to a work item field
+var trackerQueryFieldMapping = a.Type("TrackerQueryFieldMapping", func() {
+	a.Attribute("expression", d.String, "Key of the remote attribute in the flattened remote item", func() {
+		a.Example("fields.customfield_10002")
+		a.MinLength(1)
+	})
+	a.Attribute("field", d.String, "Name of the work item field", func() {
+		a.Example("storypoints")
+		a.MinLength(1)
+	})
+	a.Attribute("converter", d.String, "Converter of the remote value", func() {
+		a.Enum("value", "list", "pattern-list", "date", "enum")
+	})
+	a.Attribute("values", a.HashOf(d.String, d.String), "Mapping of the remote values to the field values, for the 'enum' converter")
+	a.Required("expression", "field", "converter")
+})
+
+// TrackerItemConflict represents a field of an imported work item which was modified both locally and on the remote tracker
+var TrackerItemConflict = a.MediaType("application/vnd.trackeritemconflict+json", func() {
+	a.TypeName("TrackerItemConflict")
+	a.Description("Field of an imported work item in conflict with the remote tracker")
+	a.Attribute("id", d.UUID, "unique id of the conflict")
+	a.Attribute("workItemID", d.String, "ID of the work item")
+	a.Attribute("field", d.String, "Name of the field in conflict")
+	a.Attribute("baseValue", d.Any, "Value of the field after the last synchronization")
+	a.Attribute("localValue", d.Any, "Local value of the field")
+	a.Attribute("remoteValue", d.Any, "Remote value of the field")
+	a.Attribute("updatedAt", d.DateTime, "When the conflict was last detected")
+
+	a.Required("id")
+	a.Required("workItemID")
+	a.Required("field")
+	a.Required("updatedAt")
+
+	a.View("default", func() {
+		a.Attribute("id")
+		a.Attribute("workItemID")
+		a.Attribute("field")
+		a.Attribute("baseValue")
+		a.Attribute("localValue")
+		a.Attribute("remoteValue")
+		a.Attribute("updatedAt")
+	})
+})
+
 var trackerQueryRelationships = a.Type("TrackerQueryRelationships", func() {
 	a.Attribute("space", relationSpaces, "This defines the owning space of this work item type.")
 })
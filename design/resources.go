@@ -101,6 +101,21 @@ var _ = a.Resource("tracker", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 		a.Response(d.Unauthorized, JSONAPIErrors)
 	})
+	a.Action("webhook", func() {
+		a.Routing(
+			a.POST("/:id/webhook"),
+		)
+		a.Description("Receive an event sent by a webhook of the tracker (GitHub or Jira) and import the remote item immediately.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("secret", d.String, "the webhook secret of the tracker, for the trackers which don't sign their events (Jira)")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 
 })
 
@@ -178,4 +193,52 @@ var _ = a.Resource("trackerquery", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
+	a.Action("run", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:id/run"),
+		)
+		a.Description("Trigger an immediate import of the remote tracker items matching the tracker query.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.Accepted, func() {
+			a.Media(TrackerQueryRun)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+	a.Action("runs", func() {
+		a.Routing(
+			a.GET("/:id/runs"),
+		)
+		a.Description("List the most recent runs of the tracker query.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("page[limit]", d.Integer, "Maximum number of runs to return")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerQueryRun))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("conflicts", func() {
+		a.Routing(
+			a.GET("/:id/conflicts"),
+		)
+		a.Description("List the fields of the imported work items which were modified both locally and on the remote tracker.")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(a.CollectionOf(TrackerItemConflict))
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
 })
@@ -32,8 +32,14 @@ var _ = a.Resource("search", func() {
 				2) "url:http://demo.almighty.io/details/500" :- Search on WI having id 500 and check 
 					if this URL is mentioned in searchable columns of work item
 				3) "simple keywords separated by space" :- Search in Work Items based on these keywords.`)
+			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
 			a.Param("page[offset]", d.String, "Paging start position") // #428
 			a.Param("page[limit]", d.Integer, "Paging size")
+			a.Param("page[cursor]", d.String, `Opaque paging position taken from the prev/next links. Selects cursor based paging,
+				which is not affected by items being added or removed between requests but does not return
+				a total count. Leave it empty to get the first page`)
+			a.Param("sort", d.String, `comma separated list of fields to sort by, prefixed with "-" for descending order,
+				e.g. "-system.created_at,system.title". Defaults to the relevance of the work items`)
 			a.Required("q")
 		})
 		a.Response(d.OK, func() {
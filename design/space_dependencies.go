@@ -0,0 +1,67 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var dependencies = a.Type("Dependencies", func() {
+	a.Description(`JSONAPI store for the analysis of the dependencies between the work items of a space. The target of a
+link of a type with the "dependency" topology depends on its source. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("dependencies")
+	})
+	a.Attribute("id", d.UUID, "ID of the space", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", dependenciesAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var dependenciesAttributes = a.Type("DependenciesAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a dependency analysis. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("order", a.ArrayOf(d.String), "The IDs of the work items, each one after its predecessors", func() {
+		a.Example([]string{"1", "3", "2"})
+	})
+	a.Attribute("blocked", a.HashOf(d.String, a.ArrayOf(d.String)), "The IDs of the unresolved predecessors of the blocked work items, by work item ID", func() {
+		a.Example(map[string]interface{}{"2": []string{"3"}})
+	})
+	a.Attribute("critical-path", a.ArrayOf(d.String), "The IDs of the work items of the heaviest chain of dependencies", func() {
+		a.Example([]string{"3", "2"})
+	})
+	a.Attribute("critical-path-weight", d.Number, "The sum of the weights of the work items of the critical path", func() {
+		a.Example(8)
+	})
+	a.Attribute("cycles", a.ArrayOf(a.ArrayOf(d.String)), "The IDs of the work items depending on each other, by cycle", func() {
+		a.Example([][]string{{"4", "5"}})
+	})
+	a.Required("order", "blocked", "critical-path", "critical-path-weight", "cycles")
+})
+
+var dependenciesSingle = JSONSingle(
+	"Dependencies", "Holds the analysis of the dependencies between the work items of a space",
+	dependencies,
+	nil)
+
+var _ = a.Resource("space_dependencies", func() {
+	a.Parent("space")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("dependencies"),
+		)
+		a.Description(`Analyze the dependencies between the work items of the space: the blocked work items,
+an order of the work items respecting their dependencies, the critical path and the cycles.`)
+		a.Params(func() {
+			a.Param("filter[iteration]", d.String, "ID of the iteration to restrict the analysis to")
+			a.Param("weight", d.String, `Name of the numeric field the critical path is weighted by (e.g. an estimate),
+				the longest chain of dependencies is returned if not set`)
+		})
+		a.Response(d.OK, func() {
+			a.Media(dependenciesSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,29 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = a.Resource("space_events", func() {
+	a.Parent("space")
+
+	a.Action("stream", func() {
+		a.Routing(
+			a.GET("events"),
+		)
+		a.Description(`Stream the changes of the work items, links and comments of the space as server-sent events
+(see https://www.w3.org/TR/eventsource/). The name of each event is its type (e.g. "workitem.updated") and its data
+holds the ids of the changed entities. Only the changes of the entities visible to the caller are streamed.
+The stream resumes after the event given in the "Last-Event-ID" header.`)
+		a.Headers(func() {
+			a.Header("Last-Event-ID", d.String, "The id of the last event received by the client, e.g. \"1042-17\"", func() {
+				a.Pattern("^[0-9]+-[0-9]+$")
+			})
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -46,6 +46,9 @@ var CreateTrackerAlternatePayload = a.Type("CreateTrackerAlternatePayload", func
 		a.Pattern("^[\\p{L}]+$")
 		a.MinLength(1)
 	})
+	a.Attribute("webhookSecret", d.String, "Secret shared with the tracker to authenticate the events of its webhooks, an empty secret disables the webhooks", func() {
+		a.Example("s3cr3t")
+	})
 	a.Required("url", "type")
 })
 
@@ -60,6 +63,9 @@ var UpdateTrackerAlternatePayload = a.Type("UpdateTrackerAlternatePayload", func
 		a.MinLength(1)
 		a.Pattern("^[\\p{L}]+$")
 	})
+	a.Attribute("webhookSecret", d.String, "Secret shared with the tracker to authenticate the events of its webhooks, an empty secret disables the webhooks", func() {
+		a.Example("s3cr3t")
+	})
 	a.Required("url", "type")
 })
 
@@ -79,6 +85,11 @@ var CreateTrackerQueryAlternatePayload = a.Type("CreateTrackerQueryAlternatePayl
 		a.MinLength(1)
 		a.Pattern("^[\\p{N}]+$")
 	})
+	a.Attribute("syncPolicy", d.String, "Policy applied to the fields modified both locally and on the remote tracker", func() {
+		a.Enum("remote-wins", "local-wins", "flag-conflict")
+	})
+	a.Attribute("workItemTypeID", d.UUID, "Type of the work items created by the tracker query")
+	a.Attribute("fieldMappings", a.ArrayOf(trackerQueryFieldMapping), "Mappings of the remote attributes to the work item fields, overriding the ones of the tracker type")
 	a.Attribute("relationships", trackerQueryRelationships)
 
 	a.Required("query", "schedule", "trackerID")
@@ -100,6 +111,11 @@ var UpdateTrackerQueryAlternatePayload = a.Type("UpdateTrackerQueryAlternatePayl
 		a.MinLength(1)
 		a.Pattern("[\\p{N}]+")
 	})
+	a.Attribute("syncPolicy", d.String, "Policy applied to the fields modified both locally and on the remote tracker", func() {
+		a.Enum("remote-wins", "local-wins", "flag-conflict")
+	})
+	a.Attribute("workItemTypeID", d.UUID, "Type of the work items created by the tracker query")
+	a.Attribute("fieldMappings", a.ArrayOf(trackerQueryFieldMapping), "Mappings of the remote attributes to the work item fields, overriding the ones of the tracker type")
 	a.Attribute("relationships", trackerQueryRelationships)
 
 	a.Required("query", "schedule", "trackerID")
@@ -0,0 +1,215 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var webhook = a.Type("Webhook", func() {
+	a.Description(`JSONAPI store for the data of a webhook subscription. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("webhooks")
+	})
+	a.Attribute("id", d.UUID, "ID of the webhook", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", webhookAttributes)
+	a.Attribute("relationships", webhookRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var webhookAttributes = a.Type("WebhookAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a webhook. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("url", d.String, "The URL the events are posted to", func() {
+		a.Example("https://ci.example.com/hooks/almighty")
+	})
+	a.Attribute("secret", d.String, "The secret used to sign the events, never returned", func() {
+		a.Example("s3cr3t")
+	})
+	a.Attribute("event-types", a.ArrayOf(d.String), "The types of the events posted to the URL", func() {
+		a.Example([]string{"workitem.created", "workitem.updated", "comment.created", "link.created", "link.updated"})
+	})
+	a.Attribute("created-at", d.DateTime, "When the webhook was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var webhookRelationships = a.Type("WebhookRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("deliveries", relationGeneric, "This defines the delivery log of the webhook")
+})
+
+var webhookList = JSONList(
+	"Webhook", "Holds the list of webhooks",
+	webhook,
+	nil,
+	nil)
+
+var webhookSingle = JSONSingle(
+	"Webhook", "Holds a single webhook",
+	webhook,
+	nil)
+
+var webhookDelivery = a.Type("WebhookDelivery", func() {
+	a.Description(`JSONAPI store for the data of a delivery of an event to a webhook. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("webhook-deliveries")
+	})
+	a.Attribute("id", d.UUID, "ID of the delivery, sent in the 'X-Almighty-Delivery' header", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", webhookDeliveryAttributes)
+	a.Required("type", "id", "attributes")
+})
+
+var webhookDeliveryAttributes = a.Type("WebhookDeliveryAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a webhook delivery. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("event-id", d.UUID, "ID of the delivered event", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("event-type", d.String, "Type of the delivered event", func() {
+		a.Example("workitem.created")
+	})
+	a.Attribute("status", d.String, "Status of the delivery", func() {
+		a.Enum("pending", "succeeded", "failed")
+	})
+	a.Attribute("attempts", d.Integer, "Number of attempts made so far", func() {
+		a.Example(1)
+	})
+	a.Attribute("response-status", d.Integer, "HTTP status code of the last response", func() {
+		a.Example(200)
+	})
+	a.Attribute("error", d.String, "Error of the last attempt", func() {
+		a.Example("unexpected response: 500 Internal Server Error")
+	})
+	a.Attribute("created-at", d.DateTime, "When the delivery was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("next-attempt-at", d.DateTime, "When the next attempt is due, for a pending delivery", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("delivered-at", d.DateTime, "When the event was delivered successfully", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("event-id", "event-type", "status", "attempts", "created-at")
+})
+
+var webhookDeliveryList = JSONList(
+	"WebhookDelivery", "Holds the deliveries of a webhook, most recent first",
+	webhookDelivery,
+	nil,
+	nil)
+
+var _ = a.Resource("webhook", func() {
+	a.BasePath("/webhooks")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:webhookID"),
+		)
+		a.Description("Retrieve the webhook with the given id.")
+		a.Params(func() {
+			a.Param("webhookID", d.String, "Webhook Identifier")
+		})
+		a.Response(d.OK, func() {
+			a.Media(webhookSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:webhookID"),
+		)
+		a.Description("Update the URL, secret or event types of the webhook with the given id.")
+		a.Params(func() {
+			a.Param("webhookID", d.String, "Webhook Identifier")
+		})
+		a.Payload(webhookSingle)
+		a.Response(d.OK, func() {
+			a.Media(webhookSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:webhookID"),
+		)
+		a.Description("Delete the webhook with the given id, its pending deliveries are abandoned.")
+		a.Params(func() {
+			a.Param("webhookID", d.String, "Webhook Identifier")
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+	a.Action("list-deliveries", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:webhookID/deliveries"),
+		)
+		a.Description("List the most recent deliveries of the webhook with the given id.")
+		a.Params(func() {
+			a.Param("webhookID", d.String, "Webhook Identifier")
+			a.Param("page[limit]", d.Integer, "Maximum number of deliveries (defaults to 20, at most 100)")
+		})
+		a.Response(d.OK, func() {
+			a.Media(webhookDeliveryList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("space_webhooks", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("webhooks"),
+		)
+		a.Description("List the webhooks of the space.")
+		a.Response(d.OK, func() {
+			a.Media(webhookList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("webhooks"),
+		)
+		a.Description("Subscribe a URL to the events of the space.")
+		a.Payload(webhookSingle)
+		a.Response(d.Created, "/webhooks/.*", func() {
+			a.Media(webhookSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -25,6 +25,37 @@ var updateWorkItemLinkPayload = a.Type("UpdateWorkItemLinkPayload", func() {
 	a.Required("data")
 })
 
+// bulkWorkItemLinkPayload holds the operations of a bulk request on work item links
+var bulkWorkItemLinkPayload = a.Type("BulkWorkItemLinkPayload", func() {
+	a.Attribute("data", a.ArrayOf(workItemLinkOperation), func() {
+		a.MinLength(1)
+		a.MaxLength(100)
+	})
+	a.Required("data")
+})
+
+// workItemLinkOperation is the creation or the deletion of a work item link within a bulk request
+var workItemLinkOperation = a.Type("WorkItemLinkOperation", func() {
+	a.Description(`An operation of a bulk request on work item links: a "create" operation holds the link to create
+in "data" and a "delete" operation holds the ID of the link to delete in "id"`)
+	a.Attribute("op", d.String, func() {
+		a.Enum("create", "delete")
+	})
+	a.Attribute("data", workItemLinkData, "The work item link to create")
+	a.Attribute("id", d.UUID, "ID of the work item link to delete")
+	a.Required("op")
+})
+
+// workItemLinkOperationResult is the outcome of an operation of a bulk request on work item links
+var workItemLinkOperationResult = a.Type("WorkItemLinkOperationResult", func() {
+	a.Attribute("op", d.String, func() {
+		a.Enum("create", "delete")
+	})
+	a.Attribute("data", workItemLinkData, "The created work item link")
+	a.Attribute("id", d.UUID, "ID of the deleted work item link")
+	a.Required("op")
+})
+
 // workItemLinkListMeta holds meta information for a work item link array response
 var workItemLinkListMeta = a.Type("WorkItemLinkListMeta", func() {
 	a.Attribute("totalCount", d.Integer, func() {
@@ -109,6 +140,15 @@ var workItemLinkList = JSONList(
 	workItemLinkListMeta,
 )
 
+// workItemLinkOperationResultList holds the outcome of each operation of a bulk request on work item links
+var workItemLinkOperationResultList = JSONList(
+	"WorkItemLinkOperationResult",
+	"Holds the outcome of each operation of a bulk request on work item links",
+	workItemLinkOperationResult,
+	nil,
+	nil,
+)
+
 // ############################################################################
 //
 //  Resource Definition
@@ -122,6 +162,48 @@ var _ = a.Resource("work_item_link", func() {
 	a.Action("create", createWorkItemLink)
 	a.Action("delete", deleteWorkItemLink)
 	a.Action("update", updateWorkItemLink)
+	a.Action("graph", func() {
+		a.Description(`Export the graph of the links of a root work item, or of the work items matching a filter,
+as a Graphviz DOT document or in the JSON Graph Format (see http://jsongraphformat.info). The links are labelled
+with the forward name of their type and the work items are colored by state.`)
+		a.Routing(
+			a.GET("/graph"),
+		)
+		a.Params(func() {
+			a.Param("root", d.String, "ID of the work item the links are walked from")
+			a.Param("filter", d.String, `a query language expression selecting the work items the links are walked from,
+				e.g. 'space = "..." AND state = "open"'`)
+			a.Param("depth", d.Integer, `Number of links walked from the selected work items, all the links are walked
+				from a root work item and only the links between the filtered work items are kept if not set`, func() {
+				a.Minimum(0)
+			})
+			a.Param("filter[linktype]", a.ArrayOf(d.UUID), "IDs of the link types to keep, all the link types if not set")
+			a.Param("filter[linkcategory]", a.ArrayOf(d.UUID), "IDs of the link categories to keep, all the link categories if not set")
+			a.Param("format", d.String, "Format of the graph, json if not set", func() {
+				a.Enum("dot", "json")
+			})
+		})
+		a.Response(d.OK)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("bulk", func() {
+		a.Description(`Create and delete many work item links at once. All the operations are validated, against
+the links created and deleted by the previous ones, and applied in one transaction: if any of them is rejected,
+none is applied and an error is returned for each rejected operation, with a pointer to it as source.`)
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/bulk"),
+		)
+		a.Payload(bulkWorkItemLinkPayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemLinkOperationResultList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
 })
 
 var _ = a.Resource("work_item_relationships_links", func() {
@@ -0,0 +1,56 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemMention = a.Type("WorkItemMention", func() {
+	a.Description(`JSONAPI store for a reference to a work item ("#123") found in the description of another work item or in a comment. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("mentions")
+	})
+	a.Attribute("id", d.UUID, "ID of the mention", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemMentionAttributes)
+	a.Attribute("relationships", workItemMentionRelationships)
+	a.Required("type", "id", "attributes", "relationships")
+})
+
+var workItemMentionAttributes = a.Type("WorkItemMentionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a mention. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("created-at", d.DateTime, "When the mention was made", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("created-at")
+})
+
+var workItemMentionRelationships = a.Type("WorkItemMentionRelations", func() {
+	a.Attribute("workitem", relationGeneric, "The work item whose description or comment contains the mention")
+	a.Attribute("comment", relationGeneric, "The comment which contains the mention, missing if the mention is in the description of the work item")
+	a.Required("workitem")
+})
+
+var workItemMentionList = JSONList(
+	"WorkItemMention", "Holds the mentions of a work item, oldest first",
+	workItemMention,
+	nil,
+	nil)
+
+var _ = a.Resource("work_item_mentions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("mentions"),
+		)
+		a.Description("List the work items and the comments which mention the given work item")
+		a.Response(d.OK, func() {
+			a.Media(workItemMentionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
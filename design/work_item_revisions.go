@@ -0,0 +1,110 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemRevision = a.Type("WorkItemRevision", func() {
+	a.Description(`JSONAPI store for a revision of a work item. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemrevisions")
+	})
+	a.Attribute("id", d.UUID, "ID of the revision", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemRevisionAttributes)
+	a.Attribute("relationships", workItemRevisionRelationships)
+	a.Required("type", "id", "attributes", "relationships")
+})
+
+var workItemRevisionAttributes = a.Type("WorkItemRevisionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item revision. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("revision-type", d.String, "The kind of modification", func() {
+		a.Enum("create", "update", "delete")
+	})
+	a.Attribute("created-at", d.DateTime, "When the modification happened", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("version", d.Integer, "The version of the work item after the modification")
+	a.Attribute("changes", a.ArrayOf(workItemFieldChange), "The changed fields, compared to the previous revision")
+	a.Required("revision-type", "created-at", "version", "changes")
+})
+
+var workItemFieldChange = a.Type("WorkItemFieldChange", func() {
+	a.Description("The change of the value of a single work item field")
+	a.Attribute("field", d.String, "The name of the field", func() {
+		a.Example("system.title")
+	})
+	a.Attribute("old-value", d.Any, "The value before the modification, missing if the field was not set")
+	a.Attribute("new-value", d.Any, "The value after the modification, missing if the field was unset")
+	a.Attribute("added", a.ArrayOf(d.Any), "For list fields, the elements added to the list")
+	a.Attribute("removed", a.ArrayOf(d.Any), "For list fields, the elements removed from the list")
+	a.Required("field")
+})
+
+var workItemRevisionRelationships = a.Type("WorkItemRevisionRelations", func() {
+	a.Attribute("modifier", revisionModifier, "The identity which made the modification")
+	a.Attribute("workitem", relationGeneric, "The work item that was modified")
+	a.Required("modifier", "workitem")
+})
+
+var workItemRevisionList = JSONList(
+	"WorkItemRevision", "Holds the revisions of a work item, oldest first",
+	workItemRevision,
+	nil,
+	nil)
+
+var restoreWorkItemRevision = a.Type("RestoreWorkItemRevision", func() {
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitems")
+	})
+	a.Attribute("attributes", restoreWorkItemRevisionAttributes)
+	a.Required("type", "attributes")
+})
+
+var restoreWorkItemRevisionAttributes = a.Type("RestoreWorkItemRevisionAttributes", func() {
+	a.Attribute("version", d.Integer, "The current version of the work item, for optimistic concurrency control")
+	a.Required("version")
+})
+
+var restoreWorkItemRevisionPayload = a.Type("RestoreWorkItemRevisionPayload", func() {
+	a.Attribute("data", restoreWorkItemRevision)
+	a.Required("data")
+})
+
+var _ = a.Resource("work_item_revisions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("revisions"),
+		)
+		a.Description("List the revisions of the given work item along with the changes of the fields")
+		a.Response(d.OK, func() {
+			a.Media(workItemRevisionList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("revisions/:revisionID/restore"),
+		)
+		a.Description("Restore the fields of the given work item to the values of the given revision. Deleted work items are restored as well.")
+		a.Params(func() {
+			a.Param("revisionID", d.UUID, "ID of the revision to restore")
+		})
+		a.Payload(restoreWorkItemRevisionPayload)
+		a.Response(d.OK, func() {
+			a.Media(workItemSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
@@ -83,6 +83,19 @@ var workItemSingle = JSONSingle(
 	workItem2,
 	workItemLinks)
 
+// workItemTreeNode is a work item of a hierarchy nested with its children
+var workItemTreeNode = a.Type("WorkItemTreeNode", func() {
+	a.Attribute("data", workItem2)
+	a.Attribute("children", a.ArrayOf("WorkItemTreeNode"), "The children of the work item, empty at the requested depth")
+	a.Required("data", "children")
+})
+
+// workItemTree is the media type for a work item with its descendants
+var workItemTree = JSONSingle(
+	"WorkItemTree", "A work item with its descendants nested in their parents",
+	workItemTreeNode,
+	nil)
+
 // Reorder creates a UserTypeDefinition for Reorder action
 func Reorder(name, description string, data *d.UserTypeDefinition, position *d.UserTypeDefinition) *d.MediaTypeDefinition {
 	return a.MediaType("application/vnd."+strings.ToLower(name)+"json", func() {
@@ -129,14 +142,20 @@ var _ = a.Resource("workitem", func() {
 		)
 		a.Description("List work items.")
 		a.Params(func() {
-			a.Param("filter", d.String, "a query language expression restricting the set of found work items")
+			a.Param("filter", d.String, `a query language expression restricting the set of found work items,
+				e.g. 'state = "open" AND (assignee = me OR iteration IN ("...", "..."))'`)
 			a.Param("page[offset]", d.String, "Paging start position")
 			a.Param("page[limit]", d.Integer, "Paging size")
+			a.Param("page[cursor]", d.String, `Opaque paging position taken from the prev/next links. Selects cursor based paging,
+				which is not affected by items being added or removed between requests but does not return
+				a total count. Leave it empty to get the first page`)
 			a.Param("filter[assignee]", d.String, "Work Items assigned to the given user")
 			a.Param("filter[iteration]", d.String, "IterationID to filter work items")
 			a.Param("filter[workitemtype]", d.UUID, "ID of work item type to filter work items by")
 			a.Param("filter[area]", d.String, "AreaID to filter work items")
 			a.Param("filter[workitemstate]", d.String, "work item state to filter work items by")
+			a.Param("sort", d.String, `comma separated list of fields to sort by, prefixed with "-" for descending order,
+				e.g. "-system.created_at,system.title". Defaults to the execution order`)
 
 		})
 		a.Response(d.OK, func() {
@@ -161,6 +180,57 @@ var _ = a.Resource("workitem", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
+	a.Action("list-ancestors", func() {
+		a.Routing(
+			a.GET("/:id/ancestors"),
+		)
+		a.Description("List the parent of the given work item, the parent of its parent and so on, nearest first")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("list-descendants", func() {
+		a.Routing(
+			a.GET("/:id/descendants"),
+		)
+		a.Description("List the children of the given work item, their children and so on, nearest first")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("depth", d.Integer, "Number of levels to return, all the levels if not set", func() {
+				a.Minimum(1)
+			})
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemList)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+	a.Action("show-tree", func() {
+		a.Routing(
+			a.GET("/:id/tree"),
+		)
+		a.Description("Retrieve the given work item with its descendants nested in their parents")
+		a.Params(func() {
+			a.Param("id", d.String, "id")
+			a.Param("depth", d.Integer, "Number of levels to return, all the levels if not set", func() {
+				a.Minimum(1)
+			})
+		})
+		a.Response(d.OK, func() {
+			a.Media(workItemTree)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
 
 	a.Action("create", func() {
 		a.Security("jwt")
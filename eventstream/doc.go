@@ -0,0 +1,4 @@
+// Package eventstream records the changes of the work items, links and comments of the spaces, and
+// notifies them through PostgreSQL LISTEN/NOTIFY, so that every server replica can stream them to
+// its clients as server-sent events.
+package eventstream
@@ -0,0 +1,182 @@
+package eventstream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// pingInterval is the time after which the connection listening to the notifications is checked
+	// if no notification was received
+	pingInterval = 90 * time.Second
+	// retention is how long the events are kept, the clients can't resume a stream older than that
+	retention = 24 * time.Hour
+	// purgeInterval is the time between two purges of the events older than the retention
+	purgeInterval = time.Hour
+)
+
+// Hub listens to the notifications of the recorded events and wakes up the streams of their space
+type Hub struct {
+	db          *gorm.DB
+	connection  string
+	listener    *pq.Listener
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan struct{}]struct{}
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewHub creates a new Hub reading the events with the given database and listening to the notifications
+// with a dedicated connection opened with the given connection string
+func NewHub(db *gorm.DB, connection string) *Hub {
+	return &Hub{
+		db:          db,
+		connection:  connection,
+		subscribers: map[uuid.UUID]map[chan struct{}]struct{}{},
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start starts listening to the notifications in the background
+func (h *Hub) Start() error {
+	h.listener = pq.NewListener(h.connection, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+			}, "the connection listening to the events of the spaces failed")
+		}
+	})
+	if err := h.listener.Listen(channel); err != nil {
+		h.listener.Close()
+		return errs.Wrapf(err, "failed to listen to the %s notifications", channel)
+	}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		purge := time.NewTicker(purgeInterval)
+		defer purge.Stop()
+		for {
+			select {
+			case <-h.stop:
+				return
+			case n := <-h.listener.Notify:
+				if n == nil {
+					// the connection was lost and established again, some notifications may be lost
+					h.notifyAll()
+					continue
+				}
+				if spaceID, err := uuid.FromString(n.Extra); err == nil {
+					h.notify(spaceID)
+				}
+			case <-time.After(pingInterval):
+				go h.listener.Ping()
+			case <-purge.C:
+				h.purge()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops listening to the notifications
+// This should be called only from main
+func (h *Hub) Stop() {
+	close(h.stop)
+	h.wg.Wait()
+	if h.listener != nil {
+		h.listener.Close()
+	}
+}
+
+// Subscribe returns a channel receiving a value when events of the given space were recorded, and the
+// function to call once the channel isn't read anymore. Several notifications may be merged into one.
+func (h *Hub) Subscribe(spaceID uuid.UUID) (<-chan struct{}, func()) {
+	c := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subscribers[spaceID] == nil {
+		h.subscribers[spaceID] = map[chan struct{}]struct{}{}
+	}
+	h.subscribers[spaceID][c] = struct{}{}
+	h.mu.Unlock()
+	return c, func() {
+		h.mu.Lock()
+		delete(h.subscribers[spaceID], c)
+		if len(h.subscribers[spaceID]) == 0 {
+			delete(h.subscribers, spaceID)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// notify wakes up the subscribers of the given space
+func (h *Hub) notify(spaceID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.subscribers[spaceID] {
+		wake(c)
+	}
+}
+
+// notifyAll wakes up all the subscribers
+func (h *Hub) notifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subscribers := range h.subscribers {
+		for c := range subscribers {
+			wake(c)
+		}
+	}
+}
+
+func wake(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+		// a notification is already pending
+	}
+}
+
+// visible restricts the records to the ones of the transactions older than all the transactions in progress:
+// a transaction still in progress may only record events positioned after them once it is committed
+const visible = "tx_id < txid_snapshot_xmin(txid_current_snapshot())"
+
+// Latest returns the position of the latest visible event of the given space, or the zero position if
+// there is none
+func (h *Hub) Latest(spaceID uuid.UUID) (Position, error) {
+	var records []Record
+	err := h.db.Where("space_id = ? AND "+visible, spaceID).Order("tx_id DESC, id DESC").Limit(1).Find(&records).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return Position{}, errs.WithStack(err)
+	}
+	if len(records) == 0 {
+		return Position{}, nil
+	}
+	return records[0].Position(), nil
+}
+
+// Since returns at most limit visible events of the given space positioned after the given position,
+// oldest first
+func (h *Hub) Since(spaceID uuid.UUID, after Position, limit int) ([]Record, error) {
+	var records []Record
+	err := h.db.Where("space_id = ? AND (tx_id, id) > (?, ?) AND "+visible, spaceID, after.TxID, after.ID).Order("tx_id, id").Limit(limit).Find(&records).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errs.WithStack(err)
+	}
+	return records, nil
+}
+
+// purge deletes the events older than the retention
+func (h *Hub) purge() {
+	if err := h.db.Where("created_at < ?", time.Now().Add(-retention)).Delete(&Record{}).Error; err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err": err,
+		}, "unable to purge the events of the spaces")
+	}
+}
@@ -0,0 +1,38 @@
+package eventstream
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubNotify(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	h := NewHub(nil, "")
+	space1 := uuid.NewV4()
+	space2 := uuid.NewV4()
+	c1, unsubscribe1 := h.Subscribe(space1)
+	c2, unsubscribe2 := h.Subscribe(space2)
+	defer unsubscribe2()
+	// when
+	h.notify(space1)
+	h.notify(space1)
+	// then the notifications are merged
+	assert.Len(t, c1, 1)
+	assert.Len(t, c2, 0)
+	<-c1
+	// when
+	h.notifyAll()
+	// then
+	assert.Len(t, c1, 1)
+	assert.Len(t, c2, 1)
+	// when
+	unsubscribe1()
+	// then
+	assert.NotContains(t, h.subscribers, space1)
+	assert.Contains(t, h.subscribers, space2)
+}
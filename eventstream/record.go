@@ -0,0 +1,144 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/application/event"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// channel is the PostgreSQL notification channel of the recorded events, the payload of the notifications
+// is the id of the space of the event
+const channel = "space_events"
+
+// The types of the streamed events, sent as the name of the server-sent events
+const (
+	EventWorkItemCreated = "workitem.created"
+	EventWorkItemUpdated = "workitem.updated"
+	EventWorkItemDeleted = "workitem.deleted"
+	EventLinkCreated     = "link.created"
+	EventLinkUpdated     = "link.updated"
+	EventLinkDeleted     = "link.deleted"
+	EventCommentCreated  = "comment.created"
+	EventCommentUpdated  = "comment.updated"
+	EventCommentDeleted  = "comment.deleted"
+)
+
+// Record is a change of a space, streamed to the clients watching the space
+type Record struct {
+	// ID is the id of the record, ordered by creation
+	ID int64 `gorm:"primary_key"`
+	// TxID is the id of the transaction which recorded the change. The records are streamed in the order
+	// of their transactions, since the ids may be committed out of order.
+	TxID      int64 `sql:"default:txid_current()"`
+	CreatedAt time.Time
+	SpaceID   uuid.UUID `sql:"type:uuid"`
+	EventType string
+	// EntityID is the id of the created, updated or deleted work item, link or comment
+	EntityID string
+	// Data is the JSON representation of the domain event
+	Data string `sql:"type:jsonb"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *Record) TableName() string {
+	return "space_events"
+}
+
+// Position returns the position of the record in the stream of its space
+func (m Record) Position() Position {
+	return Position{TxID: m.TxID, ID: m.ID}
+}
+
+// Position is the position of a record in the stream of its space, sent as the id of the server-sent event,
+// e.g. "1042-17". The clients resume the stream after the position of the last event they received.
+type Position struct {
+	TxID int64
+	ID   int64
+}
+
+// String returns the representation of the position sent to the clients
+func (p Position) String() string {
+	return strconv.FormatInt(p.TxID, 10) + "-" + strconv.FormatInt(p.ID, 10)
+}
+
+// ParsePosition parses the representation of a position sent to the clients
+func ParsePosition(s string) (Position, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return Position{}, errs.Errorf("invalid position of event: %s", s)
+	}
+	txID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Position{}, errs.Wrapf(err, "invalid position of event: %s", s)
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Position{}, errs.Wrapf(err, "invalid position of event: %s", s)
+	}
+	return Position{TxID: txID, ID: id}, nil
+}
+
+// Append records the given change of a work item, link or comment with the given transaction, so that
+// the event is recorded if and only if the change is committed. It is meant to be subscribed to the
+// events published in the transactions, see event.SubscribeInTransaction.
+// The other events are ignored.
+func Append(tx *gorm.DB, e event.Event) error {
+	var record Record
+	var workItemID string
+	switch e := e.(type) {
+	case event.WorkItemCreated:
+		record = Record{SpaceID: e.SpaceID, EventType: EventWorkItemCreated, EntityID: e.WorkItemID}
+	case event.WorkItemUpdated:
+		record = Record{SpaceID: e.SpaceID, EventType: EventWorkItemUpdated, EntityID: e.WorkItemID}
+	case event.WorkItemDeleted:
+		record = Record{SpaceID: e.SpaceID, EventType: EventWorkItemDeleted, EntityID: e.WorkItemID}
+	case event.LinkCreated:
+		record = Record{EventType: EventLinkCreated, EntityID: e.LinkID.String()}
+		workItemID = strconv.FormatUint(e.SourceID, 10)
+	case event.LinkUpdated:
+		record = Record{EventType: EventLinkUpdated, EntityID: e.LinkID.String()}
+		workItemID = strconv.FormatUint(e.SourceID, 10)
+	case event.LinkDeleted:
+		record = Record{EventType: EventLinkDeleted, EntityID: e.LinkID.String()}
+		workItemID = strconv.FormatUint(e.SourceID, 10)
+	case event.CommentAdded:
+		record = Record{EventType: EventCommentCreated, EntityID: e.CommentID.String()}
+		workItemID = e.WorkItemID
+	case event.CommentUpdated:
+		record = Record{EventType: EventCommentUpdated, EntityID: e.CommentID.String()}
+		workItemID = e.WorkItemID
+	case event.CommentDeleted:
+		record = Record{EventType: EventCommentDeleted, EntityID: e.CommentID.String()}
+		workItemID = e.WorkItemID
+	default:
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	record.Data = string(data)
+	if workItemID != "" {
+		// the links and comments belong to the space of their work item, which may be deleted already
+		var result struct {
+			SpaceID uuid.UUID
+		}
+		if err := tx.Table("work_items").Select("space_id").Where("id = ?", workItemID).Scan(&result).Error; err != nil {
+			return errs.Wrapf(err, "failed to look up the space of the work item %s", workItemID)
+		}
+		record.SpaceID = result.SpaceID
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return errs.WithStack(err)
+	}
+	// the notification is sent when the transaction is committed
+	return errs.WithStack(tx.Exec("SELECT pg_notify(?, ?)", channel, record.SpaceID.String()).Error)
+}
@@ -0,0 +1,160 @@
+package eventstream_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application/event"
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+type TestEventStream struct {
+	gormtestsupport.DBTestSuite
+	ctx   context.Context
+	clean func()
+}
+
+func TestRunEventStream(t *testing.T) {
+	suite.Run(t, &TestEventStream{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestEventStream) SetupSuite() {
+	test.DBTestSuite.SetupSuite()
+	event.SubscribeInTransaction(eventstream.Append)
+	// Make sure the database is populated with the correct types (e.g. bug etc.)
+	if _, c := os.LookupEnv(resource.Database); c != false {
+		if err := models.Transactional(test.DB, func(tx *gorm.DB) error {
+			test.ctx = migration.NewMigrationContext(context.Background())
+			return migration.PopulateCommonTypes(test.ctx, tx, workitem.NewWorkItemTypeRepository(tx))
+		}); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func (test *TestEventStream) SetupTest() {
+	test.clean = cleaner.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestEventStream) TearDownTest() {
+	test.clean()
+	test.DB.Exec("DELETE FROM space_events WHERE space_id = ?", space.SystemSpace)
+}
+
+func (test *TestEventStream) TestRecordEvents() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// given
+	hub := eventstream.NewHub(test.DB, "")
+	latest, err := hub.Latest(space.SystemSpace)
+	require.Nil(t, err)
+	// the creation of the work item is recorded along with the work item
+	var wi *app.WorkItem
+	err = models.Transactional(test.DB, func(tx *gorm.DB) error {
+		var err error
+		wi, err = workitem.NewWorkItemRepository(tx).Create(test.ctx, space.SystemSpace, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: "Title",
+				workitem.SystemState: workitem.SystemStateNew,
+			}, uuid.NewV4())
+		return err
+	})
+	require.Nil(t, err)
+	commentID := uuid.NewV4()
+	// when
+	err = models.Transactional(test.DB, func(tx *gorm.DB) error {
+		if err := eventstream.Append(tx, event.WorkItemUpdated{WorkItemID: wi.ID, SpaceID: space.SystemSpace, Version: 1}); err != nil {
+			return err
+		}
+		if err := eventstream.Append(tx, event.CommentAdded{CommentID: commentID, WorkItemID: wi.ID}); err != nil {
+			return err
+		}
+		return eventstream.Append(tx, event.IterationStarted{IterationID: uuid.NewV4(), SpaceID: space.SystemSpace})
+	})
+	require.Nil(t, err)
+	// the events of a rolled back change aren't recorded
+	err = models.Transactional(test.DB, func(tx *gorm.DB) error {
+		if err := eventstream.Append(tx, event.WorkItemDeleted{WorkItemID: wi.ID, SpaceID: space.SystemSpace}); err != nil {
+			return err
+		}
+		return errs.New("rolled back")
+	})
+	require.NotNil(t, err)
+	// then
+	records, err := hub.Since(space.SystemSpace, latest, 10)
+	require.Nil(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, eventstream.EventWorkItemCreated, records[0].EventType)
+	assert.Equal(t, wi.ID, records[0].EntityID)
+	assert.Equal(t, eventstream.EventWorkItemUpdated, records[1].EventType)
+	assert.Equal(t, wi.ID, records[1].EntityID)
+	assert.Contains(t, records[1].Data, `"version": 1`)
+	assert.Equal(t, eventstream.EventCommentCreated, records[2].EventType)
+	assert.Equal(t, commentID.String(), records[2].EntityID)
+	assert.Equal(t, space.SystemSpace, records[2].SpaceID)
+	// resume after the second event
+	records, err = hub.Since(space.SystemSpace, records[1].Position(), 10)
+	require.Nil(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, commentID.String(), records[0].EntityID)
+	latest, err = hub.Latest(space.SystemSpace)
+	require.Nil(t, err)
+	assert.Equal(t, records[0].Position(), latest)
+}
+
+func (test *TestEventStream) TestRecordEventsCommittedOutOfOrder() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// given
+	hub := eventstream.NewHub(test.DB, "")
+	latest, err := hub.Latest(space.SystemSpace)
+	require.Nil(t, err)
+	first := test.DB.Begin()
+	require.Nil(t, first.Error)
+	defer first.Rollback()
+	require.Nil(t, eventstream.Append(first, event.WorkItemCreated{WorkItemID: "1", SpaceID: space.SystemSpace}))
+	// when the event of a later transaction is committed first
+	err = models.Transactional(test.DB, func(tx *gorm.DB) error {
+		return eventstream.Append(tx, event.WorkItemCreated{WorkItemID: "2", SpaceID: space.SystemSpace})
+	})
+	require.Nil(t, err)
+	// then it is held back until the first transaction ends
+	records, err := hub.Since(space.SystemSpace, latest, 10)
+	require.Nil(t, err)
+	assert.Empty(t, records)
+	require.Nil(t, first.Commit().Error)
+	records, err = hub.Since(space.SystemSpace, latest, 10)
+	require.Nil(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "1", records[0].EntityID)
+	assert.Equal(t, "2", records[1].EntityID)
+}
+
+func TestParsePosition(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// when
+	p, err := eventstream.ParsePosition(eventstream.Position{TxID: 1042, ID: 42}.String())
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, eventstream.Position{TxID: 1042, ID: 42}, p)
+	for _, s := range []string{"", "42", "1042-", "a-42", "1042-42-1"} {
+		_, err := eventstream.ParsePosition(s)
+		assert.NotNil(t, err, s)
+	}
+}
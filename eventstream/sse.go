@@ -0,0 +1,43 @@
+package eventstream
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+// ContentType is the media type of a stream of server-sent events
+const ContentType = "text/event-stream"
+
+// WriteEvent writes the given event in the server-sent events format, the position of the record being the id of the event
+func WriteEvent(w io.Writer, r Record) error {
+	// the data can't hold a new line, it would end the field
+	data := strings.Replace(strings.Replace(r.Data, "\r", "", -1), "\n", "", -1)
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", r.Position(), r.EventType, data)
+	return err
+}
+
+// WriteHeartbeat writes a comment, ignored by the clients, which keeps the connection open through the proxies
+func WriteHeartbeat(w io.Writer) error {
+	_, err := io.WriteString(w, ": keep-alive\n\n")
+	return err
+}
+
+// SkipStreams returns a middleware which applies the given middleware to all the requests,
+// except the ones accepting a stream of server-sent events (e.g. the gzip middleware, which would
+// hold the events until its buffer is full)
+func SkipStreams(m goa.Middleware) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		wrapped := m(h)
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if strings.Contains(req.Header.Get("Accept"), ContentType) {
+				return h(ctx, rw, req)
+			}
+			return wrapped(ctx, rw, req)
+		}
+	}
+}
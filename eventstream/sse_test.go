@@ -0,0 +1,59 @@
+package eventstream_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/almighty/almighty-core/eventstream"
+	"github.com/almighty/almighty-core/resource"
+
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestWriteEvent(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	var buf bytes.Buffer
+	r := eventstream.Record{ID: 42, TxID: 1042, EventType: eventstream.EventWorkItemUpdated, Data: "{\"workItemID\": \"1\",\n\"version\": 2}"}
+	// when
+	err := eventstream.WriteEvent(&buf, r)
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "id: 1042-42\nevent: workitem.updated\ndata: {\"workItemID\": \"1\",\"version\": 2}\n\n", buf.String())
+}
+
+func TestWriteHeartbeat(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	var buf bytes.Buffer
+	require.Nil(t, eventstream.WriteHeartbeat(&buf))
+	assert.Equal(t, ": keep-alive\n\n", buf.String())
+}
+
+func TestSkipStreams(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	var applied []string
+	middleware := func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			applied = append(applied, req.URL.Path)
+			return h(ctx, rw, req)
+		}
+	}
+	handler := eventstream.SkipStreams(middleware)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	stream, _ := http.NewRequest("GET", "/api/spaces/1/events", nil)
+	stream.Header.Set("Accept", "text/event-stream")
+	list, _ := http.NewRequest("GET", "/api/spaces/1/workitems", nil)
+	list.Header.Set("Accept", "application/vnd.api+json")
+	// when
+	require.Nil(t, handler(context.Background(), httptest.NewRecorder(), stream))
+	require.Nil(t, handler(context.Background(), httptest.NewRecorder(), list))
+	// then
+	assert.Equal(t, []string{"/api/spaces/1/workitems"}, applied)
+}
@@ -6,13 +6,16 @@ import (
 
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/application"
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/area"
 	"github.com/almighty/almighty-core/auth"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/almighty/almighty-core/search"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -73,6 +76,11 @@ func (g *GormBase) WorkItemTypes() workitem.WorkItemTypeRepository {
 	return workitem.NewWorkItemTypeRepository(g.db)
 }
 
+// WorkItemRevisions returns a work item revision repository
+func (g *GormBase) WorkItemRevisions() workitem.RevisionRepository {
+	return workitem.NewRevisionRepository(g.db)
+}
+
 func (g *GormBase) Spaces() space.Repository {
 	return space.NewRepository(g.db)
 }
@@ -122,6 +130,16 @@ func (g *GormBase) Comments() comment.Repository {
 	return comment.NewRepository(g.db)
 }
 
+// CommentRevisions returns a comment revision repository
+func (g *GormBase) CommentRevisions() comment.RevisionRepository {
+	return comment.NewRevisionRepository(g.db)
+}
+
+// References returns a work item reference repository
+func (g *GormBase) References() reference.Repository {
+	return reference.NewRepository(g.db)
+}
+
 // Iterations returns a iteration repository
 func (g *GormBase) Iterations() iteration.Repository {
 	return iteration.NewIterationRepository(g.db)
@@ -137,6 +155,11 @@ func (g *GormBase) OauthStates() auth.OauthStateReferenceRepository {
 	return auth.NewOauthStateReferenceRepository(g.db)
 }
 
+// Webhooks returns a webhook subscription repository
+func (g *GormBase) Webhooks() webhook.Repository {
+	return webhook.NewRepository(g.db)
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
@@ -161,7 +184,8 @@ func (g *GormDB) SetTransactionIsolationLevel(level TXIsoLevel) error {
 
 // Begin implements TransactionSupport
 func (g *GormDB) BeginTransaction() (application.Transaction, error) {
-	tx := g.db.Begin()
+	// the events published in the transaction are dispatched once it is committed
+	tx := event.WithBuffer(g.db.Begin())
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -177,7 +201,15 @@ func (g *GormDB) BeginTransaction() (application.Transaction, error) {
 
 // Commit implements TransactionSupport
 func (g *GormTransaction) Commit() error {
+	if err := event.BeforeCommit(g.db); err != nil {
+		g.db.Rollback()
+		g.db = nil
+		return errors.WithStack(err)
+	}
 	err := g.db.Commit().Error
+	if err == nil {
+		event.Flush(g.db)
+	}
 	g.db = nil
 	return errors.WithStack(err)
 }
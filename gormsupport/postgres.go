@@ -1,6 +1,10 @@
 package gormsupport
 
-import "github.com/lib/pq"
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	errs "github.com/pkg/errors"
+)
 
 const (
 	errCheckViolation  = "23514"
@@ -24,3 +28,26 @@ func IsUniqueViolation(err error, indexName string) bool {
 	}
 	return pqError.Code == errUniqueViolation && pqError.Constraint == indexName
 }
+
+// advisoryLockKey is the bigint key of the advisory lock of an entity: the first 64 bits of the
+// md5 hash of the namespace and of the id of the entity, so that the keys of the different kinds
+// of entities and of the ids of an entity are distinct, unless there is a hash collision
+const advisoryLockKey = "('x' || substr(md5(? || ':' || ?), 1, 16))::bit(64)::bigint"
+
+// AdvisoryXactLock takes the transaction level advisory lock of the entity with the given id in
+// the given namespace, waiting for the other transactions holding it to end
+func AdvisoryXactLock(tx *gorm.DB, namespace, id string) error {
+	return errs.WithStack(tx.Exec("SELECT pg_advisory_xact_lock("+advisoryLockKey+")", namespace, id).Error)
+}
+
+// TryAdvisoryXactLock takes the transaction level advisory lock of the entity with the given id
+// in the given namespace, if no other transaction holds it. It returns false otherwise.
+func TryAdvisoryXactLock(tx *gorm.DB, namespace, id string) (bool, error) {
+	var result struct {
+		Locked bool
+	}
+	if err := tx.Raw("SELECT pg_try_advisory_xact_lock("+advisoryLockKey+") AS locked", namespace, id).Scan(&result).Error; err != nil {
+		return false, errs.WithStack(err)
+	}
+	return result.Locked, nil
+}
@@ -3,6 +3,7 @@ package iteration
 import (
 	"time"
 
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
@@ -162,6 +163,14 @@ func (m *GormIterationRepository) Save(ctx context.Context, i Iteration) (*Itera
 		}, "unable to save the iterations")
 		return nil, errors.NewInternalError(err.Error())
 	}
+	if i.State != itr.State {
+		switch i.State {
+		case IterationStateStart:
+			event.Publish(ctx, m.db, event.IterationStarted{IterationID: i.ID, SpaceID: i.SpaceID})
+		case IterationStateClose:
+			event.Publish(ctx, m.db, event.IterationClosed{IterationID: i.ID, SpaceID: i.SpaceID})
+		}
+	}
 	return &i, nil
 }
 
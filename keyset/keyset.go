@@ -0,0 +1,328 @@
+// Package keyset implements keyset (a.k.a. cursor) pagination.
+//
+// Instead of skipping a number of rows, the next page is selected by a
+// condition on the sort keys: all rows sorted after the last row of the
+// current page. This keeps pages consistent when rows are inserted or deleted
+// between requests and does not require the database to count or skip rows.
+//
+// The position of a row is stored in a Cursor which holds the values of the
+// sort keys of that row. The values are read from the database as text (see
+// Columns and Scan) and cast back to the type of the key when the condition is
+// built, so no precision is lost on the way through the client.
+package keyset
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// columnPrefix is the prefix of the columns holding the cursor values
+const columnPrefix = "keyset_"
+
+// Key describes one sort key. Rows without a value for a key are always sorted last.
+// The keys of an order must identify a row uniquely, so the last key is usually the primary key.
+type Key struct {
+	// Expression is the SQL expression to sort by, e.g. "work_items.created_at"
+	Expression string
+	// Type is the SQL type the cursor values of this key are cast to, e.g. "timestamptz"
+	Type       string
+	Descending bool
+}
+
+// valueChecks check that a cursor value can be cast to the type of its key, by type. The values
+// read by Columns always can, the others would fail the query. The values of the other types are
+// not checked.
+var valueChecks = map[string]func(value string) error{
+	"bigint": func(value string) error {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err
+	},
+	"integer": func(value string) error {
+		_, err := strconv.ParseInt(value, 10, 32)
+		return err
+	},
+	"double precision": func(value string) error {
+		_, err := strconv.ParseFloat(value, 64)
+		return err
+	},
+	"uuid": func(value string) error {
+		_, err := uuid.FromString(value)
+		return err
+	},
+	"timestamptz": checkTimestamp,
+	"jsonb": func(value string) error {
+		var v interface{}
+		return json.Unmarshal([]byte(value), &v)
+	},
+}
+
+// timestampLayouts are the layouts of the text representations of the timestamptz values,
+// depending on the offset of the time zone
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999-07:00:00",
+}
+
+func checkTimestamp(value string) error {
+	if value == "infinity" || value == "-infinity" {
+		return nil
+	}
+	var err error
+	for _, layout := range timestampLayouts {
+		if _, err = time.Parse(layout, value); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Cursor points at a row in a sorted list.
+type Cursor struct {
+	// Values holds the text representation of the sort key values of the row, nil for missing values
+	Values []*string `json:"v"`
+	// Backward cursors select the rows before the row instead of the rows after it
+	Backward bool `json:"b,omitempty"`
+}
+
+// Page holds the cursors to the pages before and after a page of results.
+// A cursor is nil if there is no such page.
+type Page struct {
+	Prev *Cursor
+	Next *Cursor
+}
+
+// NewPage returns the page of results fetched with the cursor c. first and last
+// are the cursors of the first and last row of the page (in sort order) and
+// more tells if there were more rows in the direction of c.
+func NewPage(c Cursor, first, last *Cursor, more bool) *Page {
+	result := Page{}
+	if first == nil || last == nil {
+		return &result
+	}
+	prev := Cursor{Values: first.Values, Backward: true}
+	next := Cursor{Values: last.Values}
+	if c.Backward {
+		// we came from the next page
+		result.Next = &next
+		if more {
+			result.Prev = &prev
+		}
+	} else {
+		if more {
+			result.Next = &next
+		}
+		if !c.IsStart() {
+			result.Prev = &prev
+		}
+	}
+	return &result
+}
+
+// String returns the opaque, URL safe representation of the cursor
+func (c Cursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Parse parses the result of Cursor.String(). An empty string is the cursor
+// pointing before the first row.
+func Parse(s string) (*Cursor, error) {
+	if s == "" {
+		return &Cursor{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.NewBadParameterError("page[cursor]", s)
+	}
+	var result Cursor
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, errors.NewBadParameterError("page[cursor]", s)
+	}
+	return &result, nil
+}
+
+// IsStart returns true if the cursor points before the first row
+func (c Cursor) IsStart() bool {
+	return len(c.Values) == 0 && !c.Backward
+}
+
+// Order returns an order clause for the keys which can be used with gorm.DB.Order().
+// If backward is true, the order is reversed.
+func Order(keys []Key, backward bool) string {
+	clauses := make([]string, len(keys))
+	for i, key := range keys {
+		descending := key.Descending != backward
+		direction := "ASC"
+		if descending {
+			direction = "DESC"
+		}
+		nulls := "LAST"
+		if backward {
+			nulls = "FIRST"
+		}
+		clauses[i] = fmt.Sprintf("%s %s NULLS %s", key.Expression, direction, nulls)
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// Columns returns the select list items which read the cursor values of a row.
+// The values are read with Scan.
+func Columns(keys []Key) string {
+	columns := make([]string, len(keys))
+	for i, key := range keys {
+		columns[i] = fmt.Sprintf("(%s)::text AS %s%d", key.Expression, columnPrefix, i)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Scan reads the cursor values of the current row of the result set, which
+// must contain the columns returned by Columns(keys).
+// Note that float values are only read with full precision if the connection
+// sets extra_float_digits, which lib/pq does.
+func Scan(rows *sql.Rows, keys []Key) (*Cursor, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	var ignore interface{}
+	values := make([]sql.NullString, len(keys))
+	columnValues := make([]interface{}, len(columns))
+	for index, column := range columns {
+		columnValues[index] = &ignore
+		var i int
+		if _, err := fmt.Sscanf(column, columnPrefix+"%d", &i); err == nil && i < len(keys) {
+			columnValues[index] = &values[i]
+		}
+	}
+	if err := rows.Scan(columnValues...); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	result := Cursor{Values: make([]*string, len(keys))}
+	for i, value := range values {
+		if value.Valid {
+			s := value.String
+			result.Values[i] = &s
+		}
+	}
+	return &result, nil
+}
+
+// ScanPage reads the page of rows fetched with the cursor c: the rows must be selected
+// with Columns(keys), sorted with Order(keys, c.Backward) and limited to limit+1 rows,
+// the extra row telling if there is a next page. The given function reads the current
+// row into a new value. The values are returned in sort order, along with the page.
+func ScanPage(rows *sql.Rows, keys []Key, c Cursor, limit int, scan func(rows *sql.Rows) (interface{}, error)) ([]interface{}, *Page, error) {
+	result := []interface{}{}
+	cursors := []*Cursor{}
+	for rows.Next() {
+		value, err := scan(rows)
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err.Error())
+		}
+		cursor, err := Scan(rows, keys)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, value)
+		cursors = append(cursors, cursor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.NewInternalError(err.Error())
+	}
+	more := len(result) > limit
+	if more {
+		result = result[:limit]
+		cursors = cursors[:limit]
+	}
+	if c.Backward {
+		// the rows were fetched in reverse order
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+			cursors[i], cursors[j] = cursors[j], cursors[i]
+		}
+	}
+	if len(result) == 0 {
+		return result, NewPage(c, nil, nil, false), nil
+	}
+	return result, NewPage(c, cursors[0], cursors[len(cursors)-1], more), nil
+}
+
+// Where returns a condition selecting the rows sorted after the cursor (or
+// before the cursor for backward cursors) together with its parameters.
+// The condition is "TRUE" for the start cursor.
+func Where(keys []Key, c Cursor) (string, []interface{}, error) {
+	if len(c.Values) == 0 {
+		return "TRUE", []interface{}{}, nil
+	}
+	if len(c.Values) != len(keys) {
+		return "", nil, errors.NewBadParameterError("page[cursor]", c.String()).Expected("a cursor created for the same sort order")
+	}
+	for i, key := range keys {
+		check, ok := valueChecks[key.Type]
+		if !ok || c.Values[i] == nil {
+			continue
+		}
+		if err := check(*c.Values[i]); err != nil {
+			return "", nil, errors.NewBadParameterError("page[cursor]", c.String()).Expected("a cursor created for the same sort order")
+		}
+	}
+	// (k1 after v1) or (k1 = v1 and k2 after v2) or ...
+	var buf bytes.Buffer
+	parameters := []interface{}{}
+	for i := range keys {
+		if i > 0 {
+			buf.WriteString(" OR ")
+		}
+		buf.WriteString("(")
+		for j := 0; j < i; j++ {
+			clause, params := equal(keys[j], c.Values[j])
+			buf.WriteString(clause)
+			buf.WriteString(" AND ")
+			parameters = append(parameters, params...)
+		}
+		clause, params := after(keys[i], c.Values[i], c.Backward)
+		buf.WriteString(clause)
+		parameters = append(parameters, params...)
+		buf.WriteString(")")
+	}
+	return "(" + buf.String() + ")", parameters, nil
+}
+
+func equal(key Key, value *string) (string, []interface{}) {
+	if value == nil {
+		return key.Expression + " IS NULL", nil
+	}
+	return fmt.Sprintf("%s = CAST(? AS %s)", key.Expression, key.Type), []interface{}{*value}
+}
+
+// after returns a condition selecting the values of the key sorted after (or
+// before, if backward is true) the given value, remembering that missing
+// values are sorted last.
+func after(key Key, value *string, backward bool) (string, []interface{}) {
+	if value == nil {
+		if backward {
+			return key.Expression + " IS NOT NULL", nil
+		}
+		return "FALSE", nil
+	}
+	operator := ">"
+	if key.Descending != backward {
+		operator = "<"
+	}
+	clause := fmt.Sprintf("%s %s CAST(? AS %s)", key.Expression, operator, key.Type)
+	if !backward {
+		clause = fmt.Sprintf("(%s OR %s IS NULL)", clause, key.Expression)
+	}
+	return clause, []interface{}{*value}
+}
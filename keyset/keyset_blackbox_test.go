@@ -0,0 +1,128 @@
+package keyset_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/errors"
+	. "github.com/almighty/almighty-core/keyset"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var keys = []Key{
+	{Expression: "t.created_at", Type: "timestamptz", Descending: true},
+	{Expression: "t.id", Type: "bigint", Descending: true},
+}
+
+func str(s string) *string {
+	return &s
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	c := Cursor{Values: []*string{str("2017-01-31 10:00:00.123456+00"), nil}, Backward: true}
+	parsed, err := Parse(c.String())
+	require.Nil(t, err)
+	assert.Equal(t, c, *parsed)
+
+	parsed, err = Parse("")
+	require.Nil(t, err)
+	assert.True(t, parsed.IsStart())
+
+	_, err = Parse("not a cursor")
+	assert.NotNil(t, err)
+}
+
+func TestOrder(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, "t.created_at DESC NULLS LAST, t.id DESC NULLS LAST", Order(keys, false))
+	assert.Equal(t, "t.created_at ASC NULLS FIRST, t.id ASC NULLS FIRST", Order(keys, true))
+}
+
+func TestColumns(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	assert.Equal(t, "(t.created_at)::text AS keyset_0, (t.id)::text AS keyset_1", Columns(keys))
+}
+
+func TestWhere(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	t.Run("start", func(t *testing.T) {
+		where, params, err := Where(keys, Cursor{})
+		require.Nil(t, err)
+		assert.Equal(t, "TRUE", where)
+		assert.Empty(t, params)
+	})
+	t.Run("forward", func(t *testing.T) {
+		where, params, err := Where(keys, Cursor{Values: []*string{str("2017-01-31 00:00:00+00"), str("5")}})
+		require.Nil(t, err)
+		assert.Equal(t, "(((t.created_at < CAST(? AS timestamptz) OR t.created_at IS NULL)) OR "+
+			"(t.created_at = CAST(? AS timestamptz) AND (t.id < CAST(? AS bigint) OR t.id IS NULL)))", where)
+		assert.Equal(t, []interface{}{"2017-01-31 00:00:00+00", "2017-01-31 00:00:00+00", "5"}, params)
+	})
+	t.Run("backward", func(t *testing.T) {
+		where, params, err := Where(keys, Cursor{Values: []*string{str("2017-01-31 00:00:00+00"), str("5")}, Backward: true})
+		require.Nil(t, err)
+		assert.Equal(t, "((t.created_at > CAST(? AS timestamptz)) OR "+
+			"(t.created_at = CAST(? AS timestamptz) AND t.id > CAST(? AS bigint)))", where)
+		assert.Equal(t, []interface{}{"2017-01-31 00:00:00+00", "2017-01-31 00:00:00+00", "5"}, params)
+	})
+	t.Run("missing value", func(t *testing.T) {
+		where, params, err := Where(keys, Cursor{Values: []*string{nil, str("5")}})
+		require.Nil(t, err)
+		assert.Equal(t, "((FALSE) OR (t.created_at IS NULL AND (t.id < CAST(? AS bigint) OR t.id IS NULL)))", where)
+		assert.Equal(t, []interface{}{"5"}, params)
+
+		where, params, err = Where(keys, Cursor{Values: []*string{nil, str("5")}, Backward: true})
+		require.Nil(t, err)
+		assert.Equal(t, "((t.created_at IS NOT NULL) OR (t.created_at IS NULL AND t.id > CAST(? AS bigint)))", where)
+		assert.Equal(t, []interface{}{"5"}, params)
+	})
+	t.Run("wrong number of values", func(t *testing.T) {
+		_, _, err := Where(keys, Cursor{Values: []*string{str("5")}})
+		assert.NotNil(t, err)
+	})
+	t.Run("values of the wrong type", func(t *testing.T) {
+		_, _, err := Where(keys, Cursor{Values: []*string{str("2017"), str("5")}})
+		assert.IsType(t, errors.BadParameterError{}, err)
+		_, _, err = Where(keys, Cursor{Values: []*string{str("2017-01-31 00:00:00+00"), str("5.5")}})
+		assert.IsType(t, errors.BadParameterError{}, err)
+		// half-hour time zones and precise timestamps
+		_, _, err = Where(keys, Cursor{Values: []*string{str("2017-01-31 05:30:00.123456+05:30"), str("5")}})
+		assert.Nil(t, err)
+	})
+}
+
+func TestNewPage(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	first := &Cursor{Values: []*string{str("1")}}
+	last := &Cursor{Values: []*string{str("2")}}
+
+	page := NewPage(Cursor{}, first, last, true)
+	assert.Nil(t, page.Prev)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, *last, *page.Next)
+
+	page = NewPage(Cursor{Values: []*string{str("0")}}, first, last, false)
+	require.NotNil(t, page.Prev)
+	assert.Equal(t, Cursor{Values: first.Values, Backward: true}, *page.Prev)
+	assert.Nil(t, page.Next)
+
+	page = NewPage(Cursor{Values: []*string{str("3")}, Backward: true}, first, last, false)
+	assert.Nil(t, page.Prev)
+	require.NotNil(t, page.Next)
+
+	page = NewPage(Cursor{}, nil, nil, false)
+	assert.Nil(t, page.Prev)
+	assert.Nil(t, page.Next)
+}
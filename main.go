@@ -17,9 +17,11 @@ import (
 	logrus "github.com/Sirupsen/logrus"
 	"github.com/almighty/almighty-core/account"
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/auth"
 	config "github.com/almighty/almighty-core/configuration"
 	"github.com/almighty/almighty-core/controller"
+	"github.com/almighty/almighty-core/eventstream"
 	"github.com/almighty/almighty-core/gormapplication"
 	"github.com/almighty/almighty-core/jsonapi"
 	"github.com/almighty/almighty-core/log"
@@ -29,6 +31,7 @@ import (
 	"github.com/almighty/almighty-core/remoteworkitem"
 	"github.com/almighty/almighty-core/space"
 	"github.com/almighty/almighty-core/token"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 
@@ -149,7 +152,7 @@ func main() {
 	// Mount middleware
 	service.Use(middleware.RequestID())
 	service.Use(middleware.LogRequest(configuration.IsPostgresDeveloperModeEnabled()))
-	service.Use(gzip.Middleware(9))
+	service.Use(eventstream.SkipStreams(gzip.Middleware(9)))
 	service.Use(jsonapi.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
 
@@ -159,6 +162,22 @@ func main() {
 	scheduler = remoteworkitem.NewScheduler(db)
 	defer scheduler.Stop()
 
+	// Worker to deliver the events of the spaces to their webhooks
+	webhookWorker := webhook.NewWorker(db)
+	webhookWorker.Start()
+	defer webhookWorker.Stop()
+
+	// Hub to stream the events of the spaces to the clients, across the server replicas,
+	// the events being recorded in the transactions of the changes
+	event.SubscribeInTransaction(eventstream.Append)
+	eventHub := eventstream.NewHub(db, configuration.GetPostgresConfigString())
+	if err := eventHub.Start(); err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"err": err,
+		}, "failed to listen to the events of the spaces")
+	}
+	defer eventHub.Stop()
+
 	accessTokens := controller.GetAccessTokens(configuration)
 	scheduler.ScheduleAllQueries(service.Context, accessTokens)
 
@@ -219,6 +238,14 @@ func main() {
 	workItemCommentsCtrl := controller.NewWorkItemCommentsController(service, appDB)
 	app.MountWorkItemCommentsController(service, workItemCommentsCtrl)
 
+	// Mount "work item revisions" controller
+	workItemRevisionsCtrl := controller.NewWorkItemRevisionsController(service, appDB)
+	app.MountWorkItemRevisionsController(service, workItemRevisionsCtrl)
+
+	// Mount "work item mentions" controller
+	workItemMentionsCtrl := controller.NewWorkItemMentionsController(service, appDB)
+	app.MountWorkItemMentionsController(service, workItemMentionsCtrl)
+
 	// Mount "work item relationships links" controller
 	workItemRelationshipsLinksCtrl := controller.NewWorkItemRelationshipsLinksController(service, appDB)
 	app.MountWorkItemRelationshipsLinksController(service, workItemRelationshipsLinksCtrl)
@@ -278,6 +305,21 @@ func main() {
 	spaceAreaCtrl := controller.NewSpaceAreasController(service, appDB)
 	app.MountSpaceAreasController(service, spaceAreaCtrl)
 
+	// Mount "webhooks" controller
+	webhookCtrl := controller.NewWebhookController(service, appDB)
+	app.MountWebhookController(service, webhookCtrl)
+
+	spaceWebhooksCtrl := controller.NewSpaceWebhooksController(service, appDB)
+	app.MountSpaceWebhooksController(service, spaceWebhooksCtrl)
+
+	// Mount "space events" controller
+	spaceEventsCtrl := controller.NewSpaceEventsController(service, appDB, eventHub)
+	app.MountSpaceEventsController(service, spaceEventsCtrl)
+
+	// Mount "space dependencies" controller
+	spaceDependenciesCtrl := controller.NewSpaceDependenciesController(service, appDB)
+	app.MountSpaceDependenciesController(service, spaceDependenciesCtrl)
+
 	filterCtrl := controller.NewFilterController(service)
 	app.MountFilterController(service, filterCtrl)
 
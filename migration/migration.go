@@ -257,6 +257,33 @@ func getMigrations() migrations {
 	// Version 46
 	m = append(m, steps{executeSQLFile("046-oauth-states.sql")})
 
+	// Version 47
+	m = append(m, steps{executeSQLFile("047-work-item-references.sql")})
+
+	// Version 48
+	m = append(m, steps{executeSQLFile("048-tracker-query-sync.sql")})
+
+	// Version 49
+	m = append(m, steps{executeSQLFile("049-tracker-query-runs.sql")})
+
+	// Version 50
+	m = append(m, steps{executeSQLFile("050-tracker-query-high-water-mark.sql")})
+
+	// Version 51
+	m = append(m, steps{executeSQLFile("051-tracker-query-field-mappings.sql")})
+
+	// Version 52
+	m = append(m, steps{executeSQLFile("052-tracker-webhooks.sql")})
+
+	// Version 53
+	m = append(m, steps{executeSQLFile("053-webhooks.sql")})
+
+	// Version 54
+	m = append(m, steps{executeSQLFile("054-space-events.sql")})
+
+	// Version 55
+	m = append(m, steps{executeSQLFile("055-space-events-tx-id.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
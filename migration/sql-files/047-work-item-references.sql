@@ -0,0 +1,19 @@
+-- store the references to work items ("#123") found in the work item descriptions and in the comments
+CREATE TABLE work_item_references (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone,
+    work_item_id bigint NOT NULL,
+    source_work_item_id bigint NOT NULL,
+    comment_id uuid
+);
+
+CREATE INDEX work_item_references_work_item_id_idx ON work_item_references USING BTREE (work_item_id);
+CREATE INDEX work_item_references_source_work_item_id_idx ON work_item_references USING BTREE (source_work_item_id);
+
+-- delete the references when the referenced work item, the source work item or the comment is deleted from the database.
+ALTER TABLE work_item_references
+    ADD CONSTRAINT work_item_references_work_item_id_fk FOREIGN KEY (work_item_id) REFERENCES work_items(id) ON DELETE CASCADE;
+ALTER TABLE work_item_references
+    ADD CONSTRAINT work_item_references_source_work_item_id_fk FOREIGN KEY (source_work_item_id) REFERENCES work_items(id) ON DELETE CASCADE;
+ALTER TABLE work_item_references
+    ADD CONSTRAINT work_item_references_comment_id_fk FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE;
@@ -0,0 +1,36 @@
+-- the policy applied when an imported work item was modified both locally and on the remote tracker
+ALTER TABLE tracker_queries ADD sync_policy text DEFAULT 'remote-wins' NOT NULL;
+
+-- store the fields of the imported work items which are in conflict with the remote tracker
+CREATE TABLE tracker_item_conflicts (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    tracker_query_id bigint NOT NULL,
+    tracker_item_id bigint NOT NULL,
+    work_item_id bigint NOT NULL,
+    field text NOT NULL,
+    "values" jsonb
+);
+
+CREATE UNIQUE INDEX tracker_item_conflicts_tracker_item_id_field_idx ON tracker_item_conflicts USING BTREE (tracker_item_id, field);
+CREATE INDEX tracker_item_conflicts_tracker_query_id_idx ON tracker_item_conflicts USING BTREE (tracker_query_id);
+
+ALTER TABLE tracker_item_conflicts
+    ADD CONSTRAINT tracker_item_conflicts_tracker_query_id_fk FOREIGN KEY (tracker_query_id) REFERENCES tracker_queries(id) ON DELETE CASCADE;
+ALTER TABLE tracker_item_conflicts
+    ADD CONSTRAINT tracker_item_conflicts_tracker_item_id_fk FOREIGN KEY (tracker_item_id) REFERENCES tracker_items(id) ON DELETE CASCADE;
+ALTER TABLE tracker_item_conflicts
+    ADD CONSTRAINT tracker_item_conflicts_work_item_id_fk FOREIGN KEY (work_item_id) REFERENCES work_items(id) ON DELETE CASCADE;
+
+-- store the local comments which were pushed to the remote tracker
+CREATE TABLE tracker_item_comments (
+    comment_id uuid primary key NOT NULL,
+    created_at timestamp with time zone,
+    tracker_item_id bigint NOT NULL
+);
+
+ALTER TABLE tracker_item_comments
+    ADD CONSTRAINT tracker_item_comments_comment_id_fk FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE tracker_item_comments
+    ADD CONSTRAINT tracker_item_comments_tracker_item_id_fk FOREIGN KEY (tracker_item_id) REFERENCES tracker_items(id) ON DELETE CASCADE;
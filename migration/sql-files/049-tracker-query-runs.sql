@@ -0,0 +1,19 @@
+-- store the runs of the tracker queries, i.e., the scheduled or manually triggered imports
+CREATE TABLE tracker_query_runs (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    tracker_query_id bigint NOT NULL,
+    status text NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone,
+    fetched integer DEFAULT 0 NOT NULL,
+    created integer DEFAULT 0 NOT NULL,
+    updated integer DEFAULT 0 NOT NULL,
+    failed integer DEFAULT 0 NOT NULL,
+    errors jsonb
+);
+
+CREATE INDEX tracker_query_runs_tracker_query_id_started_at_idx ON tracker_query_runs USING BTREE (tracker_query_id, started_at);
+
+-- delete the runs along with their tracker query
+ALTER TABLE tracker_query_runs
+    ADD CONSTRAINT tracker_query_runs_tracker_query_id_fk FOREIGN KEY (tracker_query_id) REFERENCES tracker_queries(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- the last update time of the remote items imported by the tracker query, only the items updated since then are fetched
+ALTER TABLE tracker_queries ADD last_updated_at timestamp with time zone;
@@ -0,0 +1,7 @@
+-- the type of the work items created by a tracker query ("bug" if null)
+ALTER TABLE tracker_queries ADD work_item_type_id uuid;
+ALTER TABLE tracker_queries
+    ADD CONSTRAINT tracker_queries_work_item_type_id_fk FOREIGN KEY (work_item_type_id) REFERENCES work_item_types(id) ON DELETE SET NULL;
+
+-- the mappings of the remote attributes to the work item fields which override the ones of the tracker provider
+ALTER TABLE tracker_queries ADD field_mappings jsonb;
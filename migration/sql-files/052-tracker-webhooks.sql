@@ -0,0 +1,7 @@
+-- the secret shared with the remote tracker to authenticate the events sent by its webhooks
+ALTER TABLE trackers ADD webhook_secret text;
+
+-- the remote comments imported from the events of the webhooks
+ALTER TABLE tracker_item_comments ADD remote_comment_id text;
+
+CREATE UNIQUE INDEX tracker_item_comments_remote_comment_id_idx ON tracker_item_comments USING BTREE (tracker_item_id, remote_comment_id);
@@ -0,0 +1,52 @@
+-- the webhooks of the spaces: the events of the given types are sent to the URL of the subscription
+CREATE TABLE webhook_subscriptions (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    space_id uuid NOT NULL,
+    url text NOT NULL,
+    secret text,
+    event_types jsonb NOT NULL
+);
+
+CREATE INDEX webhook_subscriptions_space_id_idx ON webhook_subscriptions USING BTREE (space_id);
+
+ALTER TABLE webhook_subscriptions
+    ADD CONSTRAINT webhook_subscriptions_space_id_fk FOREIGN KEY (space_id) REFERENCES spaces(id) ON DELETE CASCADE;
+
+-- the outbox: the events are recorded in the transaction of the change and dispatched to the subscriptions afterwards
+CREATE TABLE webhook_events (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone,
+    space_id uuid NOT NULL,
+    event_type text NOT NULL,
+    payload jsonb,
+    dispatched_at timestamp with time zone
+);
+
+CREATE INDEX webhook_events_not_dispatched_idx ON webhook_events USING BTREE (created_at) WHERE dispatched_at IS NULL;
+
+-- the deliveries of the events to the subscriptions, along with the outcome of their last attempt
+CREATE TABLE webhook_deliveries (
+    id uuid primary key DEFAULT uuid_generate_v4() NOT NULL,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    subscription_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type text NOT NULL,
+    status text NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    next_attempt_at timestamp with time zone NOT NULL,
+    response_status integer,
+    error text,
+    delivered_at timestamp with time zone
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries USING BTREE (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_id_created_at_idx ON webhook_deliveries USING BTREE (subscription_id, created_at);
+
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_subscription_id_fk FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_event_id_fk FOREIGN KEY (event_id) REFERENCES webhook_events(id) ON DELETE CASCADE;
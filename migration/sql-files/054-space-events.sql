@@ -0,0 +1,13 @@
+-- the changes of the work items, links and comments of the spaces, streamed to the clients as server-sent events.
+-- The id is the id of the server-sent event, used by the clients to resume the stream.
+CREATE TABLE space_events (
+    id bigserial primary key,
+    created_at timestamp with time zone,
+    space_id uuid NOT NULL,
+    event_type text NOT NULL,
+    entity_id text NOT NULL,
+    data jsonb
+);
+
+CREATE INDEX space_events_space_id_id_idx ON space_events USING BTREE (space_id, id);
+CREATE INDEX space_events_created_at_idx ON space_events USING BTREE (created_at);
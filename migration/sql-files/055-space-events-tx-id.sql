@@ -0,0 +1,6 @@
+-- the id of the transaction which recorded the event. The events are streamed in the order of their
+-- transactions, once all the older transactions ended, since the ids may be committed out of order.
+ALTER TABLE space_events ADD COLUMN tx_id bigint NOT NULL DEFAULT txid_current();
+
+DROP INDEX space_events_space_id_id_idx;
+CREATE INDEX space_events_space_id_tx_id_id_idx ON space_events USING BTREE (space_id, tx_id, id);
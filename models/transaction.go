@@ -1,6 +1,8 @@
 package models
 
 import (
+	"github.com/almighty/almighty-core/application/event"
+
 	"github.com/jinzhu/gorm"
 	errs "github.com/pkg/errors"
 )
@@ -8,7 +10,8 @@ import (
 // Transactional executes the given function in a transaction. If todo returns an error, the transaction is rolled back
 func Transactional(db *gorm.DB, todo func(tx *gorm.DB) error) error {
 	var tx *gorm.DB
-	tx = db.Begin()
+	// the events published in the transaction are dispatched once it is committed
+	tx = event.WithBuffer(db.Begin())
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -16,6 +19,13 @@ func Transactional(db *gorm.DB, todo func(tx *gorm.DB) error) error {
 		tx.Rollback()
 		return errs.WithStack(err)
 	}
-	tx.Commit()
-	return tx.Error
+	if err := event.BeforeCommit(tx); err != nil {
+		tx.Rollback()
+		return errs.WithStack(err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return errs.WithStack(err)
+	}
+	event.Flush(tx)
+	return nil
 }
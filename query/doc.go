@@ -0,0 +1,11 @@
+// Package query implements the query language used to filter work items and
+// other entities. A query is parsed into a criteria.Expression, for example
+//
+//	state = "open" AND (assignee = me OR iteration IN ("1", "2")) AND created > 2017-01-01
+//
+// Supported operators are =, != (or <>), <, <=, >, >=, [NOT] IN, [NOT] LIKE,
+// [NOT] ILIKE, IS [NOT] NULL, NOT, AND and OR.
+// Field names are identifiers and may contain dots (e.g. "system.state"),
+// strings are quoted with single or double quotes and dates are written
+// without quotes (e.g. 2017-01-31). Keywords are case-insensitive.
+package query
@@ -0,0 +1,247 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenType identifies the kind of a lexical token
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDate
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenEQ
+	tokenNE
+	tokenLT
+	tokenLE
+	tokenGT
+	tokenGE
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenIn
+	tokenLike
+	tokenILike
+	tokenIs
+	tokenNull
+	tokenTrue
+	tokenFalse
+	tokenMe
+)
+
+// keywords are matched case-insensitively
+var keywords = map[string]tokenType{
+	"and":   tokenAnd,
+	"or":    tokenOr,
+	"not":   tokenNot,
+	"in":    tokenIn,
+	"like":  tokenLike,
+	"ilike": tokenILike,
+	"is":    tokenIs,
+	"null":  tokenNull,
+	"true":  tokenTrue,
+	"false": tokenFalse,
+	"me":    tokenMe,
+}
+
+// dates are written without quotes, e.g. 2017-01-31 or 2017-01-31T14:00:00Z
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?)?`)
+
+// token is a single lexical token. pos is the byte offset of the token in the query.
+type token struct {
+	typ  tokenType
+	text string // the literal text of the token, unquoted for strings
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return fmt.Sprintf("%q", t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+// lexer splits a query into tokens
+type lexer struct {
+	input string
+	pos   int
+}
+
+// lex returns all tokens of the input, terminated by a tokenEOF
+func lex(input string) ([]token, error) {
+	l := lexer{input: input}
+	result := []token{}
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+		if t.typ == tokenEOF {
+			return result, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipWhitespace()
+	if l.pos >= len(l.input) {
+		return token{typ: tokenEOF, pos: l.pos}, nil
+	}
+	start := l.pos
+	c := l.input[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{tokenLParen, "(", start}, nil
+	case c == ')':
+		l.pos++
+		return token{tokenRParen, ")", start}, nil
+	case c == ',':
+		l.pos++
+		return token{tokenComma, ",", start}, nil
+	case c == '=':
+		l.pos++
+		return token{tokenEQ, "=", start}, nil
+	case c == '!':
+		if l.peek(1) == '=' {
+			l.pos += 2
+			return token{tokenNE, "!=", start}, nil
+		}
+		return token{}, newSyntaxError(start, "unexpected character '!', did you mean '!='?")
+	case c == '<':
+		switch l.peek(1) {
+		case '=':
+			l.pos += 2
+			return token{tokenLE, "<=", start}, nil
+		case '>':
+			l.pos += 2
+			return token{tokenNE, "<>", start}, nil
+		}
+		l.pos++
+		return token{tokenLT, "<", start}, nil
+	case c == '>':
+		if l.peek(1) == '=' {
+			l.pos += 2
+			return token{tokenGE, ">=", start}, nil
+		}
+		l.pos++
+		return token{tokenGT, ">", start}, nil
+	case c == '"' || c == '\'':
+		return l.lexString(c)
+	case c == '-' || (c >= '0' && c <= '9'):
+		return l.lexNumberOrDate()
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.pos:])
+	if isIdentStart(r) {
+		return l.lexIdent(), nil
+	}
+	return token{}, newSyntaxError(start, fmt.Sprintf("unexpected character %q", r))
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.input) {
+		return l.input[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) skipWhitespace() {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+// lexString reads a string delimited by the given quote character.
+// A backslash escapes the next character.
+func (l *lexer) lexString(quote byte) (token, error) {
+	start := l.pos
+	l.pos++ // opening quote
+	var buf bytes.Buffer
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch c {
+		case '\\':
+			if l.pos+1 >= len(l.input) {
+				return token{}, newSyntaxError(l.pos, "unterminated escape sequence")
+			}
+			buf.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+		case quote:
+			l.pos++
+			return token{tokenString, buf.String(), start}, nil
+		default:
+			buf.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, newSyntaxError(start, "unterminated string")
+}
+
+func (l *lexer) lexNumberOrDate() (token, error) {
+	start := l.pos
+	if date := datePattern.FindString(l.input[l.pos:]); date != "" {
+		l.pos += len(date)
+		return token{tokenDate, date, start}, nil
+	}
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+	digits := 0
+	dot := false
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c >= '0' && c <= '9' {
+			digits++
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+		l.pos++
+	}
+	if digits == 0 {
+		return token{}, newSyntaxError(start, "malformed number")
+	}
+	return token{tokenNumber, l.input[start:l.pos], start}, nil
+}
+
+// identifiers may contain letters, digits, '_' and '.' so that field names
+// like "system.state" can be written without quotes
+func (l *lexer) lexIdent() token {
+	start := l.pos
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !isIdentStart(r) && !unicode.IsDigit(r) && r != '.' {
+			break
+		}
+		l.pos += size
+	}
+	text := l.input[start:l.pos]
+	if typ, ok := keywords[strings.ToLower(text)]; ok {
+		return token{typ, text, start}
+	}
+	return token{tokenIdent, text, start}
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
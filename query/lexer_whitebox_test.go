@@ -0,0 +1,58 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLex(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	t.Run("tokens", func(t *testing.T) {
+		tokens, err := lex(`system.state != "open" and (x<=-1.5 OR y IN ('a\'b', 2017-01-31))`)
+		require.Nil(t, err)
+		expected := []token{
+			{tokenIdent, "system.state", 0},
+			{tokenNE, "!=", 13},
+			{tokenString, "open", 16},
+			{tokenAnd, "and", 23},
+			{tokenLParen, "(", 27},
+			{tokenIdent, "x", 28},
+			{tokenLE, "<=", 29},
+			{tokenNumber, "-1.5", 31},
+			{tokenOr, "OR", 36},
+			{tokenIdent, "y", 39},
+			{tokenIn, "IN", 41},
+			{tokenLParen, "(", 44},
+			{tokenString, "a'b", 45},
+			{tokenComma, ",", 51},
+			{tokenDate, "2017-01-31", 53},
+			{tokenRParen, ")", 63},
+			{tokenRParen, ")", 64},
+			{tokenEOF, "", 65},
+		}
+		assert.Equal(t, expected, tokens)
+	})
+
+	t.Run("date with time", func(t *testing.T) {
+		tokens, err := lex(`2017-01-31T10:15:00Z`)
+		require.Nil(t, err)
+		assert.Equal(t, token{tokenDate, "2017-01-31T10:15:00Z", 0}, tokens[0])
+	})
+
+	t.Run("unterminated string", func(t *testing.T) {
+		_, err := lex(`title = "foo`)
+		require.NotNil(t, err)
+		assert.Equal(t, SyntaxError{Pos: 8, Msg: "unterminated string"}, err)
+	})
+
+	t.Run("unexpected character", func(t *testing.T) {
+		_, err := lex(`title # 1`)
+		require.NotNil(t, err)
+		assert.Equal(t, 6, err.(SyntaxError).Pos)
+	})
+}
@@ -0,0 +1,364 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/pkg/errors"
+)
+
+// SyntaxError describes why and where a query could not be parsed.
+// Pos is the zero-based byte offset into the query.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+// Error implements the error interface
+func (err SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", err.Pos, err.Msg)
+}
+
+func newSyntaxError(pos int, msg string) SyntaxError {
+	return SyntaxError{Pos: pos, Msg: msg}
+}
+
+// Parser turns query strings into criteria expressions
+type Parser struct {
+	// Aliases maps short field names used in queries (e.g. "state") to the
+	// name of the field they stand for (e.g. "system.state")
+	Aliases map[string]string
+	// ListFields holds the (resolved) names of fields containing lists.
+	// Comparing a list field with a single value tests for membership.
+	ListFields map[string]bool
+	// Me is the value of the keyword "me", usually the ID of the current identity.
+	// Using "me" in a query is an error if Me is nil.
+	Me interface{}
+}
+
+// Parse parses the given query using a parser without aliases.
+// Returns the expression "true" if the query is empty.
+func Parse(exp *string) (criteria.Expression, error) {
+	if exp == nil {
+		return criteria.Literal(true), nil
+	}
+	return Parser{}.Parse(*exp)
+}
+
+// Parse parses the given query into an expression.
+// Returns the expression "true" if the query is empty.
+// Queries of the form { "attribute1":value1,"attribute2":value2} are
+// accepted for backwards compatibility and are parsed into
+// "attribute1=value1 and attribute2=value2"
+func (p Parser) Parse(q string) (criteria.Expression, error) {
+	trimmed := strings.TrimSpace(q)
+	if len(trimmed) == 0 {
+		return criteria.Literal(true), nil
+	}
+	if strings.HasPrefix(trimmed, "{") {
+		return parseJSON(trimmed)
+	}
+	tokens, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	s := parseState{parser: p, tokens: tokens}
+	result, err := s.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := s.peek(); t.typ != tokenEOF {
+		return nil, newSyntaxError(t.pos, fmt.Sprintf("unexpected %s, expected 'AND', 'OR' or end of query", t))
+	}
+	return result, nil
+}
+
+// parseJSON parses strings of the form { "attribute1":value1,"attribute2":value2} into an expression of the form "attribute1=value1 and attribute2=value2"
+func parseJSON(exp string) (criteria.Expression, error) {
+	var unmarshalled map[string]interface{}
+	err := json.Unmarshal([]byte(exp), &unmarshalled)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var result criteria.Expression
+	for key, value := range unmarshalled {
+		current := criteria.Equals(criteria.Field(key), criteria.Literal(value))
+		if result == nil {
+			result = current
+		} else {
+			result = criteria.And(result, current)
+		}
+	}
+	if result == nil {
+		return criteria.Literal(true), nil
+	}
+	return result, nil
+}
+
+// parseState is a recursive descent parser over the tokens of one query. The grammar is
+//
+//	query      := or EOF
+//	or         := and ( "OR" and )*
+//	and        := unary ( "AND" unary )*
+//	unary      := "NOT" unary | primary
+//	primary    := "(" or ")" | comparison
+//	comparison := field ( "=" | "!=" | "<>" | "<" | "<=" | ">" | ">=" ) value
+//	            | field [ "NOT" ] ( "LIKE" | "ILIKE" ) string
+//	            | field [ "NOT" ] "IN" "(" value ( "," value )* ")"
+//	            | field "IS" [ "NOT" ] "NULL"
+//	value      := string | number | date | "true" | "false" | "me"
+type parseState struct {
+	parser Parser
+	tokens []token
+	pos    int
+}
+
+func (s *parseState) peek() token {
+	return s.tokens[s.pos]
+}
+
+func (s *parseState) next() token {
+	t := s.tokens[s.pos]
+	if t.typ != tokenEOF {
+		s.pos++
+	}
+	return t
+}
+
+func (s *parseState) expect(typ tokenType, what string) (token, error) {
+	t := s.next()
+	if t.typ != typ {
+		return t, newSyntaxError(t.pos, fmt.Sprintf("expected %s but found %s", what, t))
+	}
+	return t, nil
+}
+
+func (s *parseState) parseOr() (criteria.Expression, error) {
+	left, err := s.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for s.peek().typ == tokenOr {
+		s.next()
+		right, err := s.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = criteria.Or(left, right)
+	}
+	return left, nil
+}
+
+func (s *parseState) parseAnd() (criteria.Expression, error) {
+	left, err := s.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for s.peek().typ == tokenAnd {
+		s.next()
+		right, err := s.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = criteria.And(left, right)
+	}
+	return left, nil
+}
+
+func (s *parseState) parseUnary() (criteria.Expression, error) {
+	if s.peek().typ == tokenNot {
+		s.next()
+		operand, err := s.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return criteria.Not(operand), nil
+	}
+	return s.parsePrimary()
+}
+
+func (s *parseState) parsePrimary() (criteria.Expression, error) {
+	if s.peek().typ == tokenLParen {
+		s.next()
+		result, err := s.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	return s.parseComparison()
+}
+
+// binaryOperators maps comparison tokens to the constructors of their expressions
+var binaryOperators = map[tokenType]func(left criteria.Expression, right criteria.Expression) criteria.Expression{
+	tokenEQ:    criteria.Equals,
+	tokenNE:    criteria.NotEquals,
+	tokenLT:    criteria.LessThan,
+	tokenLE:    criteria.LessThanOrEqual,
+	tokenGT:    criteria.GreaterThan,
+	tokenGE:    criteria.GreaterThanOrEqual,
+	tokenLike:  criteria.Like,
+	tokenILike: criteria.ILike,
+}
+
+func (s *parseState) parseComparison() (criteria.Expression, error) {
+	fieldToken, err := s.expect(tokenIdent, "field name")
+	if err != nil {
+		return nil, err
+	}
+	field := s.fieldName(fieldToken.text)
+	isList := s.parser.ListFields[field]
+	op := s.next()
+	negate := false
+	if op.typ == tokenNot {
+		negate = true
+		op = s.next()
+		if op.typ != tokenIn && op.typ != tokenLike && op.typ != tokenILike {
+			return nil, newSyntaxError(op.pos, fmt.Sprintf("expected 'IN', 'LIKE' or 'ILIKE' after 'NOT' but found %s", op))
+		}
+	}
+	var result criteria.Expression
+	switch op.typ {
+	case tokenEQ, tokenNE, tokenLT, tokenLE, tokenGT, tokenGE, tokenLike, tokenILike:
+		if isList && op.typ != tokenEQ && op.typ != tokenNE {
+			return nil, newSyntaxError(op.pos, fmt.Sprintf("operator %s can not be used with the list field %q", op, fieldToken.text))
+		}
+		value, err := s.parseValue(field)
+		if err != nil {
+			return nil, err
+		}
+		if op.typ == tokenLike || op.typ == tokenILike {
+			if _, ok := value.(*criteria.LiteralExpression).Value.(string); !ok {
+				return nil, newSyntaxError(s.tokens[s.pos-1].pos, fmt.Sprintf("operator %s requires a string pattern", op))
+			}
+		}
+		result = binaryOperators[op.typ](criteria.Field(field), value)
+	case tokenIn:
+		values, err := s.parseValueList(field)
+		if err != nil {
+			return nil, err
+		}
+		if isList {
+			// lists are compared by containment, so test each value separately
+			for _, value := range values {
+				current := criteria.Equals(criteria.Field(field), value)
+				if result == nil {
+					result = current
+				} else {
+					result = criteria.Or(result, current)
+				}
+			}
+		} else {
+			result = criteria.In(criteria.Field(field), values...)
+		}
+	case tokenIs:
+		if s.peek().typ == tokenNot {
+			s.next()
+			negate = true
+		}
+		if _, err := s.expect(tokenNull, "'NULL'"); err != nil {
+			return nil, err
+		}
+		result = criteria.IsNull(criteria.Field(field))
+	default:
+		return nil, newSyntaxError(op.pos, fmt.Sprintf("expected operator after field %q but found %s", fieldToken.text, op))
+	}
+	if negate {
+		result = criteria.Not(result)
+	}
+	return result, nil
+}
+
+// parseValueList parses "(" value ( "," value )* ")"
+func (s *parseState) parseValueList(field string) ([]criteria.Expression, error) {
+	if _, err := s.expect(tokenLParen, "'('"); err != nil {
+		return nil, err
+	}
+	result := []criteria.Expression{}
+	for {
+		value, err := s.parseValue(field)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+		t := s.next()
+		if t.typ == tokenRParen {
+			return result, nil
+		}
+		if t.typ != tokenComma {
+			return nil, newSyntaxError(t.pos, fmt.Sprintf("expected ',' or ')' but found %s", t))
+		}
+	}
+}
+
+func (s *parseState) parseValue(field string) (criteria.Expression, error) {
+	t := s.next()
+	var value interface{}
+	switch t.typ {
+	case tokenString:
+		value = t.text
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			value = i
+		} else if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			value = f
+		} else {
+			return nil, newSyntaxError(t.pos, fmt.Sprintf("invalid number %s", t))
+		}
+	case tokenDate:
+		d, err := parseDate(t.text)
+		if err != nil {
+			return nil, newSyntaxError(t.pos, fmt.Sprintf("invalid date %s", t))
+		}
+		value = d
+	case tokenTrue:
+		value = true
+	case tokenFalse:
+		value = false
+	case tokenMe:
+		if s.parser.Me == nil {
+			return nil, newSyntaxError(t.pos, "'me' can only be used by an authenticated user")
+		}
+		value = s.parser.Me
+	case tokenIdent:
+		return nil, newSyntaxError(t.pos, fmt.Sprintf("expected value but found %s, strings must be quoted", t))
+	default:
+		return nil, newSyntaxError(t.pos, fmt.Sprintf("expected value but found %s", t))
+	}
+	if s.parser.ListFields[field] {
+		// list fields are compared by containment, so the value has to be a list, too
+		str, ok := value.(string)
+		if !ok {
+			str = fmt.Sprint(value)
+		}
+		value = []string{str}
+	}
+	return criteria.Literal(value), nil
+}
+
+func (s *parseState) fieldName(name string) string {
+	if alias, ok := s.parser.Aliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// parseDate accepts the formats matched by datePattern
+func parseDate(text string) (time.Time, error) {
+	if !strings.Contains(text, "T") {
+		return time.Parse("2006-01-02", text)
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02T15:04"} {
+		if d, err := time.Parse(layout, text); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %s", text)
+}
@@ -0,0 +1,108 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	c "github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/query"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmpty(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	exp, err := query.Parse(nil)
+	require.Nil(t, err)
+	assert.Equal(t, c.Literal(true), exp)
+	empty := "  "
+	exp, err = query.Parse(&empty)
+	require.Nil(t, err)
+	assert.Equal(t, c.Literal(true), exp)
+}
+
+func TestParseJSON(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	q := `{"system.title":"foo"}`
+	exp, err := query.Parse(&q)
+	require.Nil(t, err)
+	assert.Equal(t, c.Equals(c.Field("system.title"), c.Literal("foo")), exp)
+}
+
+func TestParseExpressions(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	p := query.Parser{
+		Aliases:    map[string]string{"state": "system.state", "assignee": "system.assignees"},
+		ListFields: map[string]bool{"system.assignees": true},
+		Me:         "me-id",
+	}
+	testData := map[string]c.Expression{
+		`state = "open"`:       c.Equals(c.Field("system.state"), c.Literal("open")),
+		`foo.bar = 'x'`:        c.Equals(c.Field("foo.bar"), c.Literal("x")),
+		`count = 5`:            c.Equals(c.Field("count"), c.Literal(int64(5))),
+		`estimate = 2.5`:       c.Equals(c.Field("estimate"), c.Literal(2.5)),
+		`done = TRUE`:          c.Equals(c.Field("done"), c.Literal(true)),
+		`created = 2017-01-31`: c.Equals(c.Field("created"), c.Literal(time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC))),
+		`assignee = me`:        c.Equals(c.Field("system.assignees"), c.Literal([]string{"me-id"})),
+		`a = 1 AND b = 2 OR c = 3`: c.Or(
+			c.And(c.Equals(c.Field("a"), c.Literal(int64(1))), c.Equals(c.Field("b"), c.Literal(int64(2)))),
+			c.Equals(c.Field("c"), c.Literal(int64(3)))),
+		`a = 1 and (b = 2 or c = 3)`: c.And(
+			c.Equals(c.Field("a"), c.Literal(int64(1))),
+			c.Or(c.Equals(c.Field("b"), c.Literal(int64(2))), c.Equals(c.Field("c"), c.Literal(int64(3))))),
+		`state in ("new", "open")`: c.In(c.Field("system.state"), c.Literal("new"), c.Literal("open")),
+		`state not in ("closed")`:  c.Not(c.In(c.Field("system.state"), c.Literal("closed"))),
+		`assignee in ("a", "b")`: c.Or(
+			c.Equals(c.Field("system.assignees"), c.Literal([]string{"a"})),
+			c.Equals(c.Field("system.assignees"), c.Literal([]string{"b"}))),
+		`state != "closed"`:       c.NotEquals(c.Field("system.state"), c.Literal("closed")),
+		`state <> "closed"`:       c.NotEquals(c.Field("system.state"), c.Literal("closed")),
+		`NOT state = "closed"`:    c.Not(c.Equals(c.Field("system.state"), c.Literal("closed"))),
+		`x < 1`:                   c.LessThan(c.Field("x"), c.Literal(int64(1))),
+		`x <= 1`:                  c.LessThanOrEqual(c.Field("x"), c.Literal(int64(1))),
+		`x > -1`:                  c.GreaterThan(c.Field("x"), c.Literal(int64(-1))),
+		`x >= 1`:                  c.GreaterThanOrEqual(c.Field("x"), c.Literal(int64(1))),
+		`title like "%foo%"`:      c.Like(c.Field("title"), c.Literal("%foo%")),
+		`title not ilike "%foo%"`: c.Not(c.ILike(c.Field("title"), c.Literal("%foo%"))),
+		`assignee is null`:        c.IsNull(c.Field("system.assignees")),
+		`assignee IS NOT NULL`:    c.Not(c.IsNull(c.Field("system.assignees"))),
+		`not (a = 1 or not b = 2)`: c.Not(c.Or(
+			c.Equals(c.Field("a"), c.Literal(int64(1))),
+			c.Not(c.Equals(c.Field("b"), c.Literal(int64(2)))))),
+	}
+	for q, expected := range testData {
+		exp, err := p.Parse(q)
+		require.Nil(t, err, q)
+		assert.Equal(t, expected, exp, q)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	testData := map[string]int{
+		`state =`:                 7,
+		`state = open`:            8,
+		`(state = "open"`:         15,
+		`state = "open" "closed"`: 15,
+		`= "open"`:                0,
+		`state "open"`:            6,
+		`state in ("a" "b")`:      14,
+		`assignee = me`:           11,
+		`a is not "x"`:            9,
+		`a not = "x"`:             6,
+		`a like 5`:                7,
+		`not`:                     3,
+	}
+	for q, pos := range testData {
+		_, err := query.Parse(&q)
+		require.NotNil(t, err, q)
+		syntaxErr, ok := err.(query.SyntaxError)
+		require.True(t, ok, q)
+		assert.Equal(t, pos, syntaxErr.Pos, q)
+	}
+}
@@ -0,0 +1,3 @@
+// Package reference contains the operations to manage the references to work items
+// ("#123") found in the descriptions of the work items and in the comments.
+package reference
@@ -0,0 +1,31 @@
+package reference
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Reference is a reference to a work item found in the description of a work item or in a comment
+type Reference struct {
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt time.Time
+	// the referenced work item
+	WorkItemID uint64
+	// the work item whose description or comment contains the reference
+	SourceWorkItemID uint64
+	// the comment which contains the reference, nil if the reference is in the description of the source work item
+	CommentID *uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (r Reference) TableName() string {
+	return "work_item_references"
+}
+
+// Source identifies the content which contains references: the description of a work item
+// or, if the comment ID is set, a comment of the work item
+type Source struct {
+	WorkItemID string
+	CommentID  *uuid.UUID
+}
@@ -0,0 +1,119 @@
+package reference
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+)
+
+// Repository encapsulates storage & retrieval of the references to work items
+type Repository interface {
+	// Update replaces the references of the given source with the ones found in the given content.
+	Update(ctx context.Context, source Source, content rendering.MarkupContent) error
+	// List retrieves the references to the given work item, oldest first.
+	List(ctx context.Context, workItemID string) ([]Reference, error)
+}
+
+// NewRepository creates a GormRepository
+func NewRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository implements Repository using gorm
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Update replaces the references of the given source with the references to the existing work
+// items found in the given content. References of a work item to itself are ignored, as are the
+// references of sources which are not work items.
+func (r *GormRepository) Update(ctx context.Context, source Source, content rendering.MarkupContent) error {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "update"}, time.Now())
+	sourceID, err := strconv.ParseUint(source.WorkItemID, 10, 64)
+	if err != nil {
+		// comments can have other parents than work items
+		return nil
+	}
+	db := r.db.Where("source_work_item_id = ?", sourceID)
+	if source.CommentID != nil {
+		db = db.Where("comment_id = ?", *source.CommentID)
+	} else {
+		db = db.Where("comment_id IS NULL")
+	}
+	if err := db.Delete(&Reference{}).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	ids := []uint64{sourceID}
+	for _, workItemID := range rendering.ExtractReferences(content.Content, content.Markup).WorkItemIDs {
+		if id, err := strconv.ParseUint(workItemID, 10, 64); err == nil && id != sourceID {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 1 {
+		return nil
+	}
+	var existing []uint64
+	if err := r.db.Table("work_items").Where("id IN (?)", ids).Pluck("id", &existing).Error; err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	sourceExists := false
+	for _, id := range existing {
+		if id == sourceID {
+			sourceExists = true
+		}
+	}
+	if !sourceExists {
+		return nil
+	}
+	for _, id := range existing {
+		if id == sourceID {
+			continue
+		}
+		reference := Reference{
+			WorkItemID:       id,
+			SourceWorkItemID: sourceID,
+			CommentID:        source.CommentID,
+		}
+		if err := r.db.Create(&reference).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"wiID":       id,
+				"sourceWIID": sourceID,
+				"err":        err,
+			}, "unable to create the reference")
+			return errors.NewInternalError(err.Error())
+		}
+	}
+	log.Debug(ctx, map[string]interface{}{
+		"sourceWIID": sourceID,
+		"count":      len(existing) - 1,
+	}, "References updated")
+	return nil
+}
+
+// List retrieves the references to the given work item, oldest first. The references of the
+// deleted work items and comments are left out.
+func (r *GormRepository) List(ctx context.Context, workItemID string) ([]Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "reference", "list"}, time.Now())
+	id, err := strconv.ParseUint(workItemID, 10, 64)
+	if err != nil {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, errors.NewNotFoundError("work item", workItemID)
+	}
+	result := []Reference{}
+	db := r.db.Select("work_item_references.*").
+		Joins("JOIN work_items ON work_items.id = work_item_references.source_work_item_id AND work_items.deleted_at IS NULL").
+		Joins("LEFT JOIN comments ON comments.id = work_item_references.comment_id").
+		Where("work_item_references.work_item_id = ?", id).
+		Where("work_item_references.comment_id IS NULL OR comments.deleted_at IS NULL").
+		Order("work_item_references.created_at asc")
+	if err := db.Find(&result).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
@@ -0,0 +1,177 @@
+package reference_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/reference"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunReferenceRepositoryBlackBoxTest(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &referenceRepositoryBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type referenceRepositoryBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repository         reference.Repository
+	workItemRepository workitem.WorkItemRepository
+	commentRepository  comment.Repository
+	clean              func()
+	testIdentity       account.Identity
+	ctx                context.Context
+}
+
+// SetupSuite overrides the DBTestSuite's function but calls it before doing anything else
+// The SetupSuite method will run before the tests in the suite are run.
+// It sets up a database connection for all the tests in this suite without polluting global space.
+func (s *referenceRepositoryBlackBoxTest) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+	s.ctx = context.Background()
+	// Make sure the database is populated with the correct types (e.g. bug etc.)
+	if _, c := os.LookupEnv(resource.Database); c != false {
+		if err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+			return migration.PopulateCommonTypes(s.ctx, tx, workitem.NewWorkItemTypeRepository(tx))
+		}); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func (s *referenceRepositoryBlackBoxTest) SetupTest() {
+	s.repository = reference.NewRepository(s.DB)
+	s.workItemRepository = workitem.NewWorkItemRepository(s.DB)
+	s.commentRepository = comment.NewRepository(s.DB)
+	s.clean = cleaner.DeleteCreatedEntities(s.DB)
+	testIdentity, err := testsupport.CreateTestIdentity(s.DB, "jdoe", "test")
+	require.Nil(s.T(), err)
+	s.testIdentity = testIdentity
+}
+
+func (s *referenceRepositoryBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+func (s *referenceRepositoryBlackBoxTest) createWorkItem(description string) *app.WorkItem {
+	fields := map[string]interface{}{
+		workitem.SystemTitle: "Title",
+		workitem.SystemState: workitem.SystemStateNew,
+	}
+	if description != "" {
+		fields[workitem.SystemDescription] = rendering.NewMarkupContent(description, rendering.SystemMarkupMarkdown)
+	}
+	wi, err := s.workItemRepository.Create(s.ctx, space.SystemSpace, workitem.SystemBug, fields, s.testIdentity.ID)
+	require.Nil(s.T(), err)
+	return wi
+}
+
+func (s *referenceRepositoryBlackBoxTest) TestListReferencesFromDescriptions() {
+	// given
+	target := s.createWorkItem("")
+	source1 := s.createWorkItem(fmt.Sprintf("depends on #%s and #%s", target.ID, target.ID))
+	source2 := s.createWorkItem(fmt.Sprintf("see #%s, but not `#%s`", target.ID, target.ID))
+	s.createWorkItem(fmt.Sprintf("see `#%s`", target.ID))
+	// when
+	references, err := s.repository.List(s.ctx, target.ID)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), references, 2)
+	assert.Equal(s.T(), source1.ID, fmt.Sprint(references[0].SourceWorkItemID))
+	assert.Nil(s.T(), references[0].CommentID)
+	assert.Equal(s.T(), source2.ID, fmt.Sprint(references[1].SourceWorkItemID))
+	assert.Nil(s.T(), references[1].CommentID)
+}
+
+func (s *referenceRepositoryBlackBoxTest) TestListReferencesFromComments() {
+	// given
+	target := s.createWorkItem("")
+	source := s.createWorkItem("")
+	c := &comment.Comment{
+		ParentID: source.ID,
+		Body:     fmt.Sprintf("duplicate of #%s", target.ID),
+		Markup:   rendering.SystemMarkupMarkdown,
+	}
+	err := s.commentRepository.Create(s.ctx, c, s.testIdentity.ID)
+	require.Nil(s.T(), err)
+	// when
+	references, err := s.repository.List(s.ctx, target.ID)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), references, 1)
+	assert.Equal(s.T(), source.ID, fmt.Sprint(references[0].SourceWorkItemID))
+	require.NotNil(s.T(), references[0].CommentID)
+	assert.Equal(s.T(), c.ID, *references[0].CommentID)
+}
+
+func (s *referenceRepositoryBlackBoxTest) TestUpdateReplacesReferences() {
+	// given
+	target1 := s.createWorkItem("")
+	target2 := s.createWorkItem("")
+	source := s.createWorkItem(fmt.Sprintf("see #%s", target1.ID))
+	// when
+	source.Fields[workitem.SystemDescription] = rendering.NewMarkupContent(fmt.Sprintf("see #%s", target2.ID), rendering.SystemMarkupMarkdown)
+	_, err := s.workItemRepository.Save(s.ctx, *source, s.testIdentity.ID)
+	require.Nil(s.T(), err)
+	// then
+	references, err := s.repository.List(s.ctx, target1.ID)
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), references)
+	references, err = s.repository.List(s.ctx, target2.ID)
+	require.Nil(s.T(), err)
+	assert.Len(s.T(), references, 1)
+}
+
+func (s *referenceRepositoryBlackBoxTest) TestSelfReferencesAreIgnored() {
+	// given
+	wi := s.createWorkItem("")
+	wi.Fields[workitem.SystemDescription] = rendering.NewMarkupContent(fmt.Sprintf("see #%s", wi.ID), rendering.SystemMarkupMarkdown)
+	_, err := s.workItemRepository.Save(s.ctx, *wi, s.testIdentity.ID)
+	require.Nil(s.T(), err)
+	// when
+	references, err := s.repository.List(s.ctx, wi.ID)
+	// then
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), references)
+}
+
+func (s *referenceRepositoryBlackBoxTest) TestReferencesFromDeletedWorkItemsAreIgnored() {
+	// given
+	target := s.createWorkItem("")
+	source := s.createWorkItem(fmt.Sprintf("see #%s", target.ID))
+	err := s.workItemRepository.Delete(s.ctx, source.ID, s.testIdentity.ID)
+	require.Nil(s.T(), err)
+	// when
+	references, err := s.repository.List(s.ctx, target.ID)
+	// then
+	require.Nil(s.T(), err)
+	assert.Empty(s.T(), references)
+}
+
+func (s *referenceRepositoryBlackBoxTest) TestListReferencesOfInvalidWorkItem() {
+	// when
+	_, err := s.repository.List(s.ctx, "foo")
+	// then
+	require.NotNil(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
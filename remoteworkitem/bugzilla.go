@@ -0,0 +1,240 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	mustRegisterProvider(Provider{
+		Type: ProviderBugzilla,
+		NewTracker: func(url, query string, since *time.Time) TrackerProvider {
+			return &BugzillaTracker{URL: url, Query: query, Since: since}
+		},
+		NewAttributeAccessor: NewBugzillaRemoteWorkItem,
+		Mapping: RemoteWorkItemMap{
+			AttributeMapper{AttributeExpression(BugzillaTitle), StringConverter{}}:                                              remoteTitle,
+			AttributeMapper{AttributeExpression(BugzillaDescription), MarkupConverter{markup: rendering.SystemMarkupPlainText}}: remoteDescription,
+			AttributeMapper{AttributeExpression(BugzillaID), StringConverter{}}:                                                 remoteItemID,
+			AttributeMapper{AttributeExpression(BugzillaCreatorLogin), StringConverter{}}:                                       remoteCreatorLogin,
+			AttributeMapper{AttributeExpression(BugzillaCreatorProfileURL), StringConverter{}}:                                  remoteCreatorProfileURL,
+			AttributeMapper{AttributeExpression(BugzillaAssigneeLogin), ListConverter{}}:                                        remoteAssigneeLogins,
+			AttributeMapper{AttributeExpression(BugzillaAssigneeProfileURL), ListConverter{}}:                                   remoteAssigneeProfileURLs,
+		},
+		State:          AttributeExpression(BugzillaState),
+		StateConverter: BugzillaStateConverter{},
+	})
+}
+
+// bugzillaPageSize is the number of bugs retrieved per request
+const bugzillaPageSize = 20
+
+// BugzillaTracker represents the Bugzilla tracker provider.
+// The query holds the parameters of a search with the Bugzilla REST API,
+// e.g. "product=Fedora&component=kernel&status=NEW"
+type BugzillaTracker struct {
+	URL   string
+	Query string
+	// Since is the high-water mark of the tracker query: only the bugs changed since then are fetched, if not nil
+	Since *time.Time
+}
+
+// bugzillaFetcher provides bug listing
+type bugzillaFetcher interface {
+	// listBugs returns the bugs matching the given query, starting at the given offset
+	listBugs(query string, offset int) ([]map[string]interface{}, error)
+	// getDescriptions returns the descriptions of the given bugs, i.e., the texts of their first comments, by bug ID
+	getDescriptions(bugIDs []string) (map[string]string, error)
+}
+
+// bugzillaBugFetcher fetch bugs from Bugzilla
+type bugzillaBugFetcher struct {
+	client    *http.Client
+	url       string
+	authToken string
+}
+
+func (f *bugzillaBugFetcher) get(path string, result interface{}) error {
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", strings.TrimSuffix(f.url, "/")+path, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		req.Header.Set("Accept", "application/json")
+		if f.authToken != "" {
+			req.Header.Set("X-BUGZILLA-API-KEY", f.authToken)
+		}
+		resp, err = f.client.Do(req)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		// send the request again after a while if it was rejected because of a rate limit
+		if !waitForRateLimit(resp, attempt) {
+			break
+		}
+		resp.Body.Close()
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected response from Bugzilla: %s", resp.Status)
+	}
+	decoder := json.NewDecoder(resp.Body)
+	// keep the bug IDs as they are instead of converting them to floats
+	decoder.UseNumber()
+	return errors.WithStack(decoder.Decode(result))
+}
+
+func (f *bugzillaBugFetcher) listBugs(query string, offset int) ([]map[string]interface{}, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	values.Set("limit", strconv.Itoa(bugzillaPageSize))
+	values.Set("offset", strconv.Itoa(offset))
+	var result struct {
+		Bugs []map[string]interface{} `json:"bugs"`
+	}
+	if err := f.get("/rest/bug?"+values.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return result.Bugs, nil
+}
+
+func (f *bugzillaBugFetcher) getDescriptions(bugIDs []string) (map[string]string, error) {
+	result := map[string]string{}
+	if len(bugIDs) == 0 {
+		return result, nil
+	}
+	// the comments of the other bugs are requested along with the ones of the first bug
+	path := "/rest/bug/" + url.QueryEscape(bugIDs[0]) + "/comment"
+	if len(bugIDs) > 1 {
+		path += "?" + url.Values{"ids": bugIDs[1:]}.Encode()
+	}
+	var comments struct {
+		Bugs map[string]struct {
+			Comments []struct {
+				Text string `json:"text"`
+			} `json:"comments"`
+		} `json:"bugs"`
+	}
+	if err := f.get(path, &comments); err != nil {
+		return nil, err
+	}
+	for bugID, bug := range comments.Bugs {
+		if len(bug.Comments) > 0 {
+			result[bugID] = bug.Comments[0].Text
+		}
+	}
+	return result, nil
+}
+
+// Fetch tracker items from Bugzilla
+func (b *BugzillaTracker) Fetch(authToken string) chan TrackerItemContent {
+	f := bugzillaBugFetcher{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		url:       b.URL,
+		authToken: authToken,
+	}
+	return b.fetch(&f)
+}
+
+func (b *BugzillaTracker) fetch(f bugzillaFetcher) chan TrackerItemContent {
+	item := make(chan TrackerItemContent)
+	go func() {
+		query := b.query()
+		offset := 0
+		for {
+			bugs, err := f.listBugs(query, offset)
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"query":  query,
+					"offset": offset,
+					"err":    err,
+				}, "failed to list Bugzilla bugs")
+				item <- TrackerItemContent{Err: err}
+				break
+			}
+			bugIDs := make([]string, len(bugs))
+			for i, bug := range bugs {
+				bugIDs[i] = fmt.Sprint(bug["id"])
+			}
+			descriptions, err := f.getDescriptions(bugIDs)
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"bugIDs": bugIDs,
+					"err":    err,
+				}, "failed to get the descriptions of Bugzilla bugs")
+				for _, bugID := range bugIDs {
+					item <- TrackerItemContent{ID: bugID, Err: err}
+				}
+			} else {
+				for i, bug := range bugs {
+					content, err := b.complete(bug, descriptions[bugIDs[i]])
+					if err != nil {
+						log.Error(nil, map[string]interface{}{
+							"bugID": bug["id"],
+							"err":   err,
+						}, "failed to complete Bugzilla bug")
+						item <- TrackerItemContent{ID: bugIDs[i], Err: err}
+						continue
+					}
+					id, _ := json.Marshal(bug[BugzillaID])
+					lastChangeTime, _ := bug["last_change_time"].(string)
+					updatedAt, _ := time.Parse(time.RFC3339, lastChangeTime)
+					item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: updatedAt}
+				}
+			}
+			if len(bugs) < bugzillaPageSize {
+				break
+			}
+			offset += len(bugs)
+		}
+		close(item)
+	}()
+	return item
+}
+
+// query returns the query of the tracker, restricted to the bugs changed since the high-water mark (if any)
+func (b *BugzillaTracker) query() string {
+	if b.Since == nil {
+		return b.Query
+	}
+	values := url.Values{}
+	values.Set("last_change_time", b.Since.UTC().Format(time.RFC3339))
+	if b.Query == "" {
+		return values.Encode()
+	}
+	return b.Query + "&" + values.Encode()
+}
+
+// complete adds the attributes which the Bugzilla REST API does not provide in the bug listing:
+// the URL of the bug ("self"), its given description and the URLs of its creator and assignee profiles
+func (b *BugzillaTracker) complete(bug map[string]interface{}, description string) ([]byte, error) {
+	bugID := fmt.Sprint(bug["id"])
+	baseURL := strings.TrimSuffix(b.URL, "/")
+	bug[BugzillaID] = baseURL + "/show_bug.cgi?id=" + bugID
+	bug[BugzillaDescription] = description
+	for _, key := range []string{"creator_detail", "assigned_to_detail"} {
+		if detail, ok := bug[key].(map[string]interface{}); ok {
+			if name, ok := detail["name"].(string); ok {
+				detail["url"] = baseURL + "/user_profile?login=" + url.QueryEscape(name)
+			}
+		}
+	}
+	content, err := json.Marshal(bug)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return content, nil
+}
@@ -0,0 +1,121 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBugzillaBugFetcher struct {
+	bugs int
+	// descriptionRequests is the number of calls to getDescriptions
+	descriptionRequests int
+}
+
+func (f *fakeBugzillaBugFetcher) listBugs(query string, offset int) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	for i := offset; i < f.bugs && len(result) < bugzillaPageSize; i++ {
+		result = append(result, map[string]interface{}{
+			"id":             json.Number(strconv.Itoa(i + 1)),
+			"creator_detail": map[string]interface{}{"name": "jdoe@example.com"},
+		})
+	}
+	return result, nil
+}
+
+func (f *fakeBugzillaBugFetcher) getDescriptions(bugIDs []string) (map[string]string, error) {
+	f.descriptionRequests++
+	result := map[string]string{}
+	for _, bugID := range bugIDs {
+		result[bugID] = "description of " + bugID
+	}
+	return result, nil
+}
+
+func TestBugzillaFetch(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	f := fakeBugzillaBugFetcher{bugs: 1}
+	b := BugzillaTracker{URL: "https://bugzilla.example.com/", Query: ""}
+	// when
+	i := <-b.fetch(&f)
+	// then
+	assert.Equal(t, `"https://bugzilla.example.com/show_bug.cgi?id=1"`, i.ID)
+	var bug map[string]interface{}
+	require.Nil(t, json.Unmarshal(i.Content, &bug))
+	assert.Equal(t, "https://bugzilla.example.com/show_bug.cgi?id=1", bug["self"])
+	assert.Equal(t, "description of 1", bug["description"])
+	assert.Equal(t, map[string]interface{}{
+		"name": "jdoe@example.com",
+		"url":  "https://bugzilla.example.com/user_profile?login=jdoe%40example.com",
+	}, bug["creator_detail"])
+}
+
+func TestBugzillaFetchWithPages(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	f := fakeBugzillaBugFetcher{bugs: bugzillaPageSize + 1}
+	b := BugzillaTracker{URL: "https://bugzilla.example.com", Query: ""}
+	// when
+	count := 0
+	for range b.fetch(&f) {
+		count++
+	}
+	// then
+	assert.Equal(t, bugzillaPageSize+1, count)
+	// the descriptions are requested once per page
+	assert.Equal(t, 2, f.descriptionRequests)
+}
+
+func TestBugzillaQuery(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	since := time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC)
+	// then
+	assert.Equal(t, "product=Fedora", (&BugzillaTracker{Query: "product=Fedora"}).query())
+	assert.Equal(t, "product=Fedora&last_change_time=2017-04-12T09%3A21%3A02Z", (&BugzillaTracker{Query: "product=Fedora", Since: &since}).query())
+	assert.Equal(t, "last_change_time=2017-04-12T09%3A21%3A02Z", (&BugzillaTracker{Since: &since}).query())
+}
+
+func TestBugzillaFetchWithRecording(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	r, err := recorder.New("../test/data/bugzilla_fetch_test")
+	require.Nil(t, err)
+	defer r.Stop()
+	f := bugzillaBugFetcher{
+		client: &http.Client{
+			Timeout:   1 * time.Second,
+			Transport: r.Transport,
+		},
+		url: "https://bugzilla.redhat.com",
+	}
+	b := &BugzillaTracker{URL: "https://bugzilla.redhat.com", Query: "product=Fedora&component=almighty-test"}
+	// when
+	fetch := b.fetch(&f)
+	// then
+	var trackerItemContents []TrackerItemContent
+	for trackerItemContent := range fetch {
+		trackerItemContents = append(trackerItemContents, trackerItemContent)
+	}
+	require.Len(t, trackerItemContents, 2)
+	assert.Equal(t, `"https://bugzilla.redhat.com/show_bug.cgi?id=1441249"`, trackerItemContents[0].ID)
+	assert.Contains(t, string(trackerItemContents[0].Content), `"description":"Description of problem:\nsample desc\n"`)
+	assert.Contains(t, string(trackerItemContents[0].Content), `"id":1441249`)
+	assert.Equal(t, `"https://bugzilla.redhat.com/show_bug.cgi?id=1441250"`, trackerItemContents[1].ID)
+	assert.Contains(t, string(trackerItemContents[1].Content), `"description":"another desc"`)
+	// the fetched bugs can be mapped to work items
+	bug, err := NewBugzillaRemoteWorkItem(TrackerItem{Item: string(trackerItemContents[0].Content)})
+	require.Nil(t, err)
+	result, err := Map(bug, lookupWorkItemMap(t, ProviderBugzilla))
+	require.Nil(t, err)
+	assert.Equal(t, "https://bugzilla.redhat.com/show_bug.cgi?id=1441249", result.Fields[remoteItemID])
+	assert.Equal(t, []string{"kernel-maint@example.com"}, result.Fields[remoteAssigneeLogins])
+}
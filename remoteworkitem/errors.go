@@ -31,6 +31,11 @@ func (err BadParameterError) Error() string {
 	return fmt.Sprintf("Bad value for parameter '%s': '%v'", err.parameter, err.value)
 }
 
+// UnauthorizedError means that the request could not be authenticated
+type UnauthorizedError struct {
+	simpleError
+}
+
 // ConversionError error means something went wrong converting between different representations
 type ConversionError struct {
 	simpleError
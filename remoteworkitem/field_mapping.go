@@ -0,0 +1,161 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+
+	errs "github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// The converters available for the field mappings of the tracker queries
+const (
+	// FieldConverterValue keeps the remote value as is
+	FieldConverterValue = "value"
+	// FieldConverterList converts the remote value into a list containing this single value
+	FieldConverterList = "list"
+	// FieldConverterPatternList joins the remote values matching the expression into a list,
+	// the "?" of the expression matching the indexes of a remote list
+	FieldConverterPatternList = "pattern-list"
+	// FieldConverterDate parses the remote date
+	FieldConverterDate = "date"
+	// FieldConverterEnum maps the remote values to the values of an enumeration
+	FieldConverterEnum = "enum"
+)
+
+// FieldMapping maps a remote attribute to a work item field
+type FieldMapping struct {
+	// Expression is the key of the remote attribute in the flattened remote item (e.g. "fields.customfield_10002")
+	Expression string `json:"expression"`
+	// Field is the name of the work item field
+	Field string `json:"field"`
+	// Converter is the name of the converter of the remote value
+	Converter string `json:"converter"`
+	// Values maps the remote values to the field values, for the "enum" converter
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// FieldMappings is the list of field mappings of a tracker query
+type FieldMappings []FieldMapping
+
+// Value implements the driver.Valuer interface
+func (m FieldMappings) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements the sql.Scanner interface
+func (m *FieldMappings) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.Errorf("scan source was not []byte: %T", src)
+	}
+	return json.Unmarshal(b, m)
+}
+
+// fieldMappingConverter applies the converter of a field mapping. It is always used through a pointer, so that
+// each field mapping has its own entry in a RemoteWorkItemMap, even if the provider or another field mapping
+// uses the same expression and the same converter.
+type fieldMappingConverter struct {
+	field     string
+	converter AttributeConverter
+}
+
+// Convert converts the given value with the converter of the field mapping
+func (c *fieldMappingConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	return c.converter.Convert(value, item)
+}
+
+// attributeMapper returns the mapper of the remote attribute of the field mapping
+// returns BadParameterError if the field mapping is invalid
+func (m FieldMapping) attributeMapper() (AttributeMapper, error) {
+	if m.Expression == "" {
+		return AttributeMapper{}, BadParameterError{parameter: "expression", value: m.Expression}
+	}
+	if m.Field == "" {
+		return AttributeMapper{}, BadParameterError{parameter: "field", value: m.Field}
+	}
+	var converter AttributeConverter
+	switch m.Converter {
+	case FieldConverterValue:
+		converter = StringConverter{}
+	case FieldConverterList:
+		converter = ListConverter{}
+	case FieldConverterPatternList:
+		if !strings.Contains(m.Expression, "?") {
+			return AttributeMapper{}, BadParameterError{parameter: "expression", value: m.Expression}
+		}
+		converter = PatternToListConverter{pattern: m.Expression}
+	case FieldConverterDate:
+		converter = DateConverter{}
+	case FieldConverterEnum:
+		if len(m.Values) == 0 {
+			return AttributeMapper{}, BadParameterError{parameter: "values", value: m.Values}
+		}
+		converter = EnumConverter{values: m.Values}
+	default:
+		return AttributeMapper{}, BadParameterError{parameter: "converter", value: m.Converter}
+	}
+	return AttributeMapper{AttributeExpression(m.Expression), &fieldMappingConverter{field: m.Field, converter: converter}}, nil
+}
+
+// workItemMap returns the given mapping of a provider, where the fields targeted by the field mappings
+// are mapped according to these field mappings instead
+// returns BadParameterError if a field mapping is invalid
+func (mappings FieldMappings) workItemMap(providerMap RemoteWorkItemMap) (RemoteWorkItemMap, error) {
+	if len(mappings) == 0 {
+		return providerMap, nil
+	}
+	overridden := map[string]bool{}
+	for _, m := range mappings {
+		overridden[m.Field] = true
+	}
+	result := RemoteWorkItemMap{}
+	for mapper, field := range providerMap {
+		if !overridden[field] {
+			result[mapper] = field
+		}
+	}
+	for _, m := range mappings {
+		mapper, err := m.attributeMapper()
+		if err != nil {
+			return nil, err
+		}
+		result[mapper] = m.Field
+	}
+	return result, nil
+}
+
+// checkFieldMappings verifies that the given field mappings are valid and that they target
+// existing fields of the given work item type
+// returns BadParameterError or InternalError
+func checkFieldMappings(ctx context.Context, db *gorm.DB, workItemType uuid.UUID, mappings FieldMappings) error {
+	wit, err := workitem.NewWorkItemTypeRepository(db).LoadTypeFromDB(ctx, workItemType)
+	if err != nil {
+		cause := errors.Cause(err)
+		if _, ok := cause.(errs.NotFoundError); ok {
+			return BadParameterError{parameter: "workItemTypeID", value: workItemType}
+		}
+		return InternalError{simpleError{err.Error()}}
+	}
+	for _, m := range mappings {
+		if _, err := m.attributeMapper(); err != nil {
+			return err
+		}
+		if _, ok := wit.Fields[m.Field]; !ok {
+			return BadParameterError{parameter: "field", value: m.Field}
+		}
+	}
+	return nil
+}
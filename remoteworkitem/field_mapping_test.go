@@ -0,0 +1,66 @@
+package remoteworkitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldMappingAttributeMapper(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	for _, m := range []FieldMapping{
+		{Expression: "fields.customfield_10002", Field: "storypoints", Converter: FieldConverterValue},
+		{Expression: "fields.components.?.name", Field: "components", Converter: FieldConverterPatternList},
+		{Expression: "fields.duedate", Field: "duedate", Converter: FieldConverterDate},
+		{Expression: "fields.priority.name", Field: "priority", Converter: FieldConverterEnum, Values: map[string]string{"Major": "P2"}},
+	} {
+		_, err := m.attributeMapper()
+		assert.Nil(t, err, m.Converter)
+	}
+	for _, m := range []FieldMapping{
+		{Expression: "", Field: "storypoints", Converter: FieldConverterValue},
+		{Expression: "fields.customfield_10002", Field: "", Converter: FieldConverterValue},
+		{Expression: "fields.customfield_10002", Field: "storypoints", Converter: "number"},
+		{Expression: "fields.components", Field: "components", Converter: FieldConverterPatternList},
+		{Expression: "fields.priority.name", Field: "priority", Converter: FieldConverterEnum},
+	} {
+		_, err := m.attributeMapper()
+		assert.IsType(t, BadParameterError{}, err, m)
+	}
+}
+
+func TestFieldMappingsWorkItemMap(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	mappings := FieldMappings{
+		{Expression: "fields.customfield_10002", Field: "storypoints", Converter: FieldConverterValue},
+		{Expression: "fields.components.?.name", Field: "components", Converter: FieldConverterPatternList},
+		{Expression: JiraState, Field: workitem.SystemState, Converter: FieldConverterEnum, Values: map[string]string{"Done": workitem.SystemStateClosed}},
+		// same expression and converter as the title mapping of the provider
+		{Expression: JiraTitle, Field: "headline", Converter: FieldConverterValue},
+	}
+	workItem := TestWorkItem{
+		content: map[string]interface{}{
+			JiraTitle:                  "title",
+			JiraState:                  "Done",
+			"fields.customfield_10002": float64(5),
+			"fields.components.0.name": "UI",
+			"fields.components.1.name": "Server",
+		},
+	}
+	workItemMap, err := mappings.workItemMap(lookupWorkItemMap(t, ProviderJira))
+	require.Nil(t, err)
+	// when
+	result, err := Map(workItem, workItemMap)
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "title", result.Fields[workitem.SystemTitle])
+	assert.Equal(t, "title", result.Fields["headline"])
+	assert.Equal(t, workitem.SystemStateClosed, result.Fields[workitem.SystemState])
+	assert.Equal(t, float64(5), result.Fields["storypoints"])
+	assert.Equal(t, []string{"UI", "Server"}, result.Fields["components"])
+}
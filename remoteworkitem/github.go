@@ -1,14 +1,49 @@
 package remoteworkitem
 
 import (
+	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/workitem"
 
 	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
+func init() {
+	mustRegisterProvider(Provider{
+		Type: ProviderGithub,
+		NewTracker: func(url, query string, since *time.Time) TrackerProvider {
+			return &GithubTracker{URL: url, Query: query, Since: since}
+		},
+		NewAttributeAccessor: NewGitHubRemoteWorkItem,
+		Mapping: RemoteWorkItemMap{
+			AttributeMapper{AttributeExpression(GithubTitle), StringConverter{}}:                                                               remoteTitle,
+			AttributeMapper{AttributeExpression(GithubDescription), MarkupConverter{markup: rendering.SystemMarkupMarkdown}}:                   remoteDescription,
+			AttributeMapper{AttributeExpression(GithubID), StringConverter{}}:                                                                  remoteItemID,
+			AttributeMapper{AttributeExpression(GithubCreatorLogin), StringConverter{}}:                                                        remoteCreatorLogin,
+			AttributeMapper{AttributeExpression(GithubCreatorProfileURL), StringConverter{}}:                                                   remoteCreatorProfileURL,
+			AttributeMapper{AttributeExpression(GithubAssigneesLogin), PatternToListConverter{pattern: GithubAssigneesLoginPattern}}:           remoteAssigneeLogins,
+			AttributeMapper{AttributeExpression(GithubAssigneesProfileURL), PatternToListConverter{pattern: GithubAssigneesProfileURLPattern}}: remoteAssigneeProfileURLs,
+		},
+		State:          AttributeExpression(GithubState),
+		StateConverter: GithubStateConverter{},
+		NewPusher: func(url, authToken string) TrackerPusher {
+			return &GithubPusher{editor: &githubIssueEditor{client: newGithubClient(authToken)}}
+		},
+		Webhook: githubWebhook{},
+	})
+}
+
 // githubFetcher provides issue listing
 type githubFetcher interface {
 	listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
@@ -18,6 +53,8 @@ type githubFetcher interface {
 type GithubTracker struct {
 	URL   string
 	Query string
+	// Since is the high-water mark of the tracker query: only the issues updated since then are fetched, if not nil
+	Since *time.Time
 }
 
 // GithubIssueFetcher fetch issues from github
@@ -32,44 +69,222 @@ func (f *githubIssueFetcher) listIssues(query string, opts *github.SearchOptions
 
 // Fetch tracker items from Github
 func (g *GithubTracker) Fetch(githubAuthToken string) chan TrackerItemContent {
-	f := githubIssueFetcher{}
+	f := githubIssueFetcher{client: newGithubClient(githubAuthToken)}
+	return g.fetch(&f)
+}
+
+func newGithubClient(authToken string) *github.Client {
 	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: githubAuthToken},
+		&oauth2.Token{AccessToken: authToken},
 	)
 	tc := oauth2.NewClient(oauth2.NoContext, ts)
-	f.client = github.NewClient(tc)
-	return g.fetch(&f)
+	return github.NewClient(tc)
 }
 
 func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
 	go func() {
+		query := g.Query
 		opts := &github.SearchOptions{
 			ListOptions: github.ListOptions{
 				PerPage: 20,
 			},
 		}
+		if g.Since != nil {
+			// only fetch the issues updated since the last run, the oldest first so that
+			// an interrupted fetch can be resumed from the last update seen
+			query = fmt.Sprintf("%s updated:>=%s", query, g.Since.UTC().Format("2006-01-02T15:04:05Z"))
+			opts.Sort = "updated"
+			opts.Order = "asc"
+		}
+		attempt := 0
 		for {
-			result, response, err := f.listIssues(g.Query, opts)
-			if _, ok := err.(*github.RateLimitError); ok {
-				log.Warn(nil, map[string]interface{}{
-					"query": g.Query,
-					"opts":  opts,
-				}, "reached rate limit when listing Github issues")
+			result, response, err := f.listIssues(query, opts)
+			if err != nil {
+				if response != nil && waitForRateLimit(response.Response, attempt) {
+					attempt++
+					continue
+				}
+				if _, ok := err.(*github.RateLimitError); ok {
+					log.Warn(nil, map[string]interface{}{
+						"query": query,
+						"opts":  opts,
+					}, "reached rate limit when listing Github issues")
+				} else {
+					log.Error(nil, map[string]interface{}{
+						"query": query,
+						"opts":  opts,
+						"err":   err,
+					}, "failed to list Github issues")
+				}
+				item <- TrackerItemContent{Err: err}
 				break
 			}
+			attempt = 0
 			issues := result.Issues
 			for _, l := range issues {
 				id, _ := json.Marshal(l.URL)
 				content, _ := json.Marshal(l)
-				item <- TrackerItemContent{ID: string(id), Content: content}
+				var updatedAt time.Time
+				if l.UpdatedAt != nil {
+					updatedAt = *l.UpdatedAt
+				}
+				item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: updatedAt}
 			}
 			if response.NextPage == 0 {
 				break
 			}
+			if !waitForRemainingRequests(response.Response) {
+				log.Warn(nil, map[string]interface{}{
+					"query": query,
+					"opts":  opts,
+				}, "reached rate limit when listing Github issues")
+				item <- TrackerItemContent{Err: errors.New("reached rate limit when listing Github issues")}
+				break
+			}
 			opts.ListOptions.Page = response.NextPage
 		}
 		close(item)
 	}()
 	return item
 }
+
+// githubEditor provides issue edition
+type githubEditor interface {
+	editIssue(owner, repo string, number int, issue *github.IssueRequest) error
+	createComment(owner, repo string, number int, comment *github.IssueComment) error
+}
+
+// githubIssueEditor edits issues on github
+type githubIssueEditor struct {
+	client *github.Client
+}
+
+func (e *githubIssueEditor) editIssue(owner, repo string, number int, issue *github.IssueRequest) error {
+	_, _, err := e.client.Issues.Edit(owner, repo, number, issue)
+	return errors.WithStack(err)
+}
+
+func (e *githubIssueEditor) createComment(owner, repo string, number int, comment *github.IssueComment) error {
+	_, _, err := e.client.Issues.CreateComment(owner, repo, number, comment)
+	return errors.WithStack(err)
+}
+
+// GithubPusher pushes the local changes to Github
+type GithubPusher struct {
+	editor githubEditor
+}
+
+// githubIssueURLPattern matches the API URL of an issue, which is the ID of the remote item
+var githubIssueURLPattern = regexp.MustCompile(`/repos/([^/]+)/([^/]+)/issues/(\d+)$`)
+
+// parseGithubIssueURL returns the owner, the repository and the number of the issue with the given API URL
+func parseGithubIssueURL(issueURL string) (string, string, int, error) {
+	m := githubIssueURLPattern.FindStringSubmatch(issueURL)
+	if m == nil {
+		return "", "", 0, BadParameterError{parameter: "remote item ID", value: issueURL}
+	}
+	number, err := strconv.Atoi(m[3])
+	if err != nil {
+		return "", "", 0, BadParameterError{parameter: "remote item ID", value: issueURL}
+	}
+	return m[1], m[2], number, nil
+}
+
+// UpdateItem updates the title, state and assignees of the Github issue
+func (g *GithubPusher) UpdateItem(remoteItemID string, changes RemoteChanges) error {
+	owner, repo, number, err := parseGithubIssueURL(remoteItemID)
+	if err != nil {
+		return err
+	}
+	issue := github.IssueRequest{
+		Title:     changes.Title,
+		Assignees: changes.Assignees,
+	}
+	if changes.State != nil {
+		// Github issues are either open or closed
+		state := "open"
+		if *changes.State == workitem.SystemStateClosed || *changes.State == workitem.SystemStateResolved {
+			state = "closed"
+		}
+		issue.State = &state
+	}
+	return g.editor.editIssue(owner, repo, number, &issue)
+}
+
+// AddComment adds a comment on the Github issue
+func (g *GithubPusher) AddComment(remoteItemID string, body string) error {
+	owner, repo, number, err := parseGithubIssueURL(remoteItemID)
+	if err != nil {
+		return err
+	}
+	return g.editor.createComment(owner, repo, number, &github.IssueComment{Body: &body})
+}
+
+// githubWebhook receives the "issues" and "issue_comment" events of the Github webhooks
+type githubWebhook struct{}
+
+// Verify checks the HMAC signature of the body, sent in the 'X-Hub-Signature-256' header,
+// or in the 'X-Hub-Signature' header by the Github versions which don't support SHA-256
+func (githubWebhook) Verify(req *http.Request, body []byte, secret string) bool {
+	if signature := req.Header.Get("X-Hub-Signature-256"); signature != "" {
+		return checkHMAC(sha256.New, "sha256=", signature, body, secret)
+	}
+	return checkHMAC(sha1.New, "sha1=", req.Header.Get("X-Hub-Signature"), body, secret)
+}
+
+// Parse decodes the issue of the event, along with the created or edited comment of an "issue_comment" event.
+// The other events, and the events about pull requests, are ignored.
+func (githubWebhook) Parse(req *http.Request, body []byte) (*WebhookEvent, error) {
+	eventType := req.Header.Get("X-GitHub-Event")
+	if eventType != "issues" && eventType != "issue_comment" {
+		return nil, nil
+	}
+	var payload struct {
+		Action  string          `json:"action"`
+		Issue   json.RawMessage `json:"issue"`
+		Comment *struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+				URL   string `json:"url"`
+			} `json:"user"`
+		} `json:"comment"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid Github event")
+	}
+	var issue struct {
+		URL         string          `json:"url"`
+		UpdatedAt   *time.Time      `json:"updated_at"`
+		PullRequest json.RawMessage `json:"pull_request"`
+	}
+	if len(payload.Issue) == 0 {
+		return nil, errors.Errorf("the Github '%s' event has no issue", eventType)
+	}
+	if err := json.Unmarshal(payload.Issue, &issue); err != nil {
+		return nil, errors.Wrap(err, "invalid Github issue")
+	}
+	if issue.URL == "" {
+		return nil, errors.New("the Github issue has no URL")
+	}
+	if len(issue.PullRequest) > 0 {
+		return nil, nil
+	}
+	id, _ := json.Marshal(issue.URL)
+	event := WebhookEvent{Item: TrackerItemContent{ID: string(id), Content: payload.Issue}}
+	if issue.UpdatedAt != nil {
+		event.Item.UpdatedAt = *issue.UpdatedAt
+	}
+	if eventType == "issue_comment" && payload.Comment != nil && payload.Action != "deleted" {
+		event.Comment = &RemoteComment{
+			ID:                strconv.FormatInt(payload.Comment.ID, 10),
+			Body:              payload.Comment.Body,
+			Markup:            rendering.SystemMarkupMarkdown,
+			CreatorLogin:      payload.Comment.User.Login,
+			CreatorProfileURL: payload.Comment.User.URL,
+		}
+	}
+	return &event, nil
+}
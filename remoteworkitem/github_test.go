@@ -1,6 +1,10 @@
 package remoteworkitem
 
 import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"testing"
 	"time"
@@ -63,6 +67,33 @@ func TestGithubFetchWithRateLimit(t *testing.T) {
 	fetch := g.fetch(&f)
 	// then
 	assert.Equal(t, 0, len(fetch))
+	i := <-fetch
+	assert.IsType(t, &github.RateLimitError{}, i.Err)
+}
+
+type fakeGithubIssueFetcherWithQuery struct {
+	query string
+	opts  github.SearchOptions
+}
+
+func (f *fakeGithubIssueFetcherWithQuery) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	f.query, f.opts = query, *opts
+	return &github.IssuesSearchResult{}, &github.Response{}, nil
+}
+
+func TestGithubFetchSince(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	f := fakeGithubIssueFetcherWithQuery{}
+	since := time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC)
+	g := GithubTracker{URL: "", Query: "is:open is:issue user:almighty-test", Since: &since}
+	// when
+	for range g.fetch(&f) {
+	}
+	// then
+	assert.Equal(t, "is:open is:issue user:almighty-test updated:>=2017-04-12T09:21:02Z", f.query)
+	assert.Equal(t, "updated", f.opts.Sort)
+	assert.Equal(t, "asc", f.opts.Order)
 }
 
 func TestGithubFetchWithRecording(t *testing.T) {
@@ -88,3 +119,150 @@ func TestGithubFetchWithRecording(t *testing.T) {
 	assert.Contains(t, string(i2.Content), `"html_url":"https://github.com/almighty-test/almighty-test-unit/issues/1"`)
 	assert.Contains(t, string(i2.Content), `"body":"sample desc\n"`)
 }
+
+type fakeGithubIssueEditor struct {
+	owner   string
+	repo    string
+	number  int
+	issue   *github.IssueRequest
+	comment *github.IssueComment
+}
+
+func (e *fakeGithubIssueEditor) editIssue(owner, repo string, number int, issue *github.IssueRequest) error {
+	e.owner, e.repo, e.number, e.issue = owner, repo, number, issue
+	return nil
+}
+
+func (e *fakeGithubIssueEditor) createComment(owner, repo string, number int, comment *github.IssueComment) error {
+	e.owner, e.repo, e.number, e.comment = owner, repo, number, comment
+	return nil
+}
+
+func TestGithubPusherUpdateItem(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	e := fakeGithubIssueEditor{}
+	p := GithubPusher{editor: &e}
+	title := "new title"
+	state := "resolved"
+	assignees := []string{"alice"}
+	// when
+	err := p.UpdateItem("https://api.github.com/repos/almighty-test/almighty-test-unit/issues/3", RemoteChanges{Title: &title, State: &state, Assignees: &assignees})
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "almighty-test", e.owner)
+	assert.Equal(t, "almighty-test-unit", e.repo)
+	assert.Equal(t, 3, e.number)
+	require.NotNil(t, e.issue)
+	assert.Equal(t, "new title", *e.issue.Title)
+	assert.Equal(t, "closed", *e.issue.State)
+	assert.Equal(t, []string{"alice"}, *e.issue.Assignees)
+}
+
+func TestGithubPusherAddComment(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	e := fakeGithubIssueEditor{}
+	p := GithubPusher{editor: &e}
+	// when
+	err := p.AddComment("https://api.github.com/repos/almighty-test/almighty-test-unit/issues/3", "a comment")
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, 3, e.number)
+	require.NotNil(t, e.comment)
+	assert.Equal(t, "a comment", *e.comment.Body)
+}
+
+func TestGithubPusherInvalidRemoteItemID(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	p := GithubPusher{editor: &fakeGithubIssueEditor{}}
+	// when
+	err := p.AddComment("https://github.com/almighty-test/almighty-test-unit", "a comment")
+	// then
+	assert.IsType(t, BadParameterError{}, err)
+}
+
+const githubIssueCommentEvent = `{
+	"action": "created",
+	"issue": {
+		"url": "https://api.github.com/repos/almighty-test/almighty-test-unit/issues/3",
+		"title": "a webhook issue",
+		"state": "open",
+		"body": "body of the issue",
+		"updated_at": "2017-04-12T09:21:02Z",
+		"user": {"login": "jdoe", "url": "https://api.github.com/users/jdoe"},
+		"assignees": []
+	},
+	"comment": {
+		"id": 42,
+		"body": "a remote comment",
+		"user": {"login": "jdoe", "url": "https://api.github.com/users/jdoe"}
+	}
+}`
+
+// githubSignature returns the value of the 'X-Hub-Signature-256' header of the given body
+func githubSignature(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGithubWebhookVerify(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	body := []byte(githubIssueCommentEvent)
+	req, err := http.NewRequest("POST", "/api/trackers/1/webhook", nil)
+	require.Nil(t, err)
+	// no signature
+	assert.False(t, githubWebhook{}.Verify(req, body, "secret"))
+	// valid signature
+	req.Header.Set("X-Hub-Signature-256", githubSignature(body, "secret"))
+	assert.True(t, githubWebhook{}.Verify(req, body, "secret"))
+	// other secret
+	assert.False(t, githubWebhook{}.Verify(req, body, "other"))
+	// modified body
+	assert.False(t, githubWebhook{}.Verify(req, []byte(`{}`), "secret"))
+	// SHA-1 signature
+	req.Header.Del("X-Hub-Signature-256")
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write(body)
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+	assert.True(t, githubWebhook{}.Verify(req, body, "secret"))
+}
+
+func TestGithubWebhookParse(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	req, err := http.NewRequest("POST", "/api/trackers/1/webhook", nil)
+	require.Nil(t, err)
+	// given
+	req.Header.Set("X-GitHub-Event", "issue_comment")
+	// when
+	event, err := githubWebhook{}.Parse(req, []byte(githubIssueCommentEvent))
+	// then
+	require.Nil(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, `"https://api.github.com/repos/almighty-test/almighty-test-unit/issues/3"`, event.Item.ID)
+	assert.Equal(t, time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC), event.Item.UpdatedAt.UTC())
+	remoteItem, err := NewGitHubRemoteWorkItem(TrackerItem{Item: string(event.Item.Content)})
+	require.Nil(t, err)
+	assert.Equal(t, "a webhook issue", remoteItem.Get(GithubTitle))
+	require.NotNil(t, event.Comment)
+	assert.Equal(t, "42", event.Comment.ID)
+	assert.Equal(t, "a remote comment", event.Comment.Body)
+	assert.Equal(t, "jdoe", event.Comment.CreatorLogin)
+	// the issue events don't have comments
+	req.Header.Set("X-GitHub-Event", "issues")
+	event, err = githubWebhook{}.Parse(req, []byte(githubIssueCommentEvent))
+	require.Nil(t, err)
+	require.NotNil(t, event)
+	assert.Nil(t, event.Comment)
+	// the other events are ignored
+	req.Header.Set("X-GitHub-Event", "ping")
+	event, err = githubWebhook{}.Parse(req, []byte(`{"zen": "Keep it logically awesome."}`))
+	require.Nil(t, err)
+	assert.Nil(t, event)
+	// invalid event
+	req.Header.Set("X-GitHub-Event", "issues")
+	_, err = githubWebhook{}.Parse(req, []byte(`{"action": "opened"}`))
+	assert.NotNil(t, err)
+}
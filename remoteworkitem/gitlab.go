@@ -0,0 +1,178 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	mustRegisterProvider(Provider{
+		Type: ProviderGitlab,
+		NewTracker: func(url, query string, since *time.Time) TrackerProvider {
+			return &GitlabTracker{URL: url, Query: query, Since: since}
+		},
+		NewAttributeAccessor: NewGitlabRemoteWorkItem,
+		Mapping: RemoteWorkItemMap{
+			AttributeMapper{AttributeExpression(GitlabTitle), StringConverter{}}:                                                               remoteTitle,
+			AttributeMapper{AttributeExpression(GitlabDescription), MarkupConverter{markup: rendering.SystemMarkupMarkdown}}:                   remoteDescription,
+			AttributeMapper{AttributeExpression(GitlabID), StringConverter{}}:                                                                  remoteItemID,
+			AttributeMapper{AttributeExpression(GitlabCreatorLogin), StringConverter{}}:                                                        remoteCreatorLogin,
+			AttributeMapper{AttributeExpression(GitlabCreatorProfileURL), StringConverter{}}:                                                   remoteCreatorProfileURL,
+			AttributeMapper{AttributeExpression(GitlabAssigneesLogin), PatternToListConverter{pattern: GitlabAssigneesLoginPattern}}:           remoteAssigneeLogins,
+			AttributeMapper{AttributeExpression(GitlabAssigneesProfileURL), PatternToListConverter{pattern: GitlabAssigneesProfileURLPattern}}: remoteAssigneeProfileURLs,
+		},
+		State:          AttributeExpression(GitlabState),
+		StateConverter: GitlabStateConverter{},
+	})
+}
+
+// gitlabPageSize is the number of issues retrieved per request
+const gitlabPageSize = 20
+
+// GitlabTracker represents the GitLab tracker provider.
+// The query is the path of a project followed by the optional parameters of the issue listing,
+// e.g. "almighty-test/almighty-test-unit?state=opened&labels=bug"
+type GitlabTracker struct {
+	URL   string
+	Query string
+	// Since is the high-water mark of the tracker query: only the issues updated since then are fetched, if not nil
+	Since *time.Time
+}
+
+// gitlabFetcher provides issue listing
+type gitlabFetcher interface {
+	// listIssues returns the given page of the issues matching the given query, along with the next page (0 if none)
+	listIssues(query string, page int) ([]json.RawMessage, int, error)
+}
+
+// gitlabIssueFetcher fetch issues from GitLab
+type gitlabIssueFetcher struct {
+	client    *http.Client
+	url       string
+	authToken string
+}
+
+// get sends a GET request to the given URL, and sends it again after a while if it was rejected because of a rate limit
+func (f *gitlabIssueFetcher) get(u string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if f.authToken != "" {
+			req.Header.Set("PRIVATE-TOKEN", f.authToken)
+		}
+		resp, err := f.client.Do(req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !waitForRateLimit(resp, attempt) {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+}
+
+// listIssues lists the issues of the project given in the query
+func (f *gitlabIssueFetcher) listIssues(query string, page int) ([]json.RawMessage, int, error) {
+	project, params := query, ""
+	if i := strings.Index(query, "?"); i >= 0 {
+		project, params = query[:i], query[i+1:]
+	}
+	values, err := url.ParseQuery(params)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	values.Set("per_page", strconv.Itoa(gitlabPageSize))
+	if page > 0 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	resp, err := f.get(strings.TrimSuffix(f.url, "/") + "/api/v4/projects/" + url.QueryEscape(project) + "/issues?" + values.Encode())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, errors.Errorf("unexpected response when listing GitLab issues: %s", resp.Status)
+	}
+	var issues []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	nextPage, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	return issues, nextPage, nil
+}
+
+// Fetch tracker items from GitLab
+func (g *GitlabTracker) Fetch(authToken string) chan TrackerItemContent {
+	f := gitlabIssueFetcher{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		url:       g.URL,
+		authToken: authToken,
+	}
+	return g.fetch(&f)
+}
+
+func (g *GitlabTracker) fetch(f gitlabFetcher) chan TrackerItemContent {
+	item := make(chan TrackerItemContent)
+	go func() {
+		query := g.query()
+		page := 0
+		for {
+			issues, nextPage, err := f.listIssues(query, page)
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"query": query,
+					"page":  page,
+					"err":   err,
+				}, "failed to list GitLab issues")
+				item <- TrackerItemContent{Err: err}
+				break
+			}
+			for _, issue := range issues {
+				var i struct {
+					WebURL    string `json:"web_url"`
+					UpdatedAt string `json:"updated_at"`
+				}
+				if err := json.Unmarshal(issue, &i); err != nil {
+					continue
+				}
+				id, _ := json.Marshal(i.WebURL)
+				updatedAt, _ := time.Parse(time.RFC3339, i.UpdatedAt)
+				item <- TrackerItemContent{ID: string(id), Content: issue, UpdatedAt: updatedAt}
+			}
+			if nextPage == 0 {
+				break
+			}
+			page = nextPage
+		}
+		close(item)
+	}()
+	return item
+}
+
+// query returns the query of the tracker, restricted to the issues updated since the high-water mark (if any)
+// and ordered by update time, the oldest first so that an interrupted fetch can be resumed from the last update seen
+func (g *GitlabTracker) query() string {
+	if g.Since == nil {
+		return g.Query
+	}
+	separator := "?"
+	if strings.Contains(g.Query, "?") {
+		separator = "&"
+	}
+	values := url.Values{}
+	values.Set("updated_after", g.Since.UTC().Format(time.RFC3339))
+	values.Set("order_by", "updated_at")
+	values.Set("sort", "asc")
+	return g.Query + separator + values.Encode()
+}
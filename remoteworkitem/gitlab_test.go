@@ -0,0 +1,101 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/dnaeon/go-vcr/recorder"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGitlabIssueFetcher struct{}
+
+func (f *fakeGitlabIssueFetcher) listIssues(query string, page int) ([]json.RawMessage, int, error) {
+	if page == 0 {
+		return []json.RawMessage{json.RawMessage(`{"web_url":"https://gitlab.com/foo/bar/issues/1"}`)}, 2, nil
+	}
+	return []json.RawMessage{json.RawMessage(`{"web_url":"https://gitlab.com/foo/bar/issues/2"}`)}, 0, nil
+}
+
+func TestGitlabFetch(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	f := fakeGitlabIssueFetcher{}
+	g := GitlabTracker{URL: "", Query: ""}
+	// when
+	fetch := g.fetch(&f)
+	// then
+	i := <-fetch
+	assert.Equal(t, `"https://gitlab.com/foo/bar/issues/1"`, i.ID)
+	assert.Equal(t, `{"web_url":"https://gitlab.com/foo/bar/issues/1"}`, string(i.Content))
+	i2 := <-fetch
+	assert.Equal(t, `"https://gitlab.com/foo/bar/issues/2"`, i2.ID)
+	_, more := <-fetch
+	assert.False(t, more)
+}
+
+type fakeGitlabIssueFetcherWithError struct{}
+
+func (f *fakeGitlabIssueFetcherWithError) listIssues(query string, page int) ([]json.RawMessage, int, error) {
+	return nil, 0, errors.New("unexpected response when listing GitLab issues: 404 Not Found")
+}
+
+func TestGitlabFetchWithError(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	f := fakeGitlabIssueFetcherWithError{}
+	g := GitlabTracker{URL: "", Query: ""}
+	// when
+	fetch := g.fetch(&f)
+	// then
+	i, more := <-fetch
+	require.True(t, more)
+	assert.NotNil(t, i.Err)
+	_, more = <-fetch
+	assert.False(t, more)
+}
+
+func TestGitlabQuery(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	since := time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC)
+	// then
+	assert.Equal(t, "foo/bar?state=opened", (&GitlabTracker{Query: "foo/bar?state=opened"}).query())
+	assert.Equal(t, "foo/bar?state=opened&order_by=updated_at&sort=asc&updated_after=2017-04-12T09%3A21%3A02Z", (&GitlabTracker{Query: "foo/bar?state=opened", Since: &since}).query())
+	assert.Equal(t, "foo/bar?order_by=updated_at&sort=asc&updated_after=2017-04-12T09%3A21%3A02Z", (&GitlabTracker{Query: "foo/bar", Since: &since}).query())
+}
+
+func TestGitlabFetchWithRecording(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	r, err := recorder.New("../test/data/gitlab_fetch_test")
+	require.Nil(t, err)
+	defer r.Stop()
+	f := gitlabIssueFetcher{
+		client: &http.Client{
+			Timeout:   1 * time.Second,
+			Transport: r.Transport,
+		},
+		url: "https://gitlab.com",
+	}
+	g := &GitlabTracker{URL: "https://gitlab.com", Query: "almighty-test/almighty-test-unit?state=opened"}
+	// when
+	fetch := g.fetch(&f)
+	// then
+	var trackerItemContents []TrackerItemContent
+	for trackerItemContent := range fetch {
+		trackerItemContents = append(trackerItemContents, trackerItemContent)
+	}
+	require.Len(t, trackerItemContents, 3)
+	assert.Equal(t, `"https://gitlab.com/almighty-test/almighty-test-unit/issues/3"`, trackerItemContents[0].ID)
+	assert.Equal(t, time.Date(2017, 4, 12, 9, 23, 2, 567000000, time.UTC), trackerItemContents[0].UpdatedAt.UTC())
+	assert.Equal(t, `"https://gitlab.com/almighty-test/almighty-test-unit/issues/2"`, trackerItemContents[1].ID)
+	assert.Contains(t, string(trackerItemContents[1].Content), `"description": "desc\n"`)
+	assert.Equal(t, `"https://gitlab.com/almighty-test/almighty-test-unit/issues/1"`, trackerItemContents[2].ID)
+	assert.Contains(t, string(trackerItemContents[2].Content), `"state": "reopened"`)
+}
@@ -1,15 +1,59 @@
 package remoteworkitem
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/rendering"
 
 	jira "github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
 )
 
+func init() {
+	mustRegisterProvider(Provider{
+		Type: ProviderJira,
+		NewTracker: func(url, query string, since *time.Time) TrackerProvider {
+			return &JiraTracker{URL: url, Query: query, Since: since}
+		},
+		NewAttributeAccessor: NewJiraRemoteWorkItem,
+		Mapping: RemoteWorkItemMap{
+			AttributeMapper{AttributeExpression(JiraTitle), StringConverter{}}:                                      remoteTitle,
+			AttributeMapper{AttributeExpression(JiraBody), MarkupConverter{markup: rendering.SystemMarkupJiraWiki}}: remoteDescription,
+			AttributeMapper{AttributeExpression(JiraID), StringConverter{}}:                                         remoteItemID,
+			AttributeMapper{AttributeExpression(JiraCreatorLogin), StringConverter{}}:                               remoteCreatorLogin,
+			AttributeMapper{AttributeExpression(JiraCreatorProfileURL), StringConverter{}}:                          remoteCreatorProfileURL,
+			AttributeMapper{AttributeExpression(JiraAssigneeLogin), ListConverter{}}:                                remoteAssigneeLogins,
+			AttributeMapper{AttributeExpression(JiraAssigneeProfileURL), ListConverter{}}:                           remoteAssigneeProfileURLs,
+		},
+		State:          AttributeExpression(JiraState),
+		StateConverter: JiraStateConverter{},
+		NewPusher: func(url, authToken string) TrackerPusher {
+			ts := oauth2.StaticTokenSource(
+				&oauth2.Token{AccessToken: authToken},
+			)
+			client, err := jira.NewClient(oauth2.NewClient(oauth2.NoContext, ts), url)
+			if err != nil {
+				return nil
+			}
+			return &JiraPusher{editor: &jiraIssueEditor{client: client}}
+		},
+		Webhook: jiraWebhook{},
+	})
+}
+
 // JiraTracker represents the Jira tracker provider
 type JiraTracker struct {
 	URL   string
 	Query string
+	// Since is the high-water mark of the tracker query: only the issues updated since then are fetched, if not nil
+	Since *time.Time
 }
 
 type jiraFetcher interface {
@@ -40,14 +84,219 @@ func (j *JiraTracker) Fetch(authToken string) chan TrackerItemContent {
 func (j *JiraTracker) fetch(f jiraFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
 	go func() {
-		issues, _, _ := f.listIssues(j.Query, nil)
+		jql := j.jql()
+		issues, resp, err := f.listIssues(jql, nil)
+		for attempt := 0; err != nil && resp != nil && waitForRateLimit(resp.Response, attempt); attempt++ {
+			issues, resp, err = f.listIssues(jql, nil)
+		}
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"query": jql,
+				"err":   err,
+			}, "failed to list Jira issues")
+			item <- TrackerItemContent{Err: err}
+		}
 		for _, l := range issues {
 			id, _ := json.Marshal(l.Key)
-			issue, _, _ := f.getIssue(l.Key)
+			issue, resp, err := f.getIssue(l.Key)
+			for attempt := 0; err != nil && resp != nil && waitForRateLimit(resp.Response, attempt); attempt++ {
+				issue, resp, err = f.getIssue(l.Key)
+			}
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"issueKey": l.Key,
+					"err":      err,
+				}, "failed to get Jira issue")
+				item <- TrackerItemContent{ID: string(id), Err: err}
+				continue
+			}
 			content, _ := json.Marshal(issue)
-			item <- TrackerItemContent{ID: string(id), Content: content}
+			item <- TrackerItemContent{ID: string(id), Content: content, UpdatedAt: jiraUpdatedAt(content)}
 		}
 		close(item)
 	}()
 	return item
 }
+
+// jql returns the query of the tracker, restricted to the issues updated since the high-water mark (if any)
+// and ordered by update time, the oldest first so that an interrupted fetch can be resumed from the last update seen
+func (j *JiraTracker) jql() string {
+	if j.Since == nil {
+		return j.Query
+	}
+	// Jira interprets the dates in the time zone of the user and with a minute precision:
+	// the high-water mark is moved back by a day so that no update is missed whatever the time zone of the server
+	since := j.Since.UTC().Add(-24 * time.Hour).Format("2006/01/02 15:04")
+	query := j.Query
+	if i := strings.Index(strings.ToUpper(query), "ORDER BY"); i >= 0 {
+		query = query[:i]
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return fmt.Sprintf(`updated >= "%s" ORDER BY updated ASC`, since)
+	}
+	return fmt.Sprintf(`(%s) AND updated >= "%s" ORDER BY updated ASC`, query, since)
+}
+
+// jiraUpdatedAt returns the last update time of the given Jira issue content, or a zero time if unknown
+func jiraUpdatedAt(content []byte) time.Time {
+	var issue struct {
+		Fields struct {
+			Updated string `json:"updated"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(content, &issue); err != nil {
+		return time.Time{}
+	}
+	updatedAt, _ := time.Parse("2006-01-02T15:04:05.000-0700", issue.Fields.Updated)
+	return updatedAt
+}
+
+// jiraEditor sends requests to the Jira REST API
+type jiraEditor interface {
+	// do sends a request with the given method, path and body, and decodes the response into the given value (if not nil)
+	do(method, path string, body interface{}, v interface{}) error
+}
+
+type jiraIssueEditor struct {
+	client *jira.Client
+}
+
+func (e *jiraIssueEditor) do(method, path string, body interface{}, v interface{}) error {
+	req, err := e.client.NewRequest(method, path, body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = e.client.Do(req, v)
+	return errors.WithStack(err)
+}
+
+// JiraPusher pushes the local changes to Jira
+type JiraPusher struct {
+	editor jiraEditor
+}
+
+// jiraIssuePath returns the path of the issue in the REST API, given its URL (the 'self' attribute) which is the ID of the remote item
+func jiraIssuePath(remoteItemID string) (string, error) {
+	i := strings.LastIndex(remoteItemID, "/")
+	if i < 0 || i == len(remoteItemID)-1 {
+		return "", BadParameterError{parameter: "remote item ID", value: remoteItemID}
+	}
+	return "rest/api/2/issue/" + remoteItemID[i+1:], nil
+}
+
+// UpdateItem updates the summary and assignee of the Jira issue, and applies
+// the transition leading to the new state (if any)
+func (j *JiraPusher) UpdateItem(remoteItemID string, changes RemoteChanges) error {
+	path, err := jiraIssuePath(remoteItemID)
+	if err != nil {
+		return err
+	}
+	fields := map[string]interface{}{}
+	if changes.Title != nil {
+		fields["summary"] = *changes.Title
+	}
+	if changes.Assignees != nil {
+		// Jira issues have a single assignee
+		var assignee interface{}
+		if len(*changes.Assignees) > 0 {
+			assignee = (*changes.Assignees)[0]
+		}
+		fields["assignee"] = map[string]interface{}{"name": assignee}
+	}
+	if len(fields) > 0 {
+		if err := j.editor.do("PUT", path, map[string]interface{}{"fields": fields}, nil); err != nil {
+			return err
+		}
+	}
+	if changes.State != nil {
+		return j.transition(path, *changes.State)
+	}
+	return nil
+}
+
+// transition applies the first transition of the issue whose target status has the given name
+func (j *JiraPusher) transition(path, state string) error {
+	var result struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.editor.do("GET", path+"/transitions", nil, &result); err != nil {
+		return err
+	}
+	for _, t := range result.Transitions {
+		if strings.EqualFold(t.To.Name, state) {
+			return j.editor.do("POST", path+"/transitions", map[string]interface{}{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	return errors.Errorf("no transition to state '%s' available for the Jira issue %s", state, path)
+}
+
+// AddComment adds a comment on the Jira issue
+func (j *JiraPusher) AddComment(remoteItemID string, body string) error {
+	path, err := jiraIssuePath(remoteItemID)
+	if err != nil {
+		return err
+	}
+	return j.editor.do("POST", path+"/comment", map[string]string{"body": body}, nil)
+}
+
+// jiraWebhook receives the "jira:issue_created" and "jira:issue_updated" events of the Jira webhooks.
+// Jira doesn't sign the events: the secret is given in the 'secret' query parameter of the URL of the webhook.
+type jiraWebhook struct{}
+
+// Verify checks the secret given in the URL of the request
+func (jiraWebhook) Verify(req *http.Request, body []byte, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(req.URL.Query().Get("secret")), []byte(secret)) == 1
+}
+
+// Parse decodes the issue of the event, along with the comment added or edited by the event (if any).
+// The other events are ignored.
+func (jiraWebhook) Parse(req *http.Request, body []byte) (*WebhookEvent, error) {
+	var payload struct {
+		WebhookEvent string          `json:"webhookEvent"`
+		Issue        json.RawMessage `json:"issue"`
+		Comment      *struct {
+			ID     string `json:"id"`
+			Body   string `json:"body"`
+			Author struct {
+				Key  string `json:"key"`
+				Self string `json:"self"`
+			} `json:"author"`
+		} `json:"comment"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.Wrap(err, "invalid Jira event")
+	}
+	if payload.WebhookEvent != "jira:issue_created" && payload.WebhookEvent != "jira:issue_updated" {
+		return nil, nil
+	}
+	if len(payload.Issue) == 0 {
+		return nil, errors.Errorf("the Jira '%s' event has no issue", payload.WebhookEvent)
+	}
+	var issue struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(payload.Issue, &issue); err != nil {
+		return nil, errors.Wrap(err, "invalid Jira issue")
+	}
+	if issue.Key == "" {
+		return nil, errors.New("the Jira issue has no key")
+	}
+	id, _ := json.Marshal(issue.Key)
+	event := WebhookEvent{Item: TrackerItemContent{ID: string(id), Content: payload.Issue, UpdatedAt: jiraUpdatedAt(payload.Issue)}}
+	if payload.Comment != nil {
+		event.Comment = &RemoteComment{
+			ID:                payload.Comment.ID,
+			Body:              payload.Comment.Body,
+			Markup:            rendering.SystemMarkupJiraWiki,
+			CreatorLogin:      payload.Comment.Author.Key,
+			CreatorProfileURL: payload.Comment.Author.Self,
+		}
+	}
+	return &event, nil
+}
@@ -1,7 +1,9 @@
 package remoteworkitem
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -33,6 +35,22 @@ func TestJiraFetch(t *testing.T) {
 	assert.Equal(t, `{"id":"1"}`, string(i.Content))
 }
 
+func TestJiraQuery(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	since := time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC)
+	// then
+	assert.Equal(t, "project = ARQ ORDER BY created ASC", (&JiraTracker{Query: "project = ARQ ORDER BY created ASC"}).jql())
+	assert.Equal(t, `(project = ARQ) AND updated >= "2017/04/11 09:21" ORDER BY updated ASC`, (&JiraTracker{Query: "project = ARQ order by created ASC", Since: &since}).jql())
+	assert.Equal(t, `updated >= "2017/04/11 09:21" ORDER BY updated ASC`, (&JiraTracker{Since: &since}).jql())
+}
+
+func TestJiraUpdatedAt(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, time.Date(2016, 11, 29, 8, 17, 47, 0, time.UTC), jiraUpdatedAt([]byte(`{"fields":{"updated":"2016-11-29T09:17:47.000+0100"}}`)).UTC())
+	assert.True(t, jiraUpdatedAt([]byte(`{"id":"1"}`)).IsZero())
+}
+
 func TestJiraFetchWithRecording(t *testing.T) {
 	// given
 	resource.Require(t, resource.UnitTest)
@@ -64,3 +82,128 @@ func TestJiraFetchWithRecording(t *testing.T) {
 	assert.Equal(t, `"ARQ-2009"`, trackerItemContents[3].ID)
 	assert.Equal(t, `"ARQ-2010"`, trackerItemContents[4].ID)
 }
+
+type jiraRequest struct {
+	method string
+	path   string
+	body   interface{}
+}
+
+type fakeJiraIssueEditor struct {
+	requests []jiraRequest
+}
+
+func (e *fakeJiraIssueEditor) do(method, path string, body interface{}, v interface{}) error {
+	e.requests = append(e.requests, jiraRequest{method: method, path: path, body: body})
+	if method == "GET" && strings.HasSuffix(path, "/transitions") {
+		return json.Unmarshal([]byte(`{"transitions":[{"id":"4","to":{"name":"In Progress"}},{"id":"2","to":{"name":"Closed"}}]}`), v)
+	}
+	return nil
+}
+
+func TestJiraPusherUpdateItem(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	e := fakeJiraIssueEditor{}
+	p := JiraPusher{editor: &e}
+	title := "new title"
+	state := "closed"
+	assignees := []string{"aslak"}
+	// when
+	err := p.UpdateItem("https://issues.jboss.org/rest/api/2/issue/12345", RemoteChanges{Title: &title, State: &state, Assignees: &assignees})
+	// then
+	require.Nil(t, err)
+	require.Len(t, e.requests, 3)
+	assert.Equal(t, jiraRequest{method: "PUT", path: "rest/api/2/issue/12345", body: map[string]interface{}{
+		"fields": map[string]interface{}{
+			"summary":  "new title",
+			"assignee": map[string]interface{}{"name": "aslak"},
+		},
+	}}, e.requests[0])
+	assert.Equal(t, "GET", e.requests[1].method)
+	assert.Equal(t, jiraRequest{method: "POST", path: "rest/api/2/issue/12345/transitions", body: map[string]interface{}{
+		"transition": map[string]string{"id": "2"},
+	}}, e.requests[2])
+}
+
+func TestJiraPusherUpdateItemUnknownState(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	p := JiraPusher{editor: &fakeJiraIssueEditor{}}
+	state := "resolved"
+	// when
+	err := p.UpdateItem("https://issues.jboss.org/rest/api/2/issue/12345", RemoteChanges{State: &state})
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestJiraPusherAddComment(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	e := fakeJiraIssueEditor{}
+	p := JiraPusher{editor: &e}
+	// when
+	err := p.AddComment("https://issues.jboss.org/rest/api/2/issue/12345", "a comment")
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, []jiraRequest{{method: "POST", path: "rest/api/2/issue/12345/comment", body: map[string]string{"body": "a comment"}}}, e.requests)
+}
+
+const jiraIssueUpdatedEvent = `{
+	"webhookEvent": "jira:issue_updated",
+	"issue": {
+		"self": "https://issues.jboss.com/rest/api/2/issue/12345",
+		"key": "ARQ-1",
+		"fields": {
+			"summary": "a webhook issue",
+			"updated": "2017-04-12T11:21:02.000+0200",
+			"status": {"name": "Open"}
+		}
+	},
+	"comment": {
+		"id": "10001",
+		"body": "a remote comment",
+		"author": {"key": "jdoe", "self": "https://issues.jboss.com/rest/api/2/user?username=jdoe"}
+	}
+}`
+
+func TestJiraWebhookVerify(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	body := []byte(jiraIssueUpdatedEvent)
+	for url, expected := range map[string]bool{
+		"/api/trackers/1/webhook":               false,
+		"/api/trackers/1/webhook?secret=":       false,
+		"/api/trackers/1/webhook?secret=other":  false,
+		"/api/trackers/1/webhook?secret=s3cr3t": true,
+	} {
+		req, err := http.NewRequest("POST", url, nil)
+		require.Nil(t, err)
+		assert.Equal(t, expected, jiraWebhook{}.Verify(req, body, "s3cr3t"), url)
+	}
+}
+
+func TestJiraWebhookParse(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	req, err := http.NewRequest("POST", "/api/trackers/1/webhook?secret=s3cr3t", nil)
+	require.Nil(t, err)
+	// when
+	event, err := jiraWebhook{}.Parse(req, []byte(jiraIssueUpdatedEvent))
+	// then
+	require.Nil(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, `"ARQ-1"`, event.Item.ID)
+	assert.Equal(t, time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC), event.Item.UpdatedAt.UTC())
+	remoteItem, err := NewJiraRemoteWorkItem(TrackerItem{Item: string(event.Item.Content)})
+	require.Nil(t, err)
+	assert.Equal(t, "a webhook issue", remoteItem.Get(JiraTitle))
+	require.NotNil(t, event.Comment)
+	assert.Equal(t, "10001", event.Comment.ID)
+	assert.Equal(t, "jdoe", event.Comment.CreatorLogin)
+	// the other events are ignored
+	event, err = jiraWebhook{}.Parse(req, []byte(`{"webhookEvent": "jira:issue_deleted"}`))
+	require.Nil(t, err)
+	assert.Nil(t, event)
+	// invalid event
+	_, err = jiraWebhook{}.Parse(req, []byte(`{"webhookEvent": "jira:issue_updated", "issue": {}}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,92 @@
+package remoteworkitem
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Provider describes a type of remote tracker: how to fetch the items matching a tracker query
+// and how to map these items to work items
+type Provider struct {
+	// Type is the type of the trackers supported by this provider (e.g. "github")
+	Type string
+	// NewTracker returns the TrackerProvider which fetches the items matching the given query
+	// from the tracker at the given URL. If since is not nil, only the items updated since then are fetched.
+	NewTracker func(url, query string, since *time.Time) TrackerProvider
+	// NewAttributeAccessor decodes the content of a tracker item fetched by this provider
+	NewAttributeAccessor func(TrackerItem) (AttributeAccessor, error)
+	// Mapping relates the remote attributes to the work item fields, apart from the state
+	Mapping RemoteWorkItemMap
+	// State is the remote attribute holding the state of the item
+	State AttributeExpression
+	// StateConverter converts the remote states into work item states
+	StateConverter StateConverter
+	// NewPusher returns the TrackerPusher which pushes the local changes to the tracker at the given URL.
+	// It is optional: the work items imported by a provider without pusher are read-only on the remote side.
+	NewPusher func(url, authToken string) TrackerPusher
+	// Webhook receives the events sent by the webhooks of the trackers.
+	// It is optional: the items of a provider without webhook receiver are only imported by the tracker queries.
+	Webhook WebhookReceiver
+}
+
+// WorkItemMap returns the mapping of the remote attributes to the work item fields, including the state
+func (p Provider) WorkItemMap() RemoteWorkItemMap {
+	result := RemoteWorkItemMap{}
+	for mapper, field := range p.Mapping {
+		result[mapper] = field
+	}
+	if p.StateConverter != nil {
+		result[AttributeMapper{p.State, p.StateConverter}] = remoteState
+	}
+	return result
+}
+
+var (
+	providers     = map[string]Provider{}
+	providersLock sync.RWMutex
+)
+
+// RegisterProvider registers the given provider, making its type available to the trackers.
+// An error is returned if the provider is incomplete or if its type is already registered.
+func RegisterProvider(p Provider) error {
+	if p.Type == "" || p.NewTracker == nil || p.NewAttributeAccessor == nil {
+		return errors.Errorf("incomplete tracker provider: %+v", p)
+	}
+	providersLock.Lock()
+	defer providersLock.Unlock()
+	if _, exists := providers[p.Type]; exists {
+		return errors.Errorf("tracker provider already registered: %s", p.Type)
+	}
+	providers[p.Type] = p
+	return nil
+}
+
+// mustRegisterProvider registers the given provider and panics if it can't be registered
+func mustRegisterProvider(p Provider) {
+	if err := RegisterProvider(p); err != nil {
+		panic(err.Error())
+	}
+}
+
+// LookupProvider returns the provider registered for the given type of tracker
+func LookupProvider(providerType string) (Provider, bool) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	p, ok := providers[providerType]
+	return p, ok
+}
+
+// ProviderTypes returns the types of the registered providers, in alphabetical order
+func ProviderTypes() []string {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+	result := make([]string, 0, len(providers))
+	for providerType := range providers {
+		result = append(result, providerType)
+	}
+	sort.Strings(result)
+	return result
+}
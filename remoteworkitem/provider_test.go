@@ -0,0 +1,76 @@
+package remoteworkitem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredProviders(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	// when
+	providerTypes := ProviderTypes()
+	// then
+	for _, providerType := range []string{ProviderBugzilla, ProviderGithub, ProviderGitlab, ProviderJira} {
+		assert.Contains(t, providerTypes, providerType)
+		p, ok := LookupProvider(providerType)
+		require.True(t, ok, providerType)
+		assert.Equal(t, providerType, p.Type)
+		assert.NotNil(t, p.NewTracker("http://example.com", "query", nil), providerType)
+	}
+	_, ok := LookupProvider("unknown")
+	assert.False(t, ok)
+}
+
+func TestRegisterProvider(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	p := Provider{
+		Type: "test-register-provider",
+		NewTracker: func(url, query string, since *time.Time) TrackerProvider {
+			return &GithubTracker{URL: url, Query: query}
+		},
+		NewAttributeAccessor: NewGitHubRemoteWorkItem,
+	}
+	// when
+	err := RegisterProvider(p)
+	// then
+	require.Nil(t, err)
+	_, ok := LookupProvider(p.Type)
+	assert.True(t, ok)
+	assert.Contains(t, ProviderTypes(), p.Type)
+	// when registering the same type again
+	err = RegisterProvider(p)
+	// then
+	assert.NotNil(t, err)
+}
+
+func TestRegisterIncompleteProvider(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	// when
+	err := RegisterProvider(Provider{Type: "test-incomplete-provider"})
+	// then
+	require.NotNil(t, err)
+	_, ok := LookupProvider("test-incomplete-provider")
+	assert.False(t, ok)
+}
+
+func TestProviderWorkItemMapIncludesState(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	p, ok := LookupProvider(ProviderGitlab)
+	require.True(t, ok)
+	// when
+	workItemMap := p.WorkItemMap()
+	// then
+	assert.Len(t, workItemMap, len(p.Mapping)+1)
+	assert.Equal(t, remoteState, workItemMap[AttributeMapper{AttributeExpression(GitlabState), GitlabStateConverter{}}])
+	// the registered mapping is left unchanged
+	_, found := p.Mapping[AttributeMapper{AttributeExpression(GitlabState), GitlabStateConverter{}}]
+	assert.False(t, found)
+}
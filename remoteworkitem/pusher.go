@@ -0,0 +1,33 @@
+package remoteworkitem
+
+// RemoteChanges holds the values of the work item fields to push to a remote tracker.
+// A nil value means that the field did not change locally.
+type RemoteChanges struct {
+	Title *string
+	State *string
+	// Assignees holds the logins of the assignees on the remote tracker
+	Assignees *[]string
+}
+
+// IsEmpty returns true if there is no change to push
+func (c RemoteChanges) IsEmpty() bool {
+	return c.Title == nil && c.State == nil && c.Assignees == nil
+}
+
+// TrackerPusher pushes the local changes of the imported work items back to their remote tracker
+type TrackerPusher interface {
+	// UpdateItem applies the given changes on the remote item
+	UpdateItem(remoteItemID string, changes RemoteChanges) error
+	// AddComment adds a comment with the given body on the remote item
+	AddComment(remoteItemID string, body string) error
+}
+
+// newPusher returns the pusher of the provider of the given type, or nil if the
+// provider cannot push changes or if no auth token was given
+func newPusher(providerType, url, authToken string) TrackerPusher {
+	p, ok := LookupProvider(providerType)
+	if !ok || p.NewPusher == nil || authToken == "" {
+		return nil
+	}
+	return p.NewPusher(url, authToken)
+}
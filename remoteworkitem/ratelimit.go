@@ -0,0 +1,92 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRateLimitDelay is the longest time to wait for a rate limit to be reset. The fetch is interrupted
+	// if the remote tracker asks to wait longer, and the next run resumes from the high-water mark.
+	maxRateLimitDelay = 2 * time.Minute
+	// maxRateLimitRetries is the maximum number of times a request rejected because of a rate limit is sent again
+	maxRateLimitRetries = 3
+)
+
+// sleep pauses the fetching goroutine, it is replaced in the tests
+var sleep = time.Sleep
+
+// isRateLimited returns true if the given response was rejected because of a rate limit:
+// "429 Too Many Requests", or "403 Forbidden" along with an exhausted GitHub rate limit.
+func isRateLimited(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// rateLimitDelay returns how long to wait before sending the next request, according to the 'Retry-After' header
+// or to the reset time of the rate limit ('X-RateLimit-Reset' on GitHub, 'RateLimit-Reset' on GitLab).
+// An exponential backoff is used for the given attempt if the response doesn't tell when to retry.
+// It returns false if the delay is longer than maxRateLimitDelay.
+func rateLimitDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	delay := time.Duration(1<<uint(attempt)) * time.Second
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(resp.Header.Get("Retry-After")); err == nil {
+			delay = date.Sub(time.Now())
+		} else if reset := rateLimitReset(resp); !reset.IsZero() {
+			delay = reset.Sub(time.Now())
+		}
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, delay <= maxRateLimitDelay
+}
+
+// rateLimitReset returns the time when the rate limit will be reset, or a zero time if unknown
+func rateLimitReset(resp *http.Response) time.Time {
+	for _, header := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if epoch, err := strconv.ParseInt(resp.Header.Get(header), 10, 64); err == nil {
+			return time.Unix(epoch, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// waitForRateLimit waits until the given response can be retried and returns true,
+// or returns false if the request should not be retried
+func waitForRateLimit(resp *http.Response, attempt int) bool {
+	if !isRateLimited(resp) || attempt >= maxRateLimitRetries {
+		return false
+	}
+	delay, ok := rateLimitDelay(resp, attempt)
+	if !ok {
+		return false
+	}
+	sleep(delay)
+	return true
+}
+
+// waitForRemainingRequests waits for the reset of the rate limit if the given response says that no
+// request remains, and returns false if the wait would be too long
+func waitForRemainingRequests(resp *http.Response) bool {
+	if resp == nil || resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return true
+	}
+	delay, ok := rateLimitDelay(resp, 0)
+	if !ok {
+		return false
+	}
+	sleep(delay)
+	return true
+}
@@ -0,0 +1,70 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func rateLimitResponse(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for k, v := range headers {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+func TestIsRateLimited(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.False(t, isRateLimited(nil))
+	assert.False(t, isRateLimited(rateLimitResponse(http.StatusOK, nil)))
+	assert.False(t, isRateLimited(rateLimitResponse(http.StatusForbidden, nil)))
+	assert.True(t, isRateLimited(rateLimitResponse(http.StatusTooManyRequests, nil)))
+	assert.True(t, isRateLimited(rateLimitResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0"})))
+	assert.True(t, isRateLimited(rateLimitResponse(http.StatusForbidden, map[string]string{"Retry-After": "30"})))
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// Retry-After in seconds
+	delay, ok := rateLimitDelay(rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "30"}), 0)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, delay)
+	// reset time of the GitHub rate limit
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	delay, ok = rateLimitDelay(rateLimitResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Reset": reset}), 0)
+	assert.True(t, ok)
+	assert.True(t, delay > 0 && delay <= time.Minute)
+	// too long
+	reset = strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	_, ok = rateLimitDelay(rateLimitResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Reset": reset}), 0)
+	assert.False(t, ok)
+	// exponential backoff
+	delay, ok = rateLimitDelay(rateLimitResponse(http.StatusTooManyRequests, nil), 2)
+	assert.True(t, ok)
+	assert.Equal(t, 4*time.Second, delay)
+}
+
+func TestWaitForRateLimit(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	var slept []time.Duration
+	sleep = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+	defer func() {
+		sleep = time.Sleep
+	}()
+	resp := rateLimitResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "1"})
+	// then
+	assert.True(t, waitForRateLimit(resp, 0))
+	assert.False(t, waitForRateLimit(resp, maxRateLimitRetries))
+	assert.False(t, waitForRateLimit(rateLimitResponse(http.StatusNotFound, nil), 0))
+	assert.True(t, waitForRemainingRequests(rateLimitResponse(http.StatusOK, map[string]string{"X-RateLimit-Remaining": "10"})))
+	assert.True(t, waitForRemainingRequests(rateLimitResponse(http.StatusOK, map[string]string{"X-RateLimit-Remaining": "0", "Retry-After": "2"})))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, slept)
+}
@@ -2,8 +2,10 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/workitem"
@@ -13,8 +15,10 @@ import (
 
 // List of supported attributes
 const (
-	ProviderGithub = "github"
-	ProviderJira   = "jira"
+	ProviderGithub   = "github"
+	ProviderJira     = "jira"
+	ProviderGitlab   = "gitlab"
+	ProviderBugzilla = "bugzilla"
 
 	// The keys in the flattened response JSON of a typical Github issue.
 	GithubTitle                      = "title"
@@ -37,6 +41,28 @@ const (
 	JiraCreatorProfileURL  = "fields.creator.self"
 	JiraAssigneeLogin      = "fields.assignee.key"
 	JiraAssigneeProfileURL = "fields.assignee.self"
+
+	// The keys in the flattened response JSON of a typical GitLab issue.
+	GitlabTitle                      = "title"
+	GitlabDescription                = "description"
+	GitlabState                      = "state"
+	GitlabID                         = "web_url"
+	GitlabCreatorLogin               = "author.username"
+	GitlabCreatorProfileURL          = "author.web_url"
+	GitlabAssigneesLogin             = "assignees.0.username"
+	GitlabAssigneesLoginPattern      = "assignees.?.username"
+	GitlabAssigneesProfileURL        = "assignees.0.web_url"
+	GitlabAssigneesProfileURLPattern = "assignees.?.web_url"
+
+	// The keys in the flattened JSON of a typical Bugzilla bug, as completed by the Bugzilla fetcher.
+	BugzillaTitle              = "summary"
+	BugzillaDescription        = "description"
+	BugzillaState              = "status"
+	BugzillaID                 = "self"
+	BugzillaCreatorLogin       = "creator_detail.name"
+	BugzillaCreatorProfileURL  = "creator_detail.url"
+	BugzillaAssigneeLogin      = "assigned_to_detail.name"
+	BugzillaAssigneeProfileURL = "assigned_to_detail.url"
 )
 
 // RemoteWorkItem a temporary structure that holds the relevant field values retrieved from a remote work item
@@ -60,36 +86,15 @@ const (
 	remoteAssigneeProfileURLs = "system.assignees.profile_url"
 )
 
-// RemoteWorkItemKeyMaps relate remote attribute keys to internal representation
-var RemoteWorkItemKeyMaps = map[string]RemoteWorkItemMap{
-	ProviderGithub: {
-		AttributeMapper{AttributeExpression(GithubTitle), StringConverter{}}:                                                               remoteTitle,
-		AttributeMapper{AttributeExpression(GithubDescription), MarkupConverter{markup: rendering.SystemMarkupMarkdown}}:                   remoteDescription,
-		AttributeMapper{AttributeExpression(GithubState), GithubStateConverter{}}:                                                          remoteState,
-		AttributeMapper{AttributeExpression(GithubID), StringConverter{}}:                                                                  remoteItemID,
-		AttributeMapper{AttributeExpression(GithubCreatorLogin), StringConverter{}}:                                                        remoteCreatorLogin,
-		AttributeMapper{AttributeExpression(GithubCreatorProfileURL), StringConverter{}}:                                                   remoteCreatorProfileURL,
-		AttributeMapper{AttributeExpression(GithubAssigneesLogin), PatternToListConverter{pattern: GithubAssigneesLoginPattern}}:           remoteAssigneeLogins,
-		AttributeMapper{AttributeExpression(GithubAssigneesProfileURL), PatternToListConverter{pattern: GithubAssigneesProfileURLPattern}}: remoteAssigneeProfileURLs,
-	},
-	ProviderJira: {
-		AttributeMapper{AttributeExpression(JiraTitle), StringConverter{}}:                                      remoteTitle,
-		AttributeMapper{AttributeExpression(JiraBody), MarkupConverter{markup: rendering.SystemMarkupJiraWiki}}: remoteDescription,
-		AttributeMapper{AttributeExpression(JiraState), JiraStateConverter{}}:                                   remoteState,
-		AttributeMapper{AttributeExpression(JiraID), StringConverter{}}:                                         remoteItemID,
-		AttributeMapper{AttributeExpression(JiraCreatorLogin), StringConverter{}}:                               remoteCreatorLogin,
-		AttributeMapper{AttributeExpression(JiraCreatorProfileURL), StringConverter{}}:                          remoteCreatorProfileURL,
-		AttributeMapper{AttributeExpression(JiraAssigneeLogin), ListConverter{}}:                                remoteAssigneeLogins,
-		AttributeMapper{AttributeExpression(JiraAssigneeProfileURL), ListConverter{}}:                           remoteAssigneeProfileURLs,
-	},
-}
-
+// AttributeConverter converts a remote attribute value into a work item field value
 type AttributeConverter interface {
 	Convert(interface{}, AttributeAccessor) (interface{}, error)
 }
 
 // StateConverter converts a remote work item state
-type StateConverter interface{}
+type StateConverter interface {
+	AttributeConverter
+}
 
 // StringConverter converts a value to a string
 type StringConverter struct{}
@@ -107,12 +112,26 @@ type MarkupConverter struct {
 	markup string
 }
 
+// DateConverter converts a remote date into a time
+type DateConverter struct{}
+
+// EnumConverter converts the remote values into the values of an enumeration
+type EnumConverter struct {
+	values map[string]string
+}
+
 type ListStringConverter struct{}
 
 type GithubStateConverter struct{}
 
 type JiraStateConverter struct{}
 
+// GitlabStateConverter converts the states of the GitLab issues
+type GitlabStateConverter struct{}
+
+// BugzillaStateConverter converts the statuses of the Bugzilla bugs
+type BugzillaStateConverter struct{}
+
 // Convert converts the given value to a string
 func (converter StringConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
 	return value, nil
@@ -123,9 +142,11 @@ func (converter ListConverter) Convert(value interface{}, item AttributeAccessor
 	if value == nil {
 		return make([]string, 0), nil
 	}
-	result := make([]string, 1)
-	result[0] = value.(string)
-	return result, nil
+	v, ok := value.(string)
+	if !ok {
+		return nil, errors.Errorf("Unexpected type of value to convert: %T", value)
+	}
+	return []string{v}, nil
 }
 
 // Convert converts all fields from the given item that match this RegexpConverter's pattern, and returns an array of matching values as string
@@ -134,11 +155,15 @@ func (converter PatternToListConverter) Convert(value interface{}, item Attribut
 	i := 0
 	for {
 		key := AttributeExpression(strings.Replace(converter.pattern, "?", strconv.Itoa(i), 1))
-		if v := item.Get(key); v != nil {
-			result = append(result, v.(string))
-		} else {
+		v := item.Get(key)
+		if v == nil {
 			break
 		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("Unexpected type of value to convert: %T", v)
+		}
+		result = append(result, s)
 		i++
 	}
 	return result, nil
@@ -158,6 +183,39 @@ func (converter MarkupConverter) Convert(value interface{}, item AttributeAccess
 	}
 }
 
+// dateLayouts are the supported formats of the remote dates: RFC 3339 (GitHub, GitLab, Bugzilla),
+// the format of the Jira dates and plain dates
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05.000-0700", "2006-01-02"}
+
+// Convert parses the given date
+func (converter DateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.Errorf("Unexpected type of value to convert: %T", value)
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return nil, errors.Errorf("Unexpected date format: %s", s)
+}
+
+// Convert returns the enumeration value corresponding to the given remote value.
+// An error is returned for the values which are not mapped.
+func (converter EnumConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if result, ok := converter.values[fmt.Sprint(value)]; ok {
+		return result, nil
+	}
+	return nil, errors.Errorf("Unexpected value to convert: %v", value)
+}
+
 // Convert method map the external tracker item to ALM WorkItem
 func (sc ListStringConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
 	return []interface{}{value}, nil
@@ -185,6 +243,40 @@ func (jhc JiraStateConverter) Convert(value interface{}, item AttributeAccessor)
 	return value, nil
 }
 
+// Convert converts the "opened" and "reopened" states of a GitLab issue into the "open" state
+func (glc GitlabStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	switch value {
+	case "opened", "reopened":
+		return workitem.SystemStateOpen, nil
+	case "closed":
+		return workitem.SystemStateClosed, nil
+	}
+	return value, nil
+}
+
+// bugzillaStates maps the default Bugzilla statuses to the work item states
+var bugzillaStates = map[string]string{
+	"UNCONFIRMED": workitem.SystemStateNew,
+	"NEW":         workitem.SystemStateNew,
+	"CONFIRMED":   workitem.SystemStateNew,
+	"ASSIGNED":    workitem.SystemStateOpen,
+	"REOPENED":    workitem.SystemStateOpen,
+	"IN_PROGRESS": workitem.SystemStateInProgress,
+	"RESOLVED":    workitem.SystemStateResolved,
+	"VERIFIED":    workitem.SystemStateResolved,
+	"CLOSED":      workitem.SystemStateClosed,
+}
+
+// Convert converts the status of a Bugzilla bug into a work item state. Custom statuses are kept as is.
+func (bzc BugzillaStateConverter) Convert(value interface{}, item AttributeAccessor) (interface{}, error) {
+	if status, ok := value.(string); ok {
+		if state, ok := bugzillaStates[status]; ok {
+			return state, nil
+		}
+	}
+	return value, nil
+}
+
 type AttributeMapper struct {
 	expression         AttributeExpression
 	attributeConverter AttributeConverter
@@ -202,12 +294,6 @@ type AttributeAccessor interface {
 	Get(field AttributeExpression) interface{}
 }
 
-// RemoteWorkItemImplRegistry contains all possible providers
-var RemoteWorkItemImplRegistry = map[string]func(TrackerItem) (AttributeAccessor, error){
-	ProviderGithub: NewGitHubRemoteWorkItem,
-	ProviderJira:   NewJiraRemoteWorkItem,
-}
-
 // GitHubRemoteWorkItem knows how to implement a FieldAccessor on a GitHub Issue JSON struct
 // and it should also know how to convert a value in remote work item for use in local WI
 type GitHubRemoteWorkItem struct {
@@ -251,6 +337,48 @@ func (jira JiraRemoteWorkItem) Get(field AttributeExpression) interface{} {
 	return jira.issue[string(field)]
 }
 
+// GitlabRemoteWorkItem knows how to implement a FieldAccessor on a GitLab Issue JSON struct
+type GitlabRemoteWorkItem struct {
+	issue map[string]interface{}
+}
+
+// NewGitlabRemoteWorkItem creates a new Decoded AttributeAccessor for a GitLab Issue
+func NewGitlabRemoteWorkItem(item TrackerItem) (AttributeAccessor, error) {
+	var j map[string]interface{}
+	err := json.Unmarshal([]byte(item.Item), &j)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	j = Flatten(j)
+	return GitlabRemoteWorkItem{issue: j}, nil
+}
+
+// Get attribute from issue map
+func (gl GitlabRemoteWorkItem) Get(field AttributeExpression) interface{} {
+	return gl.issue[string(field)]
+}
+
+// BugzillaRemoteWorkItem knows how to implement a FieldAccessor on a Bugzilla Bug JSON struct
+type BugzillaRemoteWorkItem struct {
+	bug map[string]interface{}
+}
+
+// NewBugzillaRemoteWorkItem creates a new Decoded AttributeAccessor for a Bugzilla Bug
+func NewBugzillaRemoteWorkItem(item TrackerItem) (AttributeAccessor, error) {
+	var j map[string]interface{}
+	err := json.Unmarshal([]byte(item.Item), &j)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	j = Flatten(j)
+	return BugzillaRemoteWorkItem{bug: j}, nil
+}
+
+// Get attribute from bug map
+func (bz BugzillaRemoteWorkItem) Get(field AttributeExpression) interface{} {
+	return bz.bug[string(field)]
+}
+
 // Map maps the remote WorkItem to a local RemoteWorkItem
 func Map(remoteItem AttributeAccessor, mapping RemoteWorkItemMap) (RemoteWorkItem, error) {
 	remoteWorkItem := RemoteWorkItem{Fields: make(map[string]interface{})}
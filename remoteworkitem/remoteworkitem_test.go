@@ -7,7 +7,9 @@ import (
 	"math"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/almighty/almighty-core/rendering"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/test"
 	"github.com/almighty/almighty-core/workitem"
@@ -37,8 +39,9 @@ func TestWorkItemMapping(t *testing.T) {
 	}
 	jsonContent := `{"title":"abc"}`
 	remoteTrackerItem := TrackerItem{Item: jsonContent, RemoteItemID: "xyz", TrackerID: uint64(0)}
-	remoteWorkItemImpl := RemoteWorkItemImplRegistry[ProviderGithub]
-	gh, err := remoteWorkItemImpl(remoteTrackerItem)
+	provider, ok := LookupProvider(ProviderGithub)
+	require.True(t, ok)
+	gh, err := provider.NewAttributeAccessor(remoteTrackerItem)
 	require.Nil(t, err)
 	// when
 	workItem, err := Map(gh, workItemMap)
@@ -91,16 +94,47 @@ func TestJiraIssueMapping(t *testing.T) {
 	}
 }
 
+// Table driven tests for the Mapping of GitLab issues
+func TestGitlabIssueMapping(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	var gitlabData = []remoteData{
+		// JSON data file of GitLab issue with assignee
+		// Issue API URL for the respective JSON file to update the cache
+		{"gitlab_issue_mapping.json", true, "https://gitlab.com/api/v4/projects/almighty-test%2Falmighty-test-unit/issues/2"},
+	}
+	// when/then
+	for _, j := range gitlabData {
+		doTestIssueMapping(t, j, ProviderGitlab)
+	}
+}
+
+// Table driven tests for the Mapping of Bugzilla bugs
+func TestBugzillaBugMapping(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	var bugzillaData = []remoteData{
+		// JSON data file of Bugzilla bug, as completed by the Bugzilla fetcher
+		// Bug API URL for the respective JSON file to update the cache
+		{"bugzilla_bug_mapping.json", true, "https://bugzilla.redhat.com/rest/bug/1441249"},
+	}
+	// when/then
+	for _, j := range bugzillaData {
+		doTestIssueMapping(t, j, ProviderBugzilla)
+	}
+}
+
 func doTestIssueMapping(t *testing.T, data remoteData, provider string) {
 	// given
 	content, err := test.LoadTestData(data.inputFile, func() ([]byte, error) {
 		return provideRemoteData(data.inputURL)
 	})
 	require.Nil(t, err)
-	workItemMap := RemoteWorkItemKeyMaps[provider]
+	p, ok := LookupProvider(provider)
+	require.True(t, ok)
+	workItemMap := p.WorkItemMap()
 	remoteTrackerItem := TrackerItem{Item: string(content[:]), RemoteItemID: "xyz", TrackerID: uint64(0)}
-	remoteWorkItemImpl := RemoteWorkItemImplRegistry[ProviderJira]
-	issue, err := remoteWorkItemImpl(remoteTrackerItem)
+	issue, err := p.NewAttributeAccessor(remoteTrackerItem)
 	require.Nil(t, err)
 	// when
 	workItem, err := Map(issue, workItemMap)
@@ -201,7 +235,7 @@ func doTestFlattenResponseMap(t *testing.T, data remoteData, provider string, sk
 	oneLevelMap := Flatten(nestedMap)
 	// then: verifying that the newly converted map contains all expected keys
 KEYS:
-	for k := range RemoteWorkItemKeyMaps[provider] {
+	for k := range lookupWorkItemMap(t, provider) {
 		key := string(k.expression)
 		for _, skipField := range skipFields {
 			if skipField == key {
@@ -288,17 +322,10 @@ func TestNewJiraRemoteWorkItem(t *testing.T) {
 	assert.Equal(t, jiraRemoteWorkItem.Get("assignee.participants.4"), "sbose56")
 }
 
-func TestRemoteWorkItemImplRegistry(t *testing.T) {
-	// given
-	resource.Require(t, resource.UnitTest)
-	// when
-	_, ok := RemoteWorkItemImplRegistry[ProviderGithub]
-	// then
-	assert.True(t, ok)
-	// when
-	_, ok = RemoteWorkItemImplRegistry[ProviderJira]
-	// then
-	assert.True(t, ok)
+func lookupWorkItemMap(t *testing.T, providerType string) RemoteWorkItemMap {
+	provider, ok := LookupProvider(providerType)
+	require.True(t, ok, "provider %s not registered", providerType)
+	return provider.WorkItemMap()
 }
 
 func TestPatternConverter(t *testing.T) {
@@ -315,7 +342,7 @@ func TestPatternConverter(t *testing.T) {
 	workItem := TestWorkItem{
 		content: content,
 	}
-	workItemMap := RemoteWorkItemKeyMaps[ProviderGithub]
+	workItemMap := lookupWorkItemMap(t, ProviderGithub)
 	// when
 	result, err := Map(workItem, workItemMap)
 	// then
@@ -338,7 +365,7 @@ func TestPatternConverterWithNoValue(t *testing.T) {
 	workItem := TestWorkItem{
 		content: content,
 	}
-	workItemMap := RemoteWorkItemKeyMaps[ProviderGithub]
+	workItemMap := lookupWorkItemMap(t, ProviderGithub)
 	// when
 	result, err := Map(workItem, workItemMap)
 	// then
@@ -367,7 +394,7 @@ func TestListConverter(t *testing.T) {
 	workItem := TestWorkItem{
 		content: content,
 	}
-	workItemMap := RemoteWorkItemKeyMaps[ProviderJira]
+	workItemMap := lookupWorkItemMap(t, ProviderJira)
 	// when
 	result, err := Map(workItem, workItemMap)
 	// then
@@ -386,7 +413,7 @@ func TestListConverterWithNoValue(t *testing.T) {
 	workItem := TestWorkItem{
 		content: content,
 	}
-	workItemMap := RemoteWorkItemKeyMaps[ProviderJira]
+	workItemMap := lookupWorkItemMap(t, ProviderJira)
 	// when
 	result, err := Map(workItem, workItemMap)
 	// then
@@ -396,3 +423,138 @@ func TestListConverterWithNoValue(t *testing.T) {
 	require.NotNil(t, result.Fields[remoteAssigneeProfileURLs])
 	require.Empty(t, result.Fields[remoteAssigneeProfileURLs])
 }
+
+func TestMarkupConverterWithJiraWiki(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	content := make(map[string]interface{})
+	content[JiraState] = "open"
+	content[JiraBody] = "h1. Title\n*important*"
+	workItem := TestWorkItem{
+		content: content,
+	}
+	workItemMap := lookupWorkItemMap(t, ProviderJira)
+	// when
+	result, err := Map(workItem, workItemMap)
+	// then
+	require.Nil(t, err)
+	require.IsType(t, rendering.MarkupContent{}, result.Fields[remoteDescription])
+	description := result.Fields[remoteDescription].(rendering.MarkupContent)
+	assert.Equal(t, rendering.SystemMarkupJiraWiki, description.Markup)
+	// the markup is kept when the description is stored
+	assert.Equal(t, description, rendering.NewMarkupContentFromMap(description.ToMap()))
+	assert.Equal(t, "<h1>Title</h1>\n<p><strong>important</strong></p>\n", rendering.RenderMarkupToHTML(description.Content, description.Markup))
+}
+
+func TestGitlabMapping(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	content, err := test.LoadTestData("gitlab_issue_mapping.json", func() ([]byte, error) {
+		return provideRemoteData("https://gitlab.com/api/v4/projects/almighty-test%2Falmighty-test-unit/issues/2")
+	})
+	require.Nil(t, err)
+	issue, err := NewGitlabRemoteWorkItem(TrackerItem{Item: string(content), RemoteItemID: "xyz"})
+	require.Nil(t, err)
+	// when
+	result, err := Map(issue, lookupWorkItemMap(t, ProviderGitlab))
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "map flatten : test case : with assignee", result.Fields[remoteTitle])
+	assert.Equal(t, rendering.NewMarkupContent("desc\n", rendering.SystemMarkupMarkdown), result.Fields[remoteDescription])
+	assert.Equal(t, workitem.SystemStateOpen, result.Fields[remoteState])
+	assert.Equal(t, "https://gitlab.com/almighty-test/almighty-test-unit/issues/2", result.Fields[remoteItemID])
+	assert.Equal(t, "sbose78", result.Fields[remoteCreatorLogin])
+	assert.Equal(t, "https://gitlab.com/sbose78", result.Fields[remoteCreatorProfileURL])
+	assert.Equal(t, []string{"sbose78"}, result.Fields[remoteAssigneeLogins])
+	assert.Equal(t, []string{"https://gitlab.com/sbose78"}, result.Fields[remoteAssigneeProfileURLs])
+}
+
+func TestBugzillaMapping(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	content, err := test.LoadTestData("bugzilla_bug_mapping.json", func() ([]byte, error) {
+		return provideRemoteData("https://bugzilla.redhat.com/rest/bug/1441249")
+	})
+	require.Nil(t, err)
+	bug, err := NewBugzillaRemoteWorkItem(TrackerItem{Item: string(content), RemoteItemID: "xyz"})
+	require.Nil(t, err)
+	// when
+	result, err := Map(bug, lookupWorkItemMap(t, ProviderBugzilla))
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "map flatten : test case : with assignee", result.Fields[remoteTitle])
+	assert.Equal(t, rendering.NewMarkupContent("Description of problem:\nsample desc\n", rendering.SystemMarkupPlainText), result.Fields[remoteDescription])
+	assert.Equal(t, workitem.SystemStateOpen, result.Fields[remoteState])
+	assert.Equal(t, "https://bugzilla.redhat.com/show_bug.cgi?id=1441249", result.Fields[remoteItemID])
+	assert.Equal(t, "jdoe@example.com", result.Fields[remoteCreatorLogin])
+	assert.Equal(t, "https://bugzilla.redhat.com/user_profile?login=jdoe%40example.com", result.Fields[remoteCreatorProfileURL])
+	assert.Equal(t, []string{"kernel-maint@example.com"}, result.Fields[remoteAssigneeLogins])
+	assert.Equal(t, []string{"https://bugzilla.redhat.com/user_profile?login=kernel-maint%40example.com"}, result.Fields[remoteAssigneeProfileURLs])
+}
+
+func TestGitlabStateConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	for state, expected := range map[string]interface{}{
+		"opened":   workitem.SystemStateOpen,
+		"reopened": workitem.SystemStateOpen,
+		"closed":   workitem.SystemStateClosed,
+		"locked":   "locked",
+	} {
+		// when
+		result, err := GitlabStateConverter{}.Convert(state, nil)
+		// then
+		require.Nil(t, err)
+		assert.Equal(t, expected, result, state)
+	}
+}
+
+func TestBugzillaStateConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	for status, expected := range map[string]interface{}{
+		"NEW":         workitem.SystemStateNew,
+		"ASSIGNED":    workitem.SystemStateOpen,
+		"IN_PROGRESS": workitem.SystemStateInProgress,
+		"VERIFIED":    workitem.SystemStateResolved,
+		"CLOSED":      workitem.SystemStateClosed,
+		"ON_QA":       "ON_QA",
+	} {
+		// when
+		result, err := BugzillaStateConverter{}.Convert(status, nil)
+		// then
+		require.Nil(t, err)
+		assert.Equal(t, expected, result, status)
+	}
+}
+
+func TestDateConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	expected := time.Date(2017, 4, 12, 9, 21, 2, 0, time.UTC)
+	for _, value := range []string{"2017-04-12T09:21:02Z", "2017-04-12T11:21:02.000+0200"} {
+		// when
+		result, err := DateConverter{}.Convert(value, nil)
+		// then
+		require.Nil(t, err, value)
+		require.IsType(t, time.Time{}, result, value)
+		assert.True(t, expected.Equal(result.(time.Time)), value)
+	}
+	result, err := DateConverter{}.Convert(nil, nil)
+	require.Nil(t, err)
+	assert.Nil(t, result)
+	_, err = DateConverter{}.Convert("yesterday", nil)
+	assert.NotNil(t, err)
+}
+
+func TestEnumConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	converter := EnumConverter{values: map[string]string{"Blocker": "P1", "3": "P3"}}
+	// when
+	result, err := converter.Convert("Blocker", nil)
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, "P1", result)
+	result, err = converter.Convert(float64(3), nil)
+	require.Nil(t, err)
+	assert.Equal(t, "P3", result)
+	_, err = converter.Convert("Trivial", nil)
+	assert.NotNil(t, err)
+}
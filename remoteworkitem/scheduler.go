@@ -1,8 +1,16 @@
 package remoteworkitem
 
 import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
 	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/workitem"
 
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
@@ -13,31 +21,75 @@ import (
 
 // TrackerSchedule capture all configuration
 type trackerSchedule struct {
-	TrackerID   int
-	URL         string
-	TrackerType string
-	Query       string
-	Schedule    string
-	SpaceID     uuid.UUID
+	TrackerID      int
+	URL            string
+	TrackerType    string
+	TrackerQueryID uint64
+	Query          string
+	Schedule       string
+	SyncPolicy     string
+	LastUpdatedAt  *time.Time
+	WorkItemTypeID *uuid.UUID
+	FieldMappings  FieldMappings
+	SpaceID        uuid.UUID
+}
+
+// workItemType returns the type of the work items created by the tracker query
+func (ts trackerSchedule) workItemType() uuid.UUID {
+	if ts.WorkItemTypeID == nil {
+		return workitem.SystemBug
+	}
+	return *ts.WorkItemTypeID
 }
 
 // Scheduler represents scheduler
 type Scheduler struct {
 	db *gorm.DB
+	// lock protects the cron table and the scheduled queries
+	lock sync.Mutex
+	cron *cron.Cron
+	// the scheduled tracker queries, by tracker query id
+	queries map[uint64]scheduledQuery
+	// the context and access tokens of the tracker queries scheduled by the reconciliation
+	ctx          context.Context
+	accessTokens map[string]string
+	// reconciling is set once the periodic reconciliation is started, done stops it
+	reconciling bool
+	done        chan struct{}
+}
+
+// scheduledQuery is the cron entry of a tracker query. The configuration of the tracker query
+// is loaded at each run, only its schedule is kept here.
+type scheduledQuery struct {
+	schedule     string
+	ctx          context.Context
+	accessTokens map[string]string
 }
 
-var cr *cron.Cron
+// trackerQueryRunLock is the namespace of the advisory locks taken by the tracker query runs
+const trackerQueryRunLock = "tracker_query_runs"
+
+// reconcileInterval is the time between two reconciliations of the scheduled tracker queries with
+// the database, which propagate the changes made through the other replicas of the server
+const reconcileInterval = time.Minute
 
 // NewScheduler creates a new Scheduler
 func NewScheduler(db *gorm.DB) *Scheduler {
-	s := Scheduler{db: db}
+	s := Scheduler{db: db, cron: cron.New(), queries: map[uint64]scheduledQuery{}, done: make(chan struct{})}
+	s.cron.Start()
 	return &s
 }
 
 // Stop scheduler
 // This should be called only from main
 func (s *Scheduler) Stop() {
-	cr.Stop()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cron.Stop()
+	if s.reconciling {
+		close(s.done)
+		s.reconciling = false
+	}
 }
 
 func batchID() string {
@@ -45,70 +97,379 @@ func batchID() string {
 	return u1
 }
 
-// ScheduleAllQueries fetch and import of remote tracker items
+// ScheduleAllQueries fetch and import of remote tracker items.
+// It replaces all the scheduled tracker queries with the ones currently in the database, then
+// periodically reconciles them with the database to apply the changes made on the other replicas.
 func (s *Scheduler) ScheduleAllQueries(ctx context.Context, accessTokens map[string]string) {
-	cr.Stop()
-
-	trackerQueries := fetchTrackerQueries(s.db)
-	for _, tq := range trackerQueries {
-		cr.AddFunc(tq.Schedule, func() {
-			tr := lookupProvider(tq)
-			authToken := accessTokens[tq.TrackerType]
-
-			// In case of Jira, no auth token is needed hence the map wouldnt
-			// return anything. So effectively the authToken is optional.
-
-			for i := range tr.Fetch(authToken) {
-				models.Transactional(s.db, func(tx *gorm.DB) error {
-					// Save the remote items in a 'temporary' table.
-					err := upload(tx, tq.TrackerID, i)
-					if err != nil {
-						return errors.WithStack(err)
-					}
-					// Convert the remote item into a local work item and persist in the DB.
-					_, err = convert(ctx, tx, tq.TrackerID, i, tq.TrackerType, tq.SpaceID)
-					return errors.WithStack(err)
-				})
-			}
-		})
+	s.lock.Lock()
+	s.ctx = ctx
+	s.accessTokens = accessTokens
+	s.queries = map[uint64]scheduledQuery{}
+	if !s.reconciling {
+		s.reconciling = true
+		go s.reconcileEvery(reconcileInterval, s.done)
+	}
+	s.lock.Unlock()
+	s.reconcile()
+}
+
+// reconcileEvery reconciles the scheduled tracker queries at the given interval until done is closed
+func (s *Scheduler) reconcileEvery(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.reconcile()
+		case <-done:
+			return
+		}
 	}
-	cr.Start()
 }
 
-func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
+// reconcile schedules the tracker queries of the database which are not scheduled yet or whose schedule
+// changed, and unschedules the ones which were deleted. The cron table is only rebuilt if something changed.
+func (s *Scheduler) reconcile() {
 	tsList := []trackerSchedule{}
-	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule, tracker_queries.space_id").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
-	if err != nil {
+	if err := trackerSchedules(s.db).Scan(&tsList).Error; err != nil {
+		// keep the current schedules rather than unscheduling everything
 		log.Error(nil, map[string]interface{}{
 			"err": err,
-		}, "fetch operation failed for tracker queries")
+		}, "unable to reconcile the scheduled tracker queries")
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	queries := map[uint64]scheduledQuery{}
+	changed := false
+	for _, tq := range tsList {
+		if _, err := cron.Parse(tq.Schedule); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"trackerQueryID": tq.TrackerQueryID,
+				"schedule":       tq.Schedule,
+				"err":            err,
+			}, "invalid schedule of the tracker query")
+			continue
+		}
+		if current, ok := s.queries[tq.TrackerQueryID]; ok && current.schedule == tq.Schedule {
+			queries[tq.TrackerQueryID] = current
+			continue
+		}
+		queries[tq.TrackerQueryID] = scheduledQuery{schedule: tq.Schedule, ctx: s.ctx, accessTokens: s.accessTokens}
+		changed = true
+	}
+	if !changed && len(queries) == len(s.queries) {
+		return
+	}
+	s.queries = queries
+	s.reload()
+}
+
+// ScheduleQuery adds the tracker query with the given id and schedule to the cron table,
+// or replaces its entry if it is already scheduled.
+// returns NotFoundError or BadParameterError
+func (s *Scheduler) ScheduleQuery(ctx context.Context, ID string, schedule string, accessTokens map[string]string) error {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return NotFoundError{"tracker query", ID}
+	}
+	if err := checkSchedule(schedule); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if current, ok := s.queries[id]; ok && current.schedule == schedule {
+		// the configuration of the tracker query is loaded at each run, nothing else to update
+		return nil
+	}
+	s.queries[id] = scheduledQuery{schedule: schedule, ctx: ctx, accessTokens: accessTokens}
+	s.reload()
+	return nil
+}
+
+// UnscheduleQuery removes the tracker query with the given id from the cron table.
+// It does nothing if the tracker query is not scheduled.
+func (s *Scheduler) UnscheduleQuery(ID string) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.queries[id]; !ok {
+		return
+	}
+	delete(s.queries, id)
+	s.reload()
+}
+
+// reload replaces the cron table with the scheduled queries.
+// The cron library doesn't support the removal of an entry, hence the whole table is rebuilt:
+// the runs in progress are not interrupted and the next run times only depend on the schedules.
+// The caller must hold the lock.
+func (s *Scheduler) reload() {
+	s.cron.Stop()
+	s.cron = cron.New()
+	for id, sq := range s.queries {
+		id, sq := id, sq
+		if err := s.cron.AddFunc(sq.schedule, func() {
+			s.runScheduled(sq.ctx, id, sq.accessTokens)
+		}); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"trackerQueryID": id,
+				"schedule":       sq.schedule,
+				"err":            err,
+			}, "unable to schedule the tracker query")
+		}
+	}
+	s.cron.Start()
+}
+
+// runScheduled runs the tracker query with the given id, unless it is already running on
+// this server or on another replica sharing the same database
+func (s *Scheduler) runScheduled(ctx context.Context, id uint64, accessTokens map[string]string) {
+	tx, locked, err := s.lockRun(id)
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"trackerQueryID": id,
+			"err":            err,
+		}, "unable to lock the tracker query run")
+		return
+	}
+	if !locked {
+		log.Info(nil, map[string]interface{}{
+			"trackerQueryID": id,
+		}, "the tracker query is already running")
+		return
+	}
+	defer tx.Commit()
+	tq, err := loadTrackerSchedule(s.db, id)
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"trackerQueryID": id,
+			"err":            err,
+		}, "unable to load the tracker query")
+		return
 	}
-	return tsList
+	run, err := s.startRun(*tq)
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"trackerQueryID": tq.TrackerQueryID,
+			"err":            err,
+		}, "unable to record the tracker query run")
+		return
+	}
+	s.run(ctx, *tq, run, accessTokens)
+}
+
+// lockRun takes the advisory lock of the given tracker query, so that a single server replica runs it at a time.
+// It returns false if the lock is held by another run. Otherwise the lock is held until the returned transaction ends.
+func (s *Scheduler) lockRun(trackerQueryID uint64) (*gorm.DB, bool, error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, false, errors.WithStack(tx.Error)
+	}
+	locked, err := gormsupport.TryAdvisoryXactLock(tx, trackerQueryRunLock, strconv.FormatUint(trackerQueryID, 10))
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+	if !locked {
+		tx.Rollback()
+		return nil, false, nil
+	}
+	return tx, true, nil
+}
+
+// RunQuery triggers an immediate import of the remote tracker items matching the tracker query with the given id.
+// The import is performed in the background and the returned run is still in progress.
+// The run fails if the tracker query is already running.
+// returns NotFoundError or InternalError
+func (s *Scheduler) RunQuery(ctx context.Context, ID string, accessTokens map[string]string) (*TrackerQueryRun, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	tq, err := loadTrackerSchedule(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.startRun(*tq)
+	if err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := *run
+	go func() {
+		tx, locked, err := s.lockRun(tq.TrackerQueryID)
+		switch {
+		case err != nil:
+			run.Errors = append(run.Errors, TrackerQueryRunError{Message: err.Error()})
+			s.endRun(*tq, run)
+		case !locked:
+			run.Errors = append(run.Errors, TrackerQueryRunError{Message: "the tracker query is already running"})
+			s.endRun(*tq, run)
+		default:
+			defer tx.Commit()
+			s.run(ctx, *tq, run, accessTokens)
+		}
+	}()
+	return &result, nil
+}
+
+// startRun records the beginning of a run of the given tracker query
+func (s *Scheduler) startRun(tq trackerSchedule) (*TrackerQueryRun, error) {
+	run := TrackerQueryRun{
+		TrackerQueryID: tq.TrackerQueryID,
+		Status:         TrackerQueryRunRunning,
+		StartedAt:      time.Now(),
+	}
+	if err := s.db.Create(&run).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &run, nil
+}
+
+// run fetches the remote tracker items matching the given tracker query, synchronizes them
+// with the local work items and records the outcome in the given run
+func (s *Scheduler) run(ctx context.Context, tq trackerSchedule, run *TrackerQueryRun, accessTokens map[string]string) {
+	tr := lookupProvider(tq)
+	if tr == nil {
+		run.Errors = append(run.Errors, TrackerQueryRunError{Message: fmt.Sprintf("unsupported tracker type: %s", tq.TrackerType)})
+	} else {
+		authToken := accessTokens[tq.TrackerType]
+
+		// In case of Jira, no auth token is needed hence the map wouldnt
+		// return anything. So effectively the authToken is optional.
+		// Without auth token, the local changes are not pushed to the remote tracker.
+		pusher := newPusher(tq.TrackerType, tq.URL, authToken)
+
+		// the high-water mark is the last update time of the items imported during this run, unless an item
+		// could not be imported or the fetch failed, in which case it must not go beyond the failed items
+		var lastUpdatedAt, firstFailedAt time.Time
+		fetchFailed := false
+		for i := range tr.Fetch(authToken) {
+			if i.Err != nil {
+				fetchFailed = true
+				run.Errors = append(run.Errors, TrackerQueryRunError{ItemID: i.ID, Message: i.Err.Error()})
+				continue
+			}
+			run.Fetched++
+			var workItem *app.WorkItem
+			err := models.Transactional(s.db, func(tx *gorm.DB) error {
+				// Merge the remote item with the local work item, push the local changes and
+				// save the remote item in a 'temporary' table as the base of the next synchronization.
+				var err error
+				workItem, err = synchronize(ctx, tx, tq, i, pusher)
+				return errors.WithStack(err)
+			})
+			switch {
+			case err != nil:
+				log.Error(nil, map[string]interface{}{
+					"trackerQueryID": tq.TrackerQueryID,
+					"itemID":         i.ID,
+					"err":            err,
+				}, "unable to import the remote tracker item")
+				run.Failed++
+				run.Errors = append(run.Errors, TrackerQueryRunError{ItemID: i.ID, Message: err.Error()})
+				if i.UpdatedAt.IsZero() {
+					fetchFailed = true
+				} else if firstFailedAt.IsZero() || i.UpdatedAt.Before(firstFailedAt) {
+					firstFailedAt = i.UpdatedAt
+				}
+				continue
+			case workItem.Version == 0:
+				// the version of a work item is incremented each time it is saved
+				run.Created++
+			default:
+				run.Updated++
+			}
+			if i.UpdatedAt.After(lastUpdatedAt) {
+				lastUpdatedAt = i.UpdatedAt
+			}
+		}
+		if !firstFailedAt.IsZero() && firstFailedAt.Before(lastUpdatedAt) {
+			lastUpdatedAt = firstFailedAt
+		}
+		if !fetchFailed && !lastUpdatedAt.IsZero() {
+			s.moveHighWaterMark(tq, lastUpdatedAt)
+		}
+	}
+	s.endRun(tq, run)
+}
+
+// endRun records the end of the given run of the tracker query
+func (s *Scheduler) endRun(tq trackerSchedule, run *TrackerQueryRun) {
+	now := time.Now()
+	run.EndedAt = &now
+	run.Status = TrackerQueryRunSucceeded
+	if len(run.Errors) > 0 {
+		run.Status = TrackerQueryRunFailed
+	}
+	if err := s.db.Save(run).Error; err != nil {
+		log.Error(nil, map[string]interface{}{
+			"trackerQueryID": tq.TrackerQueryID,
+			"runID":          run.ID,
+			"err":            err,
+		}, "unable to record the end of the tracker query run")
+	}
+}
+
+// moveHighWaterMark records the given time as the last update seen by the tracker query, if it is more recent
+// than the current one. The next runs only fetch the items updated since then.
+func (s *Scheduler) moveHighWaterMark(tq trackerSchedule, lastUpdatedAt time.Time) {
+	if tq.LastUpdatedAt != nil && !lastUpdatedAt.After(*tq.LastUpdatedAt) {
+		return
+	}
+	err := s.db.Table("tracker_queries").Where("id = ? AND (last_updated_at IS NULL OR last_updated_at < ?)", tq.TrackerQueryID, lastUpdatedAt).Update("last_updated_at", lastUpdatedAt).Error
+	if err != nil {
+		log.Error(nil, map[string]interface{}{
+			"trackerQueryID": tq.TrackerQueryID,
+			"err":            err,
+		}, "unable to record the high-water mark of the tracker query")
+	}
+}
+
+// trackerSchedules returns the query selecting the configuration of the tracker queries
+func trackerSchedules(db *gorm.DB) *gorm.DB {
+	return db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.id as tracker_query_id, tracker_queries.query, tracker_queries.schedule, tracker_queries.sync_policy, tracker_queries.last_updated_at, tracker_queries.work_item_type_id, tracker_queries.field_mappings, tracker_queries.space_id").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL")
+}
+
+// loadTrackerSchedule returns the configuration of the tracker query with the given id
+// returns NotFoundError or InternalError
+func loadTrackerSchedule(db *gorm.DB, id uint64) (*trackerSchedule, error) {
+	tsList := []trackerSchedule{}
+	if err := trackerSchedules(db).Where("tracker_queries.id = ?", id).Scan(&tsList).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	if len(tsList) == 0 {
+		return nil, NotFoundError{"tracker query", strconv.FormatUint(id, 10)}
+	}
+	return &tsList[0], nil
 }
 
 // lookupProvider provides the respective tracker based on the type
 func lookupProvider(ts trackerSchedule) TrackerProvider {
-	switch ts.TrackerType {
-	case ProviderGithub:
-		return &GithubTracker{URL: ts.URL, Query: ts.Query}
-	case ProviderJira:
-		return &JiraTracker{URL: ts.URL, Query: ts.Query}
+	p, ok := LookupProvider(ts.TrackerType)
+	if !ok {
+		return nil
 	}
-	return nil
+	return p.NewTracker(ts.URL, ts.Query, ts.LastUpdatedAt)
 }
 
 // TrackerItemContent represents a remote tracker item with it's content and unique ID
 type TrackerItemContent struct {
 	ID      string
 	Content []byte
+	// UpdatedAt is the last update time of the remote item, or a zero time if unknown
+	UpdatedAt time.Time
+	// Err is set if the fetch failed, in which case the content is empty and the ID is set
+	// only if the error concerns a single item
+	Err error
 }
 
 // TrackerProvider represents a remote tracker
 type TrackerProvider interface {
 	Fetch(authToken string) chan TrackerItemContent // TODO: Change to an interface to enforce the contract
 }
-
-func init() {
-	cr = cron.New()
-	cr.Start()
-}
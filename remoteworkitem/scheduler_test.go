@@ -5,7 +5,9 @@ import (
 
 	"github.com/almighty/almighty-core/resource"
 	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
 )
 
 func TestLookupProvider(t *testing.T) {
@@ -19,7 +21,42 @@ func TestLookupProvider(t *testing.T) {
 	tp2 := lookupProvider(ts2)
 	require.NotNil(t, tp2)
 
+	ts4 := trackerSchedule{TrackerType: ProviderGitlab}
+	tp4 := lookupProvider(ts4)
+	require.NotNil(t, tp4)
+
+	ts5 := trackerSchedule{TrackerType: ProviderBugzilla}
+	tp5 := lookupProvider(ts5)
+	require.NotNil(t, tp5)
+
 	ts3 := trackerSchedule{TrackerType: "unknown"}
 	tp3 := lookupProvider(ts3)
 	require.Nil(t, tp3)
 }
+
+func TestScheduleQuery(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	s := NewScheduler(nil)
+	defer s.Stop()
+	// when
+	err := s.ScheduleQuery(context.Background(), "1", "0 0 * * * *", map[string]string{})
+	require.Nil(t, err)
+	err = s.ScheduleQuery(context.Background(), "2", "@hourly", map[string]string{})
+	require.Nil(t, err)
+	// then
+	assert.Len(t, s.cron.Entries(), 2)
+	// replace
+	err = s.ScheduleQuery(context.Background(), "1", "0 30 * * * *", map[string]string{})
+	require.Nil(t, err)
+	assert.Len(t, s.cron.Entries(), 2)
+	assert.Equal(t, "0 30 * * * *", s.queries[1].schedule)
+	// remove
+	s.UnscheduleQuery("2")
+	s.UnscheduleQuery("3")
+	assert.Len(t, s.cron.Entries(), 1)
+	// invalid
+	assert.IsType(t, BadParameterError{}, s.ScheduleQuery(context.Background(), "1", "every hour", map[string]string{}))
+	assert.IsType(t, NotFoundError{}, s.ScheduleQuery(context.Background(), "abc", "@hourly", map[string]string{}))
+	assert.Equal(t, "0 30 * * * *", s.queries[1].schedule)
+}
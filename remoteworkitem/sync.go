@@ -0,0 +1,358 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+const (
+	// SyncPolicyRemoteWins applies the remote value of a field modified both locally and on the remote tracker
+	SyncPolicyRemoteWins = "remote-wins"
+	// SyncPolicyLocalWins pushes the local value of a field modified both locally and on the remote tracker
+	SyncPolicyLocalWins = "local-wins"
+	// SyncPolicyFlagConflict records a conflict for a field modified both locally and on the remote tracker,
+	// and stops synchronizing this field until the conflict is resolved
+	SyncPolicyFlagConflict = "flag-conflict"
+)
+
+// IsSyncPolicySupported returns true if the given synchronization policy is supported
+func IsSyncPolicySupported(policy string) bool {
+	switch policy {
+	case SyncPolicyRemoteWins, SyncPolicyLocalWins, SyncPolicyFlagConflict:
+		return true
+	}
+	return false
+}
+
+// syncedFields are the work item fields which are compared during the synchronization.
+// Only the title, state and assignees are pushed to the remote tracker, but the local
+// changes on the description are kept until the description changes remotely.
+var syncedFields = []string{workitem.SystemTitle, workitem.SystemDescription, workitem.SystemState, workitem.SystemAssignees}
+
+// mergeResult describes what to do with the synced fields of an imported work item
+type mergeResult struct {
+	// pull holds the fields to update with the remote values
+	pull map[string]bool
+	// push holds the local values to send to the remote tracker
+	push map[string]interface{}
+	// conflicts holds the new or updated conflicts
+	conflicts map[string]ConflictValues
+	// resolved holds the fields whose conflict was resolved
+	resolved []string
+}
+
+// mergeFields performs a three-way merge of the synced fields, using the values of the last synchronization as the
+// common ancestor. A field modified on a single side is pulled or pushed, a field modified on both sides is handled
+// according to the given policy. A field which is already in conflict is ignored until the local and the remote values
+// are equal again or until the local value is modified, in which case the local value is pushed.
+func mergeFields(base, local, remote map[string]interface{}, conflicts map[string]ConflictValues, policy string) mergeResult {
+	result := mergeResult{
+		pull:      map[string]bool{},
+		push:      map[string]interface{}{},
+		conflicts: map[string]ConflictValues{},
+		resolved:  []string{},
+	}
+	for _, field := range syncedFields {
+		b, l, r := base[field], local[field], remote[field]
+		if conflict, ok := conflicts[field]; ok {
+			switch {
+			case sameValue(l, r):
+				result.resolved = append(result.resolved, field)
+			case !sameValue(l, conflict.Local):
+				result.resolved = append(result.resolved, field)
+				result.push[field] = l
+			default:
+				conflict.Remote = r
+				result.conflicts[field] = conflict
+			}
+			continue
+		}
+		if sameValue(l, r) {
+			continue
+		}
+		localChanged, remoteChanged := !sameValue(l, b), !sameValue(r, b)
+		switch {
+		case !localChanged:
+			result.pull[field] = true
+		case !remoteChanged:
+			result.push[field] = l
+		case policy == SyncPolicyLocalWins:
+			result.push[field] = l
+		case policy == SyncPolicyFlagConflict:
+			result.conflicts[field] = ConflictValues{Base: b, Local: l, Remote: r}
+		default:
+			result.pull[field] = true
+		}
+	}
+	return result
+}
+
+// sameValue compares the JSON representations of the given values, considering a nil value and an empty list as equal
+func sameValue(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeValue(a), normalizeValue(b))
+}
+
+func normalizeValue(v interface{}) interface{} {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var result interface{}
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return v
+	}
+	if list, ok := result.([]interface{}); ok && len(list) == 0 {
+		return nil
+	}
+	return result
+}
+
+// remoteValues returns the values of the synced fields of the given remote work item,
+// with the logins of the assignees in place of the identities
+func remoteValues(remoteWorkItem RemoteWorkItem) map[string]interface{} {
+	result := map[string]interface{}{
+		workitem.SystemTitle:       remoteWorkItem.Fields[remoteTitle],
+		workitem.SystemDescription: remoteWorkItem.Fields[remoteDescription],
+		workitem.SystemState:       remoteWorkItem.Fields[remoteState],
+	}
+	var logins []string
+	if l, ok := remoteWorkItem.Fields[remoteAssigneeLogins].([]string); ok {
+		logins = append(logins, l...)
+	}
+	sort.Strings(logins)
+	result[workitem.SystemAssignees] = logins
+	return result
+}
+
+// localValues returns the values of the synced fields of the given work item, with the logins of the assignees
+// in place of the identities. Assignees whose identity does not belong to the given provider are ignored.
+func localValues(ctx context.Context, db *gorm.DB, wi app.WorkItem, providerType string) (map[string]interface{}, error) {
+	result := map[string]interface{}{
+		workitem.SystemTitle:       wi.Fields[workitem.SystemTitle],
+		workitem.SystemDescription: wi.Fields[workitem.SystemDescription],
+		workitem.SystemState:       wi.Fields[workitem.SystemState],
+	}
+	var ids []string
+	switch assignees := wi.Fields[workitem.SystemAssignees].(type) {
+	case []string:
+		ids = assignees
+	case []interface{}:
+		for _, a := range assignees {
+			if id, ok := a.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	identityRepository := account.NewIdentityRepository(db)
+	var logins []string
+	for _, id := range ids {
+		identityID, err := uuid.FromString(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid assignee ID: %s", id)
+		}
+		identity, err := identityRepository.Load(ctx, identityID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if identity.ProviderType == providerType {
+			logins = append(logins, identity.Username)
+		}
+	}
+	sort.Strings(logins)
+	result[workitem.SystemAssignees] = logins
+	return result, nil
+}
+
+// remoteChanges converts the local values to push into changes for the remote tracker
+func remoteChanges(push map[string]interface{}) RemoteChanges {
+	changes := RemoteChanges{}
+	if title, ok := push[workitem.SystemTitle].(string); ok {
+		changes.Title = &title
+	}
+	if state, ok := push[workitem.SystemState].(string); ok {
+		changes.State = &state
+	}
+	if value, ok := push[workitem.SystemAssignees]; ok {
+		logins, _ := value.([]string)
+		if logins == nil {
+			logins = []string{}
+		}
+		changes.Assignees = &logins
+	}
+	return changes
+}
+
+// synchronize merges the given remote item with the imported work item, using the content of the tracker item
+// stored during the previous synchronization as the common ancestor. The local changes are pushed to the remote
+// tracker with the given pusher (if not nil) along with the comments which were not pushed yet.
+// The remote item is imported as a new work item if it was never synchronized before.
+func synchronize(ctx context.Context, db *gorm.DB, tq trackerSchedule, item TrackerItemContent, pusher TrackerPusher) (*app.WorkItem, error) {
+	provider, ok := LookupProvider(tq.TrackerType)
+	if !ok {
+		return nil, BadParameterError{parameter: "tracker type", value: tq.TrackerType}
+	}
+	var baseItem TrackerItem
+	if db.Where("remote_item_id = ? AND tracker_id = ?", item.ID, tq.TrackerID).Find(&baseItem).RecordNotFound() {
+		return importItem(ctx, db, tq, item)
+	}
+	remoteWorkItem, err := mapTrackerItem(provider, tq, TrackerItem{Item: string(item.Content), RemoteItemID: item.ID, TrackerID: uint64(tq.TrackerID)})
+	if err != nil {
+		return nil, err
+	}
+	wir := workitem.NewWorkItemRepository(db)
+	remoteID := remoteWorkItem.Fields[remoteItemID]
+	existingWorkItem, err := wir.Fetch(ctx, criteria.Equals(criteria.Field(workitem.SystemRemoteItemID), criteria.Literal(remoteID)))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if existingWorkItem == nil {
+		return importItem(ctx, db, tq, item)
+	}
+	baseWorkItem, err := mapTrackerItem(provider, tq, baseItem)
+	if err != nil {
+		return nil, err
+	}
+	local, err := localValues(ctx, db, *existingWorkItem, tq.TrackerType)
+	if err != nil {
+		return nil, InternalError{simpleError{message: "Error loading the local values: " + err.Error()}}
+	}
+	var conflicts []TrackerItemConflict
+	if err := db.Where("tracker_item_id = ?", baseItem.ID).Find(&conflicts).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	openConflicts := map[string]ConflictValues{}
+	conflictIDs := map[string]uuid.UUID{}
+	for _, c := range conflicts {
+		openConflicts[c.Field] = c.Values
+		conflictIDs[c.Field] = c.ID
+	}
+	merge := mergeFields(remoteValues(baseWorkItem), local, remoteValues(remoteWorkItem), openConflicts, tq.SyncPolicy)
+
+	if pusher != nil {
+		itemURL, _ := remoteID.(string)
+		if changes := remoteChanges(merge.push); !changes.IsEmpty() {
+			if err := pusher.UpdateItem(itemURL, changes); err != nil {
+				// the local values will be pushed again during the next synchronization
+				log.Error(ctx, map[string]interface{}{
+					"remoteItemID": itemURL,
+					"err":          err,
+				}, "failed to push the local changes to the remote tracker")
+			}
+		}
+		if err := pushComments(ctx, db, pusher, baseItem.ID, existingWorkItem.ID, itemURL); err != nil {
+			return nil, err
+		}
+	}
+
+	// keep the local values of the synced fields which are not pulled
+	workItem, err := lookupIdentities(ctx, db, remoteWorkItem, tq.TrackerType, tq.SpaceID)
+	if err != nil {
+		return nil, InternalError{simpleError{message: "Error bind assignees: " + err.Error()}}
+	}
+	for _, field := range syncedFields {
+		if !merge.pull[field] {
+			delete(workItem.Fields, field)
+		}
+	}
+	result, err := upsert(ctx, db, *workItem)
+	if err != nil {
+		return nil, err
+	}
+	if err := upload(db, tq.TrackerID, item); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := saveConflicts(db, tq, baseItem.ID, result.ID, merge, conflictIDs); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importItem imports a remote item which was never synchronized before
+func importItem(ctx context.Context, db *gorm.DB, tq trackerSchedule, item TrackerItemContent) (*app.WorkItem, error) {
+	if err := upload(db, tq.TrackerID, item); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return convert(ctx, db, tq, item)
+}
+
+// pushComments adds the comments of the work item which were not pushed yet on the remote item
+func pushComments(ctx context.Context, db *gorm.DB, pusher TrackerPusher, trackerItemID uint64, workItemID string, itemURL string) error {
+	comments, _, err := comment.NewRepository(db).List(ctx, workItemID, nil, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var pushed []TrackerItemComment
+	if err := db.Where("tracker_item_id = ?", trackerItemID).Find(&pushed).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	pushedIDs := map[uuid.UUID]bool{}
+	for _, p := range pushed {
+		pushedIDs[p.CommentID] = true
+	}
+	for _, c := range comments {
+		if pushedIDs[c.ID] {
+			continue
+		}
+		if err := pusher.AddComment(itemURL, c.Body); err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"remoteItemID": itemURL,
+				"commentID":    c.ID,
+				"err":          err,
+			}, "failed to push the comment to the remote tracker")
+			continue
+		}
+		if err := db.Create(&TrackerItemComment{CommentID: c.ID, TrackerItemID: trackerItemID}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// saveConflicts stores the new and updated conflicts and deletes the resolved ones
+func saveConflicts(db *gorm.DB, tq trackerSchedule, trackerItemID uint64, workItemID string, merge mergeResult, conflictIDs map[string]uuid.UUID) error {
+	for _, field := range merge.resolved {
+		if err := db.Delete(&TrackerItemConflict{ID: conflictIDs[field]}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if len(merge.conflicts) == 0 {
+		return nil
+	}
+	wiID, err := strconv.ParseUint(workItemID, 10, 64)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for field, values := range merge.conflicts {
+		c := TrackerItemConflict{
+			ID:             conflictIDs[field],
+			TrackerQueryID: tq.TrackerQueryID,
+			TrackerItemID:  trackerItemID,
+			WorkItemID:     wiID,
+			Field:          field,
+			Values:         values,
+		}
+		if c.ID == uuid.Nil {
+			err = db.Create(&c).Error
+		} else {
+			err = db.Model(&c).Updates(map[string]interface{}{"values": values}).Error
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
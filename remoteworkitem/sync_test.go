@@ -0,0 +1,150 @@
+package remoteworkitem
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func syncValues(title, state string, assignees ...string) map[string]interface{} {
+	return map[string]interface{}{
+		workitem.SystemTitle:     title,
+		workitem.SystemState:     state,
+		workitem.SystemAssignees: assignees,
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	base := syncValues("title", "open", "alice")
+
+	t.Run("no change", func(t *testing.T) {
+		// when
+		result := mergeFields(base, syncValues("title", "open", "alice"), syncValues("title", "open", "alice"), nil, SyncPolicyRemoteWins)
+		// then
+		assert.Empty(t, result.pull)
+		assert.Empty(t, result.push)
+		assert.Empty(t, result.conflicts)
+	})
+
+	t.Run("remote change is pulled", func(t *testing.T) {
+		// when
+		result := mergeFields(base, syncValues("title", "open", "alice"), syncValues("remote title", "closed", "alice"), nil, SyncPolicyLocalWins)
+		// then
+		assert.Equal(t, map[string]bool{workitem.SystemTitle: true, workitem.SystemState: true}, result.pull)
+		assert.Empty(t, result.push)
+	})
+
+	t.Run("local change is pushed", func(t *testing.T) {
+		// when
+		result := mergeFields(base, syncValues("local title", "open", "bob"), syncValues("title", "open", "alice"), nil, SyncPolicyRemoteWins)
+		// then
+		assert.Empty(t, result.pull)
+		assert.Equal(t, map[string]interface{}{workitem.SystemTitle: "local title", workitem.SystemAssignees: []string{"bob"}}, result.push)
+	})
+
+	t.Run("same change on both sides", func(t *testing.T) {
+		// when
+		result := mergeFields(base, syncValues("new title", "open", "alice"), syncValues("new title", "open", "alice"), nil, SyncPolicyFlagConflict)
+		// then
+		assert.Empty(t, result.pull)
+		assert.Empty(t, result.push)
+		assert.Empty(t, result.conflicts)
+	})
+
+	local := syncValues("local title", "open", "alice")
+	remote := syncValues("remote title", "open", "alice")
+
+	t.Run("conflict with remote-wins policy", func(t *testing.T) {
+		// when
+		result := mergeFields(base, local, remote, nil, SyncPolicyRemoteWins)
+		// then
+		assert.Equal(t, map[string]bool{workitem.SystemTitle: true}, result.pull)
+		assert.Empty(t, result.push)
+		assert.Empty(t, result.conflicts)
+	})
+
+	t.Run("conflict with local-wins policy", func(t *testing.T) {
+		// when
+		result := mergeFields(base, local, remote, nil, SyncPolicyLocalWins)
+		// then
+		assert.Empty(t, result.pull)
+		assert.Equal(t, map[string]interface{}{workitem.SystemTitle: "local title"}, result.push)
+		assert.Empty(t, result.conflicts)
+	})
+
+	t.Run("conflict with flag-conflict policy", func(t *testing.T) {
+		// when
+		result := mergeFields(base, local, remote, nil, SyncPolicyFlagConflict)
+		// then
+		assert.Empty(t, result.pull)
+		assert.Empty(t, result.push)
+		require.Len(t, result.conflicts, 1)
+		assert.Equal(t, ConflictValues{Base: "title", Local: "local title", Remote: "remote title"}, result.conflicts[workitem.SystemTitle])
+	})
+
+	conflicts := map[string]ConflictValues{
+		workitem.SystemTitle: {Base: "title", Local: "local title", Remote: "remote title"},
+	}
+
+	t.Run("open conflict is kept", func(t *testing.T) {
+		// when
+		result := mergeFields(syncValues("remote title", "open", "alice"), local, syncValues("other remote title", "open", "alice"), conflicts, SyncPolicyFlagConflict)
+		// then
+		assert.Empty(t, result.pull)
+		assert.Empty(t, result.push)
+		assert.Empty(t, result.resolved)
+		assert.Equal(t, "other remote title", result.conflicts[workitem.SystemTitle].Remote)
+	})
+
+	t.Run("open conflict is resolved by equal values", func(t *testing.T) {
+		// when
+		result := mergeFields(syncValues("remote title", "open", "alice"), remote, remote, conflicts, SyncPolicyFlagConflict)
+		// then
+		assert.Empty(t, result.push)
+		assert.Empty(t, result.conflicts)
+		assert.Equal(t, []string{workitem.SystemTitle}, result.resolved)
+	})
+
+	t.Run("open conflict is resolved by a local change", func(t *testing.T) {
+		// when
+		result := mergeFields(syncValues("remote title", "open", "alice"), syncValues("final title", "open", "alice"), remote, conflicts, SyncPolicyFlagConflict)
+		// then
+		assert.Equal(t, map[string]interface{}{workitem.SystemTitle: "final title"}, result.push)
+		assert.Empty(t, result.conflicts)
+		assert.Equal(t, []string{workitem.SystemTitle}, result.resolved)
+	})
+}
+
+func TestSameValue(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.True(t, sameValue(nil, []string{}))
+	assert.True(t, sameValue([]string{"a"}, []interface{}{"a"}))
+	assert.True(t, sameValue(map[string]interface{}{"content": "foo", "markup": "Markdown"}, struct {
+		Content string `json:"content"`
+		Markup  string `json:"markup"`
+	}{"foo", "Markdown"}))
+	assert.False(t, sameValue("a", "b"))
+	assert.False(t, sameValue([]string{"a"}, []string{"a", "b"}))
+}
+
+func TestRemoteChanges(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// when
+	changes := remoteChanges(map[string]interface{}{
+		workitem.SystemTitle:       "title",
+		workitem.SystemDescription: "ignored",
+		workitem.SystemAssignees:   nil,
+	})
+	// then
+	require.NotNil(t, changes.Title)
+	assert.Equal(t, "title", *changes.Title)
+	assert.Nil(t, changes.State)
+	require.NotNil(t, changes.Assignees)
+	assert.Empty(t, *changes.Assignees)
+	assert.True(t, remoteChanges(map[string]interface{}{}).IsEmpty())
+}
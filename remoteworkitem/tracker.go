@@ -10,4 +10,7 @@ type Tracker struct {
 	URL string
 	// Type of the tracker (jira, github, bugzilla, trello etc.)
 	Type string
+	// WebhookSecret is the secret shared with the tracker to authenticate the events of its webhooks.
+	// The webhook events are rejected if it is empty.
+	WebhookSecret string
 }
@@ -34,7 +34,7 @@ func (r *GormTrackerRepository) Create(ctx context.Context, url string, typeID s
 		return nil, BadParameterError{parameter: "url", value: url}
 	}
 
-	_, present := RemoteWorkItemImplRegistry[typeID]
+	_, present := LookupProvider(typeID)
 	// Ensure we support this remote tracker.
 	if present != true {
 		return nil, BadParameterError{parameter: "type", value: typeID}
@@ -50,12 +50,7 @@ func (r *GormTrackerRepository) Create(ctx context.Context, url string, typeID s
 		"tracker": t,
 	}, "Tracker reposity created")
 
-	t2 := app.Tracker{
-		ID:   strconv.FormatUint(t.ID, 10),
-		URL:  url,
-		Type: typeID}
-
-	return &t2, nil
+	return convertTrackerToApp(t), nil
 }
 
 // Load returns the tracker configuration for the given id
@@ -83,12 +78,7 @@ func (r *GormTrackerRepository) Load(ctx context.Context, ID string) (*app.Track
 	if tx.Error != nil {
 		return nil, InternalError{simpleError{fmt.Sprintf("error while loading: %s", tx.Error.Error())}}
 	}
-	t := app.Tracker{
-		ID:   strconv.FormatUint(res.ID, 10),
-		URL:  res.URL,
-		Type: res.Type}
-
-	return &t, nil
+	return convertTrackerToApp(res), nil
 }
 
 // List returns tracker selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
@@ -116,11 +106,7 @@ func (r *GormTrackerRepository) List(ctx context.Context, criteria criteria.Expr
 	result := make([]*app.Tracker, len(rows))
 
 	for i, tracker := range rows {
-		t := app.Tracker{
-			ID:   strconv.FormatUint(tracker.ID, 10),
-			URL:  tracker.URL,
-			Type: tracker.Type}
-		result[i] = &t
+		result[i] = convertTrackerToApp(tracker)
 	}
 	return result, nil
 }
@@ -146,16 +132,17 @@ func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker) (*app.T
 
 		return nil, NotFoundError{entity: "tracker", ID: t.ID}
 	}
-	_, present := RemoteWorkItemImplRegistry[t.Type]
+	_, present := LookupProvider(t.Type)
 	// Ensure we support this remote tracker.
 	if present != true {
 		return nil, BadParameterError{parameter: "type", value: t.Type}
 	}
 
 	newT := Tracker{
-		ID:   id,
-		URL:  t.URL,
-		Type: t.Type}
+		ID:            id,
+		URL:           t.URL,
+		Type:          t.Type,
+		WebhookSecret: res.WebhookSecret}
 
 	if err := tx.Save(&newT).Error; err != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -169,12 +156,7 @@ func (r *GormTrackerRepository) Save(ctx context.Context, t app.Tracker) (*app.T
 		"tracker": newT.ID,
 	}, "Tracker repository successfully updated")
 
-	t2 := app.Tracker{
-		ID:   strconv.FormatUint(id, 10),
-		URL:  t.URL,
-		Type: t.Type}
-
-	return &t2, nil
+	return convertTrackerToApp(newT), nil
 }
 
 // Delete deletes the tracker with the given id
@@ -196,3 +178,31 @@ func (r *GormTrackerRepository) Delete(ctx context.Context, ID string) error {
 	}
 	return nil
 }
+
+// SetWebhookSecret sets the secret shared with the tracker with the given id to authenticate
+// the events of its webhooks. An empty secret disables the webhooks of the tracker.
+// returns NotFoundError or InternalError
+func (r *GormTrackerRepository) SetWebhookSecret(ctx context.Context, ID string, secret string) error {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		return NotFoundError{entity: "tracker", ID: ID}
+	}
+	tx := r.db.Model(&Tracker{}).Where("id = ?", id).Update("webhook_secret", secret)
+	if err := tx.Error; err != nil {
+		return InternalError{simpleError{err.Error()}}
+	}
+	if tx.RowsAffected == 0 {
+		return NotFoundError{entity: "tracker", ID: ID}
+	}
+	return nil
+}
+
+// convertTrackerToApp converts the given tracker into its REST representation, without its webhook secret
+func convertTrackerToApp(t Tracker) *app.Tracker {
+	return &app.Tracker{
+		ID:             strconv.FormatUint(t.ID, 10),
+		URL:            t.URL,
+		Type:           t.Type,
+		WebhookEnabled: t.WebhookSecret != "",
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/almighty/almighty-core/gormtestsupport"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -86,6 +87,34 @@ func (test *TestTrackerRepository) TestTrackerSave() {
 	assert.Nil(t, tracker2)
 }
 
+func (test *TestTrackerRepository) TestTrackerWebhookSecret() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	err := test.repo.SetWebhookSecret(context.Background(), "10000", "s3cr3t")
+	assert.IsType(t, NotFoundError{}, err)
+
+	tracker, _ := test.repo.Create(context.Background(), "http://api.github.com", ProviderGithub)
+	assert.False(t, tracker.WebhookEnabled)
+	err = test.repo.SetWebhookSecret(context.Background(), tracker.ID, "s3cr3t")
+	require.Nil(t, err)
+	tracker, err = test.repo.Load(context.Background(), tracker.ID)
+	require.Nil(t, err)
+	assert.True(t, tracker.WebhookEnabled)
+
+	// the secret is kept when the tracker is saved
+	tracker.URL = "http://api.github.com/api/v3"
+	tracker, err = test.repo.Save(context.Background(), *tracker)
+	require.Nil(t, err)
+	assert.True(t, tracker.WebhookEnabled)
+
+	err = test.repo.SetWebhookSecret(context.Background(), tracker.ID, "")
+	require.Nil(t, err)
+	tracker, err = test.repo.Load(context.Background(), tracker.ID)
+	require.Nil(t, err)
+	assert.False(t, tracker.WebhookEnabled)
+}
+
 func (test *TestTrackerRepository) TestTrackerDelete() {
 	t := test.T()
 	resource.Require(t, resource.Database)
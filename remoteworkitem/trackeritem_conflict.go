@@ -0,0 +1,75 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TrackerItemConflict represents a field of an imported work item which was modified
+// both locally and on the remote tracker while the tracker query uses the "flag-conflict" policy.
+// The field is not synchronized until the conflict is resolved.
+type TrackerItemConflict struct {
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// FK to the tracker query which imported the work item
+	TrackerQueryID uint64
+	// FK to the tracker item
+	TrackerItemID uint64
+	// FK to the work item
+	WorkItemID uint64
+	// the name of the work item field in conflict (e.g. "system.title")
+	Field string
+	// the conflicting values
+	Values ConflictValues `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (c TrackerItemConflict) TableName() string {
+	return "tracker_item_conflicts"
+}
+
+// ConflictValues holds the values of a field in conflict: the value of the last
+// synchronization (common ancestor), the local value and the remote value
+type ConflictValues struct {
+	Base   interface{} `json:"base"`
+	Local  interface{} `json:"local"`
+	Remote interface{} `json:"remote"`
+}
+
+// Value implements the driver.Valuer interface
+func (v ConflictValues) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+// Scan implements the sql.Scanner interface
+func (v *ConflictValues) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.Errorf("scan source was not []byte: %T", src)
+	}
+	return json.Unmarshal(b, v)
+}
+
+// TrackerItemComment records a local comment which was pushed to the remote tracker,
+// or a remote comment which was imported from the remote tracker
+type TrackerItemComment struct {
+	CommentID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	CreatedAt time.Time
+	// FK to the tracker item
+	TrackerItemID uint64
+	// the id of the comment on the remote tracker, only known for the imported comments
+	RemoteCommentID *string
+}
+
+// TableName implements gorm.tabler
+func (c TrackerItemComment) TableName() string {
+	return "tracker_item_comments"
+}
@@ -37,25 +37,21 @@ func upload(db *gorm.DB, tID int, item TrackerItemContent) error {
 }
 
 // Map a remote work item into an ALM work item and persist it into the database.
-func convert(ctx context.Context, db *gorm.DB, tID int, item TrackerItemContent, providerType string, spaceID uuid.UUID) (*app.WorkItem, error) {
+func convert(ctx context.Context, db *gorm.DB, tq trackerSchedule, item TrackerItemContent) (*app.WorkItem, error) {
 	remoteID := item.ID
 	content := string(item.Content)
-	trackerItem := TrackerItem{Item: content, RemoteItemID: remoteID, TrackerID: uint64(tID)}
+	trackerItem := TrackerItem{Item: content, RemoteItemID: remoteID, TrackerID: uint64(tq.TrackerID)}
 
 	// Converting the remote item to a local work item
-	remoteTrackerItemConvertFunc, ok := RemoteWorkItemImplRegistry[providerType]
+	provider, ok := LookupProvider(tq.TrackerType)
 	if !ok {
-		return nil, BadParameterError{parameter: providerType, value: providerType}
+		return nil, BadParameterError{parameter: tq.TrackerType, value: tq.TrackerType}
 	}
-	remoteTrackerItem, err := remoteTrackerItemConvertFunc(trackerItem)
+	remoteWorkItem, err := mapTrackerItem(provider, tq, trackerItem)
 	if err != nil {
-		return nil, InternalError{simpleError{message: fmt.Sprintf(" Error parsing the tracker data: %s", err.Error())}}
+		return nil, err
 	}
-	remoteWorkItem, err := Map(remoteTrackerItem, RemoteWorkItemKeyMaps[providerType])
-	if err != nil {
-		return nil, ConversionError{simpleError{message: fmt.Sprintf("Error mapping to local work item: %s", err.Error())}}
-	}
-	workItem, err := lookupIdentities(ctx, db, remoteWorkItem, providerType, spaceID)
+	workItem, err := lookupIdentities(ctx, db, remoteWorkItem, tq.TrackerType, tq.SpaceID)
 	if err != nil {
 		return nil, InternalError{simpleError{message: fmt.Sprintf("Error bind assignees: %s", err.Error())}}
 	}
@@ -63,6 +59,25 @@ func convert(ctx context.Context, db *gorm.DB, tID int, item TrackerItemContent,
 	return upsert(ctx, db, *workItem)
 }
 
+// mapTrackerItem maps the content of a tracker item to the work item fields, using the given provider
+// and the field mappings of the given tracker query
+func mapTrackerItem(provider Provider, tq trackerSchedule, trackerItem TrackerItem) (RemoteWorkItem, error) {
+	remoteTrackerItem, err := provider.NewAttributeAccessor(trackerItem)
+	if err != nil {
+		return RemoteWorkItem{}, InternalError{simpleError{message: fmt.Sprintf(" Error parsing the tracker data: %s", err.Error())}}
+	}
+	mapping, err := tq.FieldMappings.workItemMap(provider.WorkItemMap())
+	if err != nil {
+		return RemoteWorkItem{}, err
+	}
+	remoteWorkItem, err := Map(remoteTrackerItem, mapping)
+	if err != nil {
+		return RemoteWorkItem{}, ConversionError{simpleError{message: fmt.Sprintf("Error mapping to local work item: %s", err.Error())}}
+	}
+	remoteWorkItem.Type = tq.workItemType()
+	return remoteWorkItem, nil
+}
+
 // lookupIdentities looks up creator and assignee remote identities to local identities (already existing or to be created)
 func lookupIdentities(ctx context.Context, db *gorm.DB, remoteWorkItem RemoteWorkItem, providerType string, spaceID uuid.UUID) (*app.WorkItem, error) {
 	identityRepository := account.NewIdentityRepository(db)
@@ -159,7 +174,11 @@ func upsert(ctx context.Context, db *gorm.DB, workItem app.WorkItem) (*app.WorkI
 				return nil, errors.Wrapf(err, "Failed to convert creator id into a UUID: %s", err.Error())
 			}
 		}
-		resultWorkItem, err = wir.Create(ctx, *workItem.Relationships.Space.Data.ID, workitem.SystemBug, workItem.Fields, creator)
+		witID := workItem.Type
+		if witID == uuid.Nil {
+			witID = workitem.SystemBug
+		}
+		resultWorkItem, err = wir.Create(ctx, *workItem.Relationships.Space.Data.ID, witID, workItem.Fields, creator)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
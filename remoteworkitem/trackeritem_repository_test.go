@@ -8,6 +8,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/gormtestsupport"
 	"github.com/almighty/almighty-core/rendering"
@@ -48,6 +49,11 @@ func (s *TrackerItemRepositorySuite) SetupTest() {
 	s.ctx = goa.NewContext(context.Background(), nil, req, params)
 }
 
+// trackerSchedule returns the configuration of the tracker query used to convert the remote items
+func (s *TrackerItemRepositorySuite) trackerSchedule() trackerSchedule {
+	return trackerSchedule{TrackerID: int(s.trackerQuery.ID), TrackerType: ProviderGithub, SpaceID: s.trackerQuery.SpaceID}
+}
+
 func (s *TrackerItemRepositorySuite) createIdentity(username string) account.Identity {
 	identityRepo := account.NewIdentityRepository(s.DB)
 	profile := "https://api.github.com/users/" + username
@@ -106,7 +112,7 @@ func (s *TrackerItemRepositorySuite) TestConvertNewWorkItemWithExistingIdentitie
 	}
 
 	// when
-	workItem, err := convert(s.ctx, s.DB, int(s.trackerQuery.ID), remoteItemData, ProviderGithub, s.trackerQuery.SpaceID)
+	workItem, err := convert(s.ctx, s.DB, s.trackerSchedule(), remoteItemData)
 	// then
 	require.Nil(s.T(), err)
 	require.NotNil(s.T(), workItem.Fields)
@@ -149,7 +155,7 @@ func (s *TrackerItemRepositorySuite) TestConvertNewWorkItemWithUnknownIdentities
 	}
 
 	// when
-	workItem, err := convert(s.ctx, s.DB, int(s.trackerQuery.ID), remoteItemData, ProviderGithub, s.trackerQuery.SpaceID)
+	workItem, err := convert(s.ctx, s.DB, s.trackerSchedule(), remoteItemData)
 	// then
 	require.Nil(s.T(), err)
 	require.NotNil(s.T(), workItem.Fields)
@@ -195,7 +201,7 @@ func (s *TrackerItemRepositorySuite) TestConvertNewWorkItemWithNoAssignee() {
 		ID: "http://github.com/sbose/api/testonly/1",
 	}
 	// when
-	workItem, err := convert(s.ctx, s.DB, int(s.trackerQuery.ID), remoteItemData, ProviderGithub, s.trackerQuery.SpaceID)
+	workItem, err := convert(s.ctx, s.DB, s.trackerSchedule(), remoteItemData)
 	// then
 	require.Nil(s.T(), err)
 	require.NotNil(s.T(), workItem.Fields)
@@ -235,7 +241,7 @@ func (s *TrackerItemRepositorySuite) TestConvertExistingWorkItem() {
 		ID: "http://github.com/sbose/api/testonly/1",
 	}
 	// when
-	workItem, err := convert(s.ctx, s.DB, int(s.trackerQuery.ID), remoteItemData, ProviderGithub, s.trackerQuery.SpaceID)
+	workItem, err := convert(s.ctx, s.DB, s.trackerSchedule(), remoteItemData)
 	// then
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), "linking", workItem.Fields[workitem.SystemTitle])
@@ -264,7 +270,7 @@ func (s *TrackerItemRepositorySuite) TestConvertExistingWorkItem() {
 		ID: "http://github.com/sbose/api/testonly/1",
 	}
 	// when
-	workItemUpdated, err := convert(s.ctx, s.DB, int(s.trackerQuery.ID), remoteItemDataUpdated, ProviderGithub, s.trackerQuery.SpaceID)
+	workItemUpdated, err := convert(s.ctx, s.DB, s.trackerSchedule(), remoteItemDataUpdated)
 	// then
 	assert.Nil(s.T(), err)
 	require.NotNil(s.T(), workItemUpdated)
@@ -288,7 +294,7 @@ func (s *TrackerItemRepositorySuite) TestConvertGithubIssue() {
 		ID:      GitIssueWithAssignee, // GH issue url
 	}
 	// when
-	workItemGithub, err := convert(s.ctx, s.DB, int(s.trackerQuery.ID), remoteItemDataGithub, ProviderGithub, s.trackerQuery.SpaceID)
+	workItemGithub, err := convert(s.ctx, s.DB, s.trackerSchedule(), remoteItemDataGithub)
 	// then
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), "map flatten : test case : with assignee", workItemGithub.Fields[workitem.SystemTitle])
@@ -296,3 +302,44 @@ func (s *TrackerItemRepositorySuite) TestConvertGithubIssue() {
 	assert.Equal(s.T(), identity.ID.String(), workItemGithub.Fields[workitem.SystemAssignees].([]interface{})[0])
 	assert.Equal(s.T(), "open", workItemGithub.Fields[workitem.SystemState])
 }
+
+func (s *TrackerItemRepositorySuite) TestConvertWithFieldMappings() {
+	// given
+	storyPoints := app.FieldDefinition{Type: &app.FieldType{Kind: string(workitem.KindFloat)}}
+	wit, err := workitem.NewWorkItemTypeRepository(s.DB).Create(s.ctx, space.SystemSpace, nil, &workitem.SystemPlannerItem, "story", nil, "fa-bomb", map[string]app.FieldDefinition{"storypoints": storyPoints})
+	require.Nil(s.T(), err)
+	tq := s.trackerSchedule()
+	tq.WorkItemTypeID = wit.Data.ID
+	tq.FieldMappings = FieldMappings{
+		{Expression: "comments", Field: "storypoints", Converter: FieldConverterValue},
+		{Expression: "state", Field: workitem.SystemState, Converter: FieldConverterEnum, Values: map[string]string{"open": workitem.SystemStateInProgress}},
+	}
+	err = checkFieldMappings(s.ctx, s.DB, *tq.WorkItemTypeID, tq.FieldMappings)
+	require.Nil(s.T(), err)
+	remoteItemData := TrackerItemContent{
+		Content: []byte(`{"title": "story", "url": "http://github.com/sbose/api/testonly/4", "state": "open", "body": "body of story", "comments": 3}`),
+		ID:      "http://github.com/sbose/api/testonly/4",
+	}
+	// when
+	workItem, err := convert(s.ctx, s.DB, tq, remoteItemData)
+	// then
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), *wit.Data.ID, workItem.Type)
+	assert.Equal(s.T(), "story", workItem.Fields[workitem.SystemTitle])
+	assert.Equal(s.T(), float64(3), workItem.Fields["storypoints"])
+	assert.Equal(s.T(), workitem.SystemStateInProgress, workItem.Fields[workitem.SystemState])
+}
+
+func (s *TrackerItemRepositorySuite) TestCheckFieldMappings() {
+	// given
+	mappings := FieldMappings{{Expression: "comments", Field: "storypoints", Converter: FieldConverterValue}}
+	// when
+	err := checkFieldMappings(s.ctx, s.DB, workitem.SystemBug, mappings)
+	// then the field doesn't exist in the work item type
+	assert.IsType(s.T(), BadParameterError{}, err)
+	err = checkFieldMappings(s.ctx, s.DB, uuid.NewV4(), FieldMappings{})
+	assert.IsType(s.T(), BadParameterError{}, err)
+	mappings = FieldMappings{{Expression: "title", Field: workitem.SystemTitle, Converter: "number"}}
+	err = checkFieldMappings(s.ctx, s.DB, workitem.SystemBug, mappings)
+	assert.IsType(s.T(), BadParameterError{}, err)
+}
@@ -1,7 +1,10 @@
 package remoteworkitem
 
 import (
+	"time"
+
 	"github.com/almighty/almighty-core/gormsupport"
+	"github.com/almighty/almighty-core/workitem"
 
 	uuid "github.com/satori/go.uuid"
 )
@@ -14,8 +17,25 @@ type TrackerQuery struct {
 	Query string
 	// Schedule to fetch and import remote tracker items
 	Schedule string
+	// SyncPolicy applies to the fields modified both locally and on the remote tracker
+	SyncPolicy string
+	// LastUpdatedAt is the high-water mark of the tracker query: the last update time of the remote items
+	// imported so far. Only the items updated since then are fetched.
+	LastUpdatedAt *time.Time
+	// WorkItemTypeID is the type of the work items created by the tracker query, "bug" if nil
+	WorkItemTypeID *uuid.UUID `sql:"type:uuid"`
+	// FieldMappings override the mapping of the remote attributes to the work item fields defined by the provider
+	FieldMappings FieldMappings `sql:"type:jsonb"`
 	// TrackerID is a foreign key for a tracker
 	TrackerID uint64 `gorm:"ForeignKey:Tracker"`
 	// SpaceID is a foreign key for a space
 	SpaceID uuid.UUID `gorm:"ForeignKey:Space"`
 }
+
+// workItemType returns the type of the work items created by the tracker query
+func (tq TrackerQuery) workItemType() uuid.UUID {
+	if tq.WorkItemTypeID == nil {
+		return workitem.SystemBug
+	}
+	return *tq.WorkItemTypeID
+}
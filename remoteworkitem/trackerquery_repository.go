@@ -12,6 +12,7 @@ import (
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
+	"github.com/robfig/cron"
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
 )
@@ -26,25 +27,39 @@ func NewTrackerQueryRepository(db *gorm.DB) *GormTrackerQueryRepository {
 	return &GormTrackerQueryRepository{db}
 }
 
-// Create creates a new tracker query in the repository
+// Create creates a new tracker query in the repository. An empty sync policy defaults to "remote-wins",
+// a nil work item type defaults to "bug".
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, tracker string, spaceID uuid.UUID) (*app.TrackerQuery, error) {
+func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, tracker string, syncPolicy string, workItemTypeID *uuid.UUID, fieldMappings []*app.TrackerQueryFieldMapping, spaceID uuid.UUID) (*app.TrackerQuery, error) {
 	tid, err := strconv.ParseUint(tracker, 10, 64)
 	if err != nil || tid == 0 {
 		// treating this as a not found error: the fact that we're using number internal is implementation detail
 		return nil, NotFoundError{"tracker", tracker}
 	}
+	if err := checkSchedule(schedule); err != nil {
+		return nil, err
+	}
+	syncPolicy, err = checkSyncPolicy(syncPolicy)
+	if err != nil {
+		return nil, err
+	}
+	tq := TrackerQuery{
+		Query:          query,
+		Schedule:       schedule,
+		SyncPolicy:     syncPolicy,
+		WorkItemTypeID: workItemTypeID,
+		FieldMappings:  convertFieldMappingsFromApp(fieldMappings),
+		TrackerID:      tid,
+		SpaceID:        spaceID,
+	}
+	if err := checkFieldMappings(ctx, r.db, tq.workItemType(), tq.FieldMappings); err != nil {
+		return nil, err
+	}
 
 	log.Info(ctx, map[string]interface{}{
 		"trackerID": tid,
 	}, "Tracker ID to be created")
 
-	tq := TrackerQuery{
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: tid,
-		SpaceID:   spaceID,
-	}
 	tx := r.db
 	if err := tx.Create(&tq).Error; err != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -56,10 +71,13 @@ func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, s
 
 	spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx), app.SpaceHref(spaceID.String()))
 	tq2 := app.TrackerQuery{
-		ID:        strconv.FormatUint(tq.ID, 10),
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: tracker,
+		ID:             strconv.FormatUint(tq.ID, 10),
+		Query:          query,
+		Schedule:       schedule,
+		SyncPolicy:     syncPolicy,
+		WorkItemTypeID: workItemTypeID,
+		FieldMappings:  convertFieldMappingsToApp(tq.FieldMappings),
+		TrackerID:      tracker,
 		Relationships: &app.TrackerQueryRelationships{
 			Space: space.NewSpaceRelation(spaceID, spaceSelfURL),
 		},
@@ -96,10 +114,14 @@ func (r *GormTrackerQueryRepository) Load(ctx context.Context, ID string) (*app.
 
 	spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx), app.SpaceHref(res.SpaceID.String()))
 	tq := app.TrackerQuery{
-		ID:        strconv.FormatUint(res.ID, 10),
-		Query:     res.Query,
-		Schedule:  res.Schedule,
-		TrackerID: strconv.FormatUint(res.TrackerID, 10),
+		ID:             strconv.FormatUint(res.ID, 10),
+		Query:          res.Query,
+		Schedule:       res.Schedule,
+		SyncPolicy:     res.SyncPolicy,
+		LastUpdatedAt:  res.LastUpdatedAt,
+		WorkItemTypeID: res.WorkItemTypeID,
+		FieldMappings:  convertFieldMappingsToApp(res.FieldMappings),
+		TrackerID:      strconv.FormatUint(res.TrackerID, 10),
 		Relationships: &app.TrackerQueryRelationships{
 			Space: space.NewSpaceRelation(res.SpaceID, spaceSelfURL),
 		},
@@ -109,7 +131,7 @@ func (r *GormTrackerQueryRepository) Load(ctx context.Context, ID string) (*app.
 }
 
 // Save updates the given tracker query in storage.
-// returns NotFoundError, ConversionError or InternalError
+// returns NotFoundError, BadParameterError, ConversionError or InternalError
 func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQuery) (*app.TrackerQuery, error) {
 	res := TrackerQuery{}
 	id, err := strconv.ParseUint(tq.ID, 10, 64)
@@ -138,6 +160,17 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 	if tx.Error != nil {
 		return nil, InternalError{simpleError{fmt.Sprintf("could not load tracker query: %s", tx.Error.Error())}}
 	}
+	if err := checkSchedule(tq.Schedule); err != nil {
+		return nil, err
+	}
+	// keep the current sync policy if none is given
+	syncPolicy := tq.SyncPolicy
+	if syncPolicy == "" {
+		syncPolicy = res.SyncPolicy
+	}
+	if syncPolicy, err = checkSyncPolicy(syncPolicy); err != nil {
+		return nil, err
+	}
 
 	tx = r.db.First(&Tracker{}, tid)
 	if tx.RecordNotFound() {
@@ -151,11 +184,28 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 	}
 
 	newTq := TrackerQuery{
-		ID:        id,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tid,
-		SpaceID:   *tq.Relationships.Space.Data.ID,
+		ID:             id,
+		Schedule:       tq.Schedule,
+		Query:          tq.Query,
+		SyncPolicy:     syncPolicy,
+		WorkItemTypeID: res.WorkItemTypeID,
+		FieldMappings:  res.FieldMappings,
+		TrackerID:      tid,
+		SpaceID:        *tq.Relationships.Space.Data.ID,
+	}
+	// keep the current work item type and field mappings if none are given
+	if tq.WorkItemTypeID != nil {
+		newTq.WorkItemTypeID = tq.WorkItemTypeID
+	}
+	if tq.FieldMappings != nil {
+		newTq.FieldMappings = convertFieldMappingsFromApp(tq.FieldMappings)
+	}
+	if err := checkFieldMappings(ctx, r.db, newTq.workItemType(), newTq.FieldMappings); err != nil {
+		return nil, err
+	}
+	// the items of another query or tracker must be fetched from the beginning
+	if res.Query == tq.Query && res.TrackerID == tid {
+		newTq.LastUpdatedAt = res.LastUpdatedAt
 	}
 
 	if err := tx.Save(&newTq).Error; err != nil {
@@ -173,10 +223,14 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 
 	spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx), app.SpaceHref(tq.Relationships.Space.Data.ID.String()))
 	t2 := app.TrackerQuery{
-		ID:        tq.ID,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tq.TrackerID,
+		ID:             tq.ID,
+		Schedule:       tq.Schedule,
+		Query:          tq.Query,
+		SyncPolicy:     syncPolicy,
+		LastUpdatedAt:  newTq.LastUpdatedAt,
+		WorkItemTypeID: newTq.WorkItemTypeID,
+		FieldMappings:  convertFieldMappingsToApp(newTq.FieldMappings),
+		TrackerID:      tq.TrackerID,
 		Relationships: &app.TrackerQueryRelationships{
 			Space: space.NewSpaceRelation(*tq.Relationships.Space.Data.ID, spaceSelfURL),
 		},
@@ -217,10 +271,14 @@ func (r *GormTrackerQueryRepository) List(ctx context.Context) ([]*app.TrackerQu
 	for i, tq := range rows {
 		spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx), app.SpaceHref(tq.SpaceID.String()))
 		t := app.TrackerQuery{
-			ID:        strconv.FormatUint(tq.ID, 10),
-			Schedule:  tq.Schedule,
-			Query:     tq.Query,
-			TrackerID: strconv.FormatUint(tq.TrackerID, 10),
+			ID:             strconv.FormatUint(tq.ID, 10),
+			Schedule:       tq.Schedule,
+			Query:          tq.Query,
+			SyncPolicy:     tq.SyncPolicy,
+			LastUpdatedAt:  tq.LastUpdatedAt,
+			WorkItemTypeID: tq.WorkItemTypeID,
+			FieldMappings:  convertFieldMappingsToApp(tq.FieldMappings),
+			TrackerID:      strconv.FormatUint(tq.TrackerID, 10),
 			Relationships: &app.TrackerQueryRelationships{
 				Space: space.NewSpaceRelation(tq.SpaceID, spaceSelfURL),
 			},
@@ -229,3 +287,132 @@ func (r *GormTrackerQueryRepository) List(ctx context.Context) ([]*app.TrackerQu
 	}
 	return result, nil
 }
+
+// ListConflicts returns the fields in conflict of the work items imported by the tracker query with the given id
+// returns NotFoundError or InternalError
+func (r *GormTrackerQueryRepository) ListConflicts(ctx context.Context, ID string) ([]*app.TrackerItemConflict, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	if r.db.First(&TrackerQuery{}, id).RecordNotFound() {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	var rows []TrackerItemConflict
+	if err := r.db.Where("tracker_query_id = ?", id).Order("updated_at desc").Find(&rows).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := make([]*app.TrackerItemConflict, len(rows))
+	for i, c := range rows {
+		result[i] = &app.TrackerItemConflict{
+			ID:          c.ID,
+			WorkItemID:  strconv.FormatUint(c.WorkItemID, 10),
+			Field:       c.Field,
+			BaseValue:   c.Values.Base,
+			LocalValue:  c.Values.Local,
+			RemoteValue: c.Values.Remote,
+			UpdatedAt:   c.UpdatedAt,
+		}
+	}
+	return result, nil
+}
+
+// ListRuns returns the most recent runs of the tracker query with the given id, starting with the latest one
+// returns NotFoundError or InternalError
+func (r *GormTrackerQueryRepository) ListRuns(ctx context.Context, ID string, limit int) ([]*app.TrackerQueryRun, error) {
+	id, err := strconv.ParseUint(ID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	if r.db.First(&TrackerQuery{}, id).RecordNotFound() {
+		return nil, NotFoundError{"tracker query", ID}
+	}
+	var rows []TrackerQueryRun
+	if err := r.db.Where("tracker_query_id = ?", id).Order("started_at desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, InternalError{simpleError{err.Error()}}
+	}
+	result := make([]*app.TrackerQueryRun, len(rows))
+	for i, run := range rows {
+		result[i] = ConvertTrackerQueryRunToApp(run)
+	}
+	return result, nil
+}
+
+// ConvertTrackerQueryRunToApp converts a tracker query run into its REST representation
+func ConvertTrackerQueryRunToApp(run TrackerQueryRun) *app.TrackerQueryRun {
+	result := app.TrackerQueryRun{
+		ID:        run.ID,
+		Status:    run.Status,
+		StartedAt: run.StartedAt,
+		EndedAt:   run.EndedAt,
+		Fetched:   run.Fetched,
+		Created:   run.Created,
+		Updated:   run.Updated,
+		Failed:    run.Failed,
+	}
+	for _, e := range run.Errors {
+		runError := app.TrackerQueryRunError{Message: e.Message}
+		if e.ItemID != "" {
+			itemID := e.ItemID
+			runError.ItemID = &itemID
+		}
+		result.Errors = append(result.Errors, &runError)
+	}
+	return &result
+}
+
+// convertFieldMappingsFromApp converts the field mappings of the REST API into their model
+func convertFieldMappingsFromApp(mappings []*app.TrackerQueryFieldMapping) FieldMappings {
+	if mappings == nil {
+		return nil
+	}
+	result := make(FieldMappings, len(mappings))
+	for i, m := range mappings {
+		result[i] = FieldMapping{
+			Expression: m.Expression,
+			Field:      m.Field,
+			Converter:  m.Converter,
+			Values:     m.Values,
+		}
+	}
+	return result
+}
+
+// convertFieldMappingsToApp converts the field mappings of a tracker query for the REST API
+func convertFieldMappingsToApp(mappings FieldMappings) []*app.TrackerQueryFieldMapping {
+	if mappings == nil {
+		return nil
+	}
+	result := make([]*app.TrackerQueryFieldMapping, len(mappings))
+	for i, m := range mappings {
+		result[i] = &app.TrackerQueryFieldMapping{
+			Expression: m.Expression,
+			Field:      m.Field,
+			Converter:  m.Converter,
+			Values:     m.Values,
+		}
+	}
+	return result
+}
+
+// checkSchedule returns BadParameterError if the given schedule is not a valid cron expression
+func checkSchedule(schedule string) error {
+	if _, err := cron.Parse(schedule); err != nil {
+		return BadParameterError{parameter: "schedule", value: schedule}
+	}
+	return nil
+}
+
+// checkSyncPolicy returns the given sync policy, or the default one if empty
+// returns BadParameterError if the policy is not supported
+func checkSyncPolicy(syncPolicy string) (string, error) {
+	if syncPolicy == "" {
+		return SyncPolicyRemoteWins, nil
+	}
+	if !IsSyncPolicySupported(syncPolicy) {
+		return "", BadParameterError{parameter: "syncPolicy", value: syncPolicy}
+	}
+	return syncPolicy, nil
+}
@@ -71,7 +71,7 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailDeleteZeroID() {
 		s.ctx,
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID, space.SystemSpace)
+		tr.ID, remoteworkitem.SyncPolicyRemoteWins, space.SystemSpace)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -94,7 +94,7 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailSaveZeroID() {
 		s.ctx,
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID, space.SystemSpace)
+		tr.ID, remoteworkitem.SyncPolicyRemoteWins, space.SystemSpace)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -118,7 +118,7 @@ func (s *trackerQueryRepoBlackBoxTest) TestFaiLoadZeroID() {
 		s.ctx,
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		tr.ID, space.SystemSpace)
+		tr.ID, remoteworkitem.SyncPolicyRemoteWins, space.SystemSpace)
 	if err != nil {
 		s.T().Error("Could not create tracker query", err)
 	}
@@ -126,3 +126,25 @@ func (s *trackerQueryRepoBlackBoxTest) TestFaiLoadZeroID() {
 	_, err = s.repo.Load(s.ctx, "0")
 	require.IsType(s.T(), remoteworkitem.NotFoundError{}, err)
 }
+
+func (s *trackerQueryRepoBlackBoxTest) TestFailCreateUnknownSyncPolicy() {
+	tr, err := s.trRepo.Create(
+		s.ctx,
+		"http://api.github.com",
+		remoteworkitem.ProviderGithub)
+	if err != nil {
+		s.T().Error("Could not create tracker", err)
+	}
+
+	_, err = s.repo.Create(
+		s.ctx,
+		"project = ARQ AND text ~ 'arquillian'",
+		"15 * * * * *",
+		tr.ID, "whoever-shouts-loudest", space.SystemSpace)
+	require.IsType(s.T(), remoteworkitem.BadParameterError{}, err)
+}
+
+func (s *trackerQueryRepoBlackBoxTest) TestFailListConflictsZeroID() {
+	_, err := s.repo.ListConflicts(s.ctx, "0")
+	require.IsType(s.T(), remoteworkitem.NotFoundError{}, err)
+}
@@ -3,8 +3,11 @@ package remoteworkitem
 import (
 	"net/http"
 	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/application"
 	"golang.org/x/net/context"
 
@@ -12,9 +15,12 @@ import (
 	"github.com/almighty/almighty-core/gormtestsupport"
 	"github.com/almighty/almighty-core/resource"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
 
 	"github.com/goadesign/goa"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -50,19 +56,37 @@ func (test *TestTrackerQueryRepository) TestTrackerQueryCreate() {
 	params := url.Values{}
 	ctx := goa.NewContext(context.Background(), nil, req, params)
 
-	query, err := test.queryRepo.Create(ctx, "abc", "xyz", "lmn", space.SystemSpace)
+	query, err := test.queryRepo.Create(ctx, "abc", "xyz", "lmn", SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
 	assert.IsType(t, NotFoundError{}, err)
 	assert.Nil(t, query)
 
 	tracker, err := test.trackerRepo.Create(ctx, "http://issues.jboss.com", ProviderJira)
-	query, err = test.queryRepo.Create(ctx, "abc", "xyz", tracker.ID, space.SystemSpace)
+	query, err = test.queryRepo.Create(ctx, "abc", "xyz", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
+	assert.IsType(t, BadParameterError{}, err)
+	assert.Nil(t, query)
+
+	query, err = test.queryRepo.Create(ctx, "abc", "0 0 * * * *", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
 	assert.Nil(t, err)
 	assert.Equal(t, "abc", query.Query)
-	assert.Equal(t, "xyz", query.Schedule)
+	assert.Equal(t, "0 0 * * * *", query.Schedule)
 
 	query2, err := test.queryRepo.Load(ctx, query.ID)
 	assert.Nil(t, err)
 	assert.Equal(t, query, query2)
+
+	// field mappings targeting an unknown field of the work item type
+	storyPoints := []*app.TrackerQueryFieldMapping{{Expression: "fields.customfield_10002", Field: "storypoints", Converter: FieldConverterValue}}
+	query, err = test.queryRepo.Create(ctx, "abc", "0 0 * * * *", tracker.ID, SyncPolicyRemoteWins, &workitem.SystemBug, storyPoints, space.SystemSpace)
+	assert.IsType(t, BadParameterError{}, err)
+	assert.Nil(t, query)
+
+	title := []*app.TrackerQueryFieldMapping{{Expression: "fields.summary", Field: workitem.SystemTitle, Converter: FieldConverterValue}}
+	query, err = test.queryRepo.Create(ctx, "abc", "0 0 * * * *", tracker.ID, SyncPolicyRemoteWins, &workitem.SystemBug, title, space.SystemSpace)
+	require.Nil(t, err)
+	require.NotNil(t, query.WorkItemTypeID)
+	assert.Equal(t, workitem.SystemBug, *query.WorkItemTypeID)
+	require.Len(t, query.FieldMappings, 1)
+	assert.Equal(t, workitem.SystemTitle, query.FieldMappings[0].Field)
 }
 
 func (test *TestTrackerQueryRepository) TestTrackerQuerySave() {
@@ -79,13 +103,20 @@ func (test *TestTrackerQueryRepository) TestTrackerQuerySave() {
 
 	tracker, err := test.trackerRepo.Create(ctx, "http://issues.jboss.com", ProviderJira)
 	tracker2, err := test.trackerRepo.Create(ctx, "http://api.github.com", ProviderGithub)
-	query, err = test.queryRepo.Create(ctx, "abc", "xyz", tracker.ID, space.SystemSpace)
+	query, err = test.queryRepo.Create(ctx, "abc", "0 0 * * * *", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
 	query2, err := test.queryRepo.Load(ctx, query.ID)
 	assert.Nil(t, err)
 	assert.Equal(t, query, query2)
 
-	query.Query = "after"
+	assert.Equal(t, SyncPolicyRemoteWins, query.SyncPolicy)
+
 	query.Schedule = "the"
+	_, err = test.queryRepo.Save(ctx, *query)
+	assert.IsType(t, BadParameterError{}, err)
+
+	query.Query = "after"
+	query.Schedule = "@hourly"
+	query.SyncPolicy = SyncPolicyFlagConflict
 	query.TrackerID = tracker2.ID
 	if err != nil {
 		t.Errorf("could not convert id: %s", tracker2.ID)
@@ -115,7 +146,7 @@ func (test *TestTrackerQueryRepository) TestTrackerQueryDelete() {
 	assert.IsType(t, NotFoundError{}, err)
 
 	tracker, _ := test.trackerRepo.Create(ctx, "http://api.github.com", ProviderGithub)
-	tq, _ := test.queryRepo.Create(ctx, "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID, space.SystemSpace)
+	tq, _ := test.queryRepo.Create(ctx, "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
 	err = test.queryRepo.Delete(ctx, tq.ID)
 	assert.Nil(t, err)
 
@@ -139,15 +170,158 @@ func (test *TestTrackerQueryRepository) TestTrackerQueryList() {
 	trackerqueries1, _ := test.queryRepo.List(ctx)
 
 	tracker1, _ := test.trackerRepo.Create(ctx, "http://api.github.com", ProviderGithub)
-	test.queryRepo.Create(ctx, "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, space.SystemSpace)
-	test.queryRepo.Create(ctx, "is:close is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, space.SystemSpace)
+	test.queryRepo.Create(ctx, "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
+	test.queryRepo.Create(ctx, "is:close is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker1.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
 
 	tracker2, _ := test.trackerRepo.Create(ctx, "http://issues.jboss.com", ProviderJira)
-	test.queryRepo.Create(ctx, "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID, space.SystemSpace)
-	test.queryRepo.Create(ctx, "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID, space.SystemSpace)
+	test.queryRepo.Create(ctx, "project = ARQ AND text ~ 'arquillian'", "15 * * * * *", tracker2.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
+	test.queryRepo.Create(ctx, "project = ARQ AND text ~ 'javadoc'", "15 * * * * *", tracker2.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
 
 	trackerqueries2, _ := test.queryRepo.List(ctx)
 	assert.Equal(t, len(trackerqueries1)+4, len(trackerqueries2))
 	trackerqueries3, _ := test.queryRepo.List(ctx)
 	assert.Equal(t, trackerqueries2[1], trackerqueries3[1])
 }
+
+// failingTracker fetches a single item which can't be converted into a work item
+type failingTracker struct{}
+
+func (f failingTracker) Fetch(authToken string) chan TrackerItemContent {
+	item := make(chan TrackerItemContent, 1)
+	item <- TrackerItemContent{ID: `"1"`, Content: []byte(`{}`)}
+	close(item)
+	return item
+}
+
+func (test *TestTrackerQueryRepository) TestTrackerQueryRunLock() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	// given
+	s := NewScheduler(test.DB)
+	tx, locked, err := s.lockRun(1)
+	require.Nil(t, err)
+	require.True(t, locked)
+	// when
+	_, lockedAgain, err := s.lockRun(1)
+	// then
+	require.Nil(t, err)
+	assert.False(t, lockedAgain)
+	tx2, lockedOther, err := s.lockRun(2)
+	require.Nil(t, err)
+	assert.True(t, lockedOther)
+	tx2.Commit()
+	// the ids are not truncated
+	tx2, lockedOther, err = s.lockRun(1<<32 + 1)
+	require.Nil(t, err)
+	assert.True(t, lockedOther)
+	tx2.Commit()
+	// the lock is released at the end of the transaction
+	tx.Commit()
+	tx, locked, err = s.lockRun(1)
+	require.Nil(t, err)
+	assert.True(t, locked)
+	tx.Commit()
+}
+
+func (test *TestTrackerQueryRepository) TestReconcileScheduledQueries() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	req := &http.Request{Host: "localhost"}
+	params := url.Values{}
+	ctx := goa.NewContext(context.Background(), nil, req, params)
+
+	// given a scheduler which doesn't serve the changes of the tracker queries
+	s := NewScheduler(test.DB)
+	defer s.Stop()
+	s.ScheduleAllQueries(ctx, map[string]string{})
+	tracker, err := test.trackerRepo.Create(ctx, "http://api.github.com", ProviderGithub)
+	require.Nil(t, err)
+	query, err := test.queryRepo.Create(ctx, "is:open is:issue user:arquillian", "0 0 * * * *", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
+	require.Nil(t, err)
+	queryID, err := strconv.ParseUint(query.ID, 10, 64)
+	require.Nil(t, err)
+	require.NotContains(t, s.queries, queryID)
+	// when
+	s.reconcile()
+	// then
+	require.Contains(t, s.queries, queryID)
+	assert.Equal(t, "0 0 * * * *", s.queries[queryID].schedule)
+
+	// when the schedule changes
+	query.Schedule = "0 30 * * * *"
+	_, err = test.queryRepo.Save(ctx, *query)
+	require.Nil(t, err)
+	s.reconcile()
+	// then
+	require.Contains(t, s.queries, queryID)
+	assert.Equal(t, "0 30 * * * *", s.queries[queryID].schedule)
+
+	// when the tracker query is deleted
+	require.Nil(t, test.queryRepo.Delete(ctx, query.ID))
+	s.reconcile()
+	// then
+	assert.NotContains(t, s.queries, queryID)
+}
+
+func (test *TestTrackerQueryRepository) TestTrackerQueryRuns() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+
+	req := &http.Request{Host: "localhost"}
+	params := url.Values{}
+	ctx := goa.NewContext(context.Background(), nil, req, params)
+
+	// given
+	RegisterProvider(Provider{
+		Type: "failing",
+		NewTracker: func(url, query string, since *time.Time) TrackerProvider {
+			return failingTracker{}
+		},
+		NewAttributeAccessor: func(TrackerItem) (AttributeAccessor, error) {
+			return nil, errors.New("unexpected content")
+		},
+	})
+	tracker, err := test.trackerRepo.Create(ctx, "http://api.github.com", ProviderGithub)
+	require.Nil(t, err)
+	query, err := test.queryRepo.Create(ctx, "is:open is:issue user:arquillian author:aslakknutsen", "15 * * * * *", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
+	require.Nil(t, err)
+	queryID, err := strconv.ParseUint(query.ID, 10, 64)
+	require.Nil(t, err)
+	runs, err := test.queryRepo.ListRuns(ctx, query.ID, 10)
+	require.Nil(t, err)
+	assert.Empty(t, runs)
+	// when
+	s := NewScheduler(test.DB)
+	for _, trackerType := range []string{"unknown", "failing"} {
+		tq := trackerSchedule{TrackerQueryID: queryID, TrackerType: trackerType, SpaceID: space.SystemSpace}
+		run, err := s.startRun(tq)
+		require.Nil(t, err)
+		s.run(ctx, tq, run, map[string]string{})
+	}
+	// then
+	runs, err = test.queryRepo.ListRuns(ctx, query.ID, 10)
+	require.Nil(t, err)
+	require.Len(t, runs, 2)
+	// latest run first
+	assert.Equal(t, TrackerQueryRunFailed, runs[0].Status)
+	assert.NotNil(t, runs[0].EndedAt)
+	assert.Equal(t, 1, runs[0].Fetched)
+	assert.Equal(t, 1, runs[0].Failed)
+	assert.Equal(t, 0, runs[0].Created)
+	require.Len(t, runs[0].Errors, 1)
+	require.NotNil(t, runs[0].Errors[0].ItemID)
+	assert.Equal(t, `"1"`, *runs[0].Errors[0].ItemID)
+	assert.Equal(t, TrackerQueryRunFailed, runs[1].Status)
+	assert.Equal(t, 0, runs[1].Fetched)
+	require.Len(t, runs[1].Errors, 1)
+	assert.Nil(t, runs[1].Errors[0].ItemID)
+	// limit
+	runs, err = test.queryRepo.ListRuns(ctx, query.ID, 1)
+	require.Nil(t, err)
+	assert.Len(t, runs, 1)
+	// unknown tracker query
+	_, err = test.queryRepo.ListRuns(ctx, "100000", 10)
+	assert.IsType(t, NotFoundError{}, err)
+}
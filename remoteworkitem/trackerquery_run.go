@@ -0,0 +1,76 @@
+package remoteworkitem
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// TrackerQueryRunRunning is the status of a run which is not finished yet
+	TrackerQueryRunRunning = "running"
+	// TrackerQueryRunSucceeded is the status of a run which imported all the fetched items
+	TrackerQueryRunSucceeded = "succeeded"
+	// TrackerQueryRunFailed is the status of a run which could not start or failed to import some of the fetched items
+	TrackerQueryRunFailed = "failed"
+)
+
+// TrackerQueryRun represents an execution of a tracker query, either scheduled or manually triggered
+type TrackerQueryRun struct {
+	ID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	// FK to the tracker query
+	TrackerQueryID uint64
+	// Status is "running", "succeeded" or "failed"
+	Status    string
+	StartedAt time.Time
+	// EndedAt is nil while the run is in progress
+	EndedAt *time.Time
+	// the number of items fetched from the remote tracker
+	Fetched int
+	// the number of work items created
+	Created int
+	// the number of work items updated
+	Updated int
+	// the number of items which could not be imported
+	Failed int
+	// the errors which occurred during the run
+	Errors TrackerQueryRunErrors `sql:"type:jsonb"`
+}
+
+// TableName implements gorm.tabler
+func (r TrackerQueryRun) TableName() string {
+	return "tracker_query_runs"
+}
+
+// TrackerQueryRunError describes an error which occurred during a run.
+// The item ID is empty if the error is not related to a specific item.
+type TrackerQueryRunError struct {
+	ItemID  string `json:"itemID,omitempty"`
+	Message string `json:"message"`
+}
+
+// TrackerQueryRunErrors is the list of errors of a run
+type TrackerQueryRunErrors []TrackerQueryRunError
+
+// Value implements the driver.Valuer interface
+func (e TrackerQueryRunErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+// Scan implements the sql.Scanner interface
+func (e *TrackerQueryRunErrors) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.Errorf("scan source was not []byte: %T", src)
+	}
+	return json.Unmarshal(b, e)
+}
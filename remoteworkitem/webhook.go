@@ -0,0 +1,176 @@
+package remoteworkitem
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/models"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// WebhookEvent is an event sent by the webhook of a remote tracker about one of its items
+type WebhookEvent struct {
+	// Item is the remote item, in the same form as the items fetched by the tracker queries
+	Item TrackerItemContent
+	// Comment is the remote comment added or edited on the item, if any
+	Comment *RemoteComment
+}
+
+// RemoteComment is a comment of a remote item
+type RemoteComment struct {
+	// ID is the id of the comment on the remote tracker
+	ID                string
+	Body              string
+	Markup            string
+	CreatorLogin      string
+	CreatorProfileURL string
+}
+
+// WebhookReceiver verifies and decodes the events sent by the webhooks of the trackers of a provider
+type WebhookReceiver interface {
+	// Verify returns true if the given request was sent by a webhook knowing the given secret
+	Verify(req *http.Request, body []byte, secret string) bool
+	// Parse decodes the event of the given request. It returns nil if the event is not about an item.
+	Parse(req *http.Request, body []byte) (*WebhookEvent, error)
+}
+
+// checkHMAC verifies that the given signature is the hex encoded HMAC of the body with the given secret,
+// prefixed with the given prefix (e.g. "sha256=")
+func checkHMAC(h func() hash.Hash, prefix, signature string, body []byte, secret string) bool {
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ReceiveWebhook verifies the given request sent by the webhook of the tracker with the given id and
+// imports the remote item of its event immediately, along with its comment. The item is synchronized
+// with the configuration of the first tracker query of the tracker, as if it had been fetched by this query.
+// The local changes are not pushed to the remote tracker, they are pushed during the next run of the query.
+// returns NotFoundError, BadParameterError, UnauthorizedError or InternalError
+func (s *Scheduler) ReceiveWebhook(ctx context.Context, trackerID string, req *http.Request, body []byte) error {
+	id, err := strconv.ParseUint(trackerID, 10, 64)
+	if err != nil || id == 0 {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return NotFoundError{"tracker", trackerID}
+	}
+	t := Tracker{}
+	tx := s.db.First(&t, id)
+	if tx.RecordNotFound() {
+		return NotFoundError{"tracker", trackerID}
+	}
+	if tx.Error != nil {
+		return InternalError{simpleError{tx.Error.Error()}}
+	}
+	provider, ok := LookupProvider(t.Type)
+	if !ok || provider.Webhook == nil {
+		return BadParameterError{parameter: "type", value: t.Type}
+	}
+	if t.WebhookSecret == "" || !provider.Webhook.Verify(req, body, t.WebhookSecret) {
+		return UnauthorizedError{simpleError{"the webhook event could not be authenticated"}}
+	}
+	event, err := provider.Webhook.Parse(req, body)
+	if err != nil {
+		return BadParameterError{parameter: "event", value: err.Error()}
+	}
+	if event == nil {
+		return nil
+	}
+	tsList := []trackerSchedule{}
+	if err := trackerSchedules(s.db).Where("trackers.id = ?", id).Order("tracker_queries.id").Limit(1).Scan(&tsList).Error; err != nil {
+		return InternalError{simpleError{err.Error()}}
+	}
+	if len(tsList) == 0 {
+		log.Info(ctx, map[string]interface{}{
+			"trackerID": id,
+			"itemID":    event.Item.ID,
+		}, "the tracker has no tracker query, the webhook event is ignored")
+		return nil
+	}
+	tq := tsList[0]
+	err = models.Transactional(s.db, func(tx *gorm.DB) error {
+		workItem, err := synchronize(ctx, tx, tq, event.Item, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if event.Comment != nil {
+			return importComment(ctx, tx, tq, event.Item.ID, workItem.ID, *event.Comment)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"trackerID": id,
+			"itemID":    event.Item.ID,
+			"err":       err,
+		}, "unable to import the remote item of the webhook event")
+		if _, ok := errors.Cause(err).(ConversionError); ok {
+			return BadParameterError{parameter: "item", value: event.Item.ID}
+		}
+		return InternalError{simpleError{err.Error()}}
+	}
+	return nil
+}
+
+// importComment creates the given remote comment on the work item imported from the remote item with the given id,
+// or updates its body if it was already imported. The imported comments are not pushed back to the remote tracker.
+func importComment(ctx context.Context, db *gorm.DB, tq trackerSchedule, remoteItemID string, workItemID string, c RemoteComment) error {
+	var trackerItem TrackerItem
+	if err := db.Where("remote_item_id = ? AND tracker_id = ?", remoteItemID, tq.TrackerID).First(&trackerItem).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	creator := uuid.Nil
+	if c.CreatorLogin != "" {
+		identity, err := account.NewIdentityRepository(db).Lookup(ctx, c.CreatorLogin, c.CreatorProfileURL, tq.TrackerType)
+		if err != nil {
+			return errors.Wrap(err, "failed to create identity during lookup")
+		}
+		creator = identity.ID
+	}
+	commentRepository := comment.NewRepository(db)
+	var imported TrackerItemComment
+	tx := db.Where("tracker_item_id = ? AND remote_comment_id = ?", trackerItem.ID, c.ID).First(&imported)
+	if tx.Error != nil && !tx.RecordNotFound() {
+		return errors.WithStack(tx.Error)
+	}
+	if !tx.RecordNotFound() {
+		existing, err := commentRepository.Load(ctx, imported.CommentID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if existing.Body == c.Body {
+			return nil
+		}
+		existing.Body = c.Body
+		existing.Markup = c.Markup
+		return errors.WithStack(commentRepository.Save(ctx, existing, creator))
+	}
+	newComment := comment.Comment{
+		ParentID:  workItemID,
+		CreatedBy: creator,
+		Body:      c.Body,
+		Markup:    c.Markup,
+	}
+	if err := commentRepository.Create(ctx, &newComment, creator); err != nil {
+		return errors.WithStack(err)
+	}
+	remoteCommentID := c.ID
+	return errors.WithStack(db.Create(&TrackerItemComment{CommentID: newComment.ID, TrackerItemID: trackerItem.ID, RemoteCommentID: &remoteCommentID}).Error)
+}
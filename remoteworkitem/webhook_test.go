@@ -0,0 +1,115 @@
+package remoteworkitem
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/almighty/almighty-core/comment"
+	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/net/context"
+)
+
+func TestRunWebhookSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &webhookSuite{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+type webhookSuite struct {
+	gormtestsupport.DBTestSuite
+	clean func()
+	ctx   context.Context
+}
+
+func (s *webhookSuite) SetupTest() {
+	s.clean = cleaner.DeleteCreatedEntities(s.DB)
+	req := &http.Request{Host: "localhost"}
+	s.ctx = goa.NewContext(context.Background(), nil, req, url.Values{})
+}
+
+func (s *webhookSuite) TearDownTest() {
+	s.clean()
+}
+
+// newGithubEvent returns a request holding the given Github event, signed with the given secret
+func (s *webhookSuite) newGithubEvent(eventType string, body []byte, secret string) *http.Request {
+	req, err := http.NewRequest("POST", "/api/trackers/1/webhook", bytes.NewReader(body))
+	require.Nil(s.T(), err)
+	req.Header.Set("X-GitHub-Event", eventType)
+	req.Header.Set("X-Hub-Signature-256", githubSignature(body, secret))
+	return req
+}
+
+func (s *webhookSuite) TestReceiveGithubIssueComment() {
+	t := s.T()
+	// given
+	trackerRepo := NewTrackerRepository(s.DB)
+	tracker, err := trackerRepo.Create(s.ctx, "https://api.github.com/", ProviderGithub)
+	require.Nil(t, err)
+	require.Nil(t, trackerRepo.SetWebhookSecret(s.ctx, tracker.ID, "s3cr3t"))
+	_, err = NewTrackerQueryRepository(s.DB).Create(s.ctx, "is:open is:issue user:almighty-test", "@hourly", tracker.ID, SyncPolicyRemoteWins, nil, nil, space.SystemSpace)
+	require.Nil(t, err)
+	scheduler := NewScheduler(s.DB)
+	defer scheduler.Stop()
+	body := []byte(githubIssueCommentEvent)
+	// when
+	err = scheduler.ReceiveWebhook(s.ctx, tracker.ID, s.newGithubEvent("issue_comment", body, "s3cr3t"), body)
+	// then
+	require.Nil(t, err)
+	wi, err := workitem.NewWorkItemRepository(s.DB).Fetch(s.ctx, criteria.Equals(criteria.Field(workitem.SystemRemoteItemID), criteria.Literal("https://api.github.com/repos/almighty-test/almighty-test-unit/issues/3")))
+	require.Nil(t, err)
+	require.NotNil(t, wi)
+	assert.Equal(t, "a webhook issue", wi.Fields[workitem.SystemTitle])
+	comments, _, err := comment.NewRepository(s.DB).List(s.ctx, wi.ID, nil, nil)
+	require.Nil(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "a remote comment", comments[0].Body)
+	// the same event sent again doesn't duplicate the comment
+	err = scheduler.ReceiveWebhook(s.ctx, tracker.ID, s.newGithubEvent("issue_comment", body, "s3cr3t"), body)
+	require.Nil(t, err)
+	comments, _, err = comment.NewRepository(s.DB).List(s.ctx, wi.ID, nil, nil)
+	require.Nil(t, err)
+	assert.Len(t, comments, 1)
+	// the imported comments are not pushed back
+	var pushed []TrackerItemComment
+	require.Nil(t, s.DB.Where("comment_id = ?", comments[0].ID).Find(&pushed).Error)
+	require.Len(t, pushed, 1)
+	require.NotNil(t, pushed[0].RemoteCommentID)
+	assert.Equal(t, "42", *pushed[0].RemoteCommentID)
+}
+
+func (s *webhookSuite) TestReceiveWebhookRejected() {
+	t := s.T()
+	// given
+	trackerRepo := NewTrackerRepository(s.DB)
+	tracker, err := trackerRepo.Create(s.ctx, "https://api.github.com/", ProviderGithub)
+	require.Nil(t, err)
+	scheduler := NewScheduler(s.DB)
+	defer scheduler.Stop()
+	body := []byte(githubIssueCommentEvent)
+	// when the webhooks of the tracker are disabled
+	err = scheduler.ReceiveWebhook(s.ctx, tracker.ID, s.newGithubEvent("issue_comment", body, ""), body)
+	// then
+	assert.IsType(t, UnauthorizedError{}, err)
+	// invalid signature
+	require.Nil(t, trackerRepo.SetWebhookSecret(s.ctx, tracker.ID, "s3cr3t"))
+	err = scheduler.ReceiveWebhook(s.ctx, tracker.ID, s.newGithubEvent("issue_comment", body, "other"), body)
+	assert.IsType(t, UnauthorizedError{}, err)
+	// unknown tracker
+	err = scheduler.ReceiveWebhook(s.ctx, "100000", s.newGithubEvent("issue_comment", body, "s3cr3t"), body)
+	assert.IsType(t, NotFoundError{}, err)
+	// a tracker without tracker query ignores the events
+	err = scheduler.ReceiveWebhook(s.ctx, tracker.ID, s.newGithubEvent("issue_comment", body, "s3cr3t"), body)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,650 @@
+package rendering
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	asciidocSectionPattern        = regexp.MustCompile(`^(={1,6})\s+(.*)$`)
+	asciidocListItemPattern       = regexp.MustCompile(`^(\*+|-|\.+|[0-9]+\.)\s+(.*)$`)
+	asciidocAdmonitionPattern     = regexp.MustCompile(`^(NOTE|TIP|IMPORTANT|WARNING|CAUTION):\s+(.*)$`)
+	asciidocDelimiterPattern      = regexp.MustCompile(`^(-{4,}|\.{4,}|_{4,}|={4,}|\*{4,}|\+{4,}|/{4,}|\|===)$`)
+	asciidocAnchorPattern         = regexp.MustCompile(`^\[\[([A-Za-z_][\w:.-]*)(?:,\s*([^\]]*))?\]\]`)
+	asciidocAttributeListPattern  = regexp.MustCompile(`^\[([^\[\]]*)\]$`)
+	asciidocAttributeEntryPattern = regexp.MustCompile(`^:!?[\w-]+!?:`)
+	asciidocBlockTitlePattern     = regexp.MustCompile(`^\.([^\s.].*)$`)
+	asciidocShorthandPattern      = regexp.MustCompile(`[#%.][^#%.]*`)
+	asciidocXrefPattern           = regexp.MustCompile(`^<<([A-Za-z_][\w:.-]*)(?:,\s*([^>]*))?>>`)
+	asciidocMacroPattern          = regexp.MustCompile(`^(link|mailto|xref):([^\s\[\]]+)\[([^\]]*)\]`)
+	asciidocURLPattern            = regexp.MustCompile(`^(https?|ftp)://[^\s\[\]<>"]+`)
+)
+
+// asciidocAdmonitions maps the admonition styles to their labels
+var asciidocAdmonitions = map[string]string{
+	"NOTE":      "Note",
+	"TIP":       "Tip",
+	"IMPORTANT": "Important",
+	"WARNING":   "Warning",
+	"CAUTION":   "Caution",
+}
+
+// asciidocEffects maps the markers of the AsciiDoc text effects to the HTML elements they
+// are rendered with. Constrained markers only apply to whole words.
+var asciidocEffects = []struct {
+	marker      string
+	element     string
+	constrained bool
+}{
+	{"**", "strong", false},
+	{"__", "em", false},
+	{"*", "strong", true},
+	{"_", "em", true},
+	{"^", "sup", false},
+	{"~", "sub", false},
+}
+
+// asciidocAttributes holds the attributes which apply to the next block
+type asciidocAttributes struct {
+	id       string
+	reftext  string
+	title    string
+	style    string
+	language string
+	header   bool
+	cols     int
+}
+
+// asciidocRenderer renders AsciiDoc documents
+type asciidocRenderer struct {
+	// titles maps the IDs of the sections and anchors to the (HTML) text of the cross references to them
+	titles map[string]string
+	// ids holds the IDs in use, to keep the generated IDs unique
+	ids map[string]bool
+}
+
+// AsciiDocToHTML converts the given AsciiDoc content to HTML. Sections, lists, admonitions,
+// listing, literal, source, quote, example and sidebar blocks, tables, links, cross references
+// and text formatting are supported, other markup is rendered as text.
+// The result is not sanitized, see RenderMarkupToHTML.
+func AsciiDocToHTML(input []byte) []byte {
+	lines := strings.Split(strings.Replace(string(input), "\r\n", "\n", -1), "\n")
+	r := &asciidocRenderer{titles: map[string]string{}, ids: map[string]bool{}}
+	// the first pass collects the titles of the sections, which are the default text
+	// of the cross references to them, including the ones which precede the sections
+	r.renderBlocks(&bytes.Buffer{}, lines)
+	r.ids = map[string]bool{}
+	var out bytes.Buffer
+	r.renderBlocks(&out, lines)
+	return out.Bytes()
+}
+
+// renderBlocks renders the given lines as a sequence of blocks
+func (r *asciidocRenderer) renderBlocks(out *bytes.Buffer, lines []string) {
+	var paragraph []string
+	var paragraphAttributes asciidocAttributes
+	var attributes asciidocAttributes
+	var lists, markers []string
+	// listItem indicates that the paragraph holds the text of the current list item
+	listItem := false
+	// continued indicates that the next block is attached to the current list item
+	continued := false
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		r.renderParagraph(out, paragraph, paragraphAttributes, listItem)
+		paragraph = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			flushParagraph()
+			listItem = false
+			continue
+		}
+		if len(paragraph) > 0 && !asciidocDelimiterPattern.MatchString(line) && !asciidocListItemPattern.MatchString(line) && line != "+" {
+			paragraph = append(paragraph, line)
+			continue
+		}
+		flushParagraph()
+		if match := asciidocListItemPattern.FindStringSubmatch(line); match != nil {
+			if len(lists) == 0 {
+				markers = nil
+			}
+			markers = nestAsciiDocListMarker(markers, match[1])
+			openListItem(out, &lists, asciidocListTags(markers))
+			paragraph, paragraphAttributes, listItem, continued = []string{match[2]}, asciidocAttributes{}, true, false
+			attributes = asciidocAttributes{}
+			continue
+		}
+		switch {
+		case line == "+" && len(lists) > 0:
+			listItem, continued = false, true
+			continue
+		case strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "////"):
+			// single line comments also separate the lists
+			closeLists(out, &lists)
+			continue
+		case asciidocAnchorPattern.MatchString(line) && len(asciidocAnchorPattern.FindString(line)) == len(line):
+			match := asciidocAnchorPattern.FindStringSubmatch(line)
+			attributes.id, attributes.reftext = match[1], match[2]
+			continue
+		case asciidocAttributeListPattern.MatchString(line):
+			parseAsciiDocAttributes(&attributes, line[1:len(line)-1])
+			continue
+		case asciidocBlockTitlePattern.MatchString(line):
+			attributes.title = line[1:]
+			continue
+		case asciidocAttributeEntryPattern.MatchString(line):
+			// document attributes are not supported
+			continue
+		}
+		if !continued {
+			closeLists(out, &lists)
+		}
+		continued = false
+		block := attributes
+		attributes = asciidocAttributes{}
+		if match := asciidocSectionPattern.FindStringSubmatch(line); match != nil {
+			r.renderSection(out, len(match[1]), match[2], block)
+			continue
+		}
+		if block.id != "" {
+			r.ids[block.id] = true
+			if block.reftext != "" {
+				r.titles[block.id] = escapeText(block.reftext)
+			}
+			out.WriteString("<a id=\"" + escapeText(block.id) + "\"></a>\n")
+		}
+		if block.title != "" {
+			out.WriteString("<p><strong>" + r.renderInline(block.title) + "</strong></p>\n")
+		}
+		switch {
+		case asciidocDelimiterPattern.MatchString(line):
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != line {
+				end++
+			}
+			r.renderDelimitedBlock(out, line, lines[i+1:end], block)
+			i = end
+		case line == "'''":
+			out.WriteString("<hr/>\n")
+		default:
+			if match := asciidocAdmonitionPattern.FindStringSubmatch(line); match != nil {
+				block.style, line = match[1], match[2]
+			}
+			paragraph, paragraphAttributes, listItem = []string{line}, block, false
+		}
+	}
+	flushParagraph()
+	closeLists(out, &lists)
+}
+
+// nestAsciiDocListMarker returns the markers of the list levels for a list item with the
+// given marker: a marker which is already in use goes back to its level, other markers
+// start a nested list. Numbered items ("1.") are on the same level as ".".
+func nestAsciiDocListMarker(markers []string, marker string) []string {
+	if marker[0] >= '0' && marker[0] <= '9' {
+		marker = "."
+	}
+	for i, m := range markers {
+		if m == marker {
+			return markers[:i+1]
+		}
+	}
+	return append(markers, marker)
+}
+
+// asciidocListTags returns the type of the list for each level
+func asciidocListTags(markers []string) []string {
+	tags := make([]string, len(markers))
+	for i, marker := range markers {
+		tags[i] = "ul"
+		if strings.HasSuffix(marker, ".") {
+			tags[i] = "ol"
+		}
+	}
+	return tags
+}
+
+// renderSection renders a section title
+func (r *asciidocRenderer) renderSection(out *bytes.Buffer, level int, title string, attributes asciidocAttributes) {
+	id := attributes.id
+	if id == "" {
+		id = r.sectionID(title)
+	}
+	r.ids[id] = true
+	text := r.renderInline(title)
+	r.titles[id] = text
+	if attributes.reftext != "" {
+		r.titles[id] = escapeText(attributes.reftext)
+	}
+	tag := "h" + strconv.Itoa(level)
+	out.WriteString("<" + tag + " id=\"" + escapeText(id) + "\">" + text + "</" + tag + ">\n")
+}
+
+// sectionID generates the ID of a section in the same way as Asciidoctor: the title in
+// lower case, with an underscore as prefix and in place of the other characters
+func (r *asciidocRenderer) sectionID(title string) string {
+	id := []byte{'_'}
+	for _, c := range []byte(strings.ToLower(title)) {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+			id = append(id, c)
+		} else if id[len(id)-1] != '_' {
+			id = append(id, '_')
+		}
+	}
+	base := strings.TrimRight(string(id), "_")
+	if base == "" {
+		base = "_section"
+	}
+	result := base
+	for n := 2; r.ids[result]; n++ {
+		result = base + "_" + strconv.Itoa(n)
+	}
+	return result
+}
+
+// renderParagraph renders a paragraph, which is styled with the given attributes
+func (r *asciidocRenderer) renderParagraph(out *bytes.Buffer, lines []string, attributes asciidocAttributes, listItem bool) {
+	text := strings.Join(lines, "\n")
+	switch {
+	case listItem:
+		out.WriteString(r.renderInline(text))
+	case attributes.style == "source":
+		renderCodeBlock(out, text, attributes.language)
+	case attributes.style == "listing" || attributes.style == "literal":
+		out.WriteString("<pre>" + escapeText(text) + "</pre>\n")
+	case attributes.style == "quote":
+		out.WriteString("<blockquote><p>" + r.renderInline(text) + "</p></blockquote>\n")
+	case asciidocAdmonitions[attributes.style] != "":
+		r.renderAdmonition(out, attributes.style, func() {
+			out.WriteString("<p>" + r.renderInline(text) + "</p>\n")
+		})
+	default:
+		out.WriteString("<p>" + r.renderInline(text) + "</p>\n")
+	}
+}
+
+// renderDelimitedBlock renders the content of the block with the given delimiter
+func (r *asciidocRenderer) renderDelimitedBlock(out *bytes.Buffer, delimiter string, content []string, attributes asciidocAttributes) {
+	text := strings.Join(content, "\n")
+	switch delimiter[0] {
+	case '-':
+		if attributes.style == "source" {
+			renderCodeBlock(out, text, attributes.language)
+			return
+		}
+		out.WriteString("<pre>" + escapeText(text) + "</pre>\n")
+	case '.':
+		out.WriteString("<pre>" + escapeText(text) + "</pre>\n")
+	case '_':
+		out.WriteString("<blockquote>\n")
+		r.renderBlocks(out, content)
+		out.WriteString("</blockquote>\n")
+	case '=':
+		if asciidocAdmonitions[attributes.style] != "" {
+			r.renderAdmonition(out, attributes.style, func() {
+				r.renderBlocks(out, content)
+			})
+			return
+		}
+		out.WriteString("<div>\n")
+		r.renderBlocks(out, content)
+		out.WriteString("</div>\n")
+	case '*':
+		out.WriteString("<aside>\n")
+		r.renderBlocks(out, content)
+		out.WriteString("</aside>\n")
+	case '+':
+		// passthrough block, the content is HTML
+		out.WriteString(text + "\n")
+	case '|':
+		r.renderTable(out, content, attributes)
+	}
+}
+
+// renderAdmonition renders an admonition with the given style around the content
+func (r *asciidocRenderer) renderAdmonition(out *bytes.Buffer, style string, renderContent func()) {
+	out.WriteString("<div class=\"admonition admonition-" + strings.ToLower(style) + "\">\n")
+	out.WriteString("<p><strong>" + asciidocAdmonitions[style] + "</strong></p>\n")
+	renderContent()
+	out.WriteString("</div>\n")
+}
+
+// renderTable renders the content of a table block. The number of columns is given by the
+// cols attribute or by the number of cells on the first line. The first row is the header
+// if the header option is set or if the first line is followed by a blank line.
+func (r *asciidocRenderer) renderTable(out *bytes.Buffer, content []string, attributes asciidocAttributes) {
+	var cells []string
+	cols, header := attributes.cols, attributes.header
+	lines, firstLineCells := 0, 0
+	for _, line := range content {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if lines == 1 && firstLineCells == cols {
+				header = true
+			}
+			continue
+		}
+		lines++
+		if !strings.HasPrefix(line, "|") {
+			// the content of the previous cell continues
+			if len(cells) > 0 {
+				cells[len(cells)-1] += "\n" + line
+			}
+			continue
+		}
+		lineCells := splitAsciiDocTableRow(line)
+		if lines == 1 {
+			firstLineCells = len(lineCells)
+			if cols == 0 {
+				cols = firstLineCells
+			}
+		}
+		cells = append(cells, lineCells...)
+	}
+	if cols == 0 {
+		cols = 1
+	}
+	out.WriteString("<table>\n")
+	if header && len(cells) >= cols {
+		out.WriteString("<thead>\n")
+		r.renderTableRow(out, cells[:cols], "th")
+		out.WriteString("</thead>\n")
+		cells = cells[cols:]
+	}
+	out.WriteString("<tbody>\n")
+	for start := 0; start < len(cells); start += cols {
+		end := start + cols
+		if end > len(cells) {
+			end = len(cells)
+		}
+		r.renderTableRow(out, cells[start:end], "td")
+	}
+	out.WriteString("</tbody>\n</table>\n")
+}
+
+func (r *asciidocRenderer) renderTableRow(out *bytes.Buffer, cells []string, tag string) {
+	out.WriteString("<tr>")
+	for _, cell := range cells {
+		out.WriteString("<" + tag + ">" + r.renderInline(cell) + "</" + tag + ">")
+	}
+	out.WriteString("</tr>\n")
+}
+
+// splitAsciiDocTableRow splits a line of a table in its cells, which all start with '|'.
+// Escaped separators ("\|") are part of the cells.
+func splitAsciiDocTableRow(line string) []string {
+	var cells []string
+	var cell bytes.Buffer
+	for i := 1; i < len(line); i++ {
+		switch {
+		case strings.HasPrefix(line[i:], `\|`):
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// parseAsciiDocAttributes parses the content of a block attribute list, e.g. "source,go"
+// or "%header,cols="1,2"", into the given attributes
+func parseAsciiDocAttributes(attributes *asciidocAttributes, content string) {
+	positional := 0
+	for _, attribute := range splitAsciiDocAttributes(content) {
+		if index := strings.Index(attribute, "="); index > 0 {
+			name := strings.TrimSpace(attribute[:index])
+			value := strings.Trim(strings.TrimSpace(attribute[index+1:]), `"'`)
+			switch name {
+			case "id":
+				attributes.id = value
+			case "reftext":
+				attributes.reftext = value
+			case "options", "opts":
+				for _, option := range strings.Split(value, ",") {
+					if strings.TrimSpace(option) == "header" {
+						attributes.header = true
+					}
+				}
+			case "cols":
+				attributes.cols = asciidocColumnCount(value)
+			}
+			continue
+		}
+		positional++
+		switch positional {
+		case 1:
+			// the style can be followed by the shorthands of the ID (#), options (%) and roles (.)
+			style, shorthands := attribute, ""
+			if index := strings.IndexAny(attribute, "#%."); index >= 0 {
+				style, shorthands = attribute[:index], attribute[index:]
+			}
+			attributes.style = style
+			for _, shorthand := range asciidocShorthandPattern.FindAllString(shorthands, -1) {
+				switch {
+				case shorthand[0] == '#' && len(shorthand) > 1:
+					attributes.id = shorthand[1:]
+				case shorthand == "%header":
+					attributes.header = true
+				}
+			}
+		case 2:
+			if attributes.style == "source" && languagePattern.MatchString(attribute) {
+				attributes.language = attribute
+			}
+		}
+	}
+}
+
+// splitAsciiDocAttributes splits an attribute list on the commas which are not quoted
+func splitAsciiDocAttributes(content string) []string {
+	var attributes []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				attributes = append(attributes, strings.TrimSpace(content[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(attributes, strings.TrimSpace(content[start:]))
+}
+
+// asciidocColumnCount returns the number of columns of the given cols attribute, which is
+// either a number of columns ("3") or a list of column specifiers ("1,2" or "2*,1"), or 0 if
+// the count or a multiplier isn't positive, so that the cells of the first line are counted instead
+func asciidocColumnCount(cols string) int {
+	specs := strings.Split(cols, ",")
+	if len(specs) == 1 {
+		if count, err := strconv.Atoi(strings.TrimSpace(specs[0])); err == nil {
+			if count <= 0 {
+				return 0
+			}
+			return count
+		}
+	}
+	count := 0
+	for _, spec := range specs {
+		if index := strings.Index(spec, "*"); index > 0 {
+			if n, err := strconv.Atoi(strings.TrimSpace(spec[:index])); err == nil {
+				if n <= 0 {
+					return 0
+				}
+				count += n
+				continue
+			}
+		}
+		count++
+	}
+	return count
+}
+
+// renderInline renders the text formatting, links, cross references and line breaks of the given text
+func (r *asciidocRenderer) renderInline(s string) string {
+	var out bytes.Buffer
+	for i := 0; i < len(s); {
+		if n := r.renderInlineElement(&out, s, i); n > 0 {
+			i += n
+			continue
+		}
+		if s[i] == '&' && entityPattern.MatchString(s[i:]) {
+			// keep entities, the content may already be escaped
+			out.WriteByte('&')
+		} else {
+			out.WriteString(html.EscapeString(s[i : i+1]))
+		}
+		i++
+	}
+	return out.String()
+}
+
+// renderInlineElement renders the element starting at the given index and returns
+// the number of bytes it spans, or 0 if there is no element at the index
+func (r *asciidocRenderer) renderInlineElement(out *bytes.Buffer, s string, i int) int {
+	rest := s[i:]
+	switch {
+	case rest == " +" || strings.HasPrefix(rest, " +\n"):
+		// hard line break
+		out.WriteString("<br/>")
+		return 2
+	case rest[0] == '\\' && len(rest) > 1:
+		// escaped formatting mark or URL
+		if url := asciidocURLPattern.FindString(rest[1:]); url != "" {
+			out.WriteString(escapeText(url))
+			return len(url) + 1
+		}
+		if !isWordChar(rest[1]) && rest[1] != ' ' {
+			out.WriteString(html.EscapeString(rest[1:2]))
+			return 2
+		}
+	case strings.HasPrefix(rest, "<<"):
+		if match := asciidocXrefPattern.FindStringSubmatch(rest); match != nil {
+			out.WriteString(r.renderXref(match[1], match[2]))
+			return len(match[0])
+		}
+	case strings.HasPrefix(rest, "[["):
+		if match := asciidocAnchorPattern.FindStringSubmatch(rest); match != nil {
+			r.ids[match[1]] = true
+			if match[2] != "" {
+				r.titles[match[1]] = escapeText(match[2])
+			}
+			out.WriteString("<a id=\"" + escapeText(match[1]) + "\"></a>")
+			return len(match[0])
+		}
+	case rest[0] == '`':
+		if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+			out.WriteString("<code>" + escapeText(rest[1:1+end]) + "</code>")
+			return end + 2
+		}
+	case asciidocMacroPattern.MatchString(rest) && (i == 0 || !isWordChar(s[i-1])):
+		match := asciidocMacroPattern.FindStringSubmatch(rest)
+		if link, ok := r.renderMacro(match[1], match[2], match[3]); ok {
+			out.WriteString(link)
+			return len(match[0])
+		}
+	case asciidocURLPattern.MatchString(rest) && (i == 0 || !isWordChar(s[i-1])):
+		url := asciidocURLPattern.FindString(rest)
+		if strings.HasPrefix(rest[len(url):], "[") {
+			if end := strings.IndexByte(rest[len(url):], ']'); end > 0 {
+				out.WriteString(r.renderLink(url, rest[len(url)+1:len(url)+end], url))
+				return len(url) + end + 1
+			}
+		}
+		url = strings.TrimRight(url, ".,;:!?)")
+		out.WriteString(r.renderLink(url, "", url))
+		return len(url)
+	}
+	for _, effect := range asciidocEffects {
+		if !strings.HasPrefix(rest, effect.marker) {
+			continue
+		}
+		if n := r.renderEffect(out, s, i, effect.marker, effect.element, effect.constrained); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// renderEffect renders the text effect with the given marker starting at the given index
+// and returns the number of bytes it spans, or 0 if the effect is not closed
+func (r *asciidocRenderer) renderEffect(out *bytes.Buffer, s string, i int, marker string, element string, constrained bool) int {
+	rest := s[i:]
+	start := len(marker)
+	if start >= len(rest) || rest[start] == ' ' || constrained && i > 0 && isWordChar(s[i-1]) {
+		return 0
+	}
+	for end := start + 1; end < len(rest); end++ {
+		if !strings.HasPrefix(rest[end:], marker) || rest[end-1] == ' ' {
+			continue
+		}
+		if constrained && end+start < len(rest) && isWordChar(rest[end+start]) {
+			continue
+		}
+		if (marker == "^" || marker == "~") && strings.ContainsAny(rest[start:end], " \n") {
+			// superscript and subscript text cannot contain spaces
+			return 0
+		}
+		out.WriteString("<" + element + ">" + r.renderInline(rest[start:end]) + "</" + element + ">")
+		return end + start
+	}
+	return 0
+}
+
+// renderMacro renders a link:target[text], mailto:address[text] or xref:id[text] macro.
+// Returns false if the target is not supported.
+func (r *asciidocRenderer) renderMacro(name, target, text string) (string, bool) {
+	switch name {
+	case "mailto":
+		return r.renderLink("mailto:"+target, text, target), true
+	case "xref":
+		if index := strings.LastIndex(target, "#"); index >= 0 {
+			target = target[index+1:]
+		}
+		return r.renderXref(target, text), true
+	}
+	if scheme := strings.Index(target, ":"); scheme >= 0 && !asciidocURLPattern.MatchString(target) &&
+		!strings.HasPrefix(target, "mailto:") && strings.IndexAny(target[:scheme], "/#?") < 0 {
+		// only the web links and the relative links are supported
+		return "", false
+	}
+	return r.renderLink(target, text, target), true
+}
+
+// renderLink renders a link with the given text, or the default text if there is none
+func (r *asciidocRenderer) renderLink(target, text, defaultText string) string {
+	if text == "" {
+		text = escapeText(defaultText)
+	} else {
+		text = r.renderInline(text)
+	}
+	return "<a href=\"" + escapeText(target) + "\">" + text + "</a>"
+}
+
+// renderXref renders a cross reference to the section or anchor with the given ID. Without
+// text, the reference shows the title of the section or the text of the anchor.
+func (r *asciidocRenderer) renderXref(id, text string) string {
+	switch {
+	case text != "":
+		text = r.renderInline(text)
+	case r.titles[id] != "":
+		text = r.titles[id]
+	default:
+		text = "[" + escapeText(id) + "]"
+	}
+	return "<a href=\"#" + escapeText(id) + "\">" + text + "</a>"
+}
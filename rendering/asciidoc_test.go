@@ -0,0 +1,101 @@
+package rendering_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderAsciiDocInline(t *testing.T) {
+	for content, expected := range map[string]string{
+		"Hello, World!": "<p>Hello, World!</p>\n",
+		"*strong* and _emphasis_ and `monospace`":     "<p><strong>strong</strong> and <em>emphasis</em> and <code>monospace</code></p>\n",
+		"**un**constrained and snake_case_name":       "<p><strong>un</strong>constrained and snake_case_name</p>\n",
+		"E=mc^2^ and H~2~O":                           "<p>E=mc<sup>2</sup> and H<sub>2</sub>O</p>\n",
+		"a * b * c":                                   "<p>a * b * c</p>\n",
+		"first\nsecond":                               "<p>first\nsecond</p>\n",
+		"line +\nbreak":                               "<p>line<br/>\nbreak</p>\n",
+		`not \*strong*`:                               "<p>not *strong*</p>\n",
+		"<script>alert('x')</script>":                 "<p>&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</p>\n",
+		"&lt;escaped&gt;":                             "<p>&lt;escaped&gt;</p>\n",
+		"https://almighty.io[Almighty]":               "<p><a href=\"https://almighty.io\" rel=\"nofollow\">Almighty</a></p>\n",
+		"see https://almighty.io.":                    "<p>see <a href=\"https://almighty.io\" rel=\"nofollow\">https://almighty.io</a>.</p>\n",
+		"link:/docs[the docs]":                        "<p><a href=\"/docs\" rel=\"nofollow\">the docs</a></p>\n",
+		"mailto:jdoe@example.com[mail]":               "<p><a href=\"mailto:jdoe@example.com\" rel=\"nofollow\">mail</a></p>\n",
+		"link:javascript:alert(1)[click] \\https://x": "<p>link:javascript:alert(1)[click] https://x</p>\n",
+	} {
+		assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc), content)
+	}
+}
+
+func TestRenderAsciiDocSectionsAndCrossReferences(t *testing.T) {
+	content := "= Title\n\n== Section *One*\n\nSee <<_section_one>>, <<details,the details>>, xref:intro[] and <<missing>>.\n\n" +
+		"[[details]]\n=== Details\n\n[[intro,Introduction]]\nSome text.\n\n== Section One"
+	expected := "<h1 id=\"_title\">Title</h1>\n" +
+		"<h2 id=\"_section_one\">Section <strong>One</strong></h2>\n" +
+		"<p>See <a href=\"#_section_one\" rel=\"nofollow\">Section <strong>One</strong></a>, " +
+		"<a href=\"#details\" rel=\"nofollow\">the details</a>, " +
+		"<a href=\"#intro\" rel=\"nofollow\">Introduction</a> and " +
+		"<a href=\"#missing\" rel=\"nofollow\">[missing]</a>.</p>\n" +
+		"<h3 id=\"details\">Details</h3>\n" +
+		"<a id=\"intro\"></a>\n<p>Some text.</p>\n" +
+		"<h2 id=\"_section_one_2\">Section One</h2>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc))
+}
+
+func TestRenderAsciiDocLists(t *testing.T) {
+	content := "* one\n* two\n** two.one\n. two.one.one\n* three\ncontinued\n+\n....\nliteral\n....\n\n// next list\n1. first\n. second"
+	expected := "<ul>\n<li>one</li>\n<li>two<ul>\n<li>two.one<ol>\n<li>two.one.one</li>\n</ol>\n</li>\n</ul>\n</li>\n" +
+		"<li>three\ncontinued<pre>literal</pre>\n</li>\n</ul>\n" +
+		"<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc))
+}
+
+func TestRenderAsciiDocAdmonitions(t *testing.T) {
+	content := "NOTE: Remember *this*.\n\n[WARNING]\n====\nDanger _zone_\n====\n\n[TIP]\nA tip."
+	expected := "<div class=\"admonition admonition-note\">\n<p><strong>Note</strong></p>\n<p>Remember <strong>this</strong>.</p>\n</div>\n" +
+		"<div class=\"admonition admonition-warning\">\n<p><strong>Warning</strong></p>\n<p>Danger <em>zone</em></p>\n</div>\n" +
+		"<div class=\"admonition admonition-tip\">\n<p><strong>Tip</strong></p>\n<p>A tip.</p>\n</div>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc))
+}
+
+func TestRenderAsciiDocBlocks(t *testing.T) {
+	content := ".Quote\n____\nquoted *block*\n____\n\n'''\n\n----\n*not strong* <b>\n----"
+	expected := "<p><strong>Quote</strong></p>\n<blockquote>\n<p>quoted <strong>block</strong></p>\n</blockquote>\n" +
+		"<hr/>\n" +
+		"<pre>*not strong* &lt;b&gt;</pre>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc))
+}
+
+func TestRenderAsciiDocSource(t *testing.T) {
+	content := "[source,go]\n----\nfunc getTrue() bool {return true}\n----"
+	result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc)
+	t.Log(result)
+	assert.True(t, strings.Contains(result, "<code class=\"prettyprint language-go\">"))
+	assert.True(t, strings.Contains(result, "<span class=\"kwd\">func</span>"))
+}
+
+func TestRenderAsciiDocTable(t *testing.T) {
+	content := "|===\n|Name |Link\n\n|foo |https://almighty.io/bar[bar]\n|*baz*\n|a\\|b\n|===\n\n" +
+		"[cols=\"2*\",options=\"header\"]\n|===\n|A\n|B\n|===\n\n|===\n|no |header\n|===\n"
+	expected := "<table>\n<thead>\n<tr><th>Name</th><th>Link</th></tr>\n</thead>\n<tbody>\n" +
+		"<tr><td>foo</td><td><a href=\"https://almighty.io/bar\" rel=\"nofollow\">bar</a></td></tr>\n" +
+		"<tr><td><strong>baz</strong></td><td>a|b</td></tr>\n" +
+		"</tbody>\n</table>\n" +
+		"<table>\n<thead>\n<tr><th>A</th><th>B</th></tr>\n</thead>\n<tbody>\n</tbody>\n</table>\n" +
+		"<table>\n<tbody>\n<tr><td>no</td><td>header</td></tr>\n</tbody>\n</table>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc))
+}
+
+func TestRenderAsciiDocTableWithInvalidColumnCount(t *testing.T) {
+	// the cells of the first line are counted instead
+	expected := "<table>\n<tbody>\n<tr><td>a</td><td>b</td></tr>\n<tr><td>c</td><td>d</td></tr>\n</tbody>\n</table>\n"
+	for _, cols := range []string{"-1", "0", "\"-5*,1\"", "\"0*,1\""} {
+		content := "[cols=" + cols + "]\n|===\n|a |b\n|c |d\n|===\n"
+		assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupAsciiDoc), cols)
+	}
+	// a table without cell doesn't break
+	assert.Equal(t, "<table>\n<tbody>\n<tr><td></td></tr>\n</tbody>\n</table>\n", rendering.RenderMarkupToHTML("[cols=-1]\n|===\n|", rendering.SystemMarkupAsciiDoc))
+}
@@ -0,0 +1,76 @@
+package rendering
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+
+	"github.com/sourcegraph/syntaxhighlight"
+)
+
+var (
+	entityPattern   = regexp.MustCompile(`^&(#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z][a-zA-Z0-9]*);`)
+	languagePattern = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+)
+
+// renderCodeBlock writes the code block highlighted in the same way as the Markdown code blocks
+func renderCodeBlock(out *bytes.Buffer, content string, language string) {
+	highlighted, err := syntaxhighlight.AsHTML([]byte(content))
+	if err != nil {
+		highlighted = []byte(html.EscapeString(content))
+	}
+	out.WriteString("<pre><code class=\"prettyprint")
+	if language != "" {
+		out.WriteString(" language-" + language)
+	}
+	out.WriteString("\">")
+	out.Write(highlighted)
+	out.WriteString("</code></pre>\n")
+}
+
+// openListItem opens a list item, opening and closing the (nested) lists as needed.
+// The tags give the type of the list ("ul" or "ol") for each level.
+func openListItem(out *bytes.Buffer, lists *[]string, tags []string) {
+	// keep the lists which have the same type on the same level
+	common := 0
+	for common < len(*lists) && common < len(tags) && (*lists)[common] == tags[common] {
+		common++
+	}
+	for len(*lists) > common {
+		out.WriteString("</li>\n</" + (*lists)[len(*lists)-1] + ">\n")
+		*lists = (*lists)[:len(*lists)-1]
+	}
+	if len(*lists) == len(tags) {
+		out.WriteString("</li>\n<li>")
+	}
+	for len(*lists) < len(tags) {
+		tag := tags[len(*lists)]
+		out.WriteString("<" + tag + ">\n<li>")
+		*lists = append(*lists, tag)
+	}
+}
+
+// closeLists closes the current list item and all the open lists
+func closeLists(out *bytes.Buffer, lists *[]string) {
+	for len(*lists) > 0 {
+		out.WriteString("</li>\n</" + (*lists)[len(*lists)-1] + ">\n")
+		*lists = (*lists)[:len(*lists)-1]
+	}
+}
+
+// escapeText escapes the given text for HTML but keeps the entities it contains
+func escapeText(s string) string {
+	var out bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '&' && entityPattern.MatchString(s[i:]) {
+			out.WriteByte('&')
+			continue
+		}
+		out.WriteString(html.EscapeString(s[i : i+1]))
+	}
+	return out.String()
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,346 @@
+package rendering
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	jiraHeadingPattern   = regexp.MustCompile(`^h([1-6])\.\s+(.*)$`)
+	jiraListItemPattern  = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	jiraCodeStartPattern = regexp.MustCompile(`^\{(code|noformat)(:[^}]*)?\}`)
+	jiraURLPattern       = regexp.MustCompile(`^(https?|ftp)://[^\s\[\]|<>"]+`)
+)
+
+// jiraEffects maps the markers of the JIRA text effects to the HTML elements they are rendered with
+var jiraEffects = []struct {
+	marker  string
+	element string
+}{
+	{"??", "cite"},
+	{"*", "strong"},
+	{"_", "em"},
+	{"-", "del"},
+	{"+", "ins"},
+	{"^", "sup"},
+	{"~", "sub"},
+}
+
+// JiraWikiToHTML converts the given JIRA wiki markup to HTML. Headings, lists, tables,
+// quotes, {code} and {noformat} blocks, links, user mentions and text effects are supported,
+// other markup is rendered as text.
+// The result is not sanitized, see RenderMarkupToHTML.
+func JiraWikiToHTML(input []byte) []byte {
+	lines := strings.Split(strings.Replace(string(input), "\r\n", "\n", -1), "\n")
+	var out bytes.Buffer
+	renderJiraBlocks(&out, lines)
+	return out.Bytes()
+}
+
+// renderJiraBlocks renders the given lines as a sequence of blocks
+func renderJiraBlocks(out *bytes.Buffer, lines []string) {
+	var paragraph []string
+	var lists []string
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		out.WriteString("<p>")
+		for i, line := range paragraph {
+			if i > 0 {
+				out.WriteString("<br/>\n")
+			}
+			out.WriteString(renderJiraInline(line))
+		}
+		out.WriteString("</p>\n")
+		paragraph = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if match := jiraListItemPattern.FindStringSubmatch(line); match != nil {
+			flushParagraph()
+			renderJiraListItem(out, &lists, match[1], match[2])
+			continue
+		}
+		closeLists(out, &lists)
+		switch {
+		case line == "":
+			flushParagraph()
+		case jiraCodeStartPattern.MatchString(line):
+			flushParagraph()
+			i = renderJiraCode(out, lines, i)
+		case strings.HasPrefix(line, "{quote}"):
+			flushParagraph()
+			i = renderJiraQuote(out, lines, i)
+		case line == "----":
+			flushParagraph()
+			out.WriteString("<hr/>\n")
+		case jiraHeadingPattern.MatchString(line):
+			flushParagraph()
+			match := jiraHeadingPattern.FindStringSubmatch(line)
+			out.WriteString("<h" + match[1] + ">" + renderJiraInline(match[2]) + "</h" + match[1] + ">\n")
+		case strings.HasPrefix(line, "bq. "):
+			flushParagraph()
+			out.WriteString("<blockquote><p>" + renderJiraInline(strings.TrimSpace(line[4:])) + "</p></blockquote>\n")
+		case strings.HasPrefix(line, "|"):
+			flushParagraph()
+			i = renderJiraTable(out, lines, i)
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flushParagraph()
+	closeLists(out, &lists)
+}
+
+// renderJiraListItem renders a list item, opening and closing the (nested) lists as
+// needed. The markers give the type of the list for each level: '#' for numbered
+// lists, '*' and '-' for bulleted lists.
+func renderJiraListItem(out *bytes.Buffer, lists *[]string, markers string, text string) {
+	tags := make([]string, len(markers))
+	for i, marker := range markers {
+		tags[i] = "ul"
+		if marker == '#' {
+			tags[i] = "ol"
+		}
+	}
+	openListItem(out, lists, tags)
+	out.WriteString(renderJiraInline(text))
+}
+
+// renderJiraCode renders the {code} or {noformat} block starting at the given line
+// and returns the index of the last line of the block
+func renderJiraCode(out *bytes.Buffer, lines []string, start int) int {
+	line := strings.TrimSpace(lines[start])
+	match := jiraCodeStartPattern.FindStringSubmatch(line)
+	macro, closing := match[1], "{"+match[1]+"}"
+	var text []string
+	end := start
+	rest := line[len(match[0]):]
+	for {
+		if index := strings.Index(rest, closing); index >= 0 {
+			text = append(text, rest[:index])
+			break
+		}
+		text = append(text, rest)
+		if end+1 >= len(lines) {
+			// unterminated block: everything up to the end belongs to it
+			break
+		}
+		end++
+		rest = lines[end]
+	}
+	// drop the line breaks directly after the opening and before the closing tag
+	if len(text) > 1 && strings.TrimSpace(text[0]) == "" {
+		text = text[1:]
+	}
+	if len(text) > 1 && strings.TrimSpace(text[len(text)-1]) == "" {
+		text = text[:len(text)-1]
+	}
+	content := strings.Join(text, "\n")
+	if macro == "noformat" {
+		out.WriteString("<pre>" + escapeText(content) + "</pre>\n")
+		return end
+	}
+	language := ""
+	if match[2] != "" {
+		for _, param := range strings.Split(match[2][1:], "|") {
+			param = strings.TrimPrefix(param, "language=")
+			if languagePattern.MatchString(param) {
+				language = param
+				break
+			}
+		}
+	}
+	renderCodeBlock(out, content, language)
+	return end
+}
+
+// renderJiraQuote renders the {quote} block starting at the given line and
+// returns the index of the last line of the block
+func renderJiraQuote(out *bytes.Buffer, lines []string, start int) int {
+	var content []string
+	end := start
+	rest := strings.TrimPrefix(strings.TrimSpace(lines[start]), "{quote}")
+	for {
+		if index := strings.Index(rest, "{quote}"); index >= 0 {
+			content = append(content, rest[:index])
+			break
+		}
+		content = append(content, rest)
+		if end+1 >= len(lines) {
+			break
+		}
+		end++
+		rest = lines[end]
+	}
+	out.WriteString("<blockquote>\n")
+	renderJiraBlocks(out, content)
+	out.WriteString("</blockquote>\n")
+	return end
+}
+
+// renderJiraTable renders the table starting at the given line and returns the
+// index of the last row of the table
+func renderJiraTable(out *bytes.Buffer, lines []string, start int) int {
+	out.WriteString("<table>\n<tbody>\n")
+	end := start
+	for ; end < len(lines); end++ {
+		line := strings.TrimSpace(lines[end])
+		if !strings.HasPrefix(line, "|") {
+			break
+		}
+		out.WriteString("<tr>")
+		for _, cell := range splitJiraTableRow(line) {
+			tag := "td"
+			if cell.header {
+				tag = "th"
+			}
+			out.WriteString("<" + tag + ">" + renderJiraInline(strings.TrimSpace(cell.text)) + "</" + tag + ">")
+		}
+		out.WriteString("</tr>\n")
+	}
+	out.WriteString("</tbody>\n</table>\n")
+	return end - 1
+}
+
+type jiraTableCell struct {
+	header bool
+	text   string
+}
+
+// splitJiraTableRow splits a table row in its cells. Header cells start with '||',
+// other cells with '|'. Separators within links and monospaced text are ignored.
+func splitJiraTableRow(line string) []jiraTableCell {
+	var cells []jiraTableCell
+	var current *jiraTableCell
+	brackets, braces := 0, 0
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '[':
+			brackets++
+		case c == ']' && brackets > 0:
+			brackets--
+		case strings.HasPrefix(line[i:], "{{"):
+			braces++
+		case strings.HasPrefix(line[i:], "}}") && braces > 0:
+			braces--
+		case c == '|' && brackets == 0 && braces == 0:
+			if current != nil {
+				cells = append(cells, *current)
+			}
+			current = &jiraTableCell{}
+			if strings.HasPrefix(line[i:], "||") {
+				current.header = true
+				i++
+			}
+			continue
+		}
+		if current != nil {
+			current.text += string(c)
+		}
+	}
+	// the row usually ends with a separator, which does not start a new cell
+	if current != nil && strings.TrimSpace(current.text) != "" {
+		cells = append(cells, *current)
+	}
+	return cells
+}
+
+// renderJiraInline renders the text effects, links and line breaks of a single line
+func renderJiraInline(s string) string {
+	var out bytes.Buffer
+	for i := 0; i < len(s); {
+		if n := renderJiraInlineElement(&out, s, i); n > 0 {
+			i += n
+			continue
+		}
+		if s[i] == '&' && entityPattern.MatchString(s[i:]) {
+			// keep entities, the content may already be escaped
+			out.WriteByte('&')
+		} else {
+			out.WriteString(html.EscapeString(s[i : i+1]))
+		}
+		i++
+	}
+	return out.String()
+}
+
+// renderJiraInlineElement renders the element starting at the given index and returns
+// the number of bytes it spans, or 0 if there is no element at the index
+func renderJiraInlineElement(out *bytes.Buffer, s string, i int) int {
+	rest := s[i:]
+	switch {
+	case strings.HasPrefix(rest, `\\`):
+		out.WriteString("<br/>")
+		return 2
+	case rest[0] == '\\' && len(rest) > 1:
+		// escaped special character
+		out.WriteString(html.EscapeString(rest[1:2]))
+		return 2
+	case strings.HasPrefix(rest, "{{"):
+		if end := strings.Index(rest[2:], "}}"); end > 0 {
+			out.WriteString("<code>" + escapeText(rest[2:2+end]) + "</code>")
+			return end + 4
+		}
+	case rest[0] == '[':
+		if end := strings.IndexByte(rest, ']'); end > 0 {
+			if link, ok := renderJiraLink(rest[1:end]); ok {
+				out.WriteString(link)
+				return end + 1
+			}
+		}
+	case jiraURLPattern.MatchString(rest) && (i == 0 || !isWordChar(s[i-1])):
+		url := strings.TrimRight(jiraURLPattern.FindString(rest), ".,;:!?)")
+		out.WriteString("<a href=\"" + escapeText(url) + "\">" + escapeText(url) + "</a>")
+		return len(url)
+	}
+	if i > 0 && isWordChar(s[i-1]) {
+		// text effects do not start within words
+		return 0
+	}
+	for _, effect := range jiraEffects {
+		if !strings.HasPrefix(rest, effect.marker) {
+			continue
+		}
+		start := len(effect.marker)
+		if start >= len(rest) || rest[start] == ' ' {
+			return 0
+		}
+		for end := start + 1; end < len(rest); end++ {
+			if strings.HasPrefix(rest[end:], effect.marker) && rest[end-1] != ' ' &&
+				(end+start == len(rest) || !isWordChar(rest[end+start])) {
+				out.WriteString("<" + effect.element + ">" + renderJiraInline(rest[start:end]) + "</" + effect.element + ">")
+				return end + start
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// renderJiraLink renders the content of a [...] link: a user mention ([~username]),
+// an anchor ([#anchor]) or a link with an optional alias ([alias|url]).
+// Returns false if the content is not a link.
+func renderJiraLink(content string) (string, bool) {
+	if strings.HasPrefix(content, "~") && len(content) > 1 {
+		return "<span class=\"user-mention\">@" + escapeText(content[1:]) + "</span>", true
+	}
+	parts := strings.Split(content, "|")
+	alias, target := "", strings.TrimSpace(parts[0])
+	if len(parts) > 1 {
+		alias, target = parts[0], strings.TrimSpace(parts[1])
+	}
+	if !strings.HasPrefix(target, "#") && !strings.HasPrefix(target, "/") &&
+		!strings.HasPrefix(target, "mailto:") && !strings.Contains(target, "://") {
+		return "", false
+	}
+	text := escapeText(strings.TrimPrefix(target, "mailto:"))
+	if alias != "" {
+		text = renderJiraInline(alias)
+	}
+	return "<a href=\"" + escapeText(target) + "\">" + text + "</a>", true
+}
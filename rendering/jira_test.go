@@ -0,0 +1,71 @@
+package rendering_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderJiraWikiInline(t *testing.T) {
+	for content, expected := range map[string]string{
+		"Hello, World!":                        "<p>Hello, World!</p>\n",
+		"*strong* and _emphasis_":              "<p><strong>strong</strong> and <em>emphasis</em></p>\n",
+		"-deleted- +inserted+ ^sup^ ~sub~":     "<p><del>deleted</del> <ins>inserted</ins> <sup>sup</sup> <sub>sub</sub></p>\n",
+		"??citation?? and {{monospace}}":       "<p><cite>citation</cite> and <code>monospace</code></p>\n",
+		"a well-known fact - or not":           "<p>a well-known fact - or not</p>\n",
+		"*bold _and italic_*":                  "<p><strong>bold <em>and italic</em></strong></p>\n",
+		"first\nsecond":                        "<p>first<br/>\nsecond</p>\n",
+		`line\\break`:                          "<p>line<br/>break</p>\n",
+		`not \*strong\*`:                       "<p>not *strong*</p>\n",
+		"<script>alert('x')</script>":          "<p>&lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</p>\n",
+		"&lt;escaped&gt;":                      "<p>&lt;escaped&gt;</p>\n",
+		"[~jdoe] please review":                "<p><span class=\"user-mention\">@jdoe</span> please review</p>\n",
+		"[Almighty|https://almighty.io]":       "<p><a href=\"https://almighty.io\" rel=\"nofollow\">Almighty</a></p>\n",
+		"[https://almighty.io]":                "<p><a href=\"https://almighty.io\" rel=\"nofollow\">https://almighty.io</a></p>\n",
+		"see https://almighty.io.":             "<p>see <a href=\"https://almighty.io\" rel=\"nofollow\">https://almighty.io</a>.</p>\n",
+		"[mail|mailto:jdoe@example.com]":       "<p><a href=\"mailto:jdoe@example.com\" rel=\"nofollow\">mail</a></p>\n",
+		"[click|javascript:alert(1)] [broken]": "<p>[click|javascript:alert(1)] [broken]</p>\n",
+	} {
+		assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki), content)
+	}
+}
+
+func TestRenderJiraWikiHeadingsAndQuotes(t *testing.T) {
+	content := "h1. Title\nh3. *Sub* title\n\nbq. quoted\n{quote}\nquoted *block*\n{quote}\n----"
+	expected := "<h1>Title</h1>\n<h3><strong>Sub</strong> title</h3>\n" +
+		"<blockquote><p>quoted</p></blockquote>\n" +
+		"<blockquote>\n<p>quoted <strong>block</strong></p>\n</blockquote>\n" +
+		"<hr/>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki))
+}
+
+func TestRenderJiraWikiLists(t *testing.T) {
+	content := "* one\n* two\n** two.one\n*# two.one.one\n* three\n\n# first\n# second"
+	expected := "<ul>\n<li>one</li>\n<li>two<ul>\n<li>two.one</li>\n</ul>\n<ol>\n<li>two.one.one</li>\n</ol>\n</li>\n<li>three</li>\n</ul>\n" +
+		"<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki))
+}
+
+func TestRenderJiraWikiTable(t *testing.T) {
+	content := "||Name||Link||\n|foo|[bar|https://almighty.io/bar]|\n| *baz* | {{a|b}} |"
+	expected := "<table>\n<tbody>\n" +
+		"<tr><th>Name</th><th>Link</th></tr>\n" +
+		"<tr><td>foo</td><td><a href=\"https://almighty.io/bar\" rel=\"nofollow\">bar</a></td></tr>\n" +
+		"<tr><td><strong>baz</strong></td><td><code>a|b</code></td></tr>\n" +
+		"</tbody>\n</table>\n"
+	assert.Equal(t, expected, rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki))
+}
+
+func TestRenderJiraWikiCode(t *testing.T) {
+	content := "{code:go}\nfunc getTrue() bool {return true}\n{code}"
+	result := rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki)
+	t.Log(result)
+	assert.True(t, strings.Contains(result, "<code class=\"prettyprint language-go\">"))
+	assert.True(t, strings.Contains(result, "<span class=\"kwd\">func</span>"))
+
+	content = "{noformat}\n*not strong* <b>\n{noformat}"
+	result = rendering.RenderMarkupToHTML(content, rendering.SystemMarkupJiraWiki)
+	assert.Equal(t, "<pre>*not strong* &lt;b&gt;</pre>\n", result)
+}
@@ -8,26 +8,51 @@ import (
 
 // IsMarkupSupported indicates if the given markup is supported
 func IsMarkupSupported(markup string) bool {
-	if markup == SystemMarkupDefault || markup == SystemMarkupMarkdown {
+	if markup == SystemMarkupDefault || markup == SystemMarkupMarkdown || markup == SystemMarkupJiraWiki ||
+		markup == SystemMarkupAsciiDoc {
 		return true
 	}
 	return false
 }
 
+// sanitizePolicy is the policy used to sanitize the rendered HTML, which keeps the
+// classes of the highlighted code blocks and of the admonitions. A policy is safe for
+// concurrent use once built.
+var sanitizePolicy = newSanitizePolicy()
+
 // RenderMarkupToHTML converts the given `content` in HTML using the markup tool corresponding to the given `markup` argument
 // or return nil if no tool for the given `markup` is available, or returns an `error` if the command was not found or failed.
 func RenderMarkupToHTML(content, markup string) string {
-	switch markup {
-	case SystemMarkupPlainText:
+	if markup == SystemMarkupPlainText {
 		return content
+	}
+	unsafe := renderUnsafe(content, markup)
+	if unsafe == nil {
+		return ""
+	}
+	return string(sanitizePolicy.SanitizeBytes(unsafe))
+}
+
+// renderUnsafe converts the given `content` in HTML without sanitizing it, or returns nil if
+// the given `markup` is not supported or is plain text
+func renderUnsafe(content, markup string) []byte {
+	switch markup {
 	case SystemMarkupMarkdown:
-		unsafe := MarkdownCommonHighlighter([]byte(content))
-		p := bluemonday.UGCPolicy()
-		p.AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$|prettyprint")).OnElements("code")
-		p.AllowAttrs("class").OnElements("span")
-		html := string(p.SanitizeBytes(unsafe))
-		return html
+		return MarkdownCommonHighlighter([]byte(content))
+	case SystemMarkupJiraWiki:
+		return JiraWikiToHTML([]byte(content))
+	case SystemMarkupAsciiDoc:
+		return AsciiDocToHTML([]byte(content))
 	default:
-		return ""
+		return nil
 	}
 }
+
+// newSanitizePolicy builds the policy used to sanitize the rendered HTML
+func newSanitizePolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$|prettyprint")).OnElements("code")
+	p.AllowAttrs("class").OnElements("span")
+	p.AllowAttrs("class").Matching(regexp.MustCompile("^admonition admonition-(note|tip|important|warning|caution)$")).OnElements("div")
+	return p
+}
@@ -38,6 +38,8 @@ func TestIsMarkupSupported(t *testing.T) {
 	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupDefault))
 	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupPlainText))
 	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupMarkdown))
+	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupJiraWiki))
+	assert.True(t, rendering.IsMarkupSupported(rendering.SystemMarkupAsciiDoc))
 	assert.False(t, rendering.IsMarkupSupported(""))
 	assert.False(t, rendering.IsMarkupSupported("foo"))
 }
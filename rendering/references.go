@@ -0,0 +1,187 @@
+package rendering
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// referencePattern matches the work item references ("#123") and the user mentions ("@username")
+// with the character which precedes them, so that e-mail addresses, URL fragments and entities
+// are not taken for references.
+var referencePattern = regexp.MustCompile(`(?:^|[^\w&#/@.-])(#([0-9]+)|@([A-Za-z0-9](?:[\w.-]*[\w])?))`)
+
+// References holds the work items and the users referenced by a content
+type References struct {
+	// WorkItemIDs holds the IDs of the work items referenced with "#ID"
+	WorkItemIDs []string
+	// Usernames holds the usernames of the users mentioned with "@username"
+	Usernames []string
+}
+
+// WorkItemReference is a work item which a reference resolves to
+type WorkItemReference struct {
+	URL   string
+	Title string
+}
+
+// UserReference is a user which a mention resolves to
+type UserReference struct {
+	URL      string
+	FullName string
+	ImageURL string
+}
+
+// ResolvedReferences holds the work items (by ID) and the users (by username) which the
+// references of some contents resolve to
+type ResolvedReferences struct {
+	WorkItems map[string]WorkItemReference
+	Users     map[string]UserReference
+}
+
+// reference is a reference found in a text
+type reference struct {
+	start, end int
+	workItemID string
+	username   string
+}
+
+// findReferences returns the references found in the given text
+func findReferences(text string) []reference {
+	var result []reference
+	for _, match := range referencePattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		if end < len(text) && isReferenceChar(text[end]) {
+			// "#12abc" and "@user@host" are not references
+			continue
+		}
+		ref := reference{start: start, end: end}
+		if match[4] >= 0 {
+			ref.workItemID = text[match[4]:match[5]]
+		} else {
+			ref.username = text[match[6]:match[7]]
+		}
+		result = append(result, ref)
+	}
+	return result
+}
+
+func isReferenceChar(c byte) bool {
+	return isWordChar(c) || c == '_' || c == '@'
+}
+
+// ExtractReferences returns the work items and users referenced in the given content,
+// ignoring the references in code blocks and links. The HTML is only scanned for its text,
+// hence it is not sanitized.
+func ExtractReferences(content, markup string) References {
+	var texts []string
+	if markup == SystemMarkupPlainText {
+		texts = []string{content}
+	} else {
+		walkText(string(renderUnsafe(content, markup)), func(raw []byte, text string, inCode bool) {
+			if !inCode {
+				texts = append(texts, text)
+			}
+		})
+	}
+	result := References{}
+	workItemIDs := map[string]bool{}
+	usernames := map[string]bool{}
+	for _, text := range texts {
+		for _, ref := range findReferences(text) {
+			if ref.workItemID != "" && !workItemIDs[ref.workItemID] {
+				workItemIDs[ref.workItemID] = true
+				result.WorkItemIDs = append(result.WorkItemIDs, ref.workItemID)
+			}
+			if ref.username != "" && !usernames[ref.username] {
+				usernames[ref.username] = true
+				result.Usernames = append(result.Usernames, ref.username)
+			}
+		}
+	}
+	return result
+}
+
+// RenderMarkupToHTMLWithReferences converts the given `content` in HTML like RenderMarkupToHTML and
+// turns the references to the given work items and users into links. Plain text is not converted.
+func RenderMarkupToHTMLWithReferences(content, markup string, references ResolvedReferences) string {
+	rendered := RenderMarkupToHTML(content, markup)
+	if markup == SystemMarkupPlainText || len(references.WorkItems) == 0 && len(references.Users) == 0 {
+		return rendered
+	}
+	var out bytes.Buffer
+	walkText(rendered, func(raw []byte, text string, inCode bool) {
+		refs := findReferences(text)
+		if inCode || len(refs) == 0 {
+			out.Write(raw)
+			return
+		}
+		last := 0
+		for _, ref := range refs {
+			link, ok := renderReference(ref, references)
+			if !ok {
+				continue
+			}
+			out.WriteString(html.EscapeString(text[last:ref.start]))
+			out.WriteString(link)
+			last = ref.end
+		}
+		out.WriteString(html.EscapeString(text[last:]))
+	})
+	return out.String()
+}
+
+// renderReference renders the link to the work item or the user of the given reference,
+// or returns false if the reference is not resolved
+func renderReference(ref reference, references ResolvedReferences) (string, bool) {
+	if ref.workItemID != "" {
+		workItem, ok := references.WorkItems[ref.workItemID]
+		if !ok {
+			return "", false
+		}
+		return "<a href=\"" + html.EscapeString(workItem.URL) + "\" class=\"work-item-reference\" title=\"" +
+			html.EscapeString(workItem.Title) + "\">#" + ref.workItemID + "</a>", true
+	}
+	user, ok := references.Users[ref.username]
+	if !ok {
+		return "", false
+	}
+	link := "<a href=\"" + html.EscapeString(user.URL) + "\" class=\"user-mention\" title=\"" + html.EscapeString(user.FullName) + "\">"
+	if user.ImageURL != "" {
+		link += "<img src=\"" + html.EscapeString(user.ImageURL) + "\" alt=\"\" class=\"user-mention-avatar\"/>"
+	}
+	return link + "@" + html.EscapeString(ref.username) + "</a>", true
+}
+
+// walkText calls the given function for each token of the given HTML, with the raw token and,
+// for the text tokens, the unescaped text and whether it is part of a code block or a link.
+// The raw tokens of the other tokens are passed unchanged.
+func walkText(s string, f func(raw []byte, text string, inCode bool)) {
+	z := html.NewTokenizer(strings.NewReader(s))
+	depth := 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return
+		}
+		raw := z.Raw()
+		switch tt {
+		case html.TextToken:
+			f(raw, string(z.Text()), depth > 0)
+			continue
+		case html.StartTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "a", "code", "pre":
+				if tt == html.StartTagToken {
+					depth++
+				} else if depth > 0 {
+					depth--
+				}
+			}
+		}
+		f(raw, "", true)
+	}
+}
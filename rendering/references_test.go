@@ -0,0 +1,71 @@
+package rendering_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractReferencesFromPlainText(t *testing.T) {
+	// when
+	references := rendering.ExtractReferences("see #12, #3 and #12 (cc @jdoe, @jane.doe.)", rendering.SystemMarkupPlainText)
+	// then
+	assert.Equal(t, []string{"12", "3"}, references.WorkItemIDs)
+	assert.Equal(t, []string{"jdoe", "jane.doe"}, references.Usernames)
+}
+
+func TestExtractReferencesIgnoresNonReferences(t *testing.T) {
+	for _, content := range []string{
+		"jdoe@example.com",
+		"http://example.com/#12",
+		"issue#12",
+		"#12abc",
+		"@jdoe@example",
+		"&#12;",
+	} {
+		references := rendering.ExtractReferences(content, rendering.SystemMarkupPlainText)
+		assert.Empty(t, references.WorkItemIDs, content)
+		assert.Empty(t, references.Usernames, content)
+	}
+}
+
+func TestExtractReferencesFromMarkdown(t *testing.T) {
+	// when
+	references := rendering.ExtractReferences("fixed by #1 and @jdoe\n\n`#2` [#3](http://example.com)\n\n```\n@jane\n```", rendering.SystemMarkupMarkdown)
+	// then
+	assert.Equal(t, []string{"1"}, references.WorkItemIDs)
+	assert.Equal(t, []string{"jdoe"}, references.Usernames)
+}
+
+func TestRenderMarkupToHTMLWithReferences(t *testing.T) {
+	// given
+	references := rendering.ResolvedReferences{
+		WorkItems: map[string]rendering.WorkItemReference{
+			"1": {URL: "http://example.com/workitems/1", Title: "A <title>"},
+		},
+		Users: map[string]rendering.UserReference{
+			"jdoe": {URL: "http://example.com/users/42", FullName: "John Doe", ImageURL: "http://example.com/jdoe.png"},
+		},
+	}
+	// when
+	result := rendering.RenderMarkupToHTMLWithReferences("fixed by #1 and #2, thanks @jdoe & @jane\n\n`#1`", rendering.SystemMarkupMarkdown, references)
+	// then
+	expected := "<p>fixed by <a href=\"http://example.com/workitems/1\" class=\"work-item-reference\" title=\"A &lt;title&gt;\">#1</a> and #2, thanks " +
+		"<a href=\"http://example.com/users/42\" class=\"user-mention\" title=\"John Doe\"><img src=\"http://example.com/jdoe.png\" alt=\"\" class=\"user-mention-avatar\"/>@jdoe</a> &amp; @jane</p>\n\n" +
+		"<p><code>#1</code></p>\n"
+	assert.Equal(t, expected, result)
+}
+
+func TestRenderPlainTextWithReferences(t *testing.T) {
+	// given
+	references := rendering.ResolvedReferences{
+		WorkItems: map[string]rendering.WorkItemReference{
+			"1": {URL: "http://example.com/workitems/1", Title: "title"},
+		},
+	}
+	// when
+	result := rendering.RenderMarkupToHTMLWithReferences("see #1", rendering.SystemMarkupPlainText, references)
+	// then
+	assert.Equal(t, "see #1", result)
+}
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"database/sql"
 	"fmt"
 	"sync"
 
@@ -15,7 +16,9 @@ import (
 	"net/url"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/log"
 	"github.com/almighty/almighty-core/rest"
 	"github.com/almighty/almighty-core/space"
@@ -282,10 +285,36 @@ func generateSQLSearchInfo(keywords searchKeyword) (sqlParameter string) {
 	return searchStr
 }
 
+// searchQuery returns the query for the work items matching the search query, the filter
+// and the work item types. The rank of the work items is available as "rank".
+func (r *GormSearchRepository) searchQuery(sqlSearchQueryParameter string, workItemTypes []uuid.UUID, filter criteria.Expression) (*gorm.DB, error) {
+	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
+	if filter != nil {
+		where, parameters, compileErrors := workitem.Compile(filter)
+		if len(compileErrors) > 0 {
+			return nil, errors.NewBadParameterError("filter", compileErrors[0].Error())
+		}
+		db = db.Where(where, parameters...)
+	}
+	if len(workItemTypes) > 0 {
+		// restrict to all given types and their subtypes
+		query := fmt.Sprintf("%[1]s.type in ("+
+			"select distinct subtype.id from %[2]s subtype "+
+			"join %[2]s supertype on subtype.path <@ supertype.path "+
+			"where supertype.id in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
+		db = db.Where(query, workItemTypes)
+	}
+	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
+	return db, nil
+}
+
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []uuid.UUID, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
-	db := r.db.Model(workitem.WorkItem{}).Where("tsv @@ query")
+func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []uuid.UUID, filter criteria.Expression, sortKeys []workitem.SortKey, start *int, limit *int) ([]workitem.WorkItem, uint64, error) {
+	db, err := r.searchQuery(sqlSearchQueryParameter, workItemTypes, filter)
+	if err != nil {
+		return nil, 0, err
+	}
 	if start != nil {
 		if *start < 0 {
 			return nil, 0, errors.NewBadParameterError("start", *start)
@@ -298,18 +327,18 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 		}
 		db = db.Limit(*limit)
 	}
-	if len(workItemTypes) > 0 {
-		// restrict to all given types and their subtypes
-		query := fmt.Sprintf("%[1]s.type in ("+
-			"select distinct subtype.id from %[2]s subtype "+
-			"join %[2]s supertype on subtype.path <@ supertype.path "+
-			"where supertype.id in (?))", workitem.WorkItem{}.TableName(), workitem.WorkItemType{}.TableName())
-		db = db.Where(query, workItemTypes)
-	}
 
 	db = db.Select("count(*) over () as cnt2 , *")
-	db = db.Joins(", to_tsquery('english', ?) as query, ts_rank(tsv, query) as rank", sqlSearchQueryParameter)
-	db = db.Order(fmt.Sprintf("rank desc,%s.updated_at desc", workitem.WorkItem{}.TableName()))
+	if len(sortKeys) == 0 {
+		// most relevant first, the tie-breaker by ID keeps the order stable for paging
+		db = db.Order(fmt.Sprintf("rank desc,%[1]s.updated_at desc,%[1]s.id desc", workitem.WorkItem{}.TableName()))
+	} else {
+		order, err := workitem.CompileSortKeys(sortKeys)
+		if err != nil {
+			return nil, 0, errors.NewBadParameterError("sort", sortKeys).Expected(err.Error())
+		}
+		db = db.Order(order)
+	}
 
 	rows, err := db.Rows()
 	if err != nil {
@@ -354,8 +383,62 @@ func (r *GormSearchRepository) search(ctx context.Context, sqlSearchQueryParamet
 	//*/
 }
 
-// SearchFullText Search returns work items for the given query
-func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+// relevanceKeys is the order of search results when paging with cursors and
+// no sort keys are given. It is the same as the order used with offsets, the
+// rank is compared as double precision to be read back without loss.
+var relevanceKeys = []keyset.Key{
+	{Expression: "rank::double precision", Type: "double precision", Descending: true},
+	{Expression: workitem.WorkItem{}.TableName() + ".updated_at", Type: "timestamptz", Descending: true},
+	{Expression: workitem.WorkItem{}.TableName() + ".id", Type: "bigint", Descending: true},
+}
+
+// searchByCursor is the keyset pagination variant of search()
+func (r *GormSearchRepository) searchByCursor(ctx context.Context, sqlSearchQueryParameter string, workItemTypes []uuid.UUID, filter criteria.Expression, sortKeys []workitem.SortKey, cursor keyset.Cursor, limit int) ([]workitem.WorkItem, *keyset.Page, error) {
+	if limit <= 0 {
+		return nil, nil, errors.NewBadParameterError("limit", limit)
+	}
+	db, err := r.searchQuery(sqlSearchQueryParameter, workItemTypes, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := relevanceKeys
+	if len(sortKeys) > 0 {
+		keys, err = workitem.SortKeyset(sortKeys)
+		if err != nil {
+			return nil, nil, errors.NewBadParameterError("sort", sortKeys).Expected(err.Error())
+		}
+	}
+	where, parameters, err := keyset.Where(keys, cursor)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	// fetch one more item to find out if there is a next page
+	db = db.Where(where, parameters...).Limit(limit + 1)
+	db = db.Select("*, " + keyset.Columns(keys)).Order(keyset.Order(keys, cursor.Backward))
+
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	defer rows.Close()
+	values, page, err := keyset.ScanPage(rows, keys, cursor, limit, func(rows *sql.Rows) (interface{}, error) {
+		value := &workitem.WorkItem{}
+		return value, db.ScanRows(rows, value)
+	})
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	result := make([]workitem.WorkItem, len(values))
+	for i, value := range values {
+		result[i] = *value.(*workitem.WorkItem)
+	}
+	return result, page, nil
+}
+
+// SearchFullText Search returns work items for the given query, restricted to the
+// work items matching the given filter expression (if not nil).
+// Results are ordered by relevance unless sort keys are given.
+func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchString string, filter criteria.Expression, sortKeys []workitem.SortKey, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
 	// parse
 	// generateSearchQuery
 	// ....
@@ -366,10 +449,39 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 
 	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
 	var rows []workitem.WorkItem
-	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, start, limit)
+	rows, count, err := r.search(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, filter, sortKeys, start, limit)
 	if err != nil {
 		return nil, 0, errs.WithStack(err)
 	}
+	result, err := r.convertWorkItems(ctx, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, count, nil
+}
+
+// SearchFullTextByCursor is like SearchFullText but returns at most limit work
+// items which come after (or before, for backward cursors) the given cursor.
+// It does not count the matching work items.
+func (r *GormSearchRepository) SearchFullTextByCursor(ctx context.Context, rawSearchString string, filter criteria.Expression, sortKeys []workitem.SortKey, cursor keyset.Cursor, limit int) ([]*app.WorkItem, *keyset.Page, error) {
+	parsedSearchDict, err := parseSearchString(rawSearchString)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+
+	sqlSearchQueryParameter := generateSQLSearchInfo(parsedSearchDict)
+	rows, page, err := r.searchByCursor(ctx, sqlSearchQueryParameter, parsedSearchDict.workItemTypes, filter, sortKeys, cursor, limit)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	result, err := r.convertWorkItems(ctx, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, page, nil
+}
+
+func (r *GormSearchRepository) convertWorkItems(ctx context.Context, rows []workitem.WorkItem) ([]*app.WorkItem, error) {
 	result := make([]*app.WorkItem, len(rows))
 
 	for index, value := range rows {
@@ -377,15 +489,14 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 		// FIXME: Against best practice http://go-database-sql.org/retrieving.html
 		wiType, err := r.wir.LoadTypeFromDB(ctx, value.Type)
 		if err != nil {
-			return nil, 0, errors.NewInternalError(err.Error())
+			return nil, errors.NewInternalError(err.Error())
 		}
 		result[index], err = convertFromModel(goa.ContextRequest(ctx), *wiType, value)
 		if err != nil {
-			return nil, 0, errors.NewConversionError(err.Error())
+			return nil, errors.NewConversionError(err.Error())
 		}
 	}
-
-	return result, count, nil
+	return result, nil
 }
 
 func init() {
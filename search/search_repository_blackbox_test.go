@@ -9,6 +9,7 @@ import (
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/resource"
@@ -74,7 +75,7 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	params := url.Values{}
 	ctx := goa.NewContext(context.Background(), nil, req, params)
 
-	res, count, err := s.searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil)
+	res, count, err := s.searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil, nil, nil)
 	require.Nil(s.T(), err)
 	require.True(s.T(), count == uint64(len(res))) // safety check for many, many instances of bogus search results.
 	for _, wi := range res {
@@ -114,37 +115,67 @@ func (s *searchRepositoryBlackboxTest) TestRestrictByType() {
 	require.Nil(s.T(), err)
 	require.NotNil(s.T(), wi2)
 
-	res, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil)
+	res, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType", nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	res, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+sub1.Data.ID.String(), nil, nil)
+	res, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+sub1.Data.ID.String(), nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(1), count)
 	if count == 1 {
 		assert.Equal(s.T(), wi1.ID, res[0].ID)
 	}
 
-	res, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+sub2.Data.ID.String(), nil, nil)
+	res, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+sub2.Data.ID.String(), nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(1), count)
 	if count == 1 {
 		assert.Equal(s.T(), wi2.ID, res[0].ID)
 	}
 
-	_, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+base.Data.ID.String(), nil, nil)
+	_, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+base.Data.ID.String(), nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+sub2.Data.ID.String()+" type:"+sub1.Data.ID.String(), nil, nil)
+	_, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+sub2.Data.ID.String()+" type:"+sub1.Data.ID.String(), nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+base.Data.ID.String()+" type:"+sub1.Data.ID.String(), nil, nil)
+	_, count, err = s.searchRepo.SearchFullText(ctx, "TestRestrictByType type:"+base.Data.ID.String()+" type:"+sub1.Data.ID.String(), nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(2), count)
 
-	_, count, err = s.searchRepo.SearchFullText(ctx, "TRBTgorxi type:"+base.Data.ID.String(), nil, nil)
+	_, count, err = s.searchRepo.SearchFullText(ctx, "TRBTgorxi type:"+base.Data.ID.String(), nil, nil, nil, nil)
 	assert.Nil(s.T(), err)
 	assert.Equal(s.T(), uint64(0), count)
 }
+
+func (s *searchRepositoryBlackboxTest) TestSearchByCursor() {
+	// given
+	req := &http.Request{Host: "localhost"}
+	params := url.Values{}
+	ctx := goa.NewContext(context.Background(), nil, req, params)
+	word := "tsbc" + uuid.NewV4().String()[:8]
+	for _, title := range []string{word, word + " " + word} {
+		_, err := s.wiRepo.Create(ctx, space.SystemSpace, workitem.SystemBug, map[string]interface{}{
+			workitem.SystemTitle: title,
+			workitem.SystemState: "closed",
+		}, s.modifierID)
+		require.Nil(s.T(), err)
+	}
+	// when
+	first, page, err := s.searchRepo.SearchFullTextByCursor(ctx, word, nil, nil, keyset.Cursor{}, 1)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), first, 1)
+	assert.Nil(s.T(), page.Prev)
+	require.NotNil(s.T(), page.Next)
+	// when
+	second, page, err := s.searchRepo.SearchFullTextByCursor(ctx, word, nil, nil, *page.Next, 1)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), second, 1)
+	assert.NotEqual(s.T(), first[0].ID, second[0].ID)
+	assert.NotNil(s.T(), page.Prev)
+	assert.Nil(s.T(), page.Next)
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/almighty/almighty-core/auth"
 	"github.com/almighty/almighty-core/comment"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/almighty/almighty-core/workitem/link"
 )
@@ -26,6 +28,9 @@ func (db *MockDB) WorkItems() workitem.WorkItemRepository {
 func (db *MockDB) WorkItemTypes() workitem.WorkItemTypeRepository {
 	return nil
 }
+func (db *MockDB) WorkItemRevisions() workitem.RevisionRepository {
+	return nil
+}
 
 func (db *MockDB) Spaces() space.Repository {
 	return nil
@@ -62,6 +67,12 @@ func (db *MockDB) WorkItemLinks() link.WorkItemLinkRepository {
 func (db *MockDB) Comments() comment.Repository {
 	return nil
 }
+func (db *MockDB) CommentRevisions() comment.RevisionRepository {
+	return nil
+}
+func (db *MockDB) References() reference.Repository {
+	return nil
+}
 
 func (db *MockDB) Iterations() iteration.Repository {
 	return nil
@@ -75,6 +86,10 @@ func (g *MockDB) OauthStates() auth.OauthStateReferenceRepository {
 	return nil
 }
 
+func (db *MockDB) Webhooks() webhook.Repository {
+	return nil
+}
+
 func (db *MockDB) Commit() error {
 	return nil
 }
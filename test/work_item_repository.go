@@ -6,6 +6,7 @@ import (
 
 	"github.com/almighty/almighty-core/app"
 	"github.com/almighty/almighty-core/criteria"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/workitem"
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/net/context"
@@ -56,6 +57,19 @@ type WorkItemRepository struct {
 	deleteReturns struct {
 		result1 error
 	}
+	RestoreStub        func(ctx context.Context, ID string, revisionID uuid.UUID, version int, modifierID uuid.UUID) (*app.WorkItem, error)
+	restoreMutex       sync.RWMutex
+	restoreArgsForCall []struct {
+		ctx        context.Context
+		ID         string
+		revisionID uuid.UUID
+		version    int
+		modifierID uuid.UUID
+	}
+	restoreReturns struct {
+		result1 *app.WorkItem
+		result2 error
+	}
 	CreateStub        func(ctx context.Context, spaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, creatorID uuid.UUID) (*app.WorkItem, error)
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
@@ -69,11 +83,12 @@ type WorkItemRepository struct {
 		result1 *app.WorkItem
 		result2 error
 	}
-	ListStub        func(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	ListStub        func(ctx context.Context, criteria criteria.Expression, sortKeys []workitem.SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
 		ctx      context.Context
 		criteria criteria.Expression
+		sortKeys []workitem.SortKey
 		start    *int
 		length   *int
 	}
@@ -82,6 +97,20 @@ type WorkItemRepository struct {
 		result2 uint64
 		result3 error
 	}
+	ListByCursorStub        func(ctx context.Context, criteria criteria.Expression, sortKeys []workitem.SortKey, cursor keyset.Cursor, limit int) ([]*app.WorkItem, *keyset.Page, error)
+	listByCursorMutex       sync.RWMutex
+	listByCursorArgsForCall []struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		sortKeys []workitem.SortKey
+		cursor   keyset.Cursor
+		limit    int
+	}
+	listByCursorReturns struct {
+		result1 []*app.WorkItem
+		result2 *keyset.Page
+		result3 error
+	}
 	FetchStub        func(ctx context.Context, criteria criteria.Expression) (*app.WorkItem, error)
 	fetchMutex       sync.RWMutex
 	fetchArgsForCall []struct {
@@ -264,6 +293,43 @@ func (fake *WorkItemRepository) DeleteReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *WorkItemRepository) Restore(ctx context.Context, ID string, revisionID uuid.UUID, version int, modifierID uuid.UUID) (*app.WorkItem, error) {
+	fake.restoreMutex.Lock()
+	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
+		ctx        context.Context
+		ID         string
+		revisionID uuid.UUID
+		version    int
+		modifierID uuid.UUID
+	}{ctx, ID, revisionID, version, modifierID})
+	fake.recordInvocation("Restore", []interface{}{ctx, ID, revisionID, version, modifierID})
+	fake.restoreMutex.Unlock()
+	if fake.RestoreStub != nil {
+		return fake.RestoreStub(ctx, ID, revisionID, version, modifierID)
+	}
+	return fake.restoreReturns.result1, fake.restoreReturns.result2
+}
+
+func (fake *WorkItemRepository) RestoreCallCount() int {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return len(fake.restoreArgsForCall)
+}
+
+func (fake *WorkItemRepository) RestoreArgsForCall(i int) (context.Context, string, uuid.UUID, int, uuid.UUID) {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return fake.restoreArgsForCall[i].ctx, fake.restoreArgsForCall[i].ID, fake.restoreArgsForCall[i].revisionID, fake.restoreArgsForCall[i].version, fake.restoreArgsForCall[i].modifierID
+}
+
+func (fake *WorkItemRepository) RestoreReturns(result1 *app.WorkItem, result2 error) {
+	fake.RestoreStub = nil
+	fake.restoreReturns = struct {
+		result1 *app.WorkItem
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *WorkItemRepository) Create(ctx context.Context, spaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, creatorID uuid.UUID) (*app.WorkItem, error) {
 	fake.createMutex.Lock()
 	fake.createArgsForCall = append(fake.createArgsForCall, struct {
@@ -301,18 +367,19 @@ func (fake *WorkItemRepository) CreateReturns(result1 *app.WorkItem, result2 err
 	}{result1, result2}
 }
 
-func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error) {
+func (fake *WorkItemRepository) List(ctx context.Context, c criteria.Expression, sortKeys []workitem.SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error) {
 	fake.listMutex.Lock()
 	fake.listArgsForCall = append(fake.listArgsForCall, struct {
 		ctx      context.Context
 		criteria criteria.Expression
+		sortKeys []workitem.SortKey
 		start    *int
 		length   *int
-	}{ctx, c, start, length})
-	fake.recordInvocation("List", []interface{}{ctx, c, start, length})
+	}{ctx, c, sortKeys, start, length})
+	fake.recordInvocation("List", []interface{}{ctx, c, sortKeys, start, length})
 	fake.listMutex.Unlock()
 	if fake.ListStub != nil {
-		return fake.ListStub(ctx, c, start, length)
+		return fake.ListStub(ctx, c, sortKeys, start, length)
 	}
 	return fake.listReturns.result1, fake.listReturns.result2, fake.listReturns.result3
 }
@@ -323,10 +390,10 @@ func (fake *WorkItemRepository) ListCallCount() int {
 	return len(fake.listArgsForCall)
 }
 
-func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, *int, *int) {
+func (fake *WorkItemRepository) ListArgsForCall(i int) (context.Context, criteria.Expression, []workitem.SortKey, *int, *int) {
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
-	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length
+	return fake.listArgsForCall[i].ctx, fake.listArgsForCall[i].criteria, fake.listArgsForCall[i].sortKeys, fake.listArgsForCall[i].start, fake.listArgsForCall[i].length
 }
 
 func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uint64, result3 error) {
@@ -338,6 +405,44 @@ func (fake *WorkItemRepository) ListReturns(result1 []*app.WorkItem, result2 uin
 	}{result1, result2, result3}
 }
 
+func (fake *WorkItemRepository) ListByCursor(ctx context.Context, c criteria.Expression, sortKeys []workitem.SortKey, cursor keyset.Cursor, limit int) ([]*app.WorkItem, *keyset.Page, error) {
+	fake.listByCursorMutex.Lock()
+	fake.listByCursorArgsForCall = append(fake.listByCursorArgsForCall, struct {
+		ctx      context.Context
+		criteria criteria.Expression
+		sortKeys []workitem.SortKey
+		cursor   keyset.Cursor
+		limit    int
+	}{ctx, c, sortKeys, cursor, limit})
+	fake.recordInvocation("ListByCursor", []interface{}{ctx, c, sortKeys, cursor, limit})
+	fake.listByCursorMutex.Unlock()
+	if fake.ListByCursorStub != nil {
+		return fake.ListByCursorStub(ctx, c, sortKeys, cursor, limit)
+	}
+	return fake.listByCursorReturns.result1, fake.listByCursorReturns.result2, fake.listByCursorReturns.result3
+}
+
+func (fake *WorkItemRepository) ListByCursorCallCount() int {
+	fake.listByCursorMutex.RLock()
+	defer fake.listByCursorMutex.RUnlock()
+	return len(fake.listByCursorArgsForCall)
+}
+
+func (fake *WorkItemRepository) ListByCursorArgsForCall(i int) (context.Context, criteria.Expression, []workitem.SortKey, keyset.Cursor, int) {
+	fake.listByCursorMutex.RLock()
+	defer fake.listByCursorMutex.RUnlock()
+	return fake.listByCursorArgsForCall[i].ctx, fake.listByCursorArgsForCall[i].criteria, fake.listByCursorArgsForCall[i].sortKeys, fake.listByCursorArgsForCall[i].cursor, fake.listByCursorArgsForCall[i].limit
+}
+
+func (fake *WorkItemRepository) ListByCursorReturns(result1 []*app.WorkItem, result2 *keyset.Page, result3 error) {
+	fake.ListByCursorStub = nil
+	fake.listByCursorReturns = struct {
+		result1 []*app.WorkItem
+		result2 *keyset.Page
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *WorkItemRepository) Fetch(ctx context.Context, c criteria.Expression) (*app.WorkItem, error) {
 	fake.fetchMutex.Lock()
 	fake.fetchArgsForCall = append(fake.fetchArgsForCall, struct {
@@ -449,10 +554,14 @@ func (fake *WorkItemRepository) Invocations() map[string][][]interface{} {
 	defer fake.saveMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
+	fake.listByCursorMutex.RLock()
+	defer fake.listByCursorMutex.RUnlock()
 	fake.fetchMutex.RLock()
 	defer fake.fetchMutex.RUnlock()
 	fake.getCountsPerIterationMutex.RLock()
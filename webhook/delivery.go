@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// The statuses of a delivery
+const (
+	// DeliveryPending means that the event was not delivered yet, the next attempt is scheduled
+	DeliveryPending = "pending"
+	// DeliverySucceeded means that the URL of the subscription accepted the event
+	DeliverySucceeded = "succeeded"
+	// DeliveryFailed means that all the attempts to deliver the event failed
+	DeliveryFailed = "failed"
+)
+
+// Delivery records the delivery of an event to a subscription: it is created when the event is
+// dispatched, and updated after each attempt to send it
+type Delivery struct {
+	ID             uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	SubscriptionID uuid.UUID `sql:"type:uuid"`
+	EventID        uuid.UUID `sql:"type:uuid"`
+	EventType      string
+	Status         string
+	Attempts       int
+	// NextAttemptAt is the time of the next attempt of a pending delivery
+	NextAttemptAt time.Time
+	// ResponseStatus is the HTTP status of the response to the last attempt, if any
+	ResponseStatus *int
+	// Error describes why the last attempt failed, if it did
+	Error       *string
+	DeliveredAt *time.Time
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *Delivery) TableName() string {
+	return "webhook_deliveries"
+}
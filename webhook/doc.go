@@ -0,0 +1,3 @@
+// Package webhook provides the subscriptions of the spaces to the events of their work items,
+// comments and links, and the delivery of these events to the URLs of the subscriptions.
+package webhook
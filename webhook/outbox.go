@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Event is an entry of the outbox: a change of a work item, comment or link, recorded in the same transaction
+// as the change itself. The events are dispatched to the matching subscriptions of their space by the Worker.
+type Event struct {
+	ID        uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	CreatedAt time.Time
+	SpaceID   uuid.UUID `sql:"type:uuid"`
+	EventType string
+	// Payload is the JSON representation of the changed entity
+	Payload string `sql:"type:jsonb"`
+	// DispatchedAt is the time when the deliveries of the event were created, nil until then
+	DispatchedAt *time.Time
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *Event) TableName() string {
+	return "webhook_events"
+}
+
+// Enqueue records an event of the given type about the given entity in the outbox, using the given
+// database (usually the transaction of the change). The event is only recorded if the space has a
+// subscription to this type of event.
+func Enqueue(db *gorm.DB, spaceID uuid.UUID, eventType string, payload interface{}) error {
+	eventTypes, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	var count int
+	if err := db.Model(&Subscription{}).Where("space_id = ? AND event_types @> ?::jsonb", spaceID, string(eventTypes)).Count(&count).Error; err != nil {
+		return errs.Wrapf(err, "failed to look up the webhooks of the space %s", spaceID)
+	}
+	if count == 0 {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return errs.Wrapf(err, "failed to encode the %s event", eventType)
+	}
+	event := Event{
+		ID:        uuid.NewV4(),
+		SpaceID:   spaceID,
+		EventType: eventType,
+		Payload:   string(data),
+	}
+	if err := db.Create(&event).Error; err != nil {
+		return errs.Wrapf(err, "failed to record the %s event", eventType)
+	}
+	return nil
+}
+
+// EnqueueForWorkItem records an event of the given type about the given entity in the outbox, in the space
+// of the work item with the given id (e.g. the work item of a comment)
+func EnqueueForWorkItem(db *gorm.DB, workItemID string, eventType string, payload interface{}) error {
+	var result struct {
+		SpaceID uuid.UUID
+	}
+	tx := db.Table("work_items").Select("space_id").Where("id = ?", workItemID).Scan(&result)
+	if tx.RecordNotFound() {
+		return nil
+	}
+	if err := tx.Error; err != nil {
+		return errs.Wrapf(err, "failed to look up the space of the work item %s", workItemID)
+	}
+	return Enqueue(db, result.SpaceID, eventType, payload)
+}
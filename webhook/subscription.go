@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// APIStringTypeWebhooks is the JSONAPI type of the webhook subscriptions
+const APIStringTypeWebhooks = "webhooks"
+
+// The types of the events sent to the webhook subscriptions
+const (
+	EventWorkItemCreated = "workitem.created"
+	EventWorkItemUpdated = "workitem.updated"
+	EventCommentCreated  = "comment.created"
+	EventLinkCreated     = "link.created"
+	EventLinkUpdated     = "link.updated"
+)
+
+// IsEventTypeSupported returns true if the given type of event is sent to the webhook subscriptions
+func IsEventTypeSupported(eventType string) bool {
+	switch eventType {
+	case EventWorkItemCreated, EventWorkItemUpdated, EventCommentCreated, EventLinkCreated, EventLinkUpdated:
+		return true
+	}
+	return false
+}
+
+// Subscription describes a webhook of a space: the events of the given types are sent to its URL
+type Subscription struct {
+	gormsupport.Lifecycle
+	ID      uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	SpaceID uuid.UUID `sql:"type:uuid"`
+	URL     string
+	// Secret is the key of the HMAC signature of the deliveries, they are not signed if it is empty
+	Secret     string
+	EventTypes EventTypes `sql:"type:jsonb"`
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m *Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Accepts returns true if the subscription receives the events of the given type
+func (m Subscription) Accepts(eventType string) bool {
+	for _, t := range m.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTypes is the list of the event types of a subscription
+type EventTypes []string
+
+// Value implements the driver.Valuer interface
+func (t EventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface
+func (t *EventTypes) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return errs.Errorf("scan source was not []byte: %T", src)
+	}
+	return json.Unmarshal(b, t)
+}
+
+// Repository describes interactions with the webhook subscriptions
+type Repository interface {
+	Create(ctx context.Context, s *Subscription) error
+	Save(ctx context.Context, s *Subscription) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Load(ctx context.Context, id uuid.UUID) (*Subscription, error)
+	List(ctx context.Context, spaceID uuid.UUID) ([]*Subscription, error)
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*Delivery, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for the webhook subscriptions.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// validate checks the URL and the event types of the given subscription
+func validate(s Subscription) error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewBadParameterError("url", s.URL).Expected("an absolute http(s) URL")
+	}
+	if len(s.EventTypes) == 0 {
+		return errors.NewBadParameterError("event-types", s.EventTypes).Expected("at least one event type")
+	}
+	for _, t := range s.EventTypes {
+		if !IsEventTypeSupported(t) {
+			return errors.NewBadParameterError("event-types", t).Expected("a supported event type")
+		}
+	}
+	return nil
+}
+
+// Create creates a new record.
+// returns BadParameterError or InternalError
+func (m *GormRepository) Create(ctx context.Context, s *Subscription) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "create"}, time.Now())
+	if err := validate(*s); err != nil {
+		return err
+	}
+	s.ID = uuid.NewV4()
+	if err := m.db.Create(s).Error; err != nil {
+		goa.LogError(ctx, "error adding webhook subscription", "error", err.Error())
+		return errors.NewInternalError(err.Error())
+	}
+	return nil
+}
+
+// Save updates the URL, the secret and the event types of the given subscription.
+// returns NotFoundError, BadParameterError or InternalError
+func (m *GormRepository) Save(ctx context.Context, s *Subscription) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "save"}, time.Now())
+	if err := validate(*s); err != nil {
+		return err
+	}
+	tx := m.db.Model(&Subscription{}).Where("id = ?", s.ID).Updates(map[string]interface{}{
+		"url":         s.URL,
+		"secret":      s.Secret,
+		"event_types": s.EventTypes,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook", s.ID.String())
+	}
+	return nil
+}
+
+// Delete deletes the subscription with the given id, its pending deliveries are abandoned
+// returns NotFoundError or InternalError
+func (m *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "delete"}, time.Now())
+	tx := m.db.Delete(&Subscription{ID: id})
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook", id.String())
+	}
+	return nil
+}
+
+// Load a single subscription
+// returns NotFoundError or InternalError
+func (m *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Subscription, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "get"}, time.Now())
+	var obj Subscription
+	tx := m.db.Where("id = ?", id).First(&obj)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("webhook", id.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	return &obj, nil
+}
+
+// List all the subscriptions of a space
+func (m *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]*Subscription, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "query"}, time.Now())
+	var objs []*Subscription
+	err := m.db.Where("space_id = ?", spaceID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
+
+// ListDeliveries returns the latest deliveries of the subscription with the given id, the most recent first
+// returns NotFoundError or InternalError
+func (m *GormRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]*Delivery, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "deliveries"}, time.Now())
+	if _, err := m.Load(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	var objs []*Delivery
+	err := m.db.Where("subscription_id = ?", subscriptionID).Order("created_at desc").Limit(limit).Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return objs, nil
+}
@@ -0,0 +1,125 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	localerror "github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	"github.com/almighty/almighty-core/webhook"
+
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWebhookRepository struct {
+	gormtestsupport.DBTestSuite
+
+	clean func()
+}
+
+func TestRunWebhookRepository(t *testing.T) {
+	suite.Run(t, &TestWebhookRepository{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestWebhookRepository) SetupTest() {
+	test.clean = cleaner.DeleteCreatedEntities(test.DB)
+}
+
+func (test *TestWebhookRepository) TearDownTest() {
+	test.clean()
+}
+
+func (test *TestWebhookRepository) createSpace() *space.Space {
+	s, err := space.NewRepository(test.DB).Create(context.Background(), &space.Space{
+		Name: "Space " + uuid.NewV4().String(),
+	})
+	require.Nil(test.T(), err)
+	return s
+}
+
+func (test *TestWebhookRepository) TestCreateWebhook() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := test.createSpace()
+	repo := webhook.NewRepository(test.DB)
+	// when
+	w := webhook.Subscription{
+		SpaceID:    s.ID,
+		URL:        "https://ci.example.com/hooks",
+		Secret:     "s3cr3t",
+		EventTypes: webhook.EventTypes{webhook.EventWorkItemCreated, webhook.EventCommentCreated},
+	}
+	err := repo.Create(context.Background(), &w)
+	// then
+	require.Nil(t, err)
+	assert.NotEqual(t, uuid.Nil, w.ID)
+	loaded, err := repo.Load(context.Background(), w.ID)
+	require.Nil(t, err)
+	assert.Equal(t, "https://ci.example.com/hooks", loaded.URL)
+	assert.Equal(t, "s3cr3t", loaded.Secret)
+	assert.Equal(t, webhook.EventTypes{webhook.EventWorkItemCreated, webhook.EventCommentCreated}, loaded.EventTypes)
+	assert.True(t, loaded.Accepts(webhook.EventCommentCreated))
+	assert.False(t, loaded.Accepts(webhook.EventLinkCreated))
+}
+
+func (test *TestWebhookRepository) TestCreateInvalidWebhook() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := test.createSpace()
+	repo := webhook.NewRepository(test.DB)
+	invalid := map[string]webhook.Subscription{
+		"relative URL":       {SpaceID: s.ID, URL: "/hooks", EventTypes: webhook.EventTypes{webhook.EventWorkItemCreated}},
+		"unsupported scheme": {SpaceID: s.ID, URL: "ftp://example.com/hooks", EventTypes: webhook.EventTypes{webhook.EventWorkItemCreated}},
+		"no event type":      {SpaceID: s.ID, URL: "https://example.com/hooks"},
+		"unknown event type": {SpaceID: s.ID, URL: "https://example.com/hooks", EventTypes: webhook.EventTypes{"workitem.deleted"}},
+	}
+	for name, w := range invalid {
+		// when
+		err := repo.Create(context.Background(), &w)
+		// then
+		_, ok := errors.Cause(err).(localerror.BadParameterError)
+		assert.True(t, ok, name)
+	}
+}
+
+func (test *TestWebhookRepository) TestSaveListAndDeleteWebhooks() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// given
+	s := test.createSpace()
+	other := test.createSpace()
+	repo := webhook.NewRepository(test.DB)
+	w1 := webhook.Subscription{SpaceID: s.ID, URL: "https://example.com/1", EventTypes: webhook.EventTypes{webhook.EventWorkItemCreated}}
+	w2 := webhook.Subscription{SpaceID: s.ID, URL: "https://example.com/2", EventTypes: webhook.EventTypes{webhook.EventLinkCreated}}
+	w3 := webhook.Subscription{SpaceID: other.ID, URL: "https://example.com/3", EventTypes: webhook.EventTypes{webhook.EventLinkCreated}}
+	for _, w := range []*webhook.Subscription{&w1, &w2, &w3} {
+		require.Nil(t, repo.Create(context.Background(), w))
+	}
+	// when
+	w1.URL = "https://example.com/updated"
+	w1.EventTypes = webhook.EventTypes{webhook.EventWorkItemUpdated}
+	require.Nil(t, repo.Save(context.Background(), &w1))
+	require.Nil(t, repo.Delete(context.Background(), w2.ID))
+	// then
+	list, err := repo.List(context.Background(), s.ID)
+	require.Nil(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, w1.ID, list[0].ID)
+	assert.Equal(t, "https://example.com/updated", list[0].URL)
+	assert.Equal(t, webhook.EventTypes{webhook.EventWorkItemUpdated}, list[0].EventTypes)
+	_, err = repo.Load(context.Background(), w2.ID)
+	assert.IsType(t, localerror.NotFoundError{}, errors.Cause(err))
+	assert.IsType(t, localerror.NotFoundError{}, errors.Cause(repo.Delete(context.Background(), w2.ID)))
+	_, err = repo.ListDeliveries(context.Background(), w2.ID, 10)
+	assert.IsType(t, localerror.NotFoundError{}, errors.Cause(err))
+}
@@ -0,0 +1,254 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/models"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// pollInterval is the time between two polls of the outbox and of the pending deliveries
+	pollInterval = 5 * time.Second
+	// batchSize is the maximum number of events dispatched, or of deliveries attempted, per poll
+	batchSize = 50
+	// leaseDuration is how long a delivery is reserved for the worker sending it, so that
+	// the workers of the other server replicas don't send it at the same time
+	leaseDuration = time.Minute
+	// deliveryTimeout is the timeout of the request sending a delivery
+	deliveryTimeout = 10 * time.Second
+	// maxAttempts is the number of attempts after which a delivery fails
+	maxAttempts = 8
+	// maxRetryDelay is the longest delay between two attempts of a delivery
+	maxRetryDelay = 6 * time.Hour
+)
+
+// Signature returns the value of the 'X-Almighty-Signature' header of a delivery with the given body:
+// the hex encoded HMAC-SHA256 of the body, using the secret of the subscription as the key
+func Signature(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay returns the delay before the next attempt of a delivery which failed the given number of times,
+// doubling from 30 seconds up to maxRetryDelay
+func retryDelay(attempts int) time.Duration {
+	delay := 30 * time.Second
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Worker dispatches the events of the outbox to the subscriptions of their space, and sends the deliveries.
+// Several workers can share the same database: the rows they process are locked or leased.
+type Worker struct {
+	db     *gorm.DB
+	client *http.Client
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewWorker creates a new Worker
+func NewWorker(db *gorm.DB) *Worker {
+	return &Worker{db: db, client: &http.Client{Timeout: deliveryTimeout}, stop: make(chan struct{})}
+}
+
+// Start starts polling the outbox and the pending deliveries in the background
+func (w *Worker) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}()
+}
+
+// Stop stops the worker and waits for the current poll to end
+// This should be called only from main
+func (w *Worker) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+}
+
+// poll dispatches the new events and sends the due deliveries
+func (w *Worker) poll() {
+	if _, err := w.dispatch(); err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err": err,
+		}, "unable to dispatch the webhook events")
+	}
+	if _, err := w.deliverDue(); err != nil {
+		log.Error(nil, map[string]interface{}{
+			"err": err,
+		}, "unable to send the webhook deliveries")
+	}
+}
+
+// dispatch creates the deliveries of the events which were not dispatched yet, and returns the number of events dispatched
+func (w *Worker) dispatch() (int, error) {
+	count := 0
+	err := models.Transactional(w.db, func(tx *gorm.DB) error {
+		var events []Event
+		if err := tx.Raw("SELECT * FROM webhook_events WHERE dispatched_at IS NULL ORDER BY created_at LIMIT ? FOR UPDATE SKIP LOCKED", batchSize).Scan(&events).Error; err != nil {
+			return errs.WithStack(err)
+		}
+		now := time.Now()
+		for _, e := range events {
+			var subscriptions []Subscription
+			if err := tx.Where("space_id = ?", e.SpaceID).Find(&subscriptions).Error; err != nil {
+				return errs.WithStack(err)
+			}
+			for _, s := range subscriptions {
+				if !s.Accepts(e.EventType) {
+					continue
+				}
+				d := Delivery{
+					ID:             uuid.NewV4(),
+					SubscriptionID: s.ID,
+					EventID:        e.ID,
+					EventType:      e.EventType,
+					Status:         DeliveryPending,
+					NextAttemptAt:  now,
+				}
+				if err := tx.Create(&d).Error; err != nil {
+					return errs.WithStack(err)
+				}
+			}
+			if err := tx.Model(&Event{}).Where("id = ?", e.ID).Update("dispatched_at", now).Error; err != nil {
+				return errs.WithStack(err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// deliverDue sends the pending deliveries whose next attempt is due, and returns the number of attempts
+func (w *Worker) deliverDue() (int, error) {
+	var deliveries []Delivery
+	err := models.Transactional(w.db, func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT * FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED", DeliveryPending, time.Now(), batchSize).Scan(&deliveries).Error; err != nil {
+			return errs.WithStack(err)
+		}
+		// lease the deliveries, they are attempted again after the lease if the server stops meanwhile
+		for _, d := range deliveries {
+			if err := tx.Model(&Delivery{}).Where("id = ?", d.ID).Update("next_attempt_at", time.Now().Add(leaseDuration)).Error; err != nil {
+				return errs.WithStack(err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i := range deliveries {
+		if err := w.deliver(&deliveries[i]); err != nil {
+			log.Error(nil, map[string]interface{}{
+				"deliveryID": deliveries[i].ID,
+				"err":        err,
+			}, "unable to record the webhook delivery")
+		}
+	}
+	return len(deliveries), nil
+}
+
+// payload is the body of a delivery
+type payload struct {
+	ID        uuid.UUID       `json:"id"`
+	Event     string          `json:"event"`
+	SpaceID   uuid.UUID       `json:"spaceID"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// deliver sends the event of the given delivery to the URL of its subscription, and records the outcome
+func (w *Worker) deliver(d *Delivery) error {
+	var s Subscription
+	tx := w.db.Where("id = ?", d.SubscriptionID).First(&s)
+	if tx.RecordNotFound() {
+		// the subscription was deleted
+		return w.record(d, nil, errs.New("the webhook was deleted"), true)
+	}
+	if tx.Error != nil {
+		return errs.WithStack(tx.Error)
+	}
+	var e Event
+	if err := w.db.Where("id = ?", d.EventID).First(&e).Error; err != nil {
+		return errs.WithStack(err)
+	}
+	body, err := json.Marshal(payload{ID: e.ID, Event: e.EventType, SpaceID: e.SpaceID, CreatedAt: e.CreatedAt, Data: json.RawMessage(e.Payload)})
+	if err != nil {
+		return w.record(d, nil, err, true)
+	}
+	req, err := http.NewRequest("POST", s.URL, bytes.NewReader(body))
+	if err != nil {
+		return w.record(d, nil, err, true)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Almighty-Event", e.EventType)
+	req.Header.Set("X-Almighty-Delivery", d.ID.String())
+	if s.Secret != "" {
+		req.Header.Set("X-Almighty-Signature", Signature(body, s.Secret))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return w.record(d, nil, err, false)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return w.record(d, &resp.StatusCode, fmt.Errorf("unexpected response: %s", resp.Status), false)
+	}
+	return w.record(d, &resp.StatusCode, nil, false)
+}
+
+// record saves the outcome of an attempt of the given delivery: it succeeded if err is nil, otherwise it is
+// attempted again later, unless the error is permanent or the maximum number of attempts is reached
+func (w *Worker) record(d *Delivery, responseStatus *int, err error, permanent bool) error {
+	now := time.Now()
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	switch {
+	case err == nil:
+		d.Status = DeliverySucceeded
+		d.Error = nil
+		d.DeliveredAt = &now
+	case permanent || d.Attempts >= maxAttempts:
+		message := err.Error()
+		d.Status = DeliveryFailed
+		d.Error = &message
+	default:
+		message := err.Error()
+		d.Error = &message
+		d.NextAttemptAt = now.Add(retryDelay(d.Attempts))
+	}
+	return errs.WithStack(w.db.Save(d).Error)
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSignature(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// the HMAC-SHA256 of "The quick brown fox jumps over the lazy dog" with the key "key"
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", Signature([]byte("The quick brown fox jumps over the lazy dog"), "key"))
+}
+
+func TestRetryDelay(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.Equal(t, 30*time.Second, retryDelay(1))
+	assert.Equal(t, time.Minute, retryDelay(2))
+	assert.Equal(t, 2*time.Minute, retryDelay(3))
+	assert.Equal(t, maxRetryDelay, retryDelay(20))
+}
+
+// the cleaner can't be used here: it imports the work item package, which imports this one
+type TestWorker struct {
+	gormtestsupport.DBTestSuite
+	space *space.Space
+}
+
+func TestRunWorker(t *testing.T) {
+	suite.Run(t, &TestWorker{DBTestSuite: gormtestsupport.NewDBTestSuite("../config.yaml")})
+}
+
+func (test *TestWorker) SetupTest() {
+	s, err := space.NewRepository(test.DB).Create(context.Background(), &space.Space{
+		Name: "Space " + uuid.NewV4().String(),
+	})
+	require.Nil(test.T(), err)
+	test.space = s
+}
+
+func (test *TestWorker) TearDownTest() {
+	// the subscriptions and their deliveries are deleted along with the space
+	test.DB.Exec("DELETE FROM webhook_events WHERE space_id = ?", test.space.ID)
+	test.DB.Exec("DELETE FROM spaces WHERE id = ?", test.space.ID)
+}
+
+func (test *TestWorker) TestEnqueueWithoutSubscription() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// when
+	err := Enqueue(test.DB, test.space.ID, EventWorkItemCreated, map[string]string{"id": "1"})
+	// then
+	require.Nil(t, err)
+	var count int
+	require.Nil(t, test.DB.Model(&Event{}).Where("space_id = ?", test.space.ID).Count(&count).Error)
+	assert.Equal(t, 0, count)
+}
+
+func (test *TestWorker) TestDeliver() {
+	t := test.T()
+	resource.Require(t, resource.Database)
+	// given
+	var received []*http.Request
+	var bodies []string
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	repo := NewRepository(test.DB)
+	subscribed := Subscription{SpaceID: test.space.ID, URL: server.URL, Secret: "s3cr3t", EventTypes: EventTypes{EventWorkItemCreated}}
+	require.Nil(t, repo.Create(context.Background(), &subscribed))
+	notSubscribed := Subscription{SpaceID: test.space.ID, URL: server.URL, EventTypes: EventTypes{EventLinkCreated}}
+	require.Nil(t, repo.Create(context.Background(), &notSubscribed))
+	require.Nil(t, Enqueue(test.DB, test.space.ID, EventWorkItemCreated, map[string]string{"id": "1"}))
+	w := NewWorker(test.DB)
+	// when
+	_, err := w.dispatch()
+	require.Nil(t, err)
+	_, err = w.deliverDue()
+	require.Nil(t, err)
+	// then the delivery is signed and scheduled again after the error
+	require.Len(t, received, 1)
+	assert.Equal(t, EventWorkItemCreated, received[0].Header.Get("X-Almighty-Event"))
+	assert.Equal(t, Signature([]byte(bodies[0]), "s3cr3t"), received[0].Header.Get("X-Almighty-Signature"))
+	assert.Contains(t, bodies[0], `"data":{"id":"1"}`)
+	deliveries, err := repo.ListDeliveries(context.Background(), subscribed.ID, 10)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, received[0].Header.Get("X-Almighty-Delivery"), deliveries[0].ID.String())
+	assert.Equal(t, DeliveryPending, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	require.NotNil(t, deliveries[0].ResponseStatus)
+	assert.Equal(t, http.StatusInternalServerError, *deliveries[0].ResponseStatus)
+	assert.True(t, deliveries[0].NextAttemptAt.After(time.Now()))
+	deliveries, err = repo.ListDeliveries(context.Background(), notSubscribed.ID, 10)
+	require.Nil(t, err)
+	assert.Len(t, deliveries, 0)
+	// when the next attempt is due and the URL accepts the event
+	status = http.StatusOK
+	require.Nil(t, test.DB.Model(&Delivery{}).Where("subscription_id = ?", subscribed.ID).Update("next_attempt_at", time.Now()).Error)
+	_, err = w.deliverDue()
+	require.Nil(t, err)
+	// then
+	require.Len(t, received, 2)
+	deliveries, err = repo.ListDeliveries(context.Background(), subscribed.ID, 10)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, DeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Nil(t, deliveries[0].Error)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+}
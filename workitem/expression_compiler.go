@@ -1,9 +1,10 @@
 package workitem
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/almighty/almighty-core/criteria"
 	uuid "github.com/satori/go.uuid"
@@ -20,7 +21,10 @@ func Compile(where criteria.Expression) (whereClause string, parameters []interf
 
 	compiler := newExpressionCompiler()
 	compiled := where.Accept(&compiler)
-
+	if compiled == nil {
+		// the expression could not be compiled, errors have been accumulated
+		return "", compiler.parameters, compiler.err
+	}
 	return compiled.(string), compiler.parameters, compiler.err
 }
 
@@ -35,17 +39,28 @@ func bubbleUpJSONContext(exp criteria.Expression) bool {
 		if t.Left().Annotation(jsonAnnotation) == true || t.Right().Annotation(jsonAnnotation) == true {
 			t.SetAnnotation(jsonAnnotation, true)
 		}
+	case *criteria.NotEqualsExpression:
+		if t.Left().Annotation(jsonAnnotation) == true || t.Right().Annotation(jsonAnnotation) == true {
+			t.SetAnnotation(jsonAnnotation, true)
+		}
 	}
 	return true
 }
 
+// fields that are stored in columns rather than in the json fields
+var columnFields = map[string]string{
+	"ID":            "ID",
+	"Type":          "Type",
+	"Version":       "Version",
+	SystemCreatedAt: "created_at",
+	SystemUpdatedAt: "updated_at",
+	SystemOrder:     "execution_order",
+}
+
 // does the field name reference a json field or a column?
 func isJSONField(fieldName string) bool {
-	switch fieldName {
-	case "ID", "Type", "Version":
-		return false
-	}
-	return true
+	_, isColumn := columnFields[fieldName]
+	return !isColumn
 }
 
 func newExpressionCompiler() expressionCompiler {
@@ -64,14 +79,18 @@ type expressionCompiler struct {
 
 func (c *expressionCompiler) Field(f *criteria.FieldExpression) interface{} {
 	if !isJSONField(f.FieldName) {
-		return f.FieldName
+		return columnFields[f.FieldName]
 	}
-	if strings.Contains(f.FieldName, "'") {
+	return c.operand(f, "")
+}
+
+func (c *expressionCompiler) checkFieldName(fieldName string) bool {
+	if strings.Contains(fieldName, "'") {
 		// beware of injection, it's a reasonable restriction for field names, make sure it's not allowed when creating wi types
 		c.err = append(c.err, fmt.Errorf("single quote not allowed in field name"))
-		return nil
+		return false
 	}
-	return "Fields@>'{\"" + f.FieldName + "\""
+	return true
 }
 
 func (c *expressionCompiler) And(a *criteria.AndExpression) interface{} {
@@ -92,13 +111,198 @@ func (c *expressionCompiler) Or(a *criteria.OrExpression) interface{} {
 	return c.binary(a, "or")
 }
 
+func (c *expressionCompiler) Not(n *criteria.NotExpression) interface{} {
+	operand := n.Operand().Accept(c)
+	if operand == nil {
+		return nil
+	}
+	return "(NOT " + operand.(string) + ")"
+}
+
 func (c *expressionCompiler) Equals(e *criteria.EqualsExpression) interface{} {
 	if isInJSONContext(e.Left()) {
-		return c.binary(e, ":")
+		return c.containment(e)
 	}
 	return c.binary(e, "=")
 }
 
+// NotEquals is the negation of Equals, so json fields that are not set or
+// lists that don't contain the value are considered "not equal"
+func (c *expressionCompiler) NotEquals(e *criteria.NotEqualsExpression) interface{} {
+	if isInJSONContext(e.Left()) {
+		result := c.containment(e)
+		if result == nil {
+			return nil
+		}
+		return "(NOT " + result.(string) + ")"
+	}
+	return c.binary(e, "<>")
+}
+
+// containment compiles the equality of a json field and a literal value as the
+// containment of a json object, which is encoded and passed as a parameter so
+// that the value never ends up in the SQL
+func (c *expressionCompiler) containment(e criteria.BinaryExpression) interface{} {
+	field, isField := e.Left().(*criteria.FieldExpression)
+	literal, isLiteral := e.Right().(*criteria.LiteralExpression)
+	if !isField || !isLiteral {
+		field, isField = e.Right().(*criteria.FieldExpression)
+		literal, isLiteral = e.Left().(*criteria.LiteralExpression)
+	}
+	if !isField || !isLiteral {
+		c.err = append(c.err, fmt.Errorf("a json field can only be compared with a literal value"))
+		return nil
+	}
+	value := literal.Value
+	switch t := value.(type) {
+	case time.Time:
+		// instants are stored as nanoseconds in json fields
+		value = t.UnixNano()
+	case uuid.UUID:
+		value = t.String()
+	}
+	object, err := json.Marshal(map[string]interface{}{field.FieldName: value})
+	if err != nil {
+		c.err = append(c.err, fmt.Errorf("unknown value type of %v: %T", literal.Value, literal.Value))
+		return nil
+	}
+	c.parameters = append(c.parameters, string(object))
+	return "(Fields @> ?::jsonb)"
+}
+
+func (c *expressionCompiler) LessThan(e *criteria.LessThanExpression) interface{} {
+	return c.comparison(e, "<")
+}
+
+func (c *expressionCompiler) LessThanOrEqual(e *criteria.LessThanOrEqualExpression) interface{} {
+	return c.comparison(e, "<=")
+}
+
+func (c *expressionCompiler) GreaterThan(e *criteria.GreaterThanExpression) interface{} {
+	return c.comparison(e, ">")
+}
+
+func (c *expressionCompiler) GreaterThanOrEqual(e *criteria.GreaterThanOrEqualExpression) interface{} {
+	return c.comparison(e, ">=")
+}
+
+func (c *expressionCompiler) Like(e *criteria.LikeExpression) interface{} {
+	return c.comparison(e, "LIKE")
+}
+
+func (c *expressionCompiler) ILike(e *criteria.ILikeExpression) interface{} {
+	return c.comparison(e, "ILIKE")
+}
+
+func (c *expressionCompiler) In(e *criteria.InExpression) interface{} {
+	if len(e.Values()) == 0 {
+		// nothing is contained in the empty set
+		return "FALSE"
+	}
+	cast := jsonCast(e.Left(), e.Values()...)
+	left := c.operand(e.Left(), cast)
+	compiledValues := []string{}
+	for _, value := range e.Values() {
+		compiled := c.operand(value, cast)
+		if compiled == nil {
+			return nil
+		}
+		compiledValues = append(compiledValues, compiled.(string))
+	}
+	if left == nil {
+		return nil
+	}
+	return "(" + left.(string) + " IN (" + strings.Join(compiledValues, ", ") + "))"
+}
+
+func (c *expressionCompiler) IsNull(e *criteria.IsNullExpression) interface{} {
+	// for json fields, ->> yields NULL for both missing keys and json null values
+	operand := c.operand(e.Operand(), "")
+	if operand == nil {
+		return nil
+	}
+	if field, ok := e.Operand().(*criteria.FieldExpression); ok && isJSONField(field.FieldName) {
+		// an empty list is not set either
+		return "(" + operand.(string) + " IS NULL OR Fields->'" + field.FieldName + "' = '[]'::jsonb)"
+	}
+	return "(" + operand.(string) + " IS NULL)"
+}
+
+// comparison compiles operators that can't be expressed as json containment.
+// Json fields are extracted as text and cast according to the type of the value
+// they are compared with, so that e.g. numbers are not compared lexically.
+func (c *expressionCompiler) comparison(e criteria.BinaryExpression, op string) interface{} {
+	cast := jsonCast(e.Left(), e.Right())
+	if cast == "" {
+		cast = jsonCast(e.Right(), e.Left())
+	}
+	left := c.operand(e.Left(), cast)
+	right := c.operand(e.Right(), cast)
+	if left != nil && right != nil {
+		return "(" + left.(string) + " " + op + " " + right.(string) + ")"
+	}
+	// something went wrong in either compilation, errors have been accumulated
+	return nil
+}
+
+// jsonTypes are the json types of the values which can be cast to the given SQL types
+var jsonTypes = map[string]string{
+	"numeric": "number",
+	"boolean": "boolean",
+}
+
+// operand compiles fields and literals used by comparison operators. A json field
+// is extracted as text and cast to the given type (if any). The values of another
+// json type are not cast but taken as NULL, so that they don't fail the query.
+func (c *expressionCompiler) operand(exp criteria.Expression, cast string) interface{} {
+	switch t := exp.(type) {
+	case *criteria.FieldExpression:
+		if !isJSONField(t.FieldName) {
+			return columnFields[t.FieldName]
+		}
+		if !c.checkFieldName(t.FieldName) {
+			return nil
+		}
+		field := "Fields->>'" + t.FieldName + "'"
+		if cast != "" {
+			field = "(CASE WHEN jsonb_typeof(Fields->'" + t.FieldName + "') = '" + jsonTypes[cast] + "' THEN (" + field + ")::" + cast + " END)"
+		}
+		return field
+	case *criteria.LiteralExpression:
+		value := t.Value
+		if instant, ok := value.(time.Time); ok && cast != "" {
+			// instants are stored as nanoseconds in json fields
+			value = instant.UnixNano()
+		}
+		c.parameters = append(c.parameters, value)
+		return "?"
+	}
+	c.err = append(c.err, fmt.Errorf("only fields and literal values can be compared, but found %T", exp))
+	return nil
+}
+
+// jsonCast returns the type a json field has to be cast to when it is compared with
+// the given values, or the empty string if exp is not a json field or no cast is needed
+func jsonCast(exp criteria.Expression, values ...criteria.Expression) string {
+	field, ok := exp.(*criteria.FieldExpression)
+	if !ok || !isJSONField(field.FieldName) {
+		return ""
+	}
+	for _, value := range values {
+		literal, ok := value.(*criteria.LiteralExpression)
+		if !ok {
+			continue
+		}
+		switch literal.Value.(type) {
+		case int, int32, int64, uint, uint32, uint64, float32, float64, time.Time:
+			return "numeric"
+		case bool:
+			return "boolean"
+		}
+	}
+	return ""
+}
+
 func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
 	c.err = append(c.err, fmt.Errorf("Parameter expression not supported"))
 	return nil
@@ -117,55 +321,9 @@ func isInJSONContext(exp criteria.Expression) bool {
 	return result
 }
 
-// literal values need to be converted differently depending on whether they are used in a JSON context or a regular SQL expression.
-// JSON values are always strings (delimited with "'"), but operators can be used depending on the dynamic type. For example,
-// you can write "a->'foo' < '5'" and it will return true for the json object { "a": 40 }.
+// Literal compiles a literal value to a parameter, the equality with json fields being
+// compiled as a whole by containment
 func (c *expressionCompiler) Literal(v *criteria.LiteralExpression) interface{} {
-	json := isInJSONContext(v)
-	if json {
-		stringVal, err := c.convertToString(v.Value)
-		if err == nil {
-			return stringVal + "}'"
-		}
-		if stringArr, ok := v.Value.([]string); ok {
-			return "[" + c.wrapStrings(stringArr) + "]}'"
-		}
-		c.err = append(c.err, err)
-		return nil
-	}
 	c.parameters = append(c.parameters, v.Value)
 	return "?"
 }
-
-func (c *expressionCompiler) wrapStrings(value []string) string {
-	wrapped := []string{}
-	for i := 0; i < len(value); i++ {
-		wrapped = append(wrapped, "\""+value[i]+"\"")
-	}
-	return strings.Join(wrapped, ",")
-}
-
-func (c *expressionCompiler) convertToString(value interface{}) (string, error) {
-	var result string
-	switch t := value.(type) {
-	case float64:
-		result = strconv.FormatFloat(t, 'f', -1, 64)
-	case int:
-		result = strconv.FormatInt(int64(t), 10)
-	case int64:
-		result = strconv.FormatInt(t, 10)
-	case uint:
-		result = strconv.FormatUint(uint64(t), 10)
-	case uint64:
-		result = strconv.FormatUint(t, 10)
-	case string:
-		result = "\"" + t + "\""
-	case bool:
-		result = strconv.FormatBool(t)
-	case uuid.UUID:
-		result = t.String()
-	default:
-		return "", fmt.Errorf("unknown value type of %v: %T", value, value)
-	}
-	return result, nil
-}
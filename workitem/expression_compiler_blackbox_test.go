@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"runtime/debug"
 	"testing"
+	"time"
 
 	. "github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/resource"
@@ -14,7 +15,7 @@ import (
 func TestField(t *testing.T) {
 	t.Parallel()
 	resource.Require(t, resource.UnitTest)
-	expect(t, Equals(Field("foo"), Literal(23)), "(Fields@>'{\"foo\" : 23}')", []interface{}{})
+	expect(t, Equals(Field("foo"), Literal(23)), "(Fields @> ?::jsonb)", []interface{}{`{"foo":23}`})
 	expect(t, Equals(Field("Type"), Literal("abcd")), "(Type = ?)", []interface{}{"abcd"})
 }
 
@@ -23,8 +24,8 @@ func TestAndOr(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	expect(t, Or(Literal(true), Literal(false)), "(? or ?)", []interface{}{true, false})
 
-	expect(t, And(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields@>'{\"foo\" : \"abcd\"}') and (? = ?))", []interface{}{true, false})
-	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields@>'{\"foo\" : \"abcd\"}') or (? = ?))", []interface{}{true, false})
+	expect(t, And(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields @> ?::jsonb) and (? = ?))", []interface{}{`{"foo":"abcd"}`, true, false})
+	expect(t, Or(Equals(Field("foo"), Literal("abcd")), Equals(Literal(true), Literal(false))), "((Fields @> ?::jsonb) or (? = ?))", []interface{}{`{"foo":"abcd"}`, true, false})
 }
 
 func expect(t *testing.T, expr Expression, expectedClause string, expectedParameters []interface{}) {
@@ -48,7 +49,65 @@ func TestArray(t *testing.T) {
 	assignees := []string{"1", "2", "3"}
 
 	exp := Equals(Field("system.assignees"), Literal(assignees))
-	where, _, _ := Compile(exp)
+	where, parameters, _ := Compile(exp)
 
-	assert.Equal(t, "(Fields@>'{\"system.assignees\" : [\"1\",\"2\",\"3\"]}')", where)
+	assert.Equal(t, "(Fields @> ?::jsonb)", where)
+	assert.Equal(t, []interface{}{`{"system.assignees":["1","2","3"]}`}, parameters)
+}
+
+func TestComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, NotEquals(Field("foo"), Literal("abcd")), "(NOT (Fields @> ?::jsonb))", []interface{}{`{"foo":"abcd"}`})
+	expect(t, NotEquals(Field("Type"), Literal("abcd")), "(Type <> ?)", []interface{}{"abcd"})
+	expect(t, LessThan(Field("foo"), Literal(5)), "((CASE WHEN jsonb_typeof(Fields->'foo') = 'number' THEN (Fields->>'foo')::numeric END) < ?)", []interface{}{5})
+	expect(t, LessThanOrEqual(Field("foo"), Literal(2.5)), "((CASE WHEN jsonb_typeof(Fields->'foo') = 'number' THEN (Fields->>'foo')::numeric END) <= ?)", []interface{}{2.5})
+	expect(t, GreaterThan(Literal(5), Field("foo")), "(? > (CASE WHEN jsonb_typeof(Fields->'foo') = 'number' THEN (Fields->>'foo')::numeric END))", []interface{}{5})
+	expect(t, GreaterThanOrEqual(Field("foo"), Literal("abcd")), "(Fields->>'foo' >= ?)", []interface{}{"abcd"})
+	expect(t, GreaterThan(Field("Version"), Literal(3)), "(Version > ?)", []interface{}{3})
+	expect(t, LessThan(Field("foo"), Literal(true)), "((CASE WHEN jsonb_typeof(Fields->'foo') = 'boolean' THEN (Fields->>'foo')::boolean END) < ?)", []interface{}{true})
+}
+
+func TestInstants(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	instant := time.Date(2017, 1, 31, 0, 0, 0, 0, time.UTC)
+	// system.created_at is a column
+	expect(t, GreaterThan(Field(SystemCreatedAt), Literal(instant)), "(created_at > ?)", []interface{}{instant})
+	// instants are stored as nanoseconds in json fields
+	expect(t, LessThan(Field("foo"), Literal(instant)), "((CASE WHEN jsonb_typeof(Fields->'foo') = 'number' THEN (Fields->>'foo')::numeric END) < ?)", []interface{}{instant.UnixNano()})
+	expect(t, Equals(Field("foo"), Literal(instant)), "(Fields @> ?::jsonb)", []interface{}{`{"foo":1485820800000000000}`})
+}
+
+func TestNotInLikeIsNull(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	expect(t, Not(Equals(Field("foo"), Literal("abcd"))), "(NOT (Fields @> ?::jsonb))", []interface{}{`{"foo":"abcd"}`})
+	expect(t, In(Field("foo"), Literal("a"), Literal("b")), "(Fields->>'foo' IN (?, ?))", []interface{}{"a", "b"})
+	expect(t, In(Field("foo"), Literal(1), Literal(2)), "((CASE WHEN jsonb_typeof(Fields->'foo') = 'number' THEN (Fields->>'foo')::numeric END) IN (?, ?))", []interface{}{1, 2})
+	expect(t, In(Field("Type"), Literal("a")), "(Type IN (?))", []interface{}{"a"})
+	expect(t, In(Field("foo")), "FALSE", []interface{}{})
+	expect(t, Like(Field("foo"), Literal("%a%")), "(Fields->>'foo' LIKE ?)", []interface{}{"%a%"})
+	expect(t, ILike(Field(SystemTitle), Literal("%a%")), "(Fields->>'system.title' ILIKE ?)", []interface{}{"%a%"})
+	expect(t, IsNull(Field("foo")), "(Fields->>'foo' IS NULL OR Fields->'foo' = '[]'::jsonb)", []interface{}{})
+	// an empty list is not set either
+	expect(t, IsNull(Field(SystemAssignees)), "(Fields->>'system.assignees' IS NULL OR Fields->'system.assignees' = '[]'::jsonb)", []interface{}{})
+	expect(t, Not(IsNull(Field(SystemUpdatedAt))), "(NOT (updated_at IS NULL))", []interface{}{})
+}
+
+func TestFieldNameInjection(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	_, _, err := Compile(LessThan(Field("foo'"), Literal(1)))
+	assert.NotEmpty(t, err)
+}
+
+func TestValueInjection(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	// the values are encoded as json and passed as parameters, never in the SQL
+	expect(t, Equals(Field(SystemTitle), Literal("don't")), "(Fields @> ?::jsonb)", []interface{}{`{"system.title":"don't"}`})
+	expect(t, Equals(Field(SystemTitle), Literal("x'}' OR true --")), "(Fields @> ?::jsonb)", []interface{}{`{"system.title":"x'}' OR true --"}`})
+	expect(t, NotEquals(Field(SystemTitle), Literal(`say "hi" \o/`)), "(NOT (Fields @> ?::jsonb))", []interface{}{`{"system.title":"say \"hi\" \\o/"}`})
+	expect(t, Equals(Field("foo'"), Literal("a")), "(Fields @> ?::jsonb)", []interface{}{`{"foo'":"a"}`})
 }
@@ -0,0 +1,90 @@
+package link
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// The operations of a bulk request on work item links
+const (
+	BulkOperationCreate = "create"
+	BulkOperationDelete = "delete"
+)
+
+// BulkOperation is the creation or the deletion of a work item link within a
+// bulk request
+type BulkOperation struct {
+	Op string
+	// SourceID, TargetID and LinkTypeID define the link to create
+	SourceID   uint64
+	TargetID   uint64
+	LinkTypeID uuid.UUID
+	// LinkID is the ID of the link to delete
+	LinkID uuid.UUID
+}
+
+// BulkResult is the outcome of an operation of a bulk request
+type BulkResult struct {
+	// Link is the created link
+	Link *app.WorkItemLinkSingle
+	// Err is the reason why the operation was rejected
+	Err error
+}
+
+// ApplyBulk creates and deletes the links of the given operations in order,
+// with one revision per link. Each operation is validated against the links
+// created and deleted by the previous ones, so that the source and target
+// types, the topology of the link types and the uniqueness of the links hold
+// for the batch as a whole. When operations are rejected, the remaining ones
+// are still validated and a BadParameterError is returned along with the
+// results of all the operations: the caller must then roll back the
+// transaction. Any other error is returned without results.
+func (r *GormWorkItemLinkRepository) ApplyBulk(ctx context.Context, operations []BulkOperation, modifierID uuid.UUID) ([]BulkResult, error) {
+	results := make([]BulkResult, len(operations))
+	rejected := 0
+	for i, op := range operations {
+		var err error
+		switch op.Op {
+		case BulkOperationCreate:
+			results[i].Link, err = r.bulkCreate(ctx, op, modifierID)
+		case BulkOperationDelete:
+			err = r.Delete(ctx, op.LinkID, modifierID)
+		default:
+			err = errors.NewBadParameterError("op", op.Op).Expected(BulkOperationCreate + " or " + BulkOperationDelete)
+		}
+		if err == nil {
+			continue
+		}
+		switch errs.Cause(err).(type) {
+		case errors.BadParameterError, errors.NotFoundError:
+			results[i].Err = err
+			rejected++
+		default:
+			// the transaction may not be usable anymore
+			return nil, errs.WithStack(err)
+		}
+	}
+	if rejected > 0 {
+		return results, errors.NewBadParameterError("operations", fmt.Sprintf("%d rejected", rejected)).Expected("valid operations")
+	}
+	return results, nil
+}
+
+// bulkCreate creates the link of the given operation, after making sure it
+// doesn't exist yet: the violation of the unique index would abort the
+// transaction, and with it the validation of the remaining operations.
+func (r *GormWorkItemLinkRepository) bulkCreate(ctx context.Context, op BulkOperation, creatorID uuid.UUID) (*app.WorkItemLinkSingle, error) {
+	existingID, err := r.findLinkID(op.LinkTypeID, "source_id = ? AND target_id = ?", op.SourceID, op.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != nil {
+		return nil, errors.NewBadParameterError("data.relationships.source + data.relationships.target + data.relationships.link_type", fmt.Sprintf("%d -> %d", op.SourceID, op.TargetID)).Expected(fmt.Sprintf("unique (it is the link %s)", *existingID))
+	}
+	return r.Create(ctx, op.SourceID, op.TargetID, op.LinkTypeID, creatorID)
+}
@@ -0,0 +1,241 @@
+package link
+
+import (
+	"sort"
+)
+
+// DependencyItem is a work item of a dependency graph
+type DependencyItem struct {
+	ID uint64
+	// Weight is the value of the numeric field the critical path is weighted by
+	Weight float64
+}
+
+// Dependency is a link of a dependency graph. The target of a link of a type
+// with the dependency topology depends on its source, which is the predecessor.
+type Dependency struct {
+	PredecessorID uint64
+	SuccessorID   uint64
+}
+
+// DependencyAnalysis holds the result of the analysis of the dependencies
+// between a set of work items
+type DependencyAnalysis struct {
+	// Order lists the work items so that each one comes after its predecessors.
+	// The work items of a cycle come together by id, after the predecessors of the cycle.
+	Order []uint64
+	// Blocked maps the blocked work items to their unresolved predecessors,
+	// including the ones outside of the analyzed work items
+	Blocked map[uint64][]uint64
+	// CriticalPath is the heaviest chain of dependencies, from the first predecessor
+	CriticalPath []uint64
+	// CriticalPathWeight is the sum of the weights of the work items of the critical path
+	CriticalPathWeight float64
+	// Cycles lists the sets of work items depending on each other
+	Cycles [][]uint64
+}
+
+// AnalyzeDependencies computes the order, the critical path and the cycles of
+// the given work items. The dependencies of which both ends aren't part of the
+// given work items are ignored, and so are the dependencies within a cycle for
+// the order and the critical path. The blocked work items are left to the caller.
+func AnalyzeDependencies(items []DependencyItem, dependencies []Dependency) DependencyAnalysis {
+	weights := make(map[uint64]float64, len(items))
+	ids := make(uint64s, 0, len(items))
+	for _, item := range items {
+		if _, ok := weights[item.ID]; !ok {
+			ids = append(ids, item.ID)
+		}
+		weights[item.ID] = item.Weight
+	}
+	sort.Sort(ids)
+	successors := map[uint64]uint64s{}
+	selfDependent := map[uint64]bool{}
+	seen := map[Dependency]bool{}
+	for _, d := range dependencies {
+		_, predecessorFound := weights[d.PredecessorID]
+		_, successorFound := weights[d.SuccessorID]
+		if !predecessorFound || !successorFound || seen[d] {
+			continue
+		}
+		seen[d] = true
+		if d.PredecessorID == d.SuccessorID {
+			selfDependent[d.PredecessorID] = true
+			continue
+		}
+		successors[d.PredecessorID] = append(successors[d.PredecessorID], d.SuccessorID)
+	}
+	for _, s := range successors {
+		sort.Sort(s)
+	}
+
+	result := DependencyAnalysis{
+		Order:        []uint64{},
+		Blocked:      map[uint64][]uint64{},
+		CriticalPath: []uint64{},
+		Cycles:       [][]uint64{},
+	}
+	components := stronglyConnectedComponents(ids, successors)
+	for _, c := range components.members {
+		if len(c) > 1 || selfDependent[c[0]] {
+			result.Cycles = append(result.Cycles, c)
+		}
+	}
+	sort.Sort(byFirstID(result.Cycles))
+	// the dependencies within a cycle are ignored
+	dependsOn := func(predecessorID, successorID uint64) bool {
+		return components.index[predecessorID] != components.index[successorID]
+	}
+
+	// order the components, picking the ready one with the lowest id first,
+	// and list the work items of each component by id
+	predecessorCount := make([]int, len(components.members))
+	for _, id := range ids {
+		for _, s := range successors[id] {
+			if dependsOn(id, s) {
+				predecessorCount[components.index[s]]++
+			}
+		}
+	}
+	ready := byFirstID{}
+	for _, c := range components.members {
+		if predecessorCount[components.index[c[0]]] == 0 {
+			ready = append(ready, c)
+		}
+	}
+	sort.Sort(ready)
+	for len(ready) > 0 {
+		c := ready[0]
+		ready = ready[1:]
+		result.Order = append(result.Order, c...)
+		added := false
+		for _, id := range c {
+			for _, s := range successors[id] {
+				if !dependsOn(id, s) {
+					continue
+				}
+				i := components.index[s]
+				predecessorCount[i]--
+				if predecessorCount[i] == 0 {
+					ready = append(ready, components.members[i])
+					added = true
+				}
+			}
+		}
+		if added {
+			sort.Sort(ready)
+		}
+	}
+
+	// the critical path ends with the work item with the heaviest chain of
+	// predecessors, the longest one among the chains of the same weight
+	chains := make(map[uint64]chain, len(ids))
+	previous := map[uint64]uint64{}
+	for _, id := range result.Order {
+		c := chains[id]
+		c.weight += weights[id]
+		c.length++
+		chains[id] = c
+		for _, s := range successors[id] {
+			if _, reached := previous[s]; dependsOn(id, s) && (!reached || c.heavierThan(chains[s])) {
+				chains[s] = c
+				previous[s] = id
+			}
+		}
+	}
+	var last uint64
+	for i, id := range result.Order {
+		if i == 0 || chains[id].heavierThan(chains[last]) {
+			last = id
+		}
+	}
+	if len(result.Order) > 0 {
+		result.CriticalPathWeight = chains[last].weight
+		for id, ok := last, true; ok; id, ok = previous[id] {
+			result.CriticalPath = append([]uint64{id}, result.CriticalPath...)
+		}
+	}
+	return result
+}
+
+// chain is the weight and length of a chain of dependencies
+type chain struct {
+	weight float64
+	length int
+}
+
+func (c chain) heavierThan(other chain) bool {
+	return c.weight > other.weight || (c.weight == other.weight && c.length > other.length)
+}
+
+// components holds the strongly connected components of a graph
+type components struct {
+	// index maps each node to the index of its component
+	index map[uint64]int
+	// members lists the nodes of each component, by id
+	members []uint64s
+}
+
+// stronglyConnectedComponents returns the strongly connected components of the
+// given graph with Tarjan's algorithm
+func stronglyConnectedComponents(ids uint64s, successors map[uint64]uint64s) components {
+	result := components{index: map[uint64]int{}}
+	order := map[uint64]int{}
+	lowLink := map[uint64]int{}
+	onStack := map[uint64]bool{}
+	stack := uint64s{}
+	var visit func(id uint64)
+	visit = func(id uint64) {
+		order[id] = len(order)
+		lowLink[id] = order[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, s := range successors[id] {
+			if _, visited := order[s]; !visited {
+				visit(s)
+				if lowLink[s] < lowLink[id] {
+					lowLink[id] = lowLink[s]
+				}
+			} else if onStack[s] && order[s] < lowLink[id] {
+				lowLink[id] = order[s]
+			}
+		}
+		if lowLink[id] != order[id] {
+			return
+		}
+		// the node is the root of a component made of the nodes above it on the stack
+		members := uint64s{}
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[member] = false
+			result.index[member] = len(result.members)
+			members = append(members, member)
+			if member == id {
+				break
+			}
+		}
+		sort.Sort(members)
+		result.members = append(result.members, members)
+	}
+	for _, id := range ids {
+		if _, visited := order[id]; !visited {
+			visit(id)
+		}
+	}
+	return result
+}
+
+// uint64s sorts work item ids in increasing order
+type uint64s []uint64
+
+func (s uint64s) Len() int           { return len(s) }
+func (s uint64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// byFirstID sorts sets of work item ids by their first id
+type byFirstID [][]uint64
+
+func (s byFirstID) Len() int           { return len(s) }
+func (s byFirstID) Less(i, j int) bool { return s[i][0] < s[j][0] }
+func (s byFirstID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,77 @@
+package link_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeDependencies(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	t.Run("no work item", func(t *testing.T) {
+		// when
+		result := link.AnalyzeDependencies(nil, nil)
+		// then
+		assert.Empty(t, result.Order)
+		assert.Empty(t, result.CriticalPath)
+		assert.Empty(t, result.Cycles)
+		assert.Equal(t, float64(0), result.CriticalPathWeight)
+	})
+
+	t.Run("acyclic", func(t *testing.T) {
+		// given 1 -> 2 -> 4 and 1 -> 3 -> 4, 3 being heavier than 2
+		items := []link.DependencyItem{{ID: 4, Weight: 1}, {ID: 3, Weight: 5}, {ID: 2, Weight: 2}, {ID: 1, Weight: 1}, {ID: 5}}
+		dependencies := []link.Dependency{
+			{PredecessorID: 1, SuccessorID: 2},
+			{PredecessorID: 1, SuccessorID: 3},
+			{PredecessorID: 2, SuccessorID: 4},
+			{PredecessorID: 3, SuccessorID: 4},
+			// outside of the analyzed work items
+			{PredecessorID: 42, SuccessorID: 1},
+		}
+		// when
+		result := link.AnalyzeDependencies(items, dependencies)
+		// then
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5}, result.Order)
+		assert.Equal(t, []uint64{1, 3, 4}, result.CriticalPath)
+		assert.Equal(t, float64(7), result.CriticalPathWeight)
+		assert.Empty(t, result.Cycles)
+	})
+
+	t.Run("longest path without weights", func(t *testing.T) {
+		// given
+		items := []link.DependencyItem{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+		dependencies := []link.Dependency{
+			{PredecessorID: 1, SuccessorID: 4},
+			{PredecessorID: 2, SuccessorID: 3},
+			{PredecessorID: 3, SuccessorID: 4},
+		}
+		// when
+		result := link.AnalyzeDependencies(items, dependencies)
+		// then
+		assert.Equal(t, []uint64{1, 2, 3, 4}, result.Order)
+		assert.Equal(t, []uint64{2, 3, 4}, result.CriticalPath)
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		// given 4 -> 1 -> 3 -> 1 -> 5, 2 alone and 6 -> 6
+		items := []link.DependencyItem{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}}
+		dependencies := []link.Dependency{
+			{PredecessorID: 4, SuccessorID: 1},
+			{PredecessorID: 1, SuccessorID: 3},
+			{PredecessorID: 3, SuccessorID: 1},
+			{PredecessorID: 3, SuccessorID: 5},
+			{PredecessorID: 6, SuccessorID: 6},
+		}
+		// when
+		result := link.AnalyzeDependencies(items, dependencies)
+		// then
+		assert.Equal(t, [][]uint64{{1, 3}, {6}}, result.Cycles)
+		assert.Equal(t, []uint64{2, 4, 1, 3, 5, 6}, result.Order)
+	})
+}
@@ -0,0 +1,139 @@
+package link
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// resolvedStates are the states of the work items which don't block their successors anymore
+var resolvedStates = []string{workitem.SystemStateResolved, workitem.SystemStateClosed}
+
+// dependencyLinkTypes selects the ids of the link types with a dependency topology
+func dependencyLinkTypes() string {
+	return fmt.Sprintf("SELECT id FROM %s WHERE topology = '%s' AND deleted_at IS NULL", WorkItemLinkType{}.TableName(), TopologyDependency)
+}
+
+// ListBlockers returns the unresolved predecessors of those of the given work
+// items which have some, by id.
+// Returns InternalError
+func (r *GormWorkItemLinkRepository) ListBlockers(ctx context.Context, wiIDs []uint64) (map[uint64][]uint64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "blockers", "query"}, time.Now())
+	if len(wiIDs) == 0 {
+		return map[uint64][]uint64{}, nil
+	}
+	return r.listBlockers("?", wiIDs)
+}
+
+// listBlockers returns the unresolved predecessors of the work items selected by the given
+// subquery or list of ids which have some, by id.
+// Returns InternalError
+func (r *GormWorkItemLinkRepository) listBlockers(targets string, args ...interface{}) (map[uint64][]uint64, error) {
+	query := fmt.Sprintf(`
+	SELECT l.target_id, l.source_id FROM %s l JOIN %s p ON p.id = l.source_id
+	WHERE l.target_id IN (%s) AND l.link_type_id IN (%s) AND l.deleted_at IS NULL
+	AND p.deleted_at IS NULL AND coalesce(p.fields->>'%s', '') NOT IN (?)
+	ORDER BY l.target_id, l.source_id`, WorkItemLink{}.TableName(), workitem.WorkItem{}.TableName(), targets, dependencyLinkTypes(), workitem.SystemState)
+	rows, err := r.db.Raw(query, append(append([]interface{}{}, args...), resolvedStates)...).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	result := map[uint64][]uint64{}
+	for rows.Next() {
+		var targetID, sourceID uint64
+		if err := rows.Scan(&targetID, &sourceID); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		result[targetID] = append(result[targetID], sourceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
+
+// analyzedWorkItems selects the ids of the work items of the given space, or of its given
+// iteration if not nil, and returns the arguments of the query
+func analyzedWorkItems(spaceID uuid.UUID, iterationID *uuid.UUID) (string, []interface{}) {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE space_id = ? AND deleted_at IS NULL", workitem.WorkItem{}.TableName())
+	args := []interface{}{spaceID}
+	if iterationID != nil {
+		query += fmt.Sprintf(" AND fields->>'%s' = ?", workitem.SystemIteration)
+		args = append(args, iterationID.String())
+	}
+	return query, args
+}
+
+// AnalyzeDependencies analyzes the dependencies between the work items of the
+// given space, or of its given iteration if not nil. The critical path is
+// weighted by the numeric field with the given name, the longest path is
+// returned if the name is empty.
+// Returns InternalError
+func (r *GormWorkItemLinkRepository) AnalyzeDependencies(ctx context.Context, spaceID uuid.UUID, iterationID *uuid.UUID, weightField string) (*DependencyAnalysis, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "dependencies", "query"}, time.Now())
+	// the work items are selected by a subquery rather than by their ids, which could exceed
+	// the maximum number of parameters of a query
+	analyzed, args := analyzedWorkItems(spaceID, iterationID)
+	var workItems []workitem.WorkItem
+	if err := r.db.Where(fmt.Sprintf("id IN (%s)", analyzed), args...).Find(&workItems).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	items := make([]DependencyItem, len(workItems))
+	for i, wi := range workItems {
+		items[i] = DependencyItem{ID: wi.ID, Weight: numericValue(wi.Fields[weightField])}
+	}
+	query := fmt.Sprintf(`
+	SELECT source_id, target_id FROM %s
+	WHERE source_id IN (%s) AND target_id IN (%s) AND link_type_id IN (%s) AND deleted_at IS NULL`,
+		WorkItemLink{}.TableName(), analyzed, analyzed, dependencyLinkTypes())
+	rows, err := r.db.Raw(query, append(append([]interface{}{}, args...), args...)...).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	var dependencies []Dependency
+	for rows.Next() {
+		var d Dependency
+		if err := rows.Scan(&d.PredecessorID, &d.SuccessorID); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		dependencies = append(dependencies, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	result := AnalyzeDependencies(items, dependencies)
+	blockers, err := r.listBlockers(analyzed, args...)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	result.Blocked = blockers
+	return &result, nil
+}
+
+// numericValue returns the given field value as a number, or 0 if it isn't one
+func numericValue(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
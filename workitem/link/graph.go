@@ -0,0 +1,156 @@
+package link
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// GraphMaxNodes is the maximum number of work items of a graph
+const GraphMaxNodes = 1000
+
+// GraphNode is a work item of a graph
+type GraphNode struct {
+	ID    uint64
+	Type  uuid.UUID
+	Title string
+	State string
+}
+
+// GraphEdge is a work item link of a graph
+type GraphEdge struct {
+	ID         uuid.UUID
+	SourceID   uint64
+	TargetID   uint64
+	LinkTypeID uuid.UUID
+	// ForwardName is the forward name of the link type, e.g. "parent of"
+	ForwardName string
+}
+
+// Graph holds work items and the links between them
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphFilter restricts the links of a graph to the ones of the given link
+// types and link categories, if not empty
+type GraphFilter struct {
+	LinkTypeIDs     []uuid.UUID
+	LinkCategoryIDs []uuid.UUID
+}
+
+// LoadGraph returns the graph of the given work items, the work items linked
+// to them in either direction, and so on down to the given depth, with the
+// links between all of them. A negative depth walks all the links, a depth of
+// 0 returns the links between the given work items only.
+// Returns BadParameterError if the graph has more than GraphMaxNodes work items, or InternalError
+func (r *GormWorkItemLinkRepository) LoadGraph(ctx context.Context, wiIDs []uint64, depth int, filter GraphFilter) (*Graph, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "graph", "query"}, time.Now())
+	visited := map[uint64]bool{}
+	ids := []uint64{}
+	for _, id := range wiIDs {
+		if !visited[id] {
+			visited[id] = true
+			ids = append(ids, id)
+		}
+	}
+	frontier := ids
+	for level := 0; len(frontier) > 0 && (depth < 0 || level < depth); level++ {
+		edges, err := r.queryGraphEdges(filter, "l.source_id IN (?) OR l.target_id IN (?)", frontier, frontier)
+		if err != nil {
+			return nil, err
+		}
+		next := []uint64{}
+		for _, e := range edges {
+			for _, id := range []uint64{e.SourceID, e.TargetID} {
+				if !visited[id] {
+					visited[id] = true
+					next = append(next, id)
+				}
+			}
+		}
+		ids = append(ids, next...)
+		if len(ids) > GraphMaxNodes {
+			return nil, errors.NewBadParameterError("depth", depth).Expected(fmt.Sprintf("a graph of at most %d work items", GraphMaxNodes))
+		}
+		frontier = next
+	}
+	result := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var workItems []workitem.WorkItem
+	if err := r.db.Where("id IN (?)", ids).Order("id").Find(&workItems).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	// the links to deleted work items are left out
+	found := map[uint64]bool{}
+	for _, wi := range workItems {
+		found[wi.ID] = true
+		title, _ := wi.Fields[workitem.SystemTitle].(string)
+		state, _ := wi.Fields[workitem.SystemState].(string)
+		result.Nodes = append(result.Nodes, GraphNode{ID: wi.ID, Type: wi.Type, Title: title, State: state})
+	}
+	edges, err := r.queryGraphEdges(filter, "l.source_id IN (?) AND l.target_id IN (?)", ids, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		if found[e.SourceID] && found[e.TargetID] {
+			result.Edges = append(result.Edges, e)
+		}
+	}
+	return result, nil
+}
+
+// queryGraphEdges returns the links matching the given condition and filter
+func (r *GormWorkItemLinkRepository) queryGraphEdges(filter GraphFilter, where string, args ...interface{}) ([]GraphEdge, error) {
+	db := r.db.Table(WorkItemLink{}.TableName()+" l").
+		Select("l.id, l.source_id, l.target_id, l.link_type_id, t.forward_name").
+		Joins(fmt.Sprintf("JOIN %s t ON t.id = l.link_type_id", WorkItemLinkType{}.TableName())).
+		Where("l.deleted_at IS NULL").
+		Where(where, args...)
+	if len(filter.LinkTypeIDs) > 0 {
+		db = db.Where("l.link_type_id IN (?)", uuidStrings(filter.LinkTypeIDs))
+	}
+	if len(filter.LinkCategoryIDs) > 0 {
+		db = db.Where("t.link_category_id IN (?)", uuidStrings(filter.LinkCategoryIDs))
+	}
+	return scanGraphEdges(db.Order("l.source_id, l.target_id, t.forward_name"))
+}
+
+func scanGraphEdges(db *gorm.DB) ([]GraphEdge, error) {
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	result := []GraphEdge{}
+	for rows.Next() {
+		var e GraphEdge
+		if err := rows.Scan(&e.ID, &e.SourceID, &e.TargetID, &e.LinkTypeID, &e.ForwardName); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package link
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/almighty/almighty-core/workitem"
+)
+
+// The media types of the exported graphs
+const (
+	GraphContentTypeDOT  = "text/vnd.graphviz"
+	GraphContentTypeJSON = "application/vnd.jgf+json"
+)
+
+// stateColors are the colors of the work items by state, the other ones are white
+var stateColors = map[string]string{
+	workitem.SystemStateNew:        "lightblue",
+	workitem.SystemStateOpen:       "lightyellow",
+	workitem.SystemStateInProgress: "gold",
+	workitem.SystemStateResolved:   "palegreen",
+	workitem.SystemStateClosed:     "lightgray",
+}
+
+// StateColor returns the color of the work items with the given state
+func StateColor(state string) string {
+	if color, ok := stateColors[state]; ok {
+		return color
+	}
+	return "white"
+}
+
+// WriteDOT writes the given graph as a Graphviz DOT document, labelling the
+// links with the forward name of their type and coloring the work items by state
+func WriteDOT(w io.Writer, g Graph) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintln(b, "digraph workitems {")
+	fmt.Fprintln(b, "\tnode [shape=box, style=filled];")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(b, "\t%s [label=%s, fillcolor=%s, tooltip=%s];\n",
+			dotID(n.ID), dotString(fmt.Sprintf("%d: %s", n.ID, n.Title)), dotString(StateColor(n.State)), dotString(n.State))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(b, "\t%s -> %s [label=%s];\n", dotID(e.SourceID), dotID(e.TargetID), dotString(e.ForwardName))
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+func dotID(id uint64) string {
+	return strconv.Quote(strconv.FormatUint(id, 10))
+}
+
+// dotString quotes the given string, escaping the characters with a meaning in DOT
+func dotString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\r", "", -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+// jsonGraph is the JSON Graph Format representation of a graph, see also http://jsongraphformat.info
+type jsonGraph struct {
+	Graph struct {
+		Directed bool            `json:"directed"`
+		Type     string          `json:"type"`
+		Nodes    []jsonGraphNode `json:"nodes"`
+		Edges    []jsonGraphEdge `json:"edges"`
+	} `json:"graph"`
+}
+
+type jsonGraphNode struct {
+	ID       string                 `json:"id"`
+	Label    string                 `json:"label"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type jsonGraphEdge struct {
+	ID       string                 `json:"id"`
+	Source   string                 `json:"source"`
+	Target   string                 `json:"target"`
+	Relation string                 `json:"relation"`
+	Label    string                 `json:"label"`
+	Directed bool                   `json:"directed"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// WriteJSON writes the given graph in the JSON Graph Format, labelling the
+// links with the forward name of their type and coloring the work items by state
+func WriteJSON(w io.Writer, g Graph) error {
+	var result jsonGraph
+	result.Graph.Directed = true
+	result.Graph.Type = EndpointWorkItemLinks
+	result.Graph.Nodes = make([]jsonGraphNode, len(g.Nodes))
+	for i, n := range g.Nodes {
+		result.Graph.Nodes[i] = jsonGraphNode{
+			ID:    strconv.FormatUint(n.ID, 10),
+			Label: n.Title,
+			Metadata: map[string]interface{}{
+				"type":  n.Type,
+				"state": n.State,
+				"color": StateColor(n.State),
+			},
+		}
+	}
+	result.Graph.Edges = make([]jsonGraphEdge, len(g.Edges))
+	for i, e := range g.Edges {
+		result.Graph.Edges[i] = jsonGraphEdge{
+			ID:       e.ID.String(),
+			Source:   strconv.FormatUint(e.SourceID, 10),
+			Target:   strconv.FormatUint(e.TargetID, 10),
+			Relation: e.ForwardName,
+			Label:    e.ForwardName,
+			Directed: true,
+			Metadata: map[string]interface{}{
+				"linkType": e.LinkTypeID,
+			},
+		}
+	}
+	return json.NewEncoder(w).Encode(result)
+}
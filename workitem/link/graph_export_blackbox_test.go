@@ -0,0 +1,73 @@
+package link_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGraph() link.Graph {
+	return link.Graph{
+		Nodes: []link.GraphNode{
+			{ID: 1, Type: workitem.SystemBug, Title: `Fix the "parser"`, State: workitem.SystemStateNew},
+			{ID: 2, Type: workitem.SystemBug, Title: "Write\nthe tests", State: "unknown"},
+		},
+		Edges: []link.GraphEdge{
+			{ID: uuid.FromStringOrNil("0e671e36-871b-43a6-9166-0c4bd573e231"), SourceID: 1, TargetID: 2, LinkTypeID: uuid.FromStringOrNil("6ba7b810-9dad-11d1-80b4-00c04fd430c8"), ForwardName: "parent of"},
+		},
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	// given
+	var b bytes.Buffer
+	// when
+	err := link.WriteDOT(&b, testGraph())
+	// then
+	require.Nil(t, err)
+	assert.Equal(t, `digraph workitems {
+	node [shape=box, style=filled];
+	"1" [label="1: Fix the \"parser\"", fillcolor="lightblue", tooltip="new"];
+	"2" [label="2: Write\nthe tests", fillcolor="white", tooltip="unknown"];
+	"1" -> "2" [label="parent of"];
+}
+`, b.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	// given
+	var b bytes.Buffer
+	// when
+	err := link.WriteJSON(&b, testGraph())
+	// then
+	require.Nil(t, err)
+	var result map[string]interface{}
+	require.Nil(t, json.Unmarshal(b.Bytes(), &result))
+	graph := result["graph"].(map[string]interface{})
+	assert.Equal(t, true, graph["directed"])
+	nodes := graph["nodes"].([]interface{})
+	require.Len(t, nodes, 2)
+	node := nodes[0].(map[string]interface{})
+	assert.Equal(t, "1", node["id"])
+	assert.Equal(t, `Fix the "parser"`, node["label"])
+	assert.Equal(t, "lightblue", node["metadata"].(map[string]interface{})["color"])
+	edges := graph["edges"].([]interface{})
+	require.Len(t, edges, 1)
+	edge := edges[0].(map[string]interface{})
+	assert.Equal(t, "1", edge["source"])
+	assert.Equal(t, "2", edge["target"])
+	assert.Equal(t, "parent of", edge["relation"])
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", edge["metadata"].(map[string]interface{})["linkType"])
+}
@@ -0,0 +1,222 @@
+package link
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/workitem"
+
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// WorkItemTreeNode is a work item of a hierarchy along with its children
+type WorkItemTreeNode struct {
+	WorkItem *app.WorkItem
+	Children []*WorkItemTreeNode
+}
+
+// hierarchyLink is a link of a hierarchy, as returned by the recursive queries
+type hierarchyLink struct {
+	// ID is the id of the ancestor or descendant work item
+	ID uint64
+	// ParentID is the id of the parent of the descendant, or of the child of the ancestor
+	ParentID uint64
+	// Depth is the distance from the work item the query started from
+	Depth int
+}
+
+// treeLinkTypes selects the ids of the link types with a tree topology
+func treeLinkTypes() string {
+	return fmt.Sprintf("SELECT id FROM %s WHERE topology = '%s' AND deleted_at IS NULL", WorkItemLinkType{}.TableName(), TopologyTree)
+}
+
+// ListWorkItemAncestors returns the parent of the given work item, the parent
+// of its parent, and so on up to the root of the hierarchy, nearest first.
+// Returns NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) ListWorkItemAncestors(ctx context.Context, wiIDStr string) ([]*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "ancestors", "query"}, time.Now())
+	wi, err := r.workItemRepo.LoadFromDB(ctx, wiIDStr)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// the path of the visited work items stops the query on a cycle
+	query := fmt.Sprintf(`
+	WITH RECURSIVE ancestors(id, parent_id, depth, path) AS (
+		SELECT source_id, target_id, 1, ARRAY[target_id, source_id] FROM %[1]s
+		WHERE target_id = ? AND link_type_id IN (%[2]s) AND deleted_at IS NULL
+		UNION ALL
+		SELECT l.source_id, l.target_id, a.depth + 1, a.path || l.source_id FROM %[1]s l JOIN ancestors a ON l.target_id = a.id
+		WHERE l.link_type_id IN (%[2]s) AND l.deleted_at IS NULL AND NOT l.source_id = ANY(a.path)
+	)
+	SELECT id, parent_id, depth FROM ancestors ORDER BY depth, id`, WorkItemLink{}.TableName(), treeLinkTypes())
+	links, err := r.queryHierarchy(query, wi.ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return r.loadHierarchyWorkItems(ctx, links)
+}
+
+// ListWorkItemDescendants returns the children of the given work item, their
+// children, and so on down to the given depth, nearest first. A depth of 0
+// returns all the descendants.
+// Returns NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) ListWorkItemDescendants(ctx context.Context, wiIDStr string, depth int) ([]*app.WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "descendants", "query"}, time.Now())
+	links, err := r.listDescendantLinks(ctx, wiIDStr, depth)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return r.loadHierarchyWorkItems(ctx, links)
+}
+
+// LoadWorkItemTree returns the given work item with its descendants down to
+// the given depth, nested in their parents. A depth of 0 returns all the
+// descendants. A work item having several parents appears under each of them.
+// Returns NotFoundError or InternalError
+func (r *GormWorkItemLinkRepository) LoadWorkItemTree(ctx context.Context, wiIDStr string, depth int) (*WorkItemTreeNode, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "tree", "query"}, time.Now())
+	links, err := r.listDescendantLinks(ctx, wiIDStr, depth)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	root, err := r.workItemRepo.Load(ctx, wiIDStr)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	descendants, err := r.loadHierarchyWorkItems(ctx, links)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	workItems := make(map[uint64]*app.WorkItem, len(descendants))
+	for _, wi := range descendants {
+		id, err := strconv.ParseUint(wi.ID, 10, 64)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		workItems[id] = wi
+	}
+	// the same link is returned once per path leading to it
+	children := map[uint64][]uint64{}
+	seen := map[hierarchyLink]bool{}
+	for _, l := range links {
+		edge := hierarchyLink{ID: l.ID, ParentID: l.ParentID}
+		if !seen[edge] {
+			seen[edge] = true
+			children[l.ParentID] = append(children[l.ParentID], l.ID)
+		}
+	}
+	rootID, err := strconv.ParseUint(root.ID, 10, 64)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	// the work items on the path from the root stop the recursion on a cycle of the stored links
+	onPath := map[uint64]bool{}
+	var build func(id uint64, wi *app.WorkItem, level int) *WorkItemTreeNode
+	build = func(id uint64, wi *app.WorkItem, level int) *WorkItemTreeNode {
+		node := &WorkItemTreeNode{WorkItem: wi, Children: []*WorkItemTreeNode{}}
+		if depth > 0 && level >= depth {
+			return node
+		}
+		onPath[id] = true
+		for _, childID := range children[id] {
+			// the deleted work items are skipped
+			if child, ok := workItems[childID]; ok && !onPath[childID] {
+				node.Children = append(node.Children, build(childID, child, level+1))
+			}
+		}
+		delete(onPath, id)
+		return node
+	}
+	return build(rootID, root, 0), nil
+}
+
+// listDescendantLinks returns the links from the given work item down to the
+// given depth, or to the leaves if the depth is 0
+func (r *GormWorkItemLinkRepository) listDescendantLinks(ctx context.Context, wiIDStr string, depth int) ([]hierarchyLink, error) {
+	if depth < 0 {
+		return nil, errors.NewBadParameterError("depth", depth).Expected("a positive number, or 0 for all the levels")
+	}
+	wi, err := r.workItemRepo.LoadFromDB(ctx, wiIDStr)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// the path of the visited work items stops the query on a cycle
+	query := fmt.Sprintf(`
+	WITH RECURSIVE descendants(id, parent_id, depth, path) AS (
+		SELECT target_id, source_id, 1, ARRAY[source_id, target_id] FROM %[1]s
+		WHERE source_id = ? AND link_type_id IN (%[2]s) AND deleted_at IS NULL
+		UNION ALL
+		SELECT l.target_id, l.source_id, d.depth + 1, d.path || l.target_id FROM %[1]s l JOIN descendants d ON l.source_id = d.id
+		WHERE l.link_type_id IN (%[2]s) AND l.deleted_at IS NULL AND NOT l.target_id = ANY(d.path) AND (? = 0 OR d.depth < ?)
+	)
+	SELECT id, parent_id, depth FROM descendants ORDER BY depth, id`, WorkItemLink{}.TableName(), treeLinkTypes())
+	return r.queryHierarchy(query, wi.ID, depth, depth)
+}
+
+// queryHierarchy runs the given recursive query and returns its links
+func (r *GormWorkItemLinkRepository) queryHierarchy(query string, args ...interface{}) ([]hierarchyLink, error) {
+	rows, err := r.db.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	defer rows.Close()
+	result := []hierarchyLink{}
+	for rows.Next() {
+		var l hierarchyLink
+		if err := rows.Scan(&l.ID, &l.ParentID, &l.Depth); err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		result = append(result, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	return result, nil
+}
+
+// loadHierarchyWorkItems loads the work items of the given links, in the order
+// of the links, each work item appearing once
+func (r *GormWorkItemLinkRepository) loadHierarchyWorkItems(ctx context.Context, links []hierarchyLink) ([]*app.WorkItem, error) {
+	ids := []uint64{}
+	seen := map[uint64]bool{}
+	for _, l := range links {
+		if !seen[l.ID] {
+			seen[l.ID] = true
+			ids = append(ids, l.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return []*app.WorkItem{}, nil
+	}
+	var workItems []workitem.WorkItem
+	if err := r.db.Where("id IN (?)", ids).Find(&workItems).Error; err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	byID := make(map[uint64]*workitem.WorkItem, len(workItems))
+	for i := range workItems {
+		byID[workItems[i].ID] = &workItems[i]
+	}
+	res := []*app.WorkItem{}
+	for _, id := range ids {
+		value, ok := byID[id]
+		if !ok {
+			// the work item was deleted
+			continue
+		}
+		wiType, err := r.workItemTypeRepo.LoadTypeFromDB(ctx, value.Type)
+		if err != nil {
+			return nil, errors.NewInternalError(err.Error())
+		}
+		wi, err := workitem.ConvertWorkItemModelToApp(goa.ContextRequest(ctx), wiType, value)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		res = append(res, wi)
+	}
+	return res, nil
+}
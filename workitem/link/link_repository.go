@@ -8,9 +8,11 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/webhook"
 	"github.com/almighty/almighty-core/workitem"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
@@ -37,6 +39,13 @@ type WorkItemLinkRepository interface {
 	Delete(ctx context.Context, ID uuid.UUID, suppressorID uuid.UUID) error
 	Save(ctx context.Context, linkCat app.WorkItemLinkSingle, modifierID uuid.UUID) (*app.WorkItemLinkSingle, error)
 	ListWorkItemChildren(ctx context.Context, parent string) ([]*app.WorkItem, error)
+	ListWorkItemAncestors(ctx context.Context, wiIDStr string) ([]*app.WorkItem, error)
+	ListWorkItemDescendants(ctx context.Context, wiIDStr string, depth int) ([]*app.WorkItem, error)
+	LoadWorkItemTree(ctx context.Context, wiIDStr string, depth int) (*WorkItemTreeNode, error)
+	ListBlockers(ctx context.Context, wiIDs []uint64) (map[uint64][]uint64, error)
+	AnalyzeDependencies(ctx context.Context, spaceID uuid.UUID, iterationID *uuid.UUID, weightField string) (*DependencyAnalysis, error)
+	LoadGraph(ctx context.Context, wiIDs []uint64, depth int, filter GraphFilter) (*Graph, error)
+	ApplyBulk(ctx context.Context, operations []BulkOperation, modifierID uuid.UUID) ([]BulkResult, error)
 }
 
 // NewWorkItemLinkRepository creates a work item link repository based on gorm
@@ -110,6 +119,13 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 	if err := r.ValidateCorrectSourceAndTargetType(ctx, sourceID, targetID, linkTypeID); err != nil {
 		return nil, errs.WithStack(err)
 	}
+	linkType, err := r.workItemLinkTypeRepo.LoadTypeFromDBByID(ctx, linkTypeID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.ValidateTopology(ctx, uuid.Nil, sourceID, targetID, *linkType); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	db := r.db.Create(link)
 	if db.Error != nil {
 		if gormsupport.IsUniqueViolation(db.Error, "work_item_links_unique_idx") {
@@ -124,6 +140,10 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 	}
 	// Convert the created link type entry into a JSONAPI response
 	result := ConvertLinkFromModel(*link)
+	if err := webhook.EnqueueForWorkItem(r.db, strconv.FormatUint(sourceID, 10), webhook.EventLinkCreated, result); err != nil {
+		return nil, errs.Wrapf(err, "error while creating work item link")
+	}
+	event.Publish(ctx, r.db, event.LinkCreated{LinkID: link.ID, LinkTypeID: linkTypeID, SourceID: sourceID, TargetID: targetID, CreatorID: creatorID})
 	return &result, nil
 }
 
@@ -212,8 +232,10 @@ func (r *GormWorkItemLinkRepository) Delete(ctx context.Context, linkID uuid.UUI
 	if tx.RecordNotFound() {
 		return errors.NewNotFoundError("work item link", linkID.String())
 	}
-	r.deleteLink(ctx, lnk, suppressorID)
-	return nil
+	if tx.Error != nil {
+		return errors.NewInternalError(tx.Error.Error())
+	}
+	return r.deleteLink(ctx, lnk, suppressorID)
 }
 
 // DeleteRelatedLinks deletes all links in which the source or target equals the
@@ -259,6 +281,7 @@ func (r *GormWorkItemLinkRepository) deleteLink(ctx context.Context, lnk WorkIte
 	if err := r.revisionRepo.Create(ctx, suppressorID, RevisionTypeDelete, lnk); err != nil {
 		return errs.Wrapf(err, "error while deleting work item")
 	}
+	event.Publish(ctx, r.db, event.LinkDeleted{LinkID: lnk.ID, LinkTypeID: lnk.LinkTypeID, SourceID: lnk.SourceID, TargetID: lnk.TargetID, SuppressorID: suppressorID})
 	return nil
 }
 
@@ -287,6 +310,7 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 	if lt.Data.Attributes.Version == nil || res.Version != *lt.Data.Attributes.Version {
 		return nil, errors.NewVersionConflictError("version conflict")
 	}
+	previous := res
 	if err := ConvertLinkToModel(lt, &res); err != nil {
 		return nil, errs.WithStack(err)
 	}
@@ -294,6 +318,15 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 	if err := r.ValidateCorrectSourceAndTargetType(ctx, res.SourceID, res.TargetID, res.LinkTypeID); err != nil {
 		return nil, errs.WithStack(err)
 	}
+	if res.SourceID != previous.SourceID || res.TargetID != previous.TargetID || res.LinkTypeID != previous.LinkTypeID {
+		linkType, err := r.workItemLinkTypeRepo.LoadTypeFromDBByID(ctx, res.LinkTypeID)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		if err := r.ValidateTopology(ctx, res.ID, res.SourceID, res.TargetID, *linkType); err != nil {
+			return nil, errs.WithStack(err)
+		}
+	}
 	db = r.db.Save(&res)
 	if db.Error != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -310,6 +343,10 @@ func (r *GormWorkItemLinkRepository) Save(ctx context.Context, lt app.WorkItemLi
 		"wilID": res.ID,
 	}, "Work item link updated")
 	result := ConvertLinkFromModel(res)
+	if err := webhook.EnqueueForWorkItem(r.db, strconv.FormatUint(res.SourceID, 10), webhook.EventLinkUpdated, result); err != nil {
+		return nil, errs.Wrapf(err, "error while saving work item link")
+	}
+	event.Publish(ctx, r.db, event.LinkUpdated{LinkID: res.ID, LinkTypeID: res.LinkTypeID, SourceID: res.SourceID, TargetID: res.TargetID, ModifierID: modifierID})
 	return &result, nil
 }
 
@@ -320,10 +357,8 @@ func (r *GormWorkItemLinkRepository) ListWorkItemChildren(ctx context.Context, p
 	where := fmt.Sprintf(`
 	id in (
 		SELECT target_id FROM %s
-		WHERE source_id = ? AND link_type_id IN (
-			SELECT id FROM %s WHERE forward_name = 'parent of'
-		)
-	)`, WorkItemLink{}.TableName(), WorkItemLinkType{}.TableName())
+		WHERE source_id = ? AND link_type_id IN (%s) AND deleted_at IS NULL
+	)`, WorkItemLink{}.TableName(), treeLinkTypes())
 	db := r.db.Model(&workitem.WorkItem{}).Where(where, parent)
 	rows, err := db.Rows()
 	if err != nil {
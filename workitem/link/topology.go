@@ -0,0 +1,98 @@
+package link
+
+import (
+	"fmt"
+
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/net/context"
+)
+
+// linkTypeLock is the namespace of the advisory locks of the link types taken before validating their topology
+const linkTypeLock = "work_item_link_types"
+
+// ValidateTopology returns a BadParameterError naming the conflicting link if
+// a link of the given type from the source to the target would break the
+// topology of the link type. A tree link must neither give a second parent to
+// the target nor create a cycle, a dependency link must not create a cycle and
+// a network link must not duplicate a link in the reverse direction. A directed
+// network accepts any link. The link with the given ID, which is being updated,
+// is ignored (uuid.Nil for a new link).
+// The lock of the link type is held until the end of the transaction, so that the
+// links created or updated concurrently are validated one after the other.
+func (r *GormWorkItemLinkRepository) ValidateTopology(ctx context.Context, linkID uuid.UUID, sourceID, targetID uint64, linkType WorkItemLinkType) error {
+	if linkType.Topology == TopologyDirectedNetwork {
+		return nil
+	}
+	if err := gormsupport.AdvisoryXactLock(r.db, linkTypeLock, linkType.ID.String()); err != nil {
+		return errors.NewInternalError(err.Error())
+	}
+	switch linkType.Topology {
+	case TopologyTree:
+		parentLinkID, err := r.findLinkID(linkType.ID, "target_id = ? AND id <> ?", targetID, linkID)
+		if err != nil {
+			return err
+		}
+		if parentLinkID != nil {
+			return errors.NewBadParameterError("data.relationships.target", targetID).Expected(fmt.Sprintf("a work item without parent in the %s tree (it is the target of the link %s)", linkType.Name, *parentLinkID))
+		}
+		return r.validateAcyclic(linkID, sourceID, targetID, linkType)
+	case TopologyDependency:
+		return r.validateAcyclic(linkID, sourceID, targetID, linkType)
+	case TopologyNetwork:
+		reverseLinkID, err := r.findLinkID(linkType.ID, "source_id = ? AND target_id = ? AND id <> ?", targetID, sourceID, linkID)
+		if err != nil {
+			return err
+		}
+		if reverseLinkID != nil {
+			return errors.NewBadParameterError("data.relationships.source + data.relationships.target", fmt.Sprintf("%d -> %d", sourceID, targetID)).Expected(fmt.Sprintf("no link in the reverse direction (it is the link %s)", *reverseLinkID))
+		}
+	}
+	return nil
+}
+
+// validateAcyclic returns a BadParameterError naming the link which closes the
+// cycle if the source can already be reached from the target with links of the
+// given type, other than the link with the given ID.
+func (r *GormWorkItemLinkRepository) validateAcyclic(linkID uuid.UUID, sourceID, targetID uint64, linkType WorkItemLinkType) error {
+	if sourceID == targetID {
+		return errors.NewBadParameterError("data.relationships.target", targetID).Expected(fmt.Sprintf("a work item other than the source in the %s %s", linkType.Name, linkType.Topology))
+	}
+	var result struct {
+		ID uuid.UUID
+	}
+	// the UNION drops the links already visited, so the query ends even if
+	// the stored links have a cycle
+	query := fmt.Sprintf(`
+	WITH RECURSIVE reachable(id, target_id) AS (
+		SELECT id, target_id FROM %[1]s
+		WHERE link_type_id = ? AND source_id = ? AND id <> ? AND deleted_at IS NULL
+		UNION
+		SELECT l.id, l.target_id FROM %[1]s l JOIN reachable r ON l.source_id = r.target_id
+		WHERE l.link_type_id = ? AND l.id <> ? AND l.deleted_at IS NULL
+	)
+	SELECT id FROM reachable WHERE target_id = ? LIMIT 1`, WorkItemLink{}.TableName())
+	db := r.db.Raw(query, linkType.ID, targetID, linkID, linkType.ID, linkID, sourceID).Scan(&result)
+	if db.RecordNotFound() {
+		return nil
+	}
+	if db.Error != nil {
+		return errors.NewInternalError(db.Error.Error())
+	}
+	return errors.NewBadParameterError("data.relationships.target", targetID).Expected(fmt.Sprintf("a work item which doesn't lead back to the source in the %s %s (the cycle would be closed by the link %s)", linkType.Name, linkType.Topology, result.ID))
+}
+
+// findLinkID returns the ID of a link of the given type matching the given
+// condition, or nil if there is none.
+func (r *GormWorkItemLinkRepository) findLinkID(linkTypeID uuid.UUID, where string, args ...interface{}) (*uuid.UUID, error) {
+	res := WorkItemLink{}
+	db := r.db.Where("link_type_id = ?", linkTypeID).Where(where, args...).First(&res)
+	if db.RecordNotFound() {
+		return nil, nil
+	}
+	if db.Error != nil {
+		return nil, errors.NewInternalError(db.Error.Error())
+	}
+	return &res.ID, nil
+}
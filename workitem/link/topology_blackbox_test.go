@@ -0,0 +1,276 @@
+package link_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/gormsupport/cleaner"
+	"github.com/almighty/almighty-core/gormtestsupport"
+	"github.com/almighty/almighty-core/migration"
+	"github.com/almighty/almighty-core/models"
+	"github.com/almighty/almighty-core/resource"
+	"github.com/almighty/almighty-core/space"
+	testsupport "github.com/almighty/almighty-core/test"
+	"github.com/almighty/almighty-core/workitem"
+	"github.com/almighty/almighty-core/workitem/link"
+
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRunWorkItemLinkTopologyBlackBoxTest(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &topologyBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite("../../config.yaml")})
+}
+
+type topologyBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repository   link.WorkItemLinkRepository
+	clean        func()
+	ctx          context.Context
+	testIdentity account.Identity
+	workItemIDs  []uint64
+	linkTypeIDs  map[string]uuid.UUID
+}
+
+func (s *topologyBlackBoxTest) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+	// Make sure the database is populated with the correct types (e.g. bug etc.)
+	if _, c := os.LookupEnv(resource.Database); c != false {
+		if err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+			s.ctx = migration.NewMigrationContext(context.Background())
+			return migration.PopulateCommonTypes(s.ctx, tx, workitem.NewWorkItemTypeRepository(tx))
+		}); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func (s *topologyBlackBoxTest) SetupTest() {
+	s.repository = link.NewWorkItemLinkRepository(s.DB)
+	s.clean = cleaner.DeleteCreatedEntities(s.DB)
+	testIdentity, err := testsupport.CreateTestIdentity(s.DB, "jdoe", "test")
+	require.Nil(s.T(), err)
+	s.testIdentity = testIdentity
+	testSpace, err := space.NewRepository(s.DB).Create(s.ctx, &space.Space{
+		Name: "test-space" + uuid.NewV4().String(),
+	})
+	require.Nil(s.T(), err)
+	// create the work items to link
+	workitemRepository := workitem.NewWorkItemRepository(s.DB)
+	s.workItemIDs = nil
+	for i := 0; i < 3; i++ {
+		wi, err := workitemRepository.Create(
+			s.ctx, testSpace.ID, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: "Bug " + strconv.Itoa(i),
+				workitem.SystemState: workitem.SystemStateNew,
+			}, s.testIdentity.ID)
+		require.Nil(s.T(), err)
+		id, err := strconv.ParseUint(wi.ID, 10, 64)
+		require.Nil(s.T(), err)
+		s.workItemIDs = append(s.workItemIDs, id)
+	}
+	// create a link type for each topology
+	categoryName := "test-category" + uuid.NewV4().String()
+	linkCategory, err := link.NewWorkItemLinkCategoryRepository(s.DB).Create(s.ctx, &categoryName, nil)
+	require.Nil(s.T(), err)
+	linkTypeRepository := link.NewWorkItemLinkTypeRepository(s.DB)
+	s.linkTypeIDs = map[string]uuid.UUID{}
+	for _, topology := range []string{link.TopologyTree, link.TopologyDependency, link.TopologyNetwork, link.TopologyDirectedNetwork} {
+		linkType, err := linkTypeRepository.Create(s.ctx, "test "+topology, nil, workitem.SystemBug, workitem.SystemBug, "foo", "bar", topology, *linkCategory.Data.ID, testSpace.ID)
+		require.Nil(s.T(), err)
+		s.linkTypeIDs[topology] = *linkType.Data.ID
+	}
+}
+
+func (s *topologyBlackBoxTest) TearDownTest() {
+	s.clean()
+}
+
+// link creates a link of the given topology between the work items at the given indexes
+func (s *topologyBlackBoxTest) link(topology string, source, target int) (*uuid.UUID, error) {
+	l, err := s.repository.Create(s.ctx, s.workItemIDs[source], s.workItemIDs[target], s.linkTypeIDs[topology], s.testIdentity.ID)
+	if err != nil {
+		return nil, err
+	}
+	return l.Data.ID, nil
+}
+
+// relink updates the given link so that it goes from the work item at the source index to the one at the target index
+func (s *topologyBlackBoxTest) relink(linkID uuid.UUID, source, target int) error {
+	l, err := s.repository.Load(s.ctx, linkID)
+	require.Nil(s.T(), err)
+	l.Data.Relationships.Source.Data.ID = strconv.FormatUint(s.workItemIDs[source], 10)
+	l.Data.Relationships.Target.Data.ID = strconv.FormatUint(s.workItemIDs[target], 10)
+	_, err = s.repository.Save(s.ctx, *l, s.testIdentity.ID)
+	return err
+}
+
+func (s *topologyBlackBoxTest) requireBadParameter(err error, conflictingLinkID uuid.UUID) {
+	require.NotNil(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+	assert.Contains(s.T(), err.Error(), conflictingLinkID.String())
+}
+
+func (s *topologyBlackBoxTest) TestTreeRejectsSecondParent() {
+	// given
+	parentLinkID, err := s.link(link.TopologyTree, 0, 2)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyTree, 1, 2)
+	// then
+	s.requireBadParameter(err, *parentLinkID)
+}
+
+func (s *topologyBlackBoxTest) TestTreeRejectsSecondParentOnUpdate() {
+	// given
+	parentLinkID, err := s.link(link.TopologyTree, 0, 2)
+	require.Nil(s.T(), err)
+	linkID, err := s.link(link.TopologyTree, 0, 1)
+	require.Nil(s.T(), err)
+	// when
+	err = s.relink(*linkID, 1, 2)
+	// then
+	s.requireBadParameter(err, *parentLinkID)
+}
+
+func (s *topologyBlackBoxTest) TestTreeAcceptsNewParentOnUpdate() {
+	// given
+	linkID, err := s.link(link.TopologyTree, 0, 2)
+	require.Nil(s.T(), err)
+	// when
+	err = s.relink(*linkID, 1, 2)
+	// then
+	require.Nil(s.T(), err)
+}
+
+func (s *topologyBlackBoxTest) TestTreeRejectsConcurrentSecondParent() {
+	// given a first transaction holding a new parent link
+	tx := s.DB.Begin()
+	require.Nil(s.T(), tx.Error)
+	parentLink, err := link.NewWorkItemLinkRepository(tx).Create(s.ctx, s.workItemIDs[0], s.workItemIDs[2], s.linkTypeIDs[link.TopologyTree], s.testIdentity.ID)
+	require.Nil(s.T(), err)
+	// when a second transaction links another parent meanwhile
+	result := make(chan error)
+	go func() {
+		result <- models.Transactional(s.DB, func(tx *gorm.DB) error {
+			_, err := link.NewWorkItemLinkRepository(tx).Create(s.ctx, s.workItemIDs[1], s.workItemIDs[2], s.linkTypeIDs[link.TopologyTree], s.testIdentity.ID)
+			return err
+		})
+	}()
+	// then it waits for the first one to end, and sees its link
+	select {
+	case err := <-result:
+		require.Fail(s.T(), "the second link was validated before the end of the first transaction", "%v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	require.Nil(s.T(), tx.Commit().Error)
+	s.requireBadParameter(<-result, *parentLink.Data.ID)
+}
+
+func (s *topologyBlackBoxTest) TestTreeRejectsCycle() {
+	// given
+	_, err := s.link(link.TopologyTree, 0, 1)
+	require.Nil(s.T(), err)
+	closingLinkID, err := s.link(link.TopologyTree, 1, 2)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyTree, 2, 0)
+	// then
+	s.requireBadParameter(err, *closingLinkID)
+}
+
+func (s *topologyBlackBoxTest) TestTreeAcceptsSeveralChildren() {
+	// given
+	_, err := s.link(link.TopologyTree, 0, 1)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyTree, 0, 2)
+	// then
+	require.Nil(s.T(), err)
+}
+
+func (s *topologyBlackBoxTest) TestDependencyRejectsCycle() {
+	// given
+	_, err := s.link(link.TopologyDependency, 0, 1)
+	require.Nil(s.T(), err)
+	closingLinkID, err := s.link(link.TopologyDependency, 1, 2)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyDependency, 2, 0)
+	// then
+	s.requireBadParameter(err, *closingLinkID)
+}
+
+func (s *topologyBlackBoxTest) TestDependencyRejectsCycleOnUpdate() {
+	// given
+	_, err := s.link(link.TopologyDependency, 0, 1)
+	require.Nil(s.T(), err)
+	closingLinkID, err := s.link(link.TopologyDependency, 1, 2)
+	require.Nil(s.T(), err)
+	linkID, err := s.link(link.TopologyDependency, 0, 2)
+	require.Nil(s.T(), err)
+	// when
+	err = s.relink(*linkID, 2, 0)
+	// then
+	s.requireBadParameter(err, *closingLinkID)
+}
+
+func (s *topologyBlackBoxTest) TestDependencyAcceptsSharedDependency() {
+	// given
+	_, err := s.link(link.TopologyDependency, 0, 2)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyDependency, 1, 2)
+	// then
+	require.Nil(s.T(), err)
+}
+
+func (s *topologyBlackBoxTest) TestDependencyRejectsSelfLink() {
+	// when
+	_, err := s.link(link.TopologyDependency, 0, 0)
+	// then
+	require.NotNil(s.T(), err)
+	require.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *topologyBlackBoxTest) TestNetworkRejectsReverseLink() {
+	// given
+	linkID, err := s.link(link.TopologyNetwork, 0, 1)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyNetwork, 1, 0)
+	// then
+	s.requireBadParameter(err, *linkID)
+}
+
+func (s *topologyBlackBoxTest) TestNetworkAcceptsReverseLinkAfterDeletion() {
+	// given
+	linkID, err := s.link(link.TopologyNetwork, 0, 1)
+	require.Nil(s.T(), err)
+	require.Nil(s.T(), s.repository.Delete(s.ctx, *linkID, s.testIdentity.ID))
+	// when
+	_, err = s.link(link.TopologyNetwork, 1, 0)
+	// then
+	require.Nil(s.T(), err)
+}
+
+func (s *topologyBlackBoxTest) TestDirectedNetworkAcceptsCycle() {
+	// given
+	_, err := s.link(link.TopologyDirectedNetwork, 0, 1)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.link(link.TopologyDirectedNetwork, 1, 0)
+	// then
+	require.Nil(s.T(), err)
+}
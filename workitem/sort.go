@@ -0,0 +1,65 @@
+package workitem
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/almighty/almighty-core/keyset"
+)
+
+// SortKey describes a field to sort work items by
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// DefaultSortKeys is the order of work items when no sort keys are given
+var DefaultSortKeys = []SortKey{{Field: SystemOrder, Descending: true}}
+
+// columnTypes holds the SQL types of the columns in columnFields, json
+// fields are of type jsonb.
+var columnTypes = map[string]string{
+	"ID":              "bigint",
+	"Type":            "uuid",
+	"Version":         "integer",
+	"created_at":      "timestamptz",
+	"updated_at":      "timestamptz",
+	"execution_order": "double precision",
+}
+
+// CompileSortKeys returns an order clause for the given sort keys that can be
+// used with gorm.DB.Order(). Columns are qualified with the work item table
+// name so that the clause can be used in joins.
+// Json fields are compared as jsonb values, so numbers (and instants, which are
+// stored as nanoseconds) are sorted numerically and strings lexically.
+// A final sort by ID is always added to get a stable order, which keeps paging consistent.
+// Work items without a value for a field are always sorted last.
+func CompileSortKeys(keys []SortKey) (string, error) {
+	k, err := SortKeyset(keys)
+	if err != nil {
+		return "", err
+	}
+	return keyset.Order(k, false), nil
+}
+
+// SortKeyset returns the keys for keyset pagination of work items sorted by
+// the given sort keys, including the final sort by ID.
+func SortKeyset(keys []SortKey) ([]keyset.Key, error) {
+	result := []keyset.Key{}
+	for _, key := range keys {
+		var k keyset.Key
+		if !isJSONField(key.Field) {
+			column := columnFields[key.Field]
+			k = keyset.Key{Expression: WorkItem{}.TableName() + "." + column, Type: columnTypes[column]}
+		} else {
+			if strings.Contains(key.Field, "'") {
+				return nil, fmt.Errorf("single quote not allowed in field name")
+			}
+			k = keyset.Key{Expression: fmt.Sprintf("%s.Fields->'%s'", WorkItem{}.TableName(), key.Field), Type: "jsonb"}
+		}
+		k.Descending = key.Descending
+		result = append(result, k)
+	}
+	result = append(result, keyset.Key{Expression: WorkItem{}.TableName() + ".ID", Type: "bigint", Descending: true})
+	return result, nil
+}
@@ -0,0 +1,30 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSortKeys(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	order, err := CompileSortKeys(nil)
+	require.Nil(t, err)
+	assert.Equal(t, "work_items.ID DESC NULLS LAST", order)
+
+	order, err = CompileSortKeys(DefaultSortKeys)
+	require.Nil(t, err)
+	assert.Equal(t, "work_items.execution_order DESC NULLS LAST, work_items.ID DESC NULLS LAST", order)
+
+	order, err = CompileSortKeys([]SortKey{{Field: SystemCreatedAt, Descending: true}, {Field: SystemTitle}})
+	require.Nil(t, err)
+	assert.Equal(t, "work_items.created_at DESC NULLS LAST, work_items.Fields->'system.title' ASC NULLS LAST, work_items.ID DESC NULLS LAST", order)
+
+	_, err = CompileSortKeys([]SortKey{{Field: "foo'"}})
+	assert.NotNil(t, err)
+}
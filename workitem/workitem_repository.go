@@ -1,6 +1,7 @@
 package workitem
 
 import (
+	"database/sql"
 	"strconv"
 
 	"golang.org/x/net/context"
@@ -8,10 +9,14 @@ import (
 	"fmt"
 
 	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/log"
+	"github.com/almighty/almighty-core/reference"
 	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/webhook"
 
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
@@ -36,8 +41,10 @@ type WorkItemRepository interface {
 	Save(ctx context.Context, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error)
 	Reorder(ctx context.Context, direction DirectionType, targetID *string, wi app.WorkItem, modifierID uuid.UUID) (*app.WorkItem, error)
 	Delete(ctx context.Context, ID string, suppressorID uuid.UUID) error
+	Restore(ctx context.Context, ID string, revisionID uuid.UUID, version int, modifierID uuid.UUID) (*app.WorkItem, error)
 	Create(ctx context.Context, spaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, creatorID uuid.UUID) (*app.WorkItem, error)
-	List(ctx context.Context, criteria criteria.Expression, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	List(ctx context.Context, criteria criteria.Expression, sortKeys []SortKey, start *int, length *int) ([]*app.WorkItem, uint64, error)
+	ListByCursor(ctx context.Context, criteria criteria.Expression, sortKeys []SortKey, cursor keyset.Cursor, limit int) ([]*app.WorkItem, *keyset.Page, error)
 	Fetch(ctx context.Context, criteria criteria.Expression) (*app.WorkItem, error)
 	GetCountsPerIteration(ctx context.Context, spaceID uuid.UUID) (map[string]WICountsPerIteration, error)
 	GetCountsForIteration(ctx context.Context, iterationID uuid.UUID) (map[string]WICountsPerIteration, error)
@@ -45,7 +52,7 @@ type WorkItemRepository interface {
 
 // NewWorkItemRepository creates a GormWorkItemRepository
 func NewWorkItemRepository(db *gorm.DB) *GormWorkItemRepository {
-	repository := &GormWorkItemRepository{db, &GormWorkItemTypeRepository{db}, &GormRevisionRepository{db}}
+	repository := &GormWorkItemRepository{db, &GormWorkItemTypeRepository{db}, &GormRevisionRepository{db}, reference.NewRepository(db)}
 	return repository
 }
 
@@ -54,6 +61,7 @@ type GormWorkItemRepository struct {
 	db   *gorm.DB
 	witr *GormWorkItemTypeRepository
 	wirr *GormRevisionRepository
+	refr *reference.GormRepository
 }
 
 // ************************************************
@@ -157,7 +165,7 @@ func (r *GormWorkItemRepository) Delete(ctx context.Context, workitemID string,
 	}
 	workItem.ID = id
 	// retrieve the current version of the work item to delete
-	r.db.Select("id, version, type").Where("id = ?", workItem.ID).Find(&workItem)
+	r.db.Select("id, version, type, space_id").Where("id = ?", workItem.ID).Find(&workItem)
 	// delete the work item
 	tx := r.db.Delete(workItem)
 	if err = tx.Error; err != nil {
@@ -171,6 +179,7 @@ func (r *GormWorkItemRepository) Delete(ctx context.Context, workitemID string,
 	if err != nil {
 		return errs.Wrapf(err, "error while deleting work item")
 	}
+	event.Publish(ctx, r.db, event.WorkItemDeleted{WorkItemID: workitemID, SpaceID: workItem.SpaceID, SuppressorID: suppressorID})
 	log.Debug(ctx, map[string]interface{}{"wiID": workitemID}, "Work item deleted successfully!")
 	return nil
 }
@@ -360,6 +369,7 @@ func (r *GormWorkItemRepository) Reorder(ctx context.Context, direction Directio
 	if err != nil {
 		return nil, err
 	}
+	event.Publish(ctx, r.db, event.WorkItemUpdated{WorkItemID: strconv.FormatUint(res.ID, 10), SpaceID: res.SpaceID, ModifierID: modifierID, Version: res.Version})
 	return ConvertWorkItemModelToApp(goa.ContextRequest(ctx), wiType, &res)
 }
 
@@ -426,10 +436,77 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, wi app.WorkItem, modi
 	if err != nil {
 		return nil, errs.Wrapf(err, "error while saving work item")
 	}
+	if err = r.updateReferences(ctx, res); err != nil {
+		return nil, errs.Wrapf(err, "error while saving work item")
+	}
+	witem, err := ConvertWorkItemModelToApp(goa.ContextRequest(ctx), wiType, &res)
+	if err != nil {
+		return nil, err
+	}
+	if err = webhook.Enqueue(r.db, res.SpaceID, webhook.EventWorkItemUpdated, witem); err != nil {
+		return nil, errs.Wrapf(err, "error while saving work item")
+	}
+	event.Publish(ctx, r.db, event.WorkItemUpdated{WorkItemID: strconv.FormatUint(res.ID, 10), SpaceID: res.SpaceID, ModifierID: modifierID, Version: res.Version})
 	log.Info(ctx, map[string]interface{}{
 		"wiID": wi.ID,
 	}, "Updated work item repository")
-	return ConvertWorkItemModelToApp(goa.ContextRequest(ctx), wiType, &res)
+	return witem, nil
+}
+
+// Restore sets the fields of the work item with the given id back to the values of the given revision,
+// which is stored as a new revision. Deleted work items are restored as well. Version must be the same
+// as the one in the stored version and the fields must be valid for the type of the work item.
+// returns NotFoundError, BadParameterError, VersionConflictError, ConversionError or InternalError
+func (r *GormWorkItemRepository) Restore(ctx context.Context, workitemID string, revisionID uuid.UUID, version int, modifierID uuid.UUID) (*app.WorkItem, error) {
+	id, err := strconv.ParseUint(workitemID, 10, 64)
+	if err != nil || id == 0 {
+		return nil, errors.NewNotFoundError("work item", workitemID)
+	}
+	revision, err := r.wirr.Load(ctx, revisionID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if revision.WorkItemID != id {
+		return nil, errors.NewNotFoundError("work item revision", revisionID.String())
+	}
+	if revision.Type == RevisionTypeDelete {
+		return nil, errors.NewBadParameterError("revisionID", revisionID).Expected("a revision holding the fields of the work item")
+	}
+	res := WorkItem{}
+	tx := r.db.Unscoped().First(&res, id)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item", workitemID)
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(tx.Error.Error())
+	}
+	if res.Version != version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	if res.DeletedAt != nil {
+		// the version is kept, so that the following save checks the same version
+		tx = r.db.Unscoped().Model(&res).Where("Version = ?", version).Update("deleted_at", nil)
+		if tx.Error != nil {
+			return nil, errors.NewInternalError(tx.Error.Error())
+		}
+		if tx.RowsAffected == 0 {
+			return nil, errors.NewVersionConflictError("version conflict")
+		}
+		log.Info(ctx, map[string]interface{}{
+			"wiID": workitemID,
+		}, "Undeleted work item")
+	}
+	wiType, err := r.witr.LoadTypeFromDB(ctx, res.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(err.Error())
+	}
+	res.Fields = revision.WorkItemFields
+	wi, err := ConvertWorkItemModelToApp(goa.ContextRequest(ctx), wiType, &res)
+	if err != nil {
+		// the fields of the revision don't match the current type of the work item anymore
+		return nil, errors.NewBadParameterError("revision", revisionID).Expected(err.Error())
+	}
+	return r.Save(ctx, *wi, modifierID)
 }
 
 // Create creates a new work item in the repository
@@ -484,10 +561,26 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, spaceID uuid.UUID,
 	if err != nil {
 		return nil, errs.Wrapf(err, "error while creating work item")
 	}
+	if err = r.updateReferences(ctx, wi); err != nil {
+		return nil, errs.Wrapf(err, "error while creating work item")
+	}
+	if err = webhook.Enqueue(r.db, spaceID, webhook.EventWorkItemCreated, witem); err != nil {
+		return nil, errs.Wrapf(err, "error while creating work item")
+	}
+	event.Publish(ctx, r.db, event.WorkItemCreated{WorkItemID: strconv.FormatUint(wi.ID, 10), SpaceID: spaceID, CreatorID: creatorID})
 	log.Debug(ctx, map[string]interface{}{"pkg": "workitem", "wiID": wi.ID}, "Work item created successfully!")
 	return witem, nil
 }
 
+// updateReferences stores the references to other work items found in the description of the given work item
+func (r *GormWorkItemRepository) updateReferences(ctx context.Context, wi WorkItem) error {
+	description := rendering.NewMarkupContentFromValue(wi.Fields[SystemDescription])
+	if description == nil {
+		description = &rendering.MarkupContent{}
+	}
+	return r.refr.Update(ctx, reference.Source{WorkItemID: strconv.FormatUint(wi.ID, 10)}, *description)
+}
+
 // ConvertWorkItemModelToApp convert work item model to app WI
 func ConvertWorkItemModelToApp(request *goa.RequestData, wiType *WorkItemType, wi *WorkItem) (*app.WorkItem, error) {
 	result, err := wiType.ConvertFromModel(request, *wi)
@@ -509,7 +602,7 @@ func ConvertWorkItemModelToApp(request *goa.RequestData, wiType *WorkItemType, w
 
 // extracted this function from List() in order to close the rows object with "defer" for more readability
 // workaround for https://github.com/lib/pq/issues/81
-func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]WorkItem, uint64, error) {
+func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, sortKeys []SortKey, start *int, limit *int) ([]WorkItem, uint64, error) {
 	where, parameters, compileError := Compile(criteria)
 	if compileError != nil {
 		return nil, 0, errors.NewBadParameterError("expression", criteria)
@@ -520,6 +613,14 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 		"parameters": parameters,
 	}, "Executing query : '%s' with params %v", where, parameters)
 
+	if len(sortKeys) == 0 {
+		sortKeys = DefaultSortKeys
+	}
+	order, err := CompileSortKeys(sortKeys)
+	if err != nil {
+		return nil, 0, errors.NewBadParameterError("sort", sortKeys).Expected(err.Error())
+	}
+
 	db := r.db.Model(&WorkItem{}).Where(where, parameters...)
 	orgDB := db
 	if start != nil {
@@ -534,7 +635,7 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 		}
 		db = db.Limit(*limit)
 	}
-	db = db.Select("count(*) over () as cnt2 , *").Order("execution_order desc")
+	db = db.Select("count(*) over () as cnt2 , *").Order(order)
 
 	rows, err := db.Rows()
 	if err != nil {
@@ -586,9 +687,10 @@ func (r *GormWorkItemRepository) listItemsFromDB(ctx context.Context, criteria c
 	return result, count, nil
 }
 
-// List returns work item selected by the given criteria.Expression, starting with start (zero-based) and returning at most limit items
-func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
-	result, count, err := r.listItemsFromDB(ctx, criteria, start, limit)
+// List returns work item selected by the given criteria.Expression, sorted by the given keys (or by execution order if there are none),
+// starting with start (zero-based) and returning at most limit items
+func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Expression, sortKeys []SortKey, start *int, limit *int) ([]*app.WorkItem, uint64, error) {
+	result, count, err := r.listItemsFromDB(ctx, criteria, sortKeys, start, limit)
 	if err != nil {
 		return nil, 0, errs.WithStack(err)
 	}
@@ -603,10 +705,82 @@ func (r *GormWorkItemRepository) List(ctx context.Context, criteria criteria.Exp
 	return res, count, nil
 }
 
+// extracted this function from ListByCursor() in order to close the rows object with "defer" for more readability
+func (r *GormWorkItemRepository) listItemsFromDBByCursor(ctx context.Context, criteria criteria.Expression, sortKeys []SortKey, cursor keyset.Cursor, limit int) ([]WorkItem, *keyset.Page, error) {
+	where, parameters, compileError := Compile(criteria)
+	if compileError != nil {
+		return nil, nil, errors.NewBadParameterError("expression", criteria)
+	}
+	if limit <= 0 {
+		return nil, nil, errors.NewBadParameterError("limit", limit)
+	}
+	if len(sortKeys) == 0 {
+		sortKeys = DefaultSortKeys
+	}
+	keys, err := SortKeyset(sortKeys)
+	if err != nil {
+		return nil, nil, errors.NewBadParameterError("sort", sortKeys).Expected(err.Error())
+	}
+	cursorWhere, cursorParameters, err := keyset.Where(keys, cursor)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+
+	log.Info(ctx, map[string]interface{}{
+		"where":      where,
+		"parameters": parameters,
+		"cursor":     cursorWhere,
+	}, "Executing query : '%s' with params %v after cursor '%s'", where, parameters, cursorWhere)
+
+	// fetch one more item to find out if there is a next page
+	db := r.db.Model(&WorkItem{}).Where(where, parameters...).Where(cursorWhere, cursorParameters...)
+	db = db.Select("*, " + keyset.Columns(keys)).Order(keyset.Order(keys, cursor.Backward)).Limit(limit + 1)
+
+	rows, err := db.Rows()
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	defer rows.Close()
+	values, page, err := keyset.ScanPage(rows, keys, cursor, limit, func(rows *sql.Rows) (interface{}, error) {
+		value := &WorkItem{}
+		return value, db.ScanRows(rows, value)
+	})
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	result := make([]WorkItem, len(values))
+	for i, value := range values {
+		result[i] = *value.(*WorkItem)
+	}
+	return result, page, nil
+}
+
+// ListByCursor returns at most limit work items selected by the given criteria.Expression, sorted by the given keys
+// (or by execution order if there are none), which come after (or before, for backward cursors) the given cursor.
+// Unlike List, it does not count the matching work items.
+func (r *GormWorkItemRepository) ListByCursor(ctx context.Context, criteria criteria.Expression, sortKeys []SortKey, cursor keyset.Cursor, limit int) ([]*app.WorkItem, *keyset.Page, error) {
+	result, page, err := r.listItemsFromDBByCursor(ctx, criteria, sortKeys, cursor, limit)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	res := make([]*app.WorkItem, len(result))
+	for index, value := range result {
+		wiType, err := r.witr.LoadTypeFromDB(ctx, value.Type)
+		if err != nil {
+			return nil, nil, errors.NewInternalError(err.Error())
+		}
+		res[index], err = ConvertWorkItemModelToApp(goa.ContextRequest(ctx), wiType, &value)
+		if err != nil {
+			return nil, nil, errs.WithStack(err)
+		}
+	}
+	return res, page, nil
+}
+
 // Fetch fetches the (first) work item matching by the given criteria.Expression.
 func (r *GormWorkItemRepository) Fetch(ctx context.Context, criteria criteria.Expression) (*app.WorkItem, error) {
 	limit := 1
-	results, count, err := r.List(ctx, criteria, nil, &limit)
+	results, count, err := r.List(ctx, criteria, nil, nil, &limit)
 	if err != nil {
 		return nil, err
 	}
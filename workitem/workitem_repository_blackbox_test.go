@@ -3,13 +3,19 @@ package workitem_test
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/almighty/almighty-core/app"
+	"github.com/almighty/almighty-core/application/event"
 	"github.com/almighty/almighty-core/codebase"
+	"github.com/almighty/almighty-core/criteria"
 	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/gormtestsupport"
 	"github.com/almighty/almighty-core/iteration"
+	"github.com/almighty/almighty-core/keyset"
 	"github.com/almighty/almighty-core/migration"
 	"github.com/almighty/almighty-core/models"
 	"github.com/almighty/almighty-core/rendering"
@@ -312,3 +318,260 @@ func (s *workItemRepoBlackBoxTest) TestCodebaseAttributes() {
 	assert.Equal(s.T(), file, cb.FileName)
 	assert.Equal(s.T(), line, cb.LineNumber)
 }
+
+func (s *workItemRepoBlackBoxTest) TestListWithComparisonOperators() {
+	// given
+	title := "TestListWithComparisonOperators " + uuid.NewV4().String()
+	before := time.Now().Add(-1 * time.Minute)
+	for _, state := range []string{workitem.SystemStateNew, workitem.SystemStateOpen, workitem.SystemStateClosed} {
+		_, err := s.repo.Create(
+			s.ctx, s.spaceID, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: state,
+			}, s.creatorID)
+		require.Nil(s.T(), err, "Could not create workitem")
+	}
+	byTitle := criteria.ILike(criteria.Field(workitem.SystemTitle), criteria.Literal(strings.ToUpper(title)))
+	// when
+	testData := map[criteria.Expression]int{
+		criteria.NotEquals(criteria.Field(workitem.SystemState), criteria.Literal(workitem.SystemStateClosed)):                                     2,
+		criteria.In(criteria.Field(workitem.SystemState), criteria.Literal(workitem.SystemStateNew), criteria.Literal(workitem.SystemStateClosed)): 2,
+		criteria.Not(criteria.IsNull(criteria.Field(workitem.SystemState))):                                                                        3,
+		criteria.IsNull(criteria.Field(workitem.SystemAssignees)):                                                                                  3,
+		criteria.GreaterThan(criteria.Field(workitem.SystemCreatedAt), criteria.Literal(before)):                                                   3,
+		criteria.LessThan(criteria.Field(workitem.SystemCreatedAt), criteria.Literal(before)):                                                      0,
+	}
+	for exp, expectedCount := range testData {
+		_, count, err := s.repo.List(s.ctx, criteria.And(byTitle, exp), nil, nil, nil)
+		// then
+		require.Nil(s.T(), err)
+		assert.Equal(s.T(), uint64(expectedCount), count)
+	}
+}
+
+func (s *workItemRepoBlackBoxTest) TestListSorted() {
+	// given
+	title := "TestListSorted " + uuid.NewV4().String()
+	for _, state := range []string{workitem.SystemStateNew, workitem.SystemStateOpen, workitem.SystemStateClosed} {
+		_, err := s.repo.Create(
+			s.ctx, s.spaceID, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: state,
+			}, s.creatorID)
+		require.Nil(s.T(), err, "Could not create workitem")
+	}
+	byTitle := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+	// when
+	result, _, err := s.repo.List(s.ctx, byTitle, []workitem.SortKey{{Field: workitem.SystemState}}, nil, nil)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 3)
+	assert.Equal(s.T(), workitem.SystemStateClosed, result[0].Fields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateNew, result[1].Fields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateOpen, result[2].Fields[workitem.SystemState])
+	// when
+	result, _, err = s.repo.List(s.ctx, byTitle, []workitem.SortKey{{Field: workitem.SystemCreatedAt, Descending: true}}, nil, nil)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 3)
+	assert.Equal(s.T(), workitem.SystemStateClosed, result[0].Fields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateNew, result[2].Fields[workitem.SystemState])
+}
+
+func (s *workItemRepoBlackBoxTest) TestListByCursor() {
+	// given
+	title := "TestListByCursor " + uuid.NewV4().String()
+	for _, state := range []string{workitem.SystemStateNew, workitem.SystemStateOpen, workitem.SystemStateClosed} {
+		_, err := s.repo.Create(
+			s.ctx, s.spaceID, workitem.SystemBug,
+			map[string]interface{}{
+				workitem.SystemTitle: title,
+				workitem.SystemState: state,
+			}, s.creatorID)
+		require.Nil(s.T(), err, "Could not create workitem")
+	}
+	byTitle := criteria.Equals(criteria.Field(workitem.SystemTitle), criteria.Literal(title))
+	byState := []workitem.SortKey{{Field: workitem.SystemState}}
+	// when
+	result, page, err := s.repo.ListByCursor(s.ctx, byTitle, byState, keyset.Cursor{}, 2)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 2)
+	assert.Equal(s.T(), workitem.SystemStateClosed, result[0].Fields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateNew, result[1].Fields[workitem.SystemState])
+	assert.Nil(s.T(), page.Prev)
+	require.NotNil(s.T(), page.Next)
+	// given a work item inserted before the cursor
+	_, err = s.repo.Create(
+		s.ctx, s.spaceID, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: title,
+			workitem.SystemState: workitem.SystemStateInProgress,
+		}, s.creatorID)
+	require.Nil(s.T(), err, "Could not create workitem")
+	// when
+	result, page, err = s.repo.ListByCursor(s.ctx, byTitle, byState, *page.Next, 2)
+	// then the next page is not shifted by the new work item
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 1)
+	assert.Equal(s.T(), workitem.SystemStateOpen, result[0].Fields[workitem.SystemState])
+	assert.Nil(s.T(), page.Next)
+	require.NotNil(s.T(), page.Prev)
+	// when
+	result, page, err = s.repo.ListByCursor(s.ctx, byTitle, byState, *page.Prev, 2)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), result, 2)
+	assert.Equal(s.T(), workitem.SystemStateInProgress, result[0].Fields[workitem.SystemState])
+	assert.Equal(s.T(), workitem.SystemStateNew, result[1].Fields[workitem.SystemState])
+	assert.NotNil(s.T(), page.Prev)
+	assert.NotNil(s.T(), page.Next)
+}
+
+// createRevisedWorkItem creates a work item and updates its title and state,
+// returning the work item along with its two revisions
+func (s *workItemRepoBlackBoxTest) createRevisedWorkItem() (*app.WorkItem, []workitem.Revision) {
+	wi, err := s.repo.Create(
+		s.ctx, s.spaceID, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, s.creatorID)
+	require.Nil(s.T(), err)
+	wi.Fields[workitem.SystemTitle] = "Updated Title"
+	wi.Fields[workitem.SystemState] = workitem.SystemStateOpen
+	wi, err = s.repo.Save(s.ctx, *wi, s.creatorID)
+	require.Nil(s.T(), err)
+	revisions, err := workitem.NewRevisionRepository(s.DB).List(s.ctx, wi.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 2)
+	return wi, revisions
+}
+
+func (s *workItemRepoBlackBoxTest) TestRestore() {
+	// given
+	wi, revisions := s.createRevisedWorkItem()
+	// when
+	restored, err := s.repo.Restore(s.ctx, wi.ID, revisions[0].ID, wi.Version, s.creatorID)
+	// then
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "Title", restored.Fields[workitem.SystemTitle])
+	assert.Equal(s.T(), workitem.SystemStateNew, restored.Fields[workitem.SystemState])
+	assert.Equal(s.T(), wi.Version+1, restored.Version)
+	revisions, err = workitem.NewRevisionRepository(s.DB).List(s.ctx, wi.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+	assert.Equal(s.T(), workitem.RevisionTypeUpdate, revisions[2].Type)
+	assert.Equal(s.T(), "Title", revisions[2].WorkItemFields[workitem.SystemTitle])
+}
+
+func (s *workItemRepoBlackBoxTest) TestRestoreVersionConflict() {
+	// given
+	wi, revisions := s.createRevisedWorkItem()
+	// when
+	_, err := s.repo.Restore(s.ctx, wi.ID, revisions[0].ID, wi.Version-1, s.creatorID)
+	// then
+	require.NotNil(s.T(), err)
+	assert.IsType(s.T(), errors.VersionConflictError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestRestoreRevisionOfOtherWorkItem() {
+	// given
+	_, revisions := s.createRevisedWorkItem()
+	other, err := s.repo.Create(
+		s.ctx, s.spaceID, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Other",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, s.creatorID)
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.repo.Restore(s.ctx, other.ID, revisions[0].ID, other.Version, s.creatorID)
+	// then
+	require.NotNil(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestRestoreDeleted() {
+	// given
+	wi, revisions := s.createRevisedWorkItem()
+	err := s.repo.Delete(s.ctx, wi.ID, s.creatorID)
+	require.Nil(s.T(), err)
+	// when
+	restored, err := s.repo.Restore(s.ctx, wi.ID, revisions[0].ID, wi.Version, s.creatorID)
+	// then
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), "Title", restored.Fields[workitem.SystemTitle])
+	loaded, err := s.repo.Load(s.ctx, wi.ID)
+	require.Nil(s.T(), err)
+	assert.Equal(s.T(), restored.Version, loaded.Version)
+}
+
+func (s *workItemRepoBlackBoxTest) TestRestoreDeletionRevision() {
+	// given
+	wi, _ := s.createRevisedWorkItem()
+	err := s.repo.Delete(s.ctx, wi.ID, s.creatorID)
+	require.Nil(s.T(), err)
+	revisions, err := workitem.NewRevisionRepository(s.DB).List(s.ctx, wi.ID)
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+	// when
+	_, err = s.repo.Restore(s.ctx, wi.ID, revisions[2].ID, wi.Version, s.creatorID)
+	// then
+	require.NotNil(s.T(), err)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestRestoreUnconvertibleRevision() {
+	// given a revision whose description is not a markup content
+	wi, revisions := s.createRevisedWorkItem()
+	err := s.DB.Exec(fmt.Sprintf("UPDATE %s SET work_item_fields = work_item_fields || ?::jsonb WHERE id = ?", workitem.Revision{}.TableName()),
+		`{"`+workitem.SystemDescription+`": "not a markup content"}`, revisions[0].ID).Error
+	require.Nil(s.T(), err)
+	// when
+	_, err = s.repo.Restore(s.ctx, wi.ID, revisions[0].ID, wi.Version, s.creatorID)
+	// then
+	require.NotNil(s.T(), err)
+	assert.IsType(s.T(), errors.BadParameterError{}, errs.Cause(err))
+}
+
+func (s *workItemRepoBlackBoxTest) TestPublishEventsAfterCommit() {
+	// given
+	var created []event.WorkItemCreated
+	creatorID := s.creatorID
+	event.Subscribe(event.TypeWorkItemCreated, func(ctx context.Context, e event.Event) {
+		if e := e.(event.WorkItemCreated); e.CreatorID == creatorID {
+			created = append(created, e)
+		}
+	})
+	fields := map[string]interface{}{
+		workitem.SystemTitle: "Title",
+		workitem.SystemState: workitem.SystemStateNew,
+	}
+	// when
+	var wi *app.WorkItem
+	err := models.Transactional(s.DB, func(tx *gorm.DB) error {
+		var err error
+		wi, err = workitem.NewWorkItemRepository(tx).Create(s.ctx, s.spaceID, workitem.SystemBug, fields, s.creatorID)
+		require.Nil(s.T(), err)
+		// then the event is held until the commit
+		assert.Empty(s.T(), created)
+		return nil
+	})
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), created, 1)
+	assert.Equal(s.T(), wi.ID, created[0].WorkItemID)
+	assert.Equal(s.T(), s.spaceID, created[0].SpaceID)
+	// when the transaction is rolled back
+	err = models.Transactional(s.DB, func(tx *gorm.DB) error {
+		_, err := workitem.NewWorkItemRepository(tx).Create(s.ctx, s.spaceID, workitem.SystemBug, fields, s.creatorID)
+		require.Nil(s.T(), err)
+		return errs.New("rollback")
+	})
+	// then the event is dropped
+	require.NotNil(s.T(), err)
+	assert.Len(s.T(), created, 1)
+}
@@ -1,8 +1,11 @@
 package workitem
 
 import (
+	"reflect"
+	"sort"
 	"time"
 
+	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -47,3 +50,88 @@ const (
 func (w Revision) TableName() string {
 	return revisionTableName
 }
+
+// FieldChange describes how the value of a single field changed between two revisions of a work item
+type FieldChange struct {
+	Field    string
+	OldValue interface{}
+	NewValue interface{}
+	// the elements added to and removed from a list field
+	Added   []interface{}
+	Removed []interface{}
+}
+
+// RevisionChanges is a revision of a work item along with the changes to the previous revision
+type RevisionChanges struct {
+	Revision
+	Changes []FieldChange
+}
+
+// DiffFields returns the changes from the old to the new field values, sorted by field name.
+// The values of fields defined in the given work item type are converted for use in the
+// REST API layer, so markup fields hold a rendering.MarkupContent. Fields which are not
+// defined by the type are given as stored.
+func DiffFields(wit WorkItemType, oldFields Fields, newFields Fields) ([]FieldChange, error) {
+	names := []string{}
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := []FieldChange{}
+	for _, name := range names {
+		oldValue, newValue := oldFields[name], newFields[name]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		change := FieldChange{Field: name, OldValue: oldValue, NewValue: newValue}
+		if field, ok := wit.Fields[name]; ok {
+			var err error
+			// the required check of FieldDefinition.ConvertFromModel does not apply to changes
+			if change.OldValue, err = field.Type.ConvertFromModel(oldValue); err != nil {
+				return nil, errs.WithStack(err)
+			}
+			if change.NewValue, err = field.Type.ConvertFromModel(newValue); err != nil {
+				return nil, errs.WithStack(err)
+			}
+			if listType, ok := field.Type.(ListType); ok {
+				fromModel := func(fieldType FieldType, value interface{}) (interface{}, error) {
+					return fieldType.ConvertFromModel(value)
+				}
+				if change.Added, err = convertList(fromModel, listType.ComponentType, listDifference(newValue, oldValue)); err != nil {
+					return nil, errs.WithStack(err)
+				}
+				if change.Removed, err = convertList(fromModel, listType.ComponentType, listDifference(oldValue, newValue)); err != nil {
+					return nil, errs.WithStack(err)
+				}
+			}
+		}
+		result = append(result, change)
+	}
+	return result, nil
+}
+
+// listDifference returns the elements of the list a which are not in the list b.
+// Values which are not lists are treated like empty lists.
+func listDifference(a, b interface{}) []interface{} {
+	result := []interface{}{}
+	listA, _ := a.([]interface{})
+	listB, _ := b.([]interface{})
+	for _, x := range listA {
+		found := false
+		for _, y := range listB {
+			if reflect.DeepEqual(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, x)
+		}
+	}
+	return result
+}
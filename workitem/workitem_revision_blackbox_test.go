@@ -0,0 +1,63 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/almighty/almighty-core/rendering"
+	"github.com/almighty/almighty-core/resource"
+	. "github.com/almighty/almighty-core/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFields(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	wit := WorkItemType{
+		Fields: FieldDefinitions{
+			SystemTitle:       {Type: SimpleType{Kind: KindString}},
+			SystemDescription: {Type: SimpleType{Kind: KindMarkup}},
+			SystemAssignees: {Type: ListType{
+				SimpleType:    SimpleType{Kind: KindList},
+				ComponentType: SimpleType{Kind: KindUser},
+			}},
+		},
+	}
+	oldFields := Fields{
+		SystemTitle:       "title",
+		SystemState:       "new",
+		SystemDescription: map[string]interface{}{"content": "description", "markup": rendering.SystemMarkupPlainText},
+		SystemAssignees:   []interface{}{"a", "b"},
+	}
+	newFields := Fields{
+		SystemTitle:       "title",
+		SystemDescription: map[string]interface{}{"content": "description", "markup": rendering.SystemMarkupMarkdown},
+		SystemAssignees:   []interface{}{"b", "c"},
+		"custom":          42.0,
+	}
+	// when
+	changes, err := DiffFields(wit, oldFields, newFields)
+	// then
+	require.Nil(t, err)
+	require.Len(t, changes, 4)
+	assert.Equal(t, FieldChange{Field: "custom", NewValue: 42.0}, changes[0])
+	assert.Equal(t, FieldChange{
+		Field:    SystemAssignees,
+		OldValue: []interface{}{"a", "b"},
+		NewValue: []interface{}{"b", "c"},
+		Added:    []interface{}{"c"},
+		Removed:  []interface{}{"a"},
+	}, changes[1])
+	assert.Equal(t, FieldChange{
+		Field:    SystemDescription,
+		OldValue: rendering.NewMarkupContent("description", rendering.SystemMarkupPlainText),
+		NewValue: rendering.NewMarkupContent("description", rendering.SystemMarkupMarkdown),
+	}, changes[2])
+	assert.Equal(t, FieldChange{Field: SystemState, OldValue: "new"}, changes[3])
+
+	// when
+	changes, err = DiffFields(wit, oldFields, oldFields)
+	// then
+	require.Nil(t, err)
+	assert.Empty(t, changes)
+}
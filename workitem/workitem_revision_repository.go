@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"fmt"
+	"strconv"
 
 	"time"
 
@@ -17,8 +18,12 @@ import (
 type RevisionRepository interface {
 	// Create stores a new revision for the given work item.
 	Create(ctx context.Context, modifierID uuid.UUID, revisionType RevisionType, workitem WorkItem) error
+	// Load retrieves the revision with the given ID
+	Load(ctx context.Context, revisionID uuid.UUID) (*Revision, error)
 	// List retrieves all revisions for a given work item
 	List(ctx context.Context, workitemID string) ([]Revision, error)
+	// ListChanges retrieves all revisions for a given work item along with the changes of the field values
+	ListChanges(ctx context.Context, workitemID string) ([]RevisionChanges, error)
 }
 
 // NewRevisionRepository creates a GormRevisionRepository
@@ -59,6 +64,20 @@ func (r *GormRevisionRepository) Create(ctx context.Context, modifierID uuid.UUI
 	return nil
 }
 
+// Load retrieves the revision with the given ID
+// returns NotFoundError or InternalError
+func (r *GormRevisionRepository) Load(ctx context.Context, revisionID uuid.UUID) (*Revision, error) {
+	revision := Revision{}
+	tx := r.db.Where("id = ?", revisionID).First(&revision)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item revision", revisionID.String())
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(fmt.Sprintf("failed to retrieve work item revision: %s", tx.Error.Error()))
+	}
+	return &revision, nil
+}
+
 // List retrieves all revisions for a given work item
 func (r *GormRevisionRepository) List(ctx context.Context, workitemID string) ([]Revision, error) {
 	log.Debug(nil, map[string]interface{}{}, "List all revisions for work item with ID=%v", workitemID)
@@ -68,3 +87,36 @@ func (r *GormRevisionRepository) List(ctx context.Context, workitemID string) ([
 	}
 	return revisions, nil
 }
+
+// ListChanges retrieves all revisions for a given work item along with the changes
+// of the field values compared to the previous revision. The fields of the first revision
+// are compared to an empty set of fields, deletions have no changes.
+func (r *GormRevisionRepository) ListChanges(ctx context.Context, workitemID string) ([]RevisionChanges, error) {
+	if _, err := strconv.ParseUint(workitemID, 10, 64); err != nil {
+		// treating this as a not found error: the fact that we're using number internal is implementation detail
+		return nil, errors.NewNotFoundError("work item", workitemID)
+	}
+	revisions, err := r.List(ctx, workitemID)
+	if err != nil {
+		return nil, err
+	}
+	witr := NewWorkItemTypeRepository(r.db)
+	result := make([]RevisionChanges, len(revisions))
+	previous := Fields{}
+	for i, revision := range revisions {
+		result[i] = RevisionChanges{Revision: revision, Changes: []FieldChange{}}
+		if revision.Type == RevisionTypeDelete {
+			continue
+		}
+		wit, err := witr.LoadTypeFromDB(ctx, revision.WorkItemTypeID)
+		if err != nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("failed to load type of work item revision: %s", err.Error()))
+		}
+		result[i].Changes, err = DiffFields(*wit, previous, revision.WorkItemFields)
+		if err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		previous = revision.WorkItemFields
+	}
+	return result, nil
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/almighty/almighty-core/account"
+	"github.com/almighty/almighty-core/errors"
 	"github.com/almighty/almighty-core/gormsupport/cleaner"
 	"github.com/almighty/almighty-core/gormtestsupport"
 	"github.com/almighty/almighty-core/migration"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -149,3 +151,51 @@ func (s *workItemRevisionRepositoryBlackBoxTest) TestStoreRevisions() {
 	assert.Equal(s.T(), s.testIdentity3.ID, revision4.ModifierIdentity)
 	require.Empty(s.T(), revision4.WorkItemFields)
 }
+
+func (s *workItemRevisionRepositoryBlackBoxTest) TestListChanges() {
+	req := &http.Request{Host: "localhost"}
+	params := url.Values{}
+	ctx := goa.NewContext(context.Background(), nil, req, params)
+
+	// given
+	workItem, err := s.repository.Create(
+		ctx, space.SystemSpace, workitem.SystemBug,
+		map[string]interface{}{
+			workitem.SystemTitle: "Title",
+			workitem.SystemState: workitem.SystemStateNew,
+		}, s.testIdentity1.ID)
+	require.Nil(s.T(), err)
+	workItem.Fields[workitem.SystemTitle] = "Updated Title"
+	workItem, err = s.repository.Save(
+		ctx, *workItem, s.testIdentity2.ID)
+	require.Nil(s.T(), err)
+	err = s.repository.Delete(
+		ctx, workItem.ID, s.testIdentity3.ID)
+	require.Nil(s.T(), err)
+	// when
+	revisions, err := s.revisionRepository.ListChanges(ctx, workItem.ID)
+	// then
+	require.Nil(s.T(), err)
+	require.Len(s.T(), revisions, 3)
+	// the creation sets all fields
+	assert.Equal(s.T(), workitem.RevisionTypeCreate, revisions[0].Type)
+	assert.Contains(s.T(), revisions[0].Changes, workitem.FieldChange{Field: workitem.SystemTitle, NewValue: "Title"})
+	assert.Contains(s.T(), revisions[0].Changes, workitem.FieldChange{Field: workitem.SystemState, NewValue: workitem.SystemStateNew})
+	// the update only changed the title
+	assert.Equal(s.T(), workitem.RevisionTypeUpdate, revisions[1].Type)
+	assert.Equal(s.T(), s.testIdentity2.ID, revisions[1].ModifierIdentity)
+	assert.Equal(s.T(), []workitem.FieldChange{
+		{Field: workitem.SystemTitle, OldValue: "Title", NewValue: "Updated Title"},
+	}, revisions[1].Changes)
+	// the deletion has no changes
+	assert.Equal(s.T(), workitem.RevisionTypeDelete, revisions[2].Type)
+	assert.Empty(s.T(), revisions[2].Changes)
+}
+
+func (s *workItemRevisionRepositoryBlackBoxTest) TestListChangesUnknownWorkItem() {
+	// when
+	_, err := s.revisionRepository.ListChanges(context.Background(), "foo")
+	// then
+	require.NotNil(s.T(), err)
+	assert.IsType(s.T(), errors.NotFoundError{}, errs.Cause(err))
+}